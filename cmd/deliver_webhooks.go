@@ -0,0 +1,38 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// deliverWebhooksCmd represents the deliver-webhooks command
+var deliverWebhooksCmd = &cobra.Command{
+	Use:   "deliver-webhooks",
+	Short: "send the webhook deliveries that are due",
+	Long:  `deliver-webhooks sends queued webhook deliveries to their subscribers, retrying failed ones with exponential backoff until they are given up on as dead. It keeps running every WEBHOOK_DELIVERY_INTERVAL seconds until interrupted, or makes a single pass with --once. It can run next to the server and other workers; each delivery is claimed before it is sent.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		once, _ := cmd.Flags().GetBool("once")
+		app.RunWebhookDelivery(once)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deliverWebhooksCmd)
+
+	deliverWebhooksCmd.Flags().Bool("once", false, "send the due deliveries once and exit")
+}
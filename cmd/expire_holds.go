@@ -0,0 +1,35 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// expireHoldsCmd represents the expire-holds command
+var expireHoldsCmd = &cobra.Command{
+	Use:   "expire-holds",
+	Short: "mark wallet holds past their expiry as expired",
+	Long:  `expire-holds marks every authorized wallet hold past its expiry as expired. Expired holds stop reserving funds at their expiry either way; this only updates their status.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunHoldExpiry()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(expireHoldsCmd)
+}
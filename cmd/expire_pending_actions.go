@@ -0,0 +1,35 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// expirePendingActionsCmd represents the expire-pending-actions command
+var expirePendingActionsCmd = &cobra.Command{
+	Use:   "expire-pending-actions",
+	Short: "mark admin actions no one reviewed before their expiry as expired",
+	Long:  `expire-pending-actions marks every proposed admin action still waiting for a second admin at its expiry as expired. Expired actions cannot be approved either way; this only updates their status.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunPendingActionExpiry()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(expirePendingActionsCmd)
+}
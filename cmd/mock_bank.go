@@ -0,0 +1,41 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// mockBankCmd represents the mock-bank command
+var mockBankCmd = &cobra.Command{
+	Use:   "mock-bank",
+	Short: "serve a mock bank to pay out withdrawals in development",
+	Long:  `mock-bank serves the payout API the server withdraws through, settling every payout after a delay and calling back signed with PAYOUT_CALLBACK_SECRET. Payouts to account numbers starting with 999 are rejected and those starting with 000 fail.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetString("port")
+		delay, _ := cmd.Flags().GetDuration("delay")
+		app.RunMockBank(port, delay)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockBankCmd)
+	mockBankCmd.Flags().String("port", "3002", "port to listen on")
+	mockBankCmd.Flags().Duration("delay", 5*time.Second, "how long payouts take to settle")
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// mockGatewayCmd represents the mock-gateway command
+var mockGatewayCmd = &cobra.Command{
+	Use:   "mock-gateway",
+	Short: "serve a mock payment gateway to charge top-ups in development",
+	Long:  `mock-gateway serves the payment gateway API top-ups are charged through. Opening the redirect URL of a charge pays it, or fails it with ?outcome=failed, and calls back signed with TOPUP_CALLBACK_SECRET.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetString("port")
+		app.RunMockGateway(port)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockGatewayCmd)
+	mockGatewayCmd.Flags().String("port", "3003", "port to listen on")
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// mockNATSCmd represents the mock-nats command
+var mockNATSCmd = &cobra.Command{
+	Use:   "mock-nats",
+	Short: "serve a stand-in NATS server to relay outbox events to in development",
+	Long:  `mock-nats speaks enough of the NATS client protocol for relay-outbox to publish to and for plain NATS subscribers, such as nats sub 'starworks.>', to watch the events go by. It keeps nothing.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetString("port")
+		app.RunMockNATS(port)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockNATSCmd)
+	mockNATSCmd.Flags().String("port", "4222", "port to listen on")
+}
@@ -0,0 +1,35 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// pollPayoutsCmd represents the poll-payouts command
+var pollPayoutsCmd = &cobra.Command{
+	Use:   "poll-payouts",
+	Short: "catch up on payouts the bank has not called back on",
+	Long:  `poll-payouts submits pending payouts to the bank again and asks it about payouts it accepted but has not called back on, settling those it has paid out or failed. Failed payouts are credited back to their wallets.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunPayoutPolling()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pollPayoutsCmd)
+}
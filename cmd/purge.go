@@ -0,0 +1,35 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// purgeCmd represents the purge-accounts command
+var purgeCmd = &cobra.Command{
+	Use:   "purge-accounts",
+	Short: "anonymize accounts whose deletion grace period has ended",
+	Long:  `purge-accounts anonymizes the personal data of every account whose deletion grace period has ended. Wallets and ledger entries are kept for legal retention.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunAccountPurge()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+}
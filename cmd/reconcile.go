@@ -0,0 +1,42 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "reconcile wallet balances against the ledger and the gateway settlement",
+	Long:  `reconcile recomputes every wallet's balance from its ledger entries and reports the wallets whose stored balance differs. With --settlement it also matches the charges in a payment gateway settlement file against top-ups. The report is written as JSON or CSV; discrepancies are logged as errors, posted to RECONCILE_ALERT_URL when it is set, and make the command exit with status 1.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		settlement, _ := cmd.Flags().GetString("settlement")
+		app.RunReconciliation(format, output, settlement)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reconcileCmd)
+
+	reconcileCmd.Flags().String("format", app.ReportFormatJSON, "report format, json or csv")
+	reconcileCmd.Flags().StringP("output", "o", "", "file to write the report to (default stdout)")
+	reconcileCmd.Flags().String("settlement", "", "gateway settlement file (CSV) to reconcile top-ups against")
+}
@@ -0,0 +1,38 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// relayOutboxCmd represents the relay-outbox command
+var relayOutboxCmd = &cobra.Command{
+	Use:   "relay-outbox",
+	Short: "publish the pending outbox events to the message broker",
+	Long:  `relay-outbox publishes the domain events recorded in the outbox to BROKER_URL, in the order they were recorded, retrying with exponential backoff while the broker is unavailable. Events are published at least once; consumers should skip event IDs they already saw. It keeps running every OUTBOX_RELAY_INTERVAL seconds until interrupted, or makes a single pass with --once.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		once, _ := cmd.Flags().GetBool("once")
+		app.RunOutboxRelay(once)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(relayOutboxCmd)
+
+	relayOutboxCmd.Flags().Bool("once", false, "publish the pending events once and exit")
+}
@@ -0,0 +1,35 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// resolveEscrowsCmd represents the resolve-escrows command
+var resolveEscrowsCmd = &cobra.Command{
+	Use:   "resolve-escrows",
+	Short: "release or refund escrows past their expiry",
+	Long:  `resolve-escrows applies the timeout action of every funded escrow past its expiry, releasing it to the merchant or refunding it to the buyer. Disputed escrows are left for staff to resolve.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunEscrowTimeouts()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resolveEscrowsCmd)
+}
@@ -0,0 +1,38 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// schedulesCmd represents the run-schedules command
+var schedulesCmd = &cobra.Command{
+	Use:   "run-schedules",
+	Short: "pay the scheduled and recurring payments that are due",
+	Long:  `run-schedules pays every scheduled and recurring payment that is due, retrying failed ones with backoff. It keeps running every SCHEDULER_INTERVAL seconds until interrupted, or makes a single pass with --once. It can run next to the server and other schedulers; each schedule is locked while it is paid.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		once, _ := cmd.Flags().GetBool("once")
+		app.RunScheduler(once)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schedulesCmd)
+
+	schedulesCmd.Flags().Bool("once", false, "run the due schedules once and exit")
+}
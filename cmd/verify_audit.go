@@ -0,0 +1,35 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// verifyAuditCmd represents the verify-audit command
+var verifyAuditCmd = &cobra.Command{
+	Use:   "verify-audit",
+	Short: "check that no audit log entry was changed or deleted",
+	Long:  `verify-audit chains the pending audit log entries, then walks the chain from the first entry to the head, recomputing every hash. It prints the result and exits with status 1 when an entry was changed or entries are missing.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunVerifyAudit()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyAuditCmd)
+}
@@ -0,0 +1,37 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"github.com/cecepsprd/starworks-test/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// walletEventsCmd represents the wallet-events command
+var walletEventsCmd = &cobra.Command{
+	Use:       "wallet-events [import|rebuild|snapshot]",
+	Short:     "maintain the event sourced wallet store",
+	Long:      `wallet-events maintains the wallet event streams used when WALLET_STORE=eventsourced. import starts the streams of wallets that have none from their ledger entries, to switch over from WALLET_STORE=mysql; run it with the server stopped. rebuild replays every stream and writes the wallet and ledger tables back from it. snapshot snapshots every stream at its current version, on top of the snapshots taken every WALLET_SNAPSHOT_INTERVAL events.`,
+	ValidArgs: []string{app.WalletEventsImport, app.WalletEventsRebuild, app.WalletEventsSnapshot},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunWalletEvents(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(walletEventsCmd)
+}
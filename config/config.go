@@ -18,6 +18,52 @@ type App struct {
 	ContextTimeout int `json:"context_timeout "`
 	// JWTSecret is a private jwt secret key
 	JWTSecret string `json:"jwt_secret"`
+	// AccountDeletionGraceDays is how many days an account deletion can be cancelled
+	AccountDeletionGraceDays int `json:"account_deletion_grace_days"`
+	// BlobStorePath is the directory uploaded documents are kept in
+	BlobStorePath string `json:"blob_store_path"`
+	// RevenueWalletID is the wallet fees are collected in
+	RevenueWalletID int64 `json:"revenue_wallet_id"`
+	// EscrowWalletID is the wallet escrowed funds are kept in until they are released or refunded
+	EscrowWalletID int64 `json:"escrow_wallet_id"`
+	// SchedulerInterval is how many seconds the server waits between runs of due payment schedules, 0 to leave them to run-schedules
+	SchedulerInterval int `json:"scheduler_interval"`
+	// PayoutProviderURL is the base URL of the bank API withdrawals are paid out through
+	PayoutProviderURL string `json:"payout_provider_url"`
+	// PayoutCallbackURL is the URL the bank calls back on once a payout is settled
+	PayoutCallbackURL string `json:"payout_callback_url"`
+	// PayoutCallbackSecret is the secret the bank signs its callbacks with
+	PayoutCallbackSecret string `json:"payout_callback_secret"`
+	// PaymentGatewayURL is the base URL of the payment gateway API top-ups are charged through
+	PaymentGatewayURL string `json:"payment_gateway_url"`
+	// TopUpCallbackURL is the URL the payment gateway calls back on once a charge is paid or fails
+	TopUpCallbackURL string `json:"top_up_callback_url"`
+	// TopUpCallbackSecret is the secret the payment gateway signs its callbacks with
+	TopUpCallbackSecret string `json:"top_up_callback_secret"`
+	// ReconcileInterval is how many seconds the server waits between reconciliations of wallet balances against the ledger, 0 to leave them to reconcile
+	ReconcileInterval int `json:"reconcile_interval"`
+	// ReconcileReportPath is the directory the server keeps its reconciliation reports in
+	ReconcileReportPath string `json:"reconcile_report_path"`
+	// ReconcileAlertURL is the URL reconciliation reports with discrepancies are posted to, empty to only log them
+	ReconcileAlertURL string `json:"reconcile_alert_url"`
+	// StatementSigningSecret is the secret account statements are signed with
+	StatementSigningSecret string `json:"statement_signing_secret"`
+	// WebhookDeliveryInterval is how many seconds the server waits between sending due webhook deliveries, 0 to leave them to deliver-webhooks
+	WebhookDeliveryInterval int `json:"webhook_delivery_interval"`
+	// BrokerURL is the message broker outbox events are published to, nats://host:port or empty to publish them in memory
+	BrokerURL string `json:"broker_url"`
+	// OutboxRelayInterval is how many seconds the server waits between publishing pending outbox events, 0 to leave them to relay-outbox
+	OutboxRelayInterval int `json:"outbox_relay_interval"`
+	// WalletStore is how wallets are stored, mysql or eventsourced
+	WalletStore string `json:"wallet_store"`
+	// WalletSnapshotInterval is how many events of an event sourced wallet are kept between snapshots, 0 for none
+	WalletSnapshotInterval int64 `json:"wallet_snapshot_interval"`
+	// AuditSealInterval is how many seconds the server waits between chaining new audit log entries, 0 to leave them unsealed; verifying the audit log fails on entries left unsealed for longer
+	AuditSealInterval int `json:"audit_seal_interval"`
+	// PendingActionTTLHours is how many hours a proposed admin action waits for a second admin before it expires
+	PendingActionTTLHours int `json:"pending_action_ttl_hours"`
+	// EscrowTimeoutInterval is how many seconds the server waits between resolving escrows past their expiry, 0 to leave them to resolve-escrows
+	EscrowTimeoutInterval int `json:"escrow_timeout_interval"`
 }
 
 type MysqlDB struct {
@@ -39,12 +85,35 @@ type Config struct {
 func NewConfig() Config {
 	return Config{
 		App: App{
-			Name:           viper.GetString("APP_NAME"),
-			HTTPPort:       viper.GetString("PORT"),
-			LogLevel:       viper.GetInt("LOG_LEVEL"),
-			LogTimeFormat:  viper.GetString("LOG_TIME_FORMAT"),
-			ContextTimeout: viper.GetInt("CONTEXT_TIMEOUT"),
-			JWTSecret:      viper.GetString("APP_JWT_SECRET"),
+			Name:                     viper.GetString("APP_NAME"),
+			HTTPPort:                 viper.GetString("PORT"),
+			LogLevel:                 viper.GetInt("LOG_LEVEL"),
+			LogTimeFormat:            viper.GetString("LOG_TIME_FORMAT"),
+			ContextTimeout:           viper.GetInt("CONTEXT_TIMEOUT"),
+			JWTSecret:                viper.GetString("APP_JWT_SECRET"),
+			AccountDeletionGraceDays: viper.GetInt("ACCOUNT_DELETION_GRACE_DAYS"),
+			BlobStorePath:            viper.GetString("BLOB_STORE_PATH"),
+			RevenueWalletID:          viper.GetInt64("REVENUE_WALLET_ID"),
+			EscrowWalletID:           viper.GetInt64("ESCROW_WALLET_ID"),
+			SchedulerInterval:        viper.GetInt("SCHEDULER_INTERVAL"),
+			PayoutProviderURL:        viper.GetString("PAYOUT_PROVIDER_URL"),
+			PayoutCallbackURL:        viper.GetString("PAYOUT_CALLBACK_URL"),
+			PayoutCallbackSecret:     viper.GetString("PAYOUT_CALLBACK_SECRET"),
+			PaymentGatewayURL:        viper.GetString("PAYMENT_GATEWAY_URL"),
+			TopUpCallbackURL:         viper.GetString("TOPUP_CALLBACK_URL"),
+			TopUpCallbackSecret:      viper.GetString("TOPUP_CALLBACK_SECRET"),
+			ReconcileInterval:        viper.GetInt("RECONCILE_INTERVAL"),
+			ReconcileReportPath:      viper.GetString("RECONCILE_REPORT_PATH"),
+			ReconcileAlertURL:        viper.GetString("RECONCILE_ALERT_URL"),
+			StatementSigningSecret:   viper.GetString("STATEMENT_SIGNING_SECRET"),
+			WebhookDeliveryInterval:  viper.GetInt("WEBHOOK_DELIVERY_INTERVAL"),
+			BrokerURL:                viper.GetString("BROKER_URL"),
+			OutboxRelayInterval:      viper.GetInt("OUTBOX_RELAY_INTERVAL"),
+			WalletStore:              viper.GetString("WALLET_STORE"),
+			WalletSnapshotInterval:   viper.GetInt64("WALLET_SNAPSHOT_INTERVAL"),
+			AuditSealInterval:        viper.GetInt("AUDIT_SEAL_INTERVAL"),
+			PendingActionTTLHours:    viper.GetInt("PENDING_ACTION_TTL_HOURS"),
+			EscrowTimeoutInterval:    viper.GetInt("ESCROW_TIMEOUT_INTERVAL"),
 		},
 		MysqlDB: MysqlDB{
 			Name:     viper.GetString("MYSQL_DB_NAME"),
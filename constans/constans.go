@@ -6,6 +6,8 @@ type contextKey string
 
 const (
 	CtxUserAgent = contextKey("user-agent")
+	CtxClientIP  = contextKey("client-ip")
+	CtxActorID   = contextKey("actor-id")
 )
 
 const (
@@ -13,9 +15,46 @@ const (
 )
 
 var (
-	ErrInternalServerError  = errors.New("internal server error")
-	ErrNotFound             = errors.New("data not found")
-	ErrUserAlreadyExist     = errors.New("user already exist")
-	ErrBadParamInput        = errors.New("given param is not valid")
-	ErrWrongEmailOrPassword = errors.New("wrong email/password")
+	ErrInternalServerError     = errors.New("internal server error")
+	ErrNotFound                = errors.New("data not found")
+	ErrUserAlreadyExist        = errors.New("user already exist")
+	ErrBadParamInput           = errors.New("given param is not valid")
+	ErrWrongEmailOrPassword    = errors.New("wrong email/password")
+	ErrBalanceNotZero          = errors.New("wallet balance must be zero to delete the account")
+	ErrDeletionRequested       = errors.New("account deletion already requested")
+	ErrAccountDeleted          = errors.New("this account has been deleted")
+	ErrForbidden               = errors.New("you are not allowed to perform this action")
+	ErrUnsupportedDocument     = errors.New("document must be a jpeg, png or pdf file")
+	ErrKYCLevelReached         = errors.New("account is already verified at the requested level")
+	ErrDocumentReviewed        = errors.New("document has already been reviewed")
+	ErrBalanceLimitExceeded    = errors.New("balance limit for your verification level exceeded")
+	ErrDailyLimitExceeded      = errors.New("daily transaction limit for your verification level exceeded")
+	ErrInsufficientBalance     = errors.New("your current balance is insufficient")
+	ErrTransactionDenied       = errors.New("transaction declined by risk assessment")
+	ErrHeldTransactionReviewed = errors.New("held transaction has already been reviewed")
+	ErrHoldNotActive           = errors.New("hold is no longer active")
+	ErrCaptureExceedsHold      = errors.New("capture amount exceeds the authorized amount")
+	ErrNotRefundable           = errors.New("only payments can be refunded")
+	ErrRefundExceedsPayment    = errors.New("refund exceeds the amount left to refund on this payment")
+	ErrMerchantNotActive       = errors.New("merchant is not active")
+	ErrFeeExceedsAmount        = errors.New("fee exceeds the transaction amount")
+	ErrRevenueWalletNotSet     = errors.New("fee revenue wallet is not configured")
+	ErrPaymentRequestNotActive = errors.New("payment request is no longer pending")
+	ErrInvalidQRPayload        = errors.New("qr payload is invalid")
+	ErrScheduleNotActive       = errors.New("schedule is no longer active")
+	ErrBillNotActive           = errors.New("bill is no longer open")
+	ErrEscrowNotFunded         = errors.New("escrow is no longer funded")
+	ErrEscrowWalletNotSet      = errors.New("escrow wallet is not configured")
+	ErrPayoutFinalized         = errors.New("payout has already been settled differently")
+	ErrTopUpFinalized          = errors.New("top-up has already been settled differently")
+	ErrInvalidSignature        = errors.New("invalid signature")
+	ErrConcurrentUpdate        = errors.New("wallet was changed by another operation, please try again")
+	ErrWalletFrozen            = errors.New("wallet is frozen")
+	ErrWalletClosed            = errors.New("wallet is closed")
+	ErrWalletStatusTransition  = errors.New("wallet cannot move to that status from its current one")
+	ErrWalletNotEmpty          = errors.New("wallet balance must be zero, with nothing held, to close it")
+	ErrApprovalRequired        = errors.New("this change must be proposed and approved by a second admin")
+	ErrPendingActionReviewed   = errors.New("pending action has already been reviewed")
+	ErrPendingActionExpired    = errors.New("pending action has expired")
+	ErrSelfApproval            = errors.New("pending action must be reviewed by an admin other than the one who proposed it")
 )
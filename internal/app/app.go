@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"net/http"
 	"os"
@@ -10,9 +11,15 @@ import (
 	"time"
 
 	"github.com/cecepsprd/starworks-test/config"
+	"github.com/cecepsprd/starworks-test/internal/event"
+	"github.com/cecepsprd/starworks-test/internal/gateway"
 	"github.com/cecepsprd/starworks-test/internal/handler"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/payout"
 	"github.com/cecepsprd/starworks-test/internal/repository"
 	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/internal/storage"
+	"github.com/cecepsprd/starworks-test/internal/webhook"
 	"github.com/cecepsprd/starworks-test/utils/logger"
 	"github.com/cecepsprd/starworks-test/utils/validate"
 	en "github.com/go-playground/validator/v10/translations/en"
@@ -21,7 +28,13 @@ import (
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
-func RunServer() {
+// eventReplaySize is how many wallet events the server keeps for clients
+// that reconnect to the event stream.
+const eventReplaySize = 10000
+
+// bootstrap loads the configuration, connects to the database and
+// initializes the logger shared by every entry point.
+func bootstrap() (config.Config, *sql.DB) {
 	cfg := config.NewConfig()
 
 	db, err := cfg.MysqlConnect()
@@ -33,9 +46,55 @@ func RunServer() {
 		log.Fatal(err)
 	}
 
+	return cfg, db
+}
+
+// newWalletService builds the wallet service for the entry points that run
+// outside the server. Nobody subscribes to its events, so the changes it
+// makes are not streamed.
+func newWalletService(cfg config.Config, db *sql.DB) service.WalletService {
+	userRepository := repository.NewUserRepository(db)
+	riskRepository := repository.NewRiskRepository(db)
+
+	return service.NewWalletService(
+		newWalletRepository(cfg, db),
+		userRepository,
+		riskRepository,
+		repository.NewHoldRepository(db),
+		repository.NewRefundRepository(db),
+		repository.NewMerchantRepository(db),
+		repository.NewPaymentRequestRepository(db),
+		repository.NewEscrowRepository(db),
+		service.NewLimitService(repository.NewLimitRepository(db)),
+		service.NewFeeService(repository.NewFeeRepository(db), cfg.App.RevenueWalletID),
+		service.NewRuleRiskEngine(userRepository, riskRepository, service.DefaultRiskWeights),
+		cfg.App.EscrowWalletID,
+		event.NewBus(0),
+		newWebhookService(db),
+		newOutboxService(cfg, db),
+		newAuditService(cfg, db),
+	)
+}
+
+// newPayoutService builds the payout service for the entry points that run
+// outside the server.
+func newPayoutService(cfg config.Config, db *sql.DB) service.PayoutService {
+	return service.NewPayoutService(
+		repository.NewPayoutRepository(db),
+		newWalletService(cfg, db),
+		payout.NewHTTPProvider(cfg.App.PayoutProviderURL),
+		cfg.App.PayoutCallbackURL,
+		cfg.App.PayoutCallbackSecret,
+	)
+}
+
+func RunServer() {
+	cfg, db := bootstrap()
+
 	e := echo.New()
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
+	e.Use(m.RequestContext())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
 		AllowMethods: []string{
@@ -55,13 +114,71 @@ func RunServer() {
 	e.Validator = customValidator
 
 	userRepository := repository.NewUserRepository(db)
-	walletRepository := repository.NewWalletRepository(db)
-
-	userService := service.NewUserService(userRepository, walletRepository, cfg.App.JWTSecret, timeoutContext)
-	walletService := service.NewWalletService(walletRepository)
+	walletRepository := newWalletRepository(cfg, db)
+	kycRepository := repository.NewKYCRepository(db)
+	limitRepository := repository.NewLimitRepository(db)
+	riskRepository := repository.NewRiskRepository(db)
+	holdRepository := repository.NewHoldRepository(db)
+	refundRepository := repository.NewRefundRepository(db)
+	merchantRepository := repository.NewMerchantRepository(db)
+	feeRepository := repository.NewFeeRepository(db)
+	paymentRequestRepository := repository.NewPaymentRequestRepository(db)
+	scheduleRepository := repository.NewScheduleRepository(db)
+	billRepository := repository.NewBillRepository(db)
+	escrowRepository := repository.NewEscrowRepository(db)
+	payoutRepository := repository.NewPayoutRepository(db)
+	topUpRepository := repository.NewTopUpRepository(db)
+	statementRepository := repository.NewStatementRepository(db)
+	webhookRepository := repository.NewWebhookRepository(db)
+	outboxRepository := repository.NewOutboxRepository(db)
+	auditRepository := repository.NewAuditRepository(db)
+	approvalRepository := repository.NewApprovalRepository(db)
+
+	blobStore := storage.NewLocalBlobStore(cfg.App.BlobStorePath)
+	eventBus := event.NewBus(eventReplaySize)
+
+	deletionGracePeriod := time.Duration(cfg.App.AccountDeletionGraceDays) * 24 * time.Hour
+
+	webhookService := service.NewWebhookService(webhookRepository, webhook.NewHTTPSender())
+	outboxService := service.NewOutboxService(outboxRepository, newBroker(cfg))
+	auditService := service.NewAuditService(auditRepository, time.Duration(cfg.App.AuditSealInterval)*time.Second)
+	userService := service.NewUserService(userRepository, walletRepository, kycRepository, blobStore, webhookService, outboxService, auditService, cfg.App.JWTSecret, timeoutContext, deletionGracePeriod)
+	m.CheckAccounts(userService)
+	limitService := service.NewLimitService(limitRepository)
+	feeService := service.NewFeeService(feeRepository, cfg.App.RevenueWalletID)
+	riskEngine := service.NewRuleRiskEngine(userRepository, riskRepository, service.DefaultRiskWeights)
+	walletService := service.NewWalletService(walletRepository, userRepository, riskRepository, holdRepository, refundRepository, merchantRepository, paymentRequestRepository, escrowRepository, limitService, feeService, riskEngine, cfg.App.EscrowWalletID, eventBus, webhookService, outboxService, auditService)
+	kycService := service.NewKYCService(kycRepository, userRepository, blobStore, auditService)
+	merchantService := service.NewMerchantService(merchantRepository, walletRepository)
+	paymentRequestService := service.NewPaymentRequestService(paymentRequestRepository, userRepository, walletRepository)
+	scheduleService := service.NewScheduleService(scheduleRepository, userRepository, merchantRepository, walletService)
+	billService := service.NewBillService(billRepository, paymentRequestRepository, userRepository, walletRepository, webhookService, outboxService)
+	topUpService := service.NewTopUpService(topUpRepository, walletService, gateway.NewHTTPGateway(cfg.App.PaymentGatewayURL), cfg.App.TopUpCallbackURL, cfg.App.TopUpCallbackSecret)
+	statementService := service.NewStatementService(walletRepository, statementRepository, cfg.App.StatementSigningSecret)
+	payoutService := service.NewPayoutService(payoutRepository, walletService, payout.NewHTTPProvider(cfg.App.PayoutProviderURL), cfg.App.PayoutCallbackURL, cfg.App.PayoutCallbackSecret)
+	adminService := service.NewAdminService(userRepository, walletRepository)
+	approvalService := service.NewApprovalService(approvalRepository, walletService, limitService, auditService, time.Duration(cfg.App.PendingActionTTLHours)*time.Hour)
+
+	e.Use(m.AuditAdminActions(auditService))
 
 	handler.NewUserHandler(e, userService)
-	handler.NewWalletHandler(e, walletService)
+	handler.NewWalletHandler(e, walletService, approvalService, topUpService, payoutService)
+	handler.NewTopUpHandler(e, topUpService)
+	handler.NewStatementHandler(e, statementService)
+	handler.NewHoldHandler(e, walletService)
+	handler.NewRefundHandler(e, walletService)
+	handler.NewMerchantHandler(e, merchantService)
+	handler.NewPaymentRequestHandler(e, paymentRequestService, walletService)
+	handler.NewScheduleHandler(e, scheduleService)
+	handler.NewBillHandler(e, billService)
+	handler.NewEscrowHandler(e, walletService)
+	handler.NewPayoutHandler(e, payoutService)
+	handler.NewKYCHandler(e, kycService)
+	handler.NewLimitHandler(e, limitService, approvalService)
+	handler.NewFeeHandler(e, feeService)
+	handler.NewWebhookHandler(e, webhookService)
+	handler.NewAdminHandler(e, adminService, auditService, approvalService)
+	handler.NewApprovalHandler(e, approvalService)
 
 	e.GET("/api/check", func(c echo.Context) error {
 		return c.String(http.StatusOK, "OK!")
@@ -79,6 +196,34 @@ func RunServer() {
 		}
 	}()
 
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+
+	if cfg.App.SchedulerInterval > 0 {
+		go runScheduler(schedulerCtx, scheduleService, time.Duration(cfg.App.SchedulerInterval)*time.Second)
+	}
+
+	if cfg.App.WebhookDeliveryInterval > 0 {
+		go runWebhookDelivery(schedulerCtx, webhookService, time.Duration(cfg.App.WebhookDeliveryInterval)*time.Second)
+	}
+
+	if cfg.App.OutboxRelayInterval > 0 {
+		go runOutboxRelay(schedulerCtx, outboxService, time.Duration(cfg.App.OutboxRelayInterval)*time.Second)
+	}
+
+	if cfg.App.AuditSealInterval > 0 {
+		go runAuditSealer(schedulerCtx, auditService, time.Duration(cfg.App.AuditSealInterval)*time.Second)
+	}
+
+	if cfg.App.EscrowTimeoutInterval > 0 {
+		go runEscrowTimeouts(schedulerCtx, walletService, time.Duration(cfg.App.EscrowTimeoutInterval)*time.Second)
+	}
+
+	if cfg.App.ReconcileInterval > 0 {
+		reconciliationService := service.NewReconciliationService(walletRepository, topUpRepository)
+		go runReconciler(schedulerCtx, reconciliationService, time.Duration(cfg.App.ReconcileInterval)*time.Second, cfg.App.ReconcileReportPath, cfg.App.ReconcileAlertURL)
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	signal.Notify(quit, syscall.SIGTERM)
@@ -88,6 +233,8 @@ func RunServer() {
 
 	log.Println("server shutdown of 5 second.")
 
+	stopScheduler()
+
 	// gracefully shutdown the server, waiting max 5 seconds for current operations to complete
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// RunPendingActionExpiry marks the admin actions no second admin reviewed
+// before their expiry as expired. It is meant to be run periodically, e.g.
+// from cron.
+func RunPendingActionExpiry() {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	approvalService := service.NewApprovalService(
+		repository.NewApprovalRepository(db),
+		newWalletService(cfg, db),
+		service.NewLimitService(repository.NewLimitRepository(db)),
+		newAuditService(cfg, db),
+		time.Duration(cfg.App.PendingActionTTLHours)*time.Hour,
+	)
+
+	expired, err := approvalService.ExpireActions(context.Background())
+	if err != nil {
+		log.Fatal("error expiring pending actions: ", err)
+	}
+
+	logger.Log.Info(fmt.Sprintf("%d pending action(s) expired", expired))
+}
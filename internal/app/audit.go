@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/config"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// newAuditService builds the audit service, which every entry point that
+// changes users or balances records the changes through.
+func newAuditService(cfg config.Config, db *sql.DB) service.AuditService {
+	return service.NewAuditService(repository.NewAuditRepository(db), time.Duration(cfg.App.AuditSealInterval)*time.Second)
+}
+
+// RunVerifyAudit seals the pending audit entries, then checks the chain of
+// the audit log and prints the result. It exits with status 1 when the chain
+// is broken, so it can be run from cron.
+func RunVerifyAudit() {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	auditService := newAuditService(cfg, db)
+	ctx := context.Background()
+
+	if _, err := auditService.Seal(ctx); err != nil {
+		log.Fatal("error sealing audit log: ", err)
+	}
+
+	result, err := auditService.Verify(ctx)
+	if err != nil {
+		log.Fatal("error verifying audit log: ", err)
+	}
+
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+
+	if !result.Valid {
+		logger.Log.Error(fmt.Sprintf("audit log broken at entry %d: %s", result.BrokenAt, result.Problem))
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// runAuditSealer chains the entries added to the audit log every interval
+// until ctx is cancelled. Entries logged outside a transaction are chained
+// right away; this picks up those logged in one.
+func runAuditSealer(ctx context.Context, auditService service.AuditService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := auditService.Seal(ctx); err != nil {
+			logger.Log.Error("error sealing audit log: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// RunEscrowTimeouts releases or refunds the funded escrows past their expiry,
+// as each was set up to. The server does this every configured interval;
+// this runs a single pass, e.g. from cron when the server is not set to.
+func RunEscrowTimeouts() {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	walletService := newWalletService(cfg, db)
+
+	resolved, err := walletService.ResolveTimedOutEscrows(context.Background())
+	if err != nil {
+		log.Fatal("error resolving escrows: ", err)
+	}
+
+	logger.Log.Info(fmt.Sprintf("%d escrow(s) resolved on timeout", resolved))
+}
+
+// runEscrowTimeouts resolves the escrows past their expiry every interval
+// until ctx is done.
+func runEscrowTimeouts(ctx context.Context, walletService service.WalletService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		resolved, err := walletService.ResolveTimedOutEscrows(ctx)
+		if err != nil {
+			logger.Log.Error("error resolving escrows: " + err.Error())
+		} else if resolved > 0 {
+			logger.Log.Info(fmt.Sprintf("%d escrow(s) resolved on timeout", resolved))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// RunHoldExpiry marks the wallet holds past their expiry as expired. It is
+// meant to be run periodically, e.g. from cron.
+func RunHoldExpiry() {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	walletService := newWalletService(cfg, db)
+
+	expired, err := walletService.ExpireHolds(context.Background())
+	if err != nil {
+		log.Fatal("error expiring holds: ", err)
+	}
+
+	logger.Log.Info(fmt.Sprintf("%d hold(s) expired", expired))
+}
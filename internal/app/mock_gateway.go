@@ -0,0 +1,22 @@
+package app
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/cecepsprd/starworks-test/config"
+	"github.com/cecepsprd/starworks-test/internal/gateway"
+)
+
+// RunMockGateway serves a mock payment gateway for top-ups to be charged
+// through in development. It calls back signed with the configured top-up
+// callback secret.
+func RunMockGateway(port string) {
+	cfg := config.NewConfig()
+
+	log.Printf("mock payment gateway listening on :%s", port)
+
+	if err := http.ListenAndServe(":"+port, gateway.NewMockGateway(cfg.App.TopUpCallbackSecret)); err != nil {
+		log.Fatal("error starting mock payment gateway: ", err)
+	}
+}
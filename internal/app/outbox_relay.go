@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/config"
+	"github.com/cecepsprd/starworks-test/internal/broker"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// defaultOutboxRelayInterval is used by relay-outbox when no interval is
+// configured.
+const defaultOutboxRelayInterval = 5 * time.Second
+
+// newBroker connects to the configured message broker.
+func newBroker(cfg config.Config) broker.Broker {
+	b, err := broker.New(cfg.App.BrokerURL)
+	if err != nil {
+		log.Fatal("error configuring broker: ", err)
+	}
+
+	return b
+}
+
+// newOutboxService builds the outbox service, which every entry point that
+// changes wallets or users records its events through.
+func newOutboxService(cfg config.Config, db *sql.DB) service.OutboxService {
+	return service.NewOutboxService(repository.NewOutboxRepository(db), newBroker(cfg))
+}
+
+// RunOutboxRelay publishes the pending outbox events to the broker. With
+// once it makes a single pass, e.g. from cron; otherwise it keeps publishing
+// them every configured interval until interrupted.
+func RunOutboxRelay(once bool) {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	outboxService := newOutboxService(cfg, db)
+
+	if once {
+		relayOutbox(context.Background(), outboxService)
+		return
+	}
+
+	interval := time.Duration(cfg.App.OutboxRelayInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultOutboxRelayInterval
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runOutboxRelay(ctx, outboxService, interval)
+}
+
+// runOutboxRelay publishes the pending outbox events every interval until
+// ctx is done.
+func runOutboxRelay(ctx context.Context, outboxService service.OutboxService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		relayOutbox(ctx, outboxService)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func relayOutbox(ctx context.Context, outboxService service.OutboxService) {
+	published, err := outboxService.Relay(ctx)
+	if err != nil {
+		logger.Log.Error("error relaying outbox: " + err.Error())
+		return
+	}
+
+	if published > 0 {
+		logger.Log.Info(fmt.Sprintf("%d outbox event(s) published", published))
+	}
+}
+
+// RunMockNATS serves a stand-in for a NATS server for the outbox relay to
+// publish to in development.
+func RunMockNATS(port string) {
+	l, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Fatal("error starting mock nats: ", err)
+	}
+
+	log.Printf("mock nats listening on :%s", port)
+
+	if err = broker.NewMockNATS().Serve(l); err != nil {
+		log.Fatal("error serving mock nats: ", err)
+	}
+}
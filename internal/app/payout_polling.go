@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/config"
+	"github.com/cecepsprd/starworks-test/internal/payout"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// RunPayoutPolling submits again the payouts the bank has not accepted and
+// settles those it has not called back on. It is meant to be run
+// periodically, e.g. from cron.
+func RunPayoutPolling() {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	payoutService := newPayoutService(cfg, db)
+
+	settled, err := payoutService.Poll(context.Background())
+	if err != nil {
+		log.Fatal("error polling payouts: ", err)
+	}
+
+	logger.Log.Info(fmt.Sprintf("%d payout(s) settled", settled))
+}
+
+// RunMockBank serves a mock bank for the payout provider to talk to in
+// development. It settles every payout after delay and calls back signed
+// with the configured callback secret.
+func RunMockBank(port string, delay time.Duration) {
+	cfg := config.NewConfig()
+
+	log.Printf("mock bank listening on :%s", port)
+
+	if err := http.ListenAndServe(":"+port, payout.NewMockBank(cfg.App.PayoutCallbackSecret, delay)); err != nil {
+		log.Fatal("error starting mock bank: ", err)
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/internal/storage"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// RunAccountPurge anonymizes the accounts whose deletion grace period has
+// ended. It is meant to be run periodically, e.g. from cron.
+func RunAccountPurge() {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	timeoutContext := time.Duration(cfg.App.ContextTimeout) * time.Second
+	deletionGracePeriod := time.Duration(cfg.App.AccountDeletionGraceDays) * 24 * time.Hour
+
+	userService := service.NewUserService(
+		repository.NewUserRepository(db),
+		newWalletRepository(cfg, db),
+		repository.NewKYCRepository(db),
+		storage.NewLocalBlobStore(cfg.App.BlobStorePath),
+		newWebhookService(db),
+		newOutboxService(cfg, db),
+		newAuditService(cfg, db),
+		cfg.App.JWTSecret,
+		timeoutContext,
+		deletionGracePeriod,
+	)
+
+	purged, err := userService.PurgeDeletedAccounts(context.Background())
+	if err != nil {
+		log.Fatal("error purging accounts: ", err)
+	}
+
+	logger.Log.Info(fmt.Sprintf("%d account(s) purged", purged))
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/gateway"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// Report formats written by reconciliation.
+const (
+	ReportFormatJSON = "json"
+	ReportFormatCSV  = "csv"
+)
+
+const reconcileAlertTimeout = 10 * time.Second
+
+// RunReconciliation reconciles wallet balances against the ledger, and top-ups
+// against the gateway settlement file at settlementPath when it is given, and
+// writes the report in format to output, or stdout when it is empty. It
+// alerts on discrepancies and exits with status 1 when there are any, so it
+// can be run from cron.
+func RunReconciliation(format, output, settlementPath string) {
+	if format != ReportFormatJSON && format != ReportFormatCSV {
+		log.Fatalf("unknown report format %q, want %s or %s", format, ReportFormatJSON, ReportFormatCSV)
+	}
+
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	var settlement []gateway.SettlementRecord
+	if settlementPath != "" {
+		file, err := os.Open(settlementPath)
+		if err != nil {
+			log.Fatal("error opening settlement file: ", err)
+		}
+
+		settlement, err = gateway.ParseSettlement(file)
+		file.Close()
+		if err != nil {
+			log.Fatal("error reading settlement file: ", err)
+		}
+	}
+
+	reconciliationService := service.NewReconciliationService(newWalletRepository(cfg, db), repository.NewTopUpRepository(db))
+
+	report, err := reconciliationService.Reconcile(context.Background(), settlement)
+	if err != nil {
+		log.Fatal("error reconciling: ", err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			log.Fatal("error creating report: ", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err = writeReconciliationReport(out, format, report); err != nil {
+		log.Fatal("error writing report: ", err)
+	}
+
+	alertDiscrepancies(cfg.App.ReconcileAlertURL, report)
+
+	if len(report.Discrepancies) > 0 {
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// runReconciler reconciles wallet balances against the ledger every interval
+// until ctx is done, keeping each report as JSON in dir.
+func runReconciler(ctx context.Context, reconciliationService service.ReconciliationService, interval time.Duration, dir, alertURL string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := reconciliationService.Reconcile(ctx, nil)
+		if err != nil {
+			logger.Log.Error("error reconciling: " + err.Error())
+			continue
+		}
+
+		if err = saveReconciliationReport(dir, report); err != nil {
+			logger.Log.Error("error saving reconciliation report: " + err.Error())
+		}
+
+		alertDiscrepancies(alertURL, report)
+	}
+}
+
+func saveReconciliationReport(dir string, report *model.ReconciliationReport) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filepath.Join(dir, "reconcile-"+report.GeneratedAt.Format("20060102-150405")+".json"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return writeReconciliationReport(file, ReportFormatJSON, report)
+}
+
+func writeReconciliationReport(w io.Writer, format string, report *model.ReconciliationReport) error {
+	switch format {
+	case ReportFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case ReportFormatCSV:
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"kind", "wallet_id", "reference", "expected", "actual", "detail"})
+		for _, d := range report.Discrepancies {
+			writer.Write([]string{
+				d.Kind,
+				strconv.FormatInt(d.WalletID, 10),
+				d.Reference,
+				strconv.FormatFloat(d.Expected, 'f', -1, 64),
+				strconv.FormatFloat(d.Actual, 'f', -1, 64),
+				d.Detail,
+			})
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// alertDiscrepancies logs the discrepancies in a report as errors and, when
+// alertURL is set, posts the report to it.
+func alertDiscrepancies(alertURL string, report *model.ReconciliationReport) {
+	if len(report.Discrepancies) == 0 {
+		logger.Log.Info(fmt.Sprintf("reconciliation found no discrepancies over %d wallet(s) and %d settlement record(s)", report.WalletsChecked, report.SettlementRecords))
+		return
+	}
+
+	for _, d := range report.Discrepancies {
+		logger.Log.Error(fmt.Sprintf("reconciliation: %s wallet=%d reference=%s expected=%.0f actual=%.0f: %s", d.Kind, d.WalletID, d.Reference, d.Expected, d.Actual, d.Detail))
+	}
+
+	if alertURL == "" {
+		return
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return
+	}
+
+	client := &http.Client{Timeout: reconcileAlertTimeout}
+
+	res, err := client.Post(alertURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.Log.Error("error sending reconciliation alert: " + err.Error())
+		return
+	}
+	res.Body.Close()
+}
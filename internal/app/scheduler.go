@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// defaultSchedulerInterval is used by run-schedules when no interval is
+// configured.
+const defaultSchedulerInterval = time.Minute
+
+// RunScheduler pays the payment schedules that are due. With once it makes a
+// single pass, e.g. from cron; otherwise it keeps running them every
+// configured interval until interrupted.
+func RunScheduler(once bool) {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	scheduleService := service.NewScheduleService(
+		repository.NewScheduleRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewMerchantRepository(db),
+		newWalletService(cfg, db),
+	)
+
+	if once {
+		runDueSchedules(context.Background(), scheduleService)
+		return
+	}
+
+	interval := time.Duration(cfg.App.SchedulerInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultSchedulerInterval
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runScheduler(ctx, scheduleService, interval)
+}
+
+// runScheduler runs the due payment schedules every interval until ctx is
+// done.
+func runScheduler(ctx context.Context, scheduleService service.ScheduleService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runDueSchedules(ctx, scheduleService)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runDueSchedules(ctx context.Context, scheduleService service.ScheduleService) {
+	attempted, err := scheduleService.RunDue(ctx)
+	if err != nil {
+		logger.Log.Error("error running schedules: " + err.Error())
+		return
+	}
+
+	if attempted > 0 {
+		logger.Log.Info(fmt.Sprintf("%d scheduled payment(s) attempted", attempted))
+	}
+}
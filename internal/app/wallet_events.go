@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/cecepsprd/starworks-test/config"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// Wallet stores WALLET_STORE selects from.
+const (
+	walletStoreMySQL        = "mysql"
+	walletStoreEventSourced = "eventsourced"
+)
+
+// Operations of the wallet-events command.
+const (
+	WalletEventsImport   = "import"
+	WalletEventsRebuild  = "rebuild"
+	WalletEventsSnapshot = "snapshot"
+)
+
+// newWalletRepository returns the configured wallet store.
+func newWalletRepository(cfg config.Config, db *sql.DB) repository.WalletRepository {
+	switch cfg.App.WalletStore {
+	case "", walletStoreMySQL:
+		return repository.NewWalletRepository(db)
+	case walletStoreEventSourced:
+		return repository.NewEventSourcedWalletRepository(db, cfg.App.WalletSnapshotInterval)
+	}
+
+	log.Fatalf("unknown wallet store %q", cfg.App.WalletStore)
+	return nil
+}
+
+// RunWalletEvents maintains the event sourced wallet store: import starts
+// the streams of wallets that have none from their ledger, rebuild writes
+// the wallet and ledger tables back from the streams and snapshot snapshots
+// every stream.
+func RunWalletEvents(op string) {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	store := repository.NewEventSourcedWalletRepository(db, cfg.App.WalletSnapshotInterval)
+	ctx := context.Background()
+
+	var (
+		n   int
+		err error
+	)
+
+	switch op {
+	case WalletEventsImport:
+		n, err = store.ImportLedger(ctx)
+	case WalletEventsRebuild:
+		n, err = store.RebuildProjections(ctx)
+	case WalletEventsSnapshot:
+		n, err = store.TakeSnapshots(ctx)
+	default:
+		log.Fatalf("unknown operation %q", op)
+	}
+
+	if err != nil {
+		log.Fatalf("error running %s after %d wallet(s): %v", op, n, err)
+	}
+
+	logger.Log.Info(fmt.Sprintf("%s: %d wallet(s) done", op, n))
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/internal/webhook"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// defaultWebhookDeliveryInterval is used by deliver-webhooks when no
+// interval is configured.
+const defaultWebhookDeliveryInterval = 10 * time.Second
+
+// newWebhookService builds the webhook service, which every entry point
+// that changes wallets or users queues its deliveries through.
+func newWebhookService(db *sql.DB) service.WebhookService {
+	return service.NewWebhookService(repository.NewWebhookRepository(db), webhook.NewHTTPSender())
+}
+
+// RunWebhookDelivery sends the webhook deliveries that are due. With once
+// it makes a single pass, e.g. from cron; otherwise it keeps sending them
+// every configured interval until interrupted.
+func RunWebhookDelivery(once bool) {
+	cfg, db := bootstrap()
+	defer db.Close()
+
+	webhookService := newWebhookService(db)
+
+	if once {
+		deliverWebhooks(context.Background(), webhookService)
+		return
+	}
+
+	interval := time.Duration(cfg.App.WebhookDeliveryInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultWebhookDeliveryInterval
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runWebhookDelivery(ctx, webhookService, interval)
+}
+
+// runWebhookDelivery sends the due webhook deliveries every interval until
+// ctx is done.
+func runWebhookDelivery(ctx context.Context, webhookService service.WebhookService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deliverWebhooks(ctx, webhookService)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func deliverWebhooks(ctx context.Context, webhookService service.WebhookService) {
+	delivered, err := webhookService.Deliver(ctx)
+	if err != nil {
+		logger.Log.Error("error delivering webhooks: " + err.Error())
+		return
+	}
+
+	if delivered > 0 {
+		logger.Log.Info(fmt.Sprintf("%d webhook(s) delivered", delivered))
+	}
+}
@@ -0,0 +1,67 @@
+// Package broker publishes domain events to a message broker. Events are
+// published at least once, so consumers should skip IDs they already saw.
+package broker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Message is an event to publish on Subject. Key identifies what the event
+// is about, for brokers that partition by key; ID is unique per event.
+type Message struct {
+	ID      string
+	Subject string
+	Key     string
+	Data    []byte
+}
+
+// Broker publishes messages. Publish returns once the broker accepted the
+// message.
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// New returns the broker rawURL points to: an in-memory one for an empty URL
+// or memory://, or a NATS server for nats://host:port.
+func New(rawURL string) (Broker, error) {
+	if rawURL == "" {
+		return NewMemoryBroker(), nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "memory":
+		return NewMemoryBroker(), nil
+	case "nats":
+		return NewNATSBroker(u.Host), nil
+	}
+
+	return nil, fmt.Errorf("unsupported broker %q", u.Scheme)
+}
+
+// MatchSubject reports whether subject matches pattern, where tokens are
+// separated by dots, * matches any one token and a trailing > matches one or
+// more.
+func MatchSubject(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" && i == len(patternTokens)-1 {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+
+	return len(patternTokens) == len(subjectTokens)
+}
@@ -0,0 +1,138 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchSubject(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{"starworks.user.registered", "starworks.user.registered", true},
+		{"starworks.*.registered", "starworks.user.registered", true},
+		{"starworks.>", "starworks.user.registered", true},
+		{"starworks.>", "starworks", false},
+		{"starworks.*", "starworks.user.registered", false},
+		{"starworks.user", "starworks.user.registered", false},
+		{"starworks.user.registered.more", "starworks.user.registered", false},
+	}
+	for _, tt := range tests {
+		if got := MatchSubject(tt.pattern, tt.subject); got != tt.want {
+			t.Errorf("MatchSubject(%q, %q) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryBroker(t *testing.T) {
+	b := NewMemoryBroker()
+	defer b.Close()
+
+	users := b.Subscribe("starworks.user.>")
+	all := b.Subscribe("starworks.>")
+
+	ctx := context.Background()
+	b.Publish(ctx, Message{ID: "1", Subject: "starworks.user.registered", Data: []byte(`{}`)})
+	b.Publish(ctx, Message{ID: "2", Subject: "starworks.top_up.completed", Data: []byte(`{}`)})
+
+	if got := (<-users.Messages).ID; got != "1" || len(users.Messages) != 0 {
+		t.Errorf("user subscriber got %s and %d more", got, len(users.Messages))
+	}
+	if len(all.Messages) != 2 {
+		t.Errorf("catch-all subscriber got %d message(s), want 2", len(all.Messages))
+	}
+
+	users.Close()
+	b.Publish(ctx, Message{ID: "3", Subject: "starworks.user.registered"})
+	if _, ok := <-users.Messages; ok {
+		t.Error("closed subscription still received a message")
+	}
+}
+
+func TestNATSBroker_MockNATS(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go NewMockNATS().Serve(l)
+
+	// A plain subscriber, as any NATS client would subscribe.
+	sub, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	sub.SetDeadline(time.Now().Add(5 * time.Second))
+
+	reader := bufio.NewReader(sub)
+	readLine := func() string {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(line)
+	}
+
+	readLine()
+	fmt.Fprint(sub, "CONNECT {\"verbose\":false}\r\nSUB starworks.> 1\r\nPING\r\n")
+	if got := readLine(); got != "PONG" {
+		t.Fatalf("subscriber got %q, want PONG", got)
+	}
+
+	b, err := New("nats://" + l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	ctx := context.Background()
+	if err = b.Publish(ctx, Message{ID: "1", Subject: "starworks.user.registered", Data: []byte(`{"id":"1"}`)}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err = b.Publish(ctx, Message{ID: "2", Subject: "other.subject", Data: []byte(`{}`)}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err = b.Publish(ctx, Message{ID: "3", Subject: "starworks.top_up.completed", Data: []byte(`{"id":"3"}`)}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err = b.Publish(ctx, Message{Subject: "has space"}); err == nil {
+		t.Error("Publish() accepted an invalid subject")
+	}
+
+	for _, want := range []string{`{"id":"1"}`, `{"id":"3"}`} {
+		var subject, sid string
+		var size int
+		if _, err = fmt.Sscanf(readLine(), "MSG %s %s %d", &subject, &sid, &size); err != nil {
+			t.Fatal(err)
+		}
+		payload := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, payload); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(payload[:size]); got != want || sid != "1" {
+			t.Errorf("subscriber got %s on %s, want %s", got, sid, want)
+		}
+	}
+
+	// Publishing reconnects after the connection is lost.
+	b.(*natsBroker).conn.Close()
+	if err = b.Publish(ctx, Message{ID: "4", Subject: "starworks.top_up.completed", Data: []byte(`{}`)}); err == nil {
+		t.Fatal("Publish() on a lost connection succeeded")
+	}
+	if err = b.Publish(ctx, Message{ID: "5", Subject: "starworks.top_up.completed", Data: []byte(`{}`)}); err != nil {
+		t.Fatalf("Publish() after reconnecting error = %v", err)
+	}
+
+	if _, err = New("kafka://localhost:9092"); err == nil {
+		t.Error("New() accepted an unsupported broker")
+	}
+}
@@ -0,0 +1,87 @@
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// memorySubscriberBuffer is how many messages a subscriber of the in-memory
+// broker may fall behind by before further ones are dropped for it.
+const memorySubscriberBuffer = 256
+
+// MemoryBroker delivers messages to subscribers in the same process. It
+// keeps nothing, so messages published without subscribers are lost.
+type MemoryBroker struct {
+	mu          sync.Mutex
+	subscribers map[*MemorySubscription]struct{}
+}
+
+// MemorySubscription receives the messages whose subject matches its
+// pattern.
+type MemorySubscription struct {
+	Messages <-chan Message
+
+	broker  *MemoryBroker
+	pattern string
+	ch      chan Message
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subscribers: map[*MemorySubscription]struct{}{},
+	}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, msg Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscribers {
+		if !MatchSubject(s.pattern, msg.Subject) {
+			continue
+		}
+		select {
+		case s.ch <- msg:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe delivers the messages published from now on whose subject
+// matches pattern.
+func (b *MemoryBroker) Subscribe(pattern string) *MemorySubscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Message, memorySubscriberBuffer)
+	s := &MemorySubscription{Messages: ch, broker: b, pattern: pattern, ch: ch}
+	b.subscribers[s] = struct{}{}
+
+	return s
+}
+
+// Close stops the delivery of messages to s and closes s.Messages.
+func (s *MemorySubscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	if _, ok := s.broker.subscribers[s]; ok {
+		delete(s.broker.subscribers, s)
+		close(s.ch)
+	}
+}
+
+// Close closes every subscription.
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subscribers {
+		delete(b.subscribers, s)
+		close(s.ch)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package broker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// mockNATSMaxPayload is the largest message the mock NATS server accepts.
+const mockNATSMaxPayload = 1 << 20
+
+// MockNATS stands in for a NATS server in development and tests. It speaks
+// enough of the client protocol for publishers and plain subscribers:
+// CONNECT, PING/PONG, PUB, SUB and UNSUB, without queue groups, headers or
+// persistence.
+type MockNATS struct {
+	mu      sync.Mutex
+	clients map[*mockNATSClient]struct{}
+}
+
+type mockNATSClient struct {
+	conn    net.Conn
+	writeMu sync.Mutex
+	verbose bool
+	// subs maps subscription IDs to subject patterns, guarded by MockNATS.mu.
+	subs map[string]string
+}
+
+func NewMockNATS() *MockNATS {
+	return &MockNATS{
+		clients: map[*mockNATSClient]struct{}{},
+	}
+}
+
+// Serve accepts clients on l until it is closed.
+func (s *MockNATS) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serve(conn)
+	}
+}
+
+func (s *MockNATS) serve(conn net.Conn) {
+	c := &mockNATSClient{conn: conn, subs: map[string]string{}}
+
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	c.write(fmt.Sprintf("INFO {\"server_id\":\"mock\",\"version\":\"2.0.0\",\"proto\":1,\"max_payload\":%d}\r\n", mockNATSMaxPayload))
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch strings.ToUpper(op) {
+		case "CONNECT":
+			var opts struct {
+				Verbose bool `json:"verbose"`
+			}
+			if err = json.Unmarshal([]byte(args), &opts); err != nil {
+				c.fail("Invalid Connect Options")
+				return
+			}
+			c.verbose = opts.Verbose
+			c.ok()
+		case "PING":
+			c.write("PONG\r\n")
+		case "PONG":
+		case "SUB":
+			fields := strings.Fields(args)
+			if len(fields) < 2 {
+				c.fail("Invalid Subscription")
+				return
+			}
+			s.mu.Lock()
+			c.subs[fields[len(fields)-1]] = fields[0]
+			s.mu.Unlock()
+			c.ok()
+		case "UNSUB":
+			fields := strings.Fields(args)
+			if len(fields) < 1 {
+				c.fail("Invalid Subscription")
+				return
+			}
+			s.mu.Lock()
+			delete(c.subs, fields[0])
+			s.mu.Unlock()
+			c.ok()
+		case "PUB":
+			fields := strings.Fields(args)
+			if len(fields) < 2 {
+				c.fail("Invalid Publish")
+				return
+			}
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil || size < 0 || size > mockNATSMaxPayload {
+				c.fail("Maximum Payload Violation")
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err = io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			s.deliver(fields[0], payload[:size])
+			c.ok()
+		default:
+			c.fail("Unknown Protocol Operation")
+			return
+		}
+	}
+}
+
+// deliver sends a published message to every matching subscription.
+func (s *MockNATS) deliver(subject string, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.clients {
+		for sid, pattern := range c.subs {
+			if MatchSubject(pattern, subject) {
+				c.write(fmt.Sprintf("MSG %s %s %d\r\n%s\r\n", subject, sid, len(payload), payload))
+			}
+		}
+	}
+}
+
+func (c *mockNATSClient) ok() {
+	if c.verbose {
+		c.write("+OK\r\n")
+	}
+}
+
+func (c *mockNATSClient) fail(reason string) {
+	c.write("-ERR '" + reason + "'\r\n")
+}
+
+func (c *mockNATSClient) write(s string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if _, err := io.WriteString(c.conn, s); err != nil {
+		log.Printf("mock nats: writing to %s: %v", c.conn.RemoteAddr(), err)
+	}
+}
@@ -0,0 +1,145 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsTimeout bounds connecting to the server and each publish when the
+// caller's context has no deadline.
+const natsTimeout = 10 * time.Second
+
+// natsBroker publishes to a NATS server over its text protocol. The
+// connection is opened in verbose mode, so the server acknowledges every
+// publish, and reopened on the next publish after any error.
+type natsBroker struct {
+	addr string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSBroker returns a Broker that publishes to the NATS server at addr.
+// It connects on the first publish.
+func NewNATSBroker(addr string) Broker {
+	return &natsBroker{
+		addr: addr,
+	}
+}
+
+func (b *natsBroker) Publish(ctx context.Context, msg Message) error {
+	if msg.Subject == "" || strings.ContainsAny(msg.Subject, " \t\r\n") {
+		return fmt.Errorf("invalid subject %q", msg.Subject)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(natsTimeout)
+	}
+
+	err := b.publish(deadline, msg)
+	if err != nil {
+		b.close()
+	}
+
+	return err
+}
+
+func (b *natsBroker) publish(deadline time.Time, msg Message) error {
+	if b.conn == nil {
+		if err := b.connect(deadline); err != nil {
+			return err
+		}
+	}
+
+	if err := b.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(b.conn, "PUB %s %d\r\n%s\r\n", msg.Subject, len(msg.Data), msg.Data); err != nil {
+		return err
+	}
+
+	return b.readAck()
+}
+
+func (b *natsBroker) connect(deadline time.Time) error {
+	conn, err := net.DialTimeout("tcp", b.addr, time.Until(deadline))
+	if err != nil {
+		return err
+	}
+
+	b.conn = conn
+	b.reader = bufio.NewReader(conn)
+
+	if err = conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	line, err := b.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(line, "INFO") {
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+
+	if _, err = fmt.Fprint(conn, "CONNECT {\"verbose\":true,\"pedantic\":false,\"name\":\"starworks\"}\r\n"); err != nil {
+		return err
+	}
+
+	return b.readAck()
+}
+
+// readAck waits for the server to acknowledge the last operation, answering
+// its pings in the meantime.
+func (b *natsBroker) readAck() error {
+	for {
+		line, err := b.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "+OK":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New("nats: " + strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		case line == "PING":
+			if _, err = fmt.Fprint(b.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case line == "PONG", strings.HasPrefix(line, "INFO"):
+		default:
+			return fmt.Errorf("unexpected reply %q", line)
+		}
+	}
+}
+
+func (b *natsBroker) close() {
+	if b.conn != nil {
+		b.conn.Close()
+		b.conn = nil
+		b.reader = nil
+	}
+}
+
+func (b *natsBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.close()
+	return nil
+}
@@ -0,0 +1,225 @@
+// Package emvqr encodes and decodes merchant-presented QR payloads in the
+// EMVCo format: a run of fields written as a two digit tag, a two digit
+// length and the value, closed by a CRC-16/CCITT-FALSE checksum in tag 63.
+package emvqr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// GloballyUniqueID identifies this wallet in the merchant account template,
+// so payers only accept codes issued for it.
+const GloballyUniqueID = "COM.STARWORKS"
+
+const (
+	tagPayloadFormat   = "00"
+	tagInitiation      = "01"
+	tagMerchantAccount = "26"
+	tagCurrency        = "53"
+	tagAmount          = "54"
+	tagCountry         = "58"
+	tagMerchantName    = "59"
+	tagMerchantCity    = "60"
+	tagCRC             = "63"
+
+	subTagGUI        = "00"
+	subTagMerchantID = "01"
+
+	payloadFormat     = "01"
+	initiationStatic  = "11"
+	initiationDynamic = "12"
+	countryCode       = "ID"
+
+	maxMerchantName = 25
+	maxMerchantCity = 15
+)
+
+// currencyCodes maps ISO 4217 alphabetic codes to the numeric codes EMV
+// payloads carry.
+var currencyCodes = map[string]string{
+	"IDR": "360",
+}
+
+var (
+	ErrMalformed           = errors.New("malformed qr payload")
+	ErrChecksum            = errors.New("qr payload checksum mismatch")
+	ErrUnknownMerchant     = errors.New("qr payload was not issued for this wallet")
+	ErrUnsupportedCurrency = errors.New("qr payload currency is not supported")
+)
+
+// Payload is the content of a merchant-presented code. A static code has no
+// amount and lets the payer enter one; a dynamic code fixes it.
+type Payload struct {
+	MerchantID   int64
+	MerchantName string
+	MerchantCity string
+	Currency     string
+	Amount       float64
+}
+
+// Static reports whether the payer chooses the amount.
+func (p Payload) Static() bool {
+	return p.Amount == 0
+}
+
+// Encode writes p as a payload string ready to be rendered as a QR code.
+// The merchant name and city are cut to the lengths EMV allows.
+func Encode(p Payload) (string, error) {
+	currency, ok := currencyCodes[p.Currency]
+	if !ok {
+		return "", ErrUnsupportedCurrency
+	}
+
+	if p.Amount < 0 {
+		return "", ErrMalformed
+	}
+
+	initiation := initiationDynamic
+	if p.Static() {
+		initiation = initiationStatic
+	}
+
+	account := field(subTagGUI, GloballyUniqueID) + field(subTagMerchantID, strconv.FormatInt(p.MerchantID, 10))
+
+	var b strings.Builder
+	b.WriteString(field(tagPayloadFormat, payloadFormat))
+	b.WriteString(field(tagInitiation, initiation))
+	b.WriteString(field(tagMerchantAccount, account))
+	b.WriteString(field(tagCurrency, currency))
+	if !p.Static() {
+		b.WriteString(field(tagAmount, strconv.FormatFloat(p.Amount, 'f', -1, 64)))
+	}
+	b.WriteString(field(tagCountry, countryCode))
+	b.WriteString(field(tagMerchantName, truncate(p.MerchantName, maxMerchantName)))
+	if p.MerchantCity != "" {
+		b.WriteString(field(tagMerchantCity, truncate(p.MerchantCity, maxMerchantCity)))
+	}
+
+	b.WriteString(tagCRC + "04")
+	b.WriteString(checksum(b.String()))
+
+	return b.String(), nil
+}
+
+// Decode parses and verifies a payload produced by Encode. It fails unless
+// the checksum matches and the code was issued for this wallet.
+func Decode(s string) (*Payload, error) {
+	if len(s) < 8 || s[len(s)-8:len(s)-4] != tagCRC+"04" {
+		return nil, ErrMalformed
+	}
+
+	if !strings.EqualFold(s[len(s)-4:], checksum(s[:len(s)-4])) {
+		return nil, ErrChecksum
+	}
+
+	fields, err := parse(s[:len(s)-8])
+	if err != nil {
+		return nil, err
+	}
+
+	if fields[tagPayloadFormat] != payloadFormat {
+		return nil, ErrMalformed
+	}
+
+	account, err := parse(fields[tagMerchantAccount])
+	if err != nil {
+		return nil, err
+	}
+
+	if account[subTagGUI] != GloballyUniqueID {
+		return nil, ErrUnknownMerchant
+	}
+
+	p := Payload{
+		MerchantName: fields[tagMerchantName],
+		MerchantCity: fields[tagMerchantCity],
+	}
+
+	p.MerchantID, err = strconv.ParseInt(account[subTagMerchantID], 10, 64)
+	if err != nil || p.MerchantID <= 0 {
+		return nil, ErrMalformed
+	}
+
+	for alpha, numeric := range currencyCodes {
+		if fields[tagCurrency] == numeric {
+			p.Currency = alpha
+		}
+	}
+
+	if p.Currency == "" {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	if amount, ok := fields[tagAmount]; ok {
+		p.Amount, err = strconv.ParseFloat(amount, 64)
+		if err != nil || p.Amount <= 0 {
+			return nil, ErrMalformed
+		}
+	}
+
+	if (fields[tagInitiation] == initiationDynamic) == p.Static() {
+		return nil, ErrMalformed
+	}
+
+	return &p, nil
+}
+
+// Image renders a payload as a PNG QR code of size by size pixels.
+func Image(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
+
+func field(tag, value string) string {
+	return fmt.Sprintf("%s%02d%s", tag, len(value), value)
+}
+
+// parse splits a run of fields into their values by tag.
+func parse(s string) (map[string]string, error) {
+	fields := map[string]string{}
+
+	for len(s) > 0 {
+		if len(s) < 4 {
+			return nil, ErrMalformed
+		}
+
+		n, err := strconv.Atoi(s[2:4])
+		if err != nil || len(s) < 4+n {
+			return nil, ErrMalformed
+		}
+
+		fields[s[:2]] = s[4 : 4+n]
+		s = s[4+n:]
+	}
+
+	return fields, nil
+}
+
+// checksum returns the CRC-16/CCITT-FALSE of s as four upper case hex digits.
+func checksum(s string) string {
+	crc := uint16(0xFFFF)
+
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%04X", crc)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
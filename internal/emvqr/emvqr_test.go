@@ -0,0 +1,98 @@
+package emvqr
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func Test_checksum(t *testing.T) {
+	if got := checksum("123456789"); got != "29B1" {
+		t.Errorf("checksum() = %v, want 29B1", got)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload Payload
+		want    string
+	}{
+		{
+			name:    "static",
+			payload: Payload{MerchantID: 8, MerchantName: "Ojek", Currency: "IDR"},
+			want:    "00020101021126220013COM.STARWORKS0101853033605802ID5904Ojek6304",
+		},
+		{
+			name:    "dynamic",
+			payload: Payload{MerchantID: 8, MerchantName: "Ojek", MerchantCity: "Jakarta", Currency: "IDR", Amount: 25000},
+			want:    "00020101021226220013COM.STARWORKS0101853033605405250005802ID5904Ojek6007Jakarta6304",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := Encode(tt.payload)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if encoded[:len(encoded)-4] != tt.want {
+				t.Errorf("Encode() = %v, want prefix %v", encoded, tt.want)
+			}
+
+			decoded, err := Decode(encoded)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(*decoded, tt.payload) {
+				t.Errorf("Decode() = %+v, want %+v", *decoded, tt.payload)
+			}
+		})
+	}
+}
+
+func TestDecode_invalid(t *testing.T) {
+	valid, _ := Encode(Payload{MerchantID: 8, MerchantName: "Ojek", Currency: "IDR", Amount: 25000})
+	foreign := "00020101021126210012COM.OTHERPAY0101853033605405250005802ID5904Ojek6304"
+	foreign += checksum(foreign)
+
+	tests := []struct {
+		name    string
+		payload string
+		wantErr error
+	}{
+		{
+			name:    "tampered amount",
+			payload: valid[:44] + "9" + valid[45:],
+			wantErr: ErrChecksum,
+		},
+		{
+			name:    "missing checksum",
+			payload: valid[:len(valid)-8],
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "issued for another wallet",
+			payload: foreign,
+			wantErr: ErrUnknownMerchant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.payload); err != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImage(t *testing.T) {
+	payload, _ := Encode(Payload{MerchantID: 8, MerchantName: "Ojek", Currency: "IDR"})
+
+	png, err := Image(payload, 256)
+	if err != nil {
+		t.Fatalf("Image() error = %v", err)
+	}
+	if !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("Image() did not return a png")
+	}
+}
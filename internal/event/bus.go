@@ -0,0 +1,148 @@
+// Package event is an in-process publish/subscribe bus for the changes a
+// user's wallets go through, kept in memory for a while so a subscriber
+// that reconnects can pick up where it left off.
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types.
+const (
+	// TypeBalance carries a wallet's balance after a change.
+	TypeBalance = "balance"
+	// TypeTransaction carries a new ledger entry.
+	TypeTransaction = "transaction"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind by
+// before it is dropped.
+const subscriberBuffer = 64
+
+// Event is a change to one of a user's wallets. IDs only ever increase,
+// also across restarts of the process.
+type Event struct {
+	ID     int64
+	UserID int64
+	Type   string
+	Data   interface{}
+}
+
+// Subscription delivers a user's events. Replay holds the buffered events
+// after the last one the subscriber saw; Missed reports that some of them
+// are no longer buffered, so the subscriber has to catch up some other way.
+// Events is closed when the subscriber falls too far behind or Close is
+// called.
+type Subscription struct {
+	LastID int64
+	Replay []Event
+	Missed bool
+	Events <-chan Event
+
+	bus    *Bus
+	userID int64
+	ch     chan Event
+}
+
+// Close stops the delivery of events to s.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	s.bus.unsubscribe(s)
+}
+
+// Bus fans events out to the subscribers of their user and keeps the last
+// ones published in a ring buffer.
+type Bus struct {
+	mu          sync.Mutex
+	lastID      int64
+	buffer      []Event
+	next        int
+	subscribers map[int64]map[*Subscription]struct{}
+}
+
+// NewBus returns a bus that keeps the last size events for replay. IDs start
+// from the current time in microseconds, so they keep increasing when the
+// process restarts.
+func NewBus(size int) *Bus {
+	return &Bus{
+		lastID:      time.Now().UnixMicro(),
+		buffer:      make([]Event, 0, size),
+		subscribers: map[int64]map[*Subscription]struct{}{},
+	}
+}
+
+// Publish sends an event to the subscribers of userID.
+func (b *Bus) Publish(userID int64, typ string, data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e := Event{ID: b.lastID, UserID: userID, Type: typ, Data: data}
+
+	if cap(b.buffer) > 0 {
+		if len(b.buffer) < cap(b.buffer) {
+			b.buffer = append(b.buffer, e)
+		} else {
+			b.buffer[b.next] = e
+			b.next = (b.next + 1) % cap(b.buffer)
+		}
+	}
+
+	for s := range b.subscribers[userID] {
+		select {
+		case s.ch <- e:
+		default:
+			// The subscriber reconnects and replays what it missed.
+			b.unsubscribe(s)
+		}
+	}
+}
+
+// Subscribe starts delivering the events of userID published from now on,
+// after replaying those published since lastID. A lastID of zero replays
+// nothing and reports nothing missed.
+func (b *Bus) Subscribe(userID, lastID int64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	s := &Subscription{LastID: b.lastID, Events: ch, bus: b, userID: userID, ch: ch}
+
+	if lastID > 0 {
+		oldest := b.lastID + 1
+		for i := range b.buffer {
+			e := b.buffer[(b.next+i)%len(b.buffer)]
+			if i == 0 {
+				oldest = e.ID
+			}
+			if e.ID > lastID && e.UserID == userID {
+				s.Replay = append(s.Replay, e)
+			}
+		}
+		s.Missed = lastID < oldest-1 || lastID > b.lastID
+	}
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = map[*Subscription]struct{}{}
+	}
+	b.subscribers[userID][s] = struct{}{}
+
+	return s
+}
+
+// unsubscribe removes s and closes its channel. b.mu must be held.
+func (b *Bus) unsubscribe(s *Subscription) {
+	subscribers := b.subscribers[s.userID]
+	if _, ok := subscribers[s]; !ok {
+		return
+	}
+
+	delete(subscribers, s)
+	if len(subscribers) == 0 {
+		delete(b.subscribers, s.userID)
+	}
+	close(s.ch)
+}
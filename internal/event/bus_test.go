@@ -0,0 +1,85 @@
+package event
+
+import "testing"
+
+func TestBus(t *testing.T) {
+	bus := NewBus(4)
+
+	first := bus.Subscribe(1, 0)
+	other := bus.Subscribe(2, 0)
+	defer other.Close()
+
+	bus.Publish(1, TypeBalance, 100)
+	bus.Publish(2, TypeBalance, 200)
+	bus.Publish(1, TypeBalance, 300)
+
+	if len(first.Events) != 2 || len(other.Events) != 1 {
+		t.Fatalf("delivered %d and %d events, want 2 and 1", len(first.Events), len(other.Events))
+	}
+
+	e := <-first.Events
+	if e.UserID != 1 || e.Data != 100 || e.ID != first.LastID+1 {
+		t.Errorf("first event = %+v", e)
+	}
+	first.Close()
+
+	tests := []struct {
+		name       string
+		lastID     int64
+		wantReplay int
+		wantMissed bool
+	}{
+		{
+			name:       "new subscriber",
+			lastID:     0,
+			wantReplay: 0,
+		},
+		{
+			name:       "reconnect",
+			lastID:     e.ID,
+			wantReplay: 1,
+		},
+		{
+			name:       "fell out of the buffer",
+			lastID:     1,
+			wantMissed: true,
+		},
+		{
+			name:       "ahead of the bus",
+			lastID:     e.ID + 100,
+			wantMissed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := bus.Subscribe(1, tt.lastID)
+			defer s.Close()
+
+			if s.Missed != tt.wantMissed {
+				t.Errorf("Subscribe() missed = %v, want %v", s.Missed, tt.wantMissed)
+			}
+			if !tt.wantMissed && len(s.Replay) != tt.wantReplay {
+				t.Errorf("Subscribe() replayed %d events, want %d", len(s.Replay), tt.wantReplay)
+			}
+		})
+	}
+}
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	bus := NewBus(0)
+
+	s := bus.Subscribe(1, 0)
+	for i := 0; i < subscriberBuffer+1; i++ {
+		bus.Publish(1, TypeBalance, i)
+	}
+
+	n := 0
+	for range s.Events {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("slow subscriber got %d events before being dropped, want %d", n, subscriberBuffer)
+	}
+
+	s.Close()
+}
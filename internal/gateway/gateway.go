@@ -0,0 +1,60 @@
+// Package gateway charges users through a payment gateway to top up their
+// wallets. A PaymentGateway creates a charge the user completes at its
+// redirect URL; the outcome is reported through a signed callback to the URL
+// given with the charge.
+package gateway
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Statuses a gateway reports for a charge.
+const (
+	StatusPending = "pending"
+	StatusPaid    = "paid"
+	StatusFailed  = "failed"
+)
+
+// SignatureHeader carries the signature of a callback body.
+const SignatureHeader = "X-Gateway-Signature"
+
+// Charge asks a gateway to collect Amount from the user. Reference
+// identifies the charge on both sides.
+type Charge struct {
+	Reference   string  `json:"reference"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	Description string  `json:"description"`
+	CallbackURL string  `json:"callback_url"`
+}
+
+// Result is a gateway's view of a charge. It is also the body of the
+// callbacks gateways send once a charge is paid or fails.
+type Result struct {
+	Reference        string  `json:"reference"`
+	GatewayReference string  `json:"gateway_reference"`
+	Amount           float64 `json:"amount"`
+	Status           string  `json:"status"`
+	RedirectURL      string  `json:"redirect_url,omitempty"`
+	Reason           string  `json:"reason,omitempty"`
+}
+
+type PaymentGateway interface {
+	CreateCharge(ctx context.Context, charge Charge) (*Result, error)
+}
+
+// Sign returns the signature of a callback body under secret, a hex encoded
+// HMAC-SHA256.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body under secret.
+func Verify(secret string, body []byte, signature string) bool {
+	return secret != "" && hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
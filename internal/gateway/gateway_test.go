@@ -0,0 +1,122 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPGateway_MockGateway(t *testing.T) {
+	const secret = "secret"
+
+	var received []Result
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify(secret, body, r.Header.Get(SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var result Result
+		json.Unmarshal(body, &result)
+		received = append(received, result)
+	}))
+	defer receiver.Close()
+
+	mock := httptest.NewServer(NewMockGateway(secret))
+	defer mock.Close()
+
+	var (
+		ctx = context.Background()
+		gw  = NewHTTPGateway(mock.URL + "/")
+	)
+
+	tests := []struct {
+		name      string
+		reference string
+		outcome   string
+		want      string
+	}{
+		{
+			name:      "paid",
+			reference: "REF1",
+			want:      StatusPaid,
+		},
+		{
+			name:      "failed",
+			reference: "REF2",
+			outcome:   StatusFailed,
+			want:      StatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+
+			charge, err := gw.CreateCharge(ctx, Charge{Reference: tt.reference, Amount: 50000, Currency: "IDR", CallbackURL: receiver.URL})
+			if err != nil || charge.Status != StatusPending || charge.RedirectURL == "" {
+				t.Fatalf("CreateCharge() = %+v, %v", charge, err)
+			}
+
+			res, err := http.Get(charge.RedirectURL + "?outcome=" + tt.outcome)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+
+			if res.StatusCode != http.StatusOK || len(received) != 1 {
+				t.Fatalf("checkout responded %d with %d callback(s)", res.StatusCode, len(received))
+			}
+			if got := received[0]; got.Reference != tt.reference || got.Status != tt.want || got.Amount != 50000 {
+				t.Errorf("callback = %+v, want %s %s", got, tt.reference, tt.want)
+			}
+		})
+	}
+
+	if _, err := gw.CreateCharge(ctx, Charge{Reference: "REF3"}); err == nil {
+		t.Error("CreateCharge() without amount error = nil")
+	}
+}
+
+func TestParseSettlement(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "columns in any order",
+			file: "status,reference,amount,settled_at,gateway_reference\npaid,REF1,50000,2026-03-02T10:00:00Z,MG1\nfailed,REF2,20000,2026-03-02T11:00:00+07:00,MG2\n",
+			want: 2,
+		},
+		{
+			name:    "missing column",
+			file:    "reference,amount,status,settled_at\nREF1,50000,paid,2026-03-02T10:00:00Z\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid amount",
+			file:    "reference,gateway_reference,amount,status,settled_at\nREF1,MG1,lots,paid,2026-03-02T10:00:00Z\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSettlement(strings.NewReader(tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSettlement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ParseSettlement() = %+v, want %d record(s)", got, tt.want)
+			}
+			if tt.want > 0 && (got[0].Reference != "REF1" || got[0].Amount != 50000 || got[0].Status != StatusPaid || got[0].GatewayReference != "MG1") {
+				t.Errorf("ParseSettlement() first record = %+v", got[0])
+			}
+		})
+	}
+}
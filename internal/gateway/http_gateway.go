@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const httpGatewayTimeout = 10 * time.Second
+
+type httpGateway struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPGateway returns a PaymentGateway for a gateway exposing
+// POST /charges, such as the one served by NewMockGateway.
+func NewHTTPGateway(baseURL string) PaymentGateway {
+	return &httpGateway{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: httpGatewayTimeout},
+	}
+}
+
+func (g *httpGateway) CreateCharge(ctx context.Context, charge Charge) (*Result, error) {
+	body, err := json.Marshal(charge)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/charges", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return nil, fmt.Errorf("payment gateway responded %s", res.Status)
+	}
+
+	var result Result
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,141 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type mockCharge struct {
+	charge Charge
+	result Result
+}
+
+type mockGateway struct {
+	secret string
+	client *http.Client
+
+	mu      sync.Mutex
+	charges map[string]*mockCharge
+	seq     int
+}
+
+// NewMockGateway returns a handler standing in for a payment gateway: it
+// creates charges on POST /charges and redirects users to
+// GET /checkout/{gateway reference}, which pays the charge, or fails it with
+// ?outcome=failed, and reports the outcome to its callback URL signed with
+// secret.
+func NewMockGateway(secret string) http.Handler {
+	return &mockGateway{
+		secret:  secret,
+		client:  &http.Client{Timeout: httpGatewayTimeout},
+		charges: map[string]*mockCharge{},
+	}
+}
+
+func (g *mockGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/charges":
+		g.create(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/checkout/"):
+		g.checkout(w, r, strings.TrimPrefix(r.URL.Path, "/checkout/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (g *mockGateway) create(w http.ResponseWriter, r *http.Request) {
+	var charge Charge
+	if err := json.NewDecoder(r.Body).Decode(&charge); err != nil || charge.Reference == "" || charge.Amount <= 0 {
+		writeJSON(w, http.StatusBadRequest, Result{Reference: charge.Reference, Status: StatusFailed, Reason: "malformed charge"})
+		return
+	}
+
+	g.mu.Lock()
+	g.seq++
+	reference := fmt.Sprintf("MG%08d", g.seq)
+	created := &mockCharge{
+		charge: charge,
+		result: Result{
+			Reference:        charge.Reference,
+			GatewayReference: reference,
+			Amount:           charge.Amount,
+			Status:           StatusPending,
+			RedirectURL:      fmt.Sprintf("http://%s/checkout/%s", r.Host, reference),
+		},
+	}
+	g.charges[reference] = created
+	result := created.result
+	g.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, result)
+}
+
+// checkout completes a pending charge as the user would at the gateway and
+// sends the callback before answering.
+func (g *mockGateway) checkout(w http.ResponseWriter, r *http.Request, reference string) {
+	g.mu.Lock()
+	created, ok := g.charges[reference]
+	if !ok {
+		g.mu.Unlock()
+		writeJSON(w, http.StatusNotFound, Result{GatewayReference: reference, Reason: "unknown charge"})
+		return
+	}
+
+	if created.result.Status == StatusPending {
+		created.result.Status = StatusPaid
+		if r.URL.Query().Get("outcome") == StatusFailed {
+			created.result.Status = StatusFailed
+			created.result.Reason = "card declined"
+		}
+	}
+	result, callbackURL := created.result, created.charge.CallbackURL
+	g.mu.Unlock()
+
+	if err := g.notify(r.Context(), callbackURL, result); err != nil {
+		writeJSON(w, http.StatusBadGateway, Result{Reference: result.Reference, GatewayReference: reference, Status: result.Status, Reason: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (g *mockGateway) notify(ctx context.Context, callbackURL string, result Result) error {
+	if callbackURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(g.secret, body))
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("callback responded %s", res.Status)
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
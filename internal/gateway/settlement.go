@@ -0,0 +1,74 @@
+package gateway
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettlementRecord is a line of the settlement file a gateway sends for the
+// charges it settled.
+type SettlementRecord struct {
+	Reference        string
+	GatewayReference string
+	Amount           float64
+	Status           string
+	SettledAt        time.Time
+}
+
+var settlementColumns = []string{"reference", "gateway_reference", "amount", "status", "settled_at"}
+
+// ParseSettlement reads a settlement file: a CSV file whose header names
+// the columns reference, gateway_reference, amount, status and settled_at,
+// in any order, with settled_at in RFC 3339.
+func ParseSettlement(r io.Reader) ([]SettlementRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading settlement header: %w", err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range settlementColumns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("settlement file has no %s column", name)
+		}
+	}
+
+	records := []SettlementRecord{}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		amount, err := strconv.ParseFloat(row[index["amount"]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("settlement line %d: invalid amount %q", line, row[index["amount"]])
+		}
+
+		settledAt, err := time.Parse(time.RFC3339, row[index["settled_at"]])
+		if err != nil {
+			return nil, fmt.Errorf("settlement line %d: invalid settled_at %q", line, row[index["settled_at"]])
+		}
+
+		records = append(records, SettlementRecord{
+			Reference:        row[index["reference"]],
+			GatewayReference: row[index["gateway_reference"]],
+			Amount:           amount,
+			Status:           row[index["status"]],
+			SettledAt:        settledAt,
+		})
+	}
+}
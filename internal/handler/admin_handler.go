@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type AdminHandler struct {
+	adminService    service.AdminService
+	auditService    service.AuditService
+	approvalService service.ApprovalService
+}
+
+func NewAdminHandler(e *echo.Echo, adminService service.AdminService, auditService service.AuditService, approvalService service.ApprovalService) {
+	handler := &AdminHandler{
+		adminService:    adminService,
+		auditService:    auditService,
+		approvalService: approvalService,
+	}
+
+	admin := e.Group("/api/admin", m.Auth(), m.RequireRole(model.RoleAdmin))
+
+	admin.GET("/users", handler.SearchUsers)
+	admin.GET("/users/:id", handler.GetUser)
+	admin.GET("/users/:id/login-history", handler.ListLoginHistory)
+	admin.GET("/wallets/:id/transactions", handler.ListTransactions)
+	admin.POST("/wallets/:id/adjustments", handler.Adjust)
+	admin.GET("/audit-log", handler.ListAuditLog)
+}
+
+// @Summary      Search Users
+// @Description  Finds users whose name, email, phone and username contain the given parts. Sortable by id, first_name, last_name, email, username and created_at.
+// @Tags         admin
+// @Produce      json
+// @Param        name      query   string  false  "Part of the full name"
+// @Param        email     query   string  false  "Part of the email"
+// @Param        phone     query   string  false  "Part of the phone number"
+// @Param        username  query   string  false  "Part of the username"
+// @Param        page      query   int     false  "Page, from 1"
+// @Param        per_page  query   int     false  "Page size, up to 100"
+// @Param        sort      query   string  false  "Sort field"
+// @Param        order     query   string  false  "asc or desc"
+// @Success      200  {object}  model.APIResponse{data=model.Page{items=[]model.AdminUser}}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/users [get]
+func (h *AdminHandler) SearchUsers(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.UserSearchRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	page, err := h.adminService.SearchUsers(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    page,
+	})
+}
+
+// @Summary      Get User
+// @Description  Returns a user with their wallets and most recent logins.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path    int  true  "User ID"
+// @Success      200  {object}  model.APIResponse{data=model.AdminUserDetail}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: constans.ErrBadParamInput.Error()})
+	}
+
+	detail, err := h.adminService.GetUser(ctx, id)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    detail,
+	})
+}
+
+// @Summary      List Login History
+// @Description  Lists the logins of a user, most recent first by default. Sortable by created_at and updated_at.
+// @Tags         admin
+// @Produce      json
+// @Param        id        path    int     true   "User ID"
+// @Param        page      query   int     false  "Page, from 1"
+// @Param        per_page  query   int     false  "Page size, up to 100"
+// @Param        sort      query   string  false  "Sort field"
+// @Param        order     query   string  false  "asc or desc"
+// @Success      200  {object}  model.APIResponse{data=model.Page{items=[]model.LoginHistory}}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/users/{id}/login-history [get]
+func (h *AdminHandler) ListLoginHistory(c echo.Context) error {
+	return h.listPage(c, h.adminService.ListLoginHistory)
+}
+
+// @Summary      List Wallet Transactions
+// @Description  Lists the ledger entries of any wallet, most recent first by default. Sortable by id, type, amount and created_at.
+// @Tags         admin
+// @Produce      json
+// @Param        id        path    int     true   "Wallet ID"
+// @Param        page      query   int     false  "Page, from 1"
+// @Param        per_page  query   int     false  "Page size, up to 100"
+// @Param        sort      query   string  false  "Sort field"
+// @Param        order     query   string  false  "asc or desc"
+// @Success      200  {object}  model.APIResponse{data=model.Page{items=[]model.Transaction}}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/wallets/{id}/transactions [get]
+func (h *AdminHandler) ListTransactions(c echo.Context) error {
+	return h.listPage(c, h.adminService.ListTransactions)
+}
+
+// @Summary      Adjust Balance
+// @Description  Proposes crediting a wallet by a positive amount or debiting it by a negative one. A second admin has to approve it under /api/admin/pending-actions before it is made; the reason is recorded as the description of the ledger entry.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id                  path    int                      true  "Wallet ID"
+// @Param        adjustmentRequest   body    model.AdjustmentRequest  true  "Adjustment"
+// @Success      202  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/wallets/{id}/adjustments [post]
+func (h *AdminHandler) Adjust(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.AdjustmentRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: constans.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.WalletID = id
+	req.Actor = utils.GetUserByContext(c)
+
+	action, err := h.approvalService.ProposeAdjustment(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, model.APIResponse{
+		Code:    http.StatusAccepted,
+		Message: constans.MessageSuccess,
+		Data:    action,
+	})
+}
+
+// @Summary      List Audit Log
+// @Description  Lists the audit log entries matching the filters, most recent first by default. Sortable by id and created_at.
+// @Tags         admin
+// @Produce      json
+// @Param        action       query   string  false  "Action, e.g. auth.login"
+// @Param        actor_id     query   int     false  "User who acted"
+// @Param        target_type  query   string  false  "user, wallet or route"
+// @Param        target_id    query   int     false  "ID of the target"
+// @Param        from         query   string  false  "Logged at or after, RFC 3339"
+// @Param        to           query   string  false  "Logged before, RFC 3339"
+// @Param        page         query   int     false  "Page, from 1"
+// @Param        per_page     query   int     false  "Page size, up to 100"
+// @Param        sort         query   string  false  "Sort field"
+// @Param        order        query   string  false  "asc or desc"
+// @Success      200  {object}  model.APIResponse{data=model.Page{items=[]model.AuditEntry}}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/audit-log [get]
+func (h *AdminHandler) ListAuditLog(c echo.Context) error {
+	var (
+		ctx    = c.Request().Context()
+		filter = model.AuditFilter{}
+	)
+
+	if err := c.Bind(&filter); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(filter); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	page, err := h.auditService.ListEntries(ctx, filter)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    page,
+	})
+}
+
+func (h *AdminHandler) listPage(c echo.Context, list func(ctx context.Context, id int64, page model.PageRequest) (*model.Page, error)) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.PageRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: constans.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	page, err := list(ctx, id, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    page,
+	})
+}
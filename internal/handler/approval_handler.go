@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type ApprovalHandler struct {
+	approvalService service.ApprovalService
+}
+
+func NewApprovalHandler(e *echo.Echo, approvalService service.ApprovalService) {
+	handler := &ApprovalHandler{
+		approvalService: approvalService,
+	}
+
+	admin := e.Group("/api/admin/pending-actions", m.Auth(), m.RequireRole(model.RoleAdmin))
+
+	admin.GET("", handler.ListActions)
+	admin.GET("/:id", handler.GetAction)
+	admin.POST("/:id/approve", handler.Approve)
+	admin.POST("/:id/reject", handler.Reject)
+}
+
+// @Summary      List Pending Actions
+// @Description  Lists the admin actions waiting for a second admin, or those with the given status, most recent first by default. Sortable by id, type, expires_at and created_at.
+// @Tags         admin
+// @Produce      json
+// @Param        status    query   string  false  "pending, approved, rejected or expired"
+// @Param        page      query   int     false  "Page, from 1"
+// @Param        per_page  query   int     false  "Page size, up to 100"
+// @Param        sort      query   string  false  "Sort field"
+// @Param        order     query   string  false  "asc or desc"
+// @Success      200  {object}  model.APIResponse{data=model.Page{items=[]model.PendingAction}}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/pending-actions [get]
+func (h *ApprovalHandler) ListActions(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.PageRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	status := c.QueryParam("status")
+	if status == "" {
+		status = model.PendingActionPending
+	}
+
+	actions, err := h.approvalService.ListActions(ctx, status, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    actions,
+	})
+}
+
+// @Summary      Get Pending Action
+// @Description  Returns a proposed admin action, with what it made once approved.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path    int  true  "Pending Action ID"
+// @Success      200  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/admin/pending-actions/{id} [get]
+func (h *ApprovalHandler) GetAction(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	action, err := h.approvalService.GetAction(ctx, id)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    action,
+	})
+}
+
+// @Summary      Approve Pending Action
+// @Description  Makes a proposed admin action exactly as it was proposed. It cannot be approved by the admin who proposed it, or once it has expired.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path    int                        true   "Pending Action ID"
+// @Param        review   body    model.PendingActionReview  false  "Note"
+// @Success      200  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/pending-actions/{id}/approve [post]
+func (h *ApprovalHandler) Approve(c echo.Context) error {
+	return h.review(c, h.approvalService.Approve)
+}
+
+// @Summary      Reject Pending Action
+// @Description  Rejects a proposed admin action without making it.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path    int                        true   "Pending Action ID"
+// @Param        review   body    model.PendingActionReview  false  "Note"
+// @Success      200  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/pending-actions/{id}/reject [post]
+func (h *ApprovalHandler) Reject(c echo.Context) error {
+	return h.review(c, h.approvalService.Reject)
+}
+
+func (h *ApprovalHandler) review(c echo.Context, review func(ctx context.Context, review model.PendingActionReview) (*model.PendingAction, error)) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.PendingActionReview{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.ActionID = id
+	req.Reviewer = utils.GetUserByContext(c)
+
+	action, err := review(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    action,
+	})
+}
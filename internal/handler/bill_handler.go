@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type BillHandler struct {
+	billService service.BillService
+}
+
+func NewBillHandler(e *echo.Echo, billService service.BillService) {
+	handler := &BillHandler{
+		billService: billService,
+	}
+
+	e.GET("/api/bills", handler.List, m.Auth())
+	e.POST("/api/bills", handler.Create, m.Auth())
+	e.GET("/api/bills/:id", handler.Get, m.Auth())
+	e.POST("/api/bills/:id/cancel", handler.Cancel, m.Auth())
+	e.POST("/api/bills/:id/remind", handler.Remind, m.Auth())
+}
+
+// @Summary      List Bills
+// @Description  Lists the bills the caller organized or has a share in, newest first.
+// @Tags         bill
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.Bill}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/bills [get]
+func (h *BillHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	bills, err := h.billService.List(ctx, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	for i := range bills {
+		setShareLinks(c, &bills[i])
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    bills,
+	})
+}
+
+// @Summary      Create Bill
+// @Description  Splits a bill between the caller and other users named by username or email. Shares without amounts split it equally; the caller covers the rest. Each share is a payment request into the caller's wallet, paid through /api/payment-requests/{code}/pay.
+// @Tags         bill
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.CreateBillRequest  true  "Create Bill Request"
+// @Success      200  {object}  model.APIResponse{data=model.Bill}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/bills [post]
+func (h *BillHandler) Create(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.CreateBillRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	bill, err := h.billService.Create(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    setShareLinks(c, bill),
+	})
+}
+
+// @Summary      Get Bill
+// @Description  Returns a bill with its shares to its organizer and participants.
+// @Tags         bill
+// @Produce      json
+// @Param        id   path    int  true  "Bill ID"
+// @Success      200  {object}  model.APIResponse{data=model.Bill}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/bills/{id} [get]
+func (h *BillHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	bill, err := h.billService.Get(ctx, id, utils.GetUserByContext(c))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    setShareLinks(c, bill),
+	})
+}
+
+// @Summary      Cancel Bill
+// @Description  Closes an open bill and withdraws the shares not paid yet. Shares already paid are kept.
+// @Tags         bill
+// @Produce      json
+// @Param        id   path    int  true  "Bill ID"
+// @Success      200  {object}  model.APIResponse{data=model.Bill}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/bills/{id}/cancel [post]
+func (h *BillHandler) Cancel(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	bill, err := h.billService.Cancel(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    setShareLinks(c, bill),
+	})
+}
+
+// @Summary      Remind Bill Participants
+// @Description  Reminds the participants who have not paid their share yet with a bill.reminder event, sent to their webhooks and published through the outbox. Each participant is reminded at most once a day.
+// @Tags         bill
+// @Produce      json
+// @Param        id   path    int  true  "Bill ID"
+// @Success      200  {object}  model.APIResponse{data=model.Bill}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/bills/{id}/remind [post]
+func (h *BillHandler) Remind(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	bill, err := h.billService.Remind(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    setShareLinks(c, bill),
+	})
+}
+
+func setShareLinks(c echo.Context, bill *model.Bill) *model.Bill {
+	for i := range bill.Shares {
+		setPaymentLink(c, &bill.Shares[i])
+	}
+
+	return bill
+}
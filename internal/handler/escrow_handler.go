@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type EscrowHandler struct {
+	walletService service.WalletService
+}
+
+func NewEscrowHandler(e *echo.Echo, walletService service.WalletService) {
+	handler := &EscrowHandler{
+		walletService: walletService,
+	}
+
+	e.GET("/api/escrows", handler.List, m.Auth())
+	e.POST("/api/escrows", handler.Fund, m.Auth())
+	e.GET("/api/escrows/:id", handler.Get, m.Auth())
+	e.POST("/api/escrows/:id/release", handler.Release, m.Auth())
+	e.POST("/api/escrows/:id/refund", handler.Refund, m.Auth())
+	e.POST("/api/escrows/:id/dispute", handler.Dispute, m.Auth())
+}
+
+// @Summary      List Escrows
+// @Description  Lists the escrows the caller funded or is the seller in, newest first.
+// @Tags         escrow
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.Escrow}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/escrows [get]
+func (h *EscrowHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	escrows, err := h.walletService.ListEscrows(ctx, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    escrows,
+	})
+}
+
+// @Summary      Fund Escrow
+// @Description  Moves a payment to an active merchant from the caller's wallet into escrow. The caller releases it to the merchant, the merchant's owner refunds it, or either disputes it for staff to resolve. Unless resolved it times out after expires_in_seconds, or 14 days when omitted, and timeout_action, release by default, is applied.
+// @Tags         escrow
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.EscrowRequest  true  "Escrow Request"
+// @Success      200  {object}  model.APIResponse{data=model.Escrow}
+// @Success      202  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/escrows [post]
+func (h *EscrowHandler) Fund(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.EscrowRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	escrow, err := h.walletService.FundEscrow(ctx, req)
+	if err != nil {
+		return operationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    escrow,
+	})
+}
+
+// @Summary      Get Escrow
+// @Description  Returns an escrow with every transition it went through to its buyer, the merchant's owner and staff.
+// @Tags         escrow
+// @Produce      json
+// @Param        id   path    int  true  "Escrow ID"
+// @Success      200  {object}  model.APIResponse{data=model.Escrow}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/escrows/{id} [get]
+func (h *EscrowHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	escrow, err := h.walletService.GetEscrow(ctx, id, utils.GetUserByContext(c))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    escrow,
+	})
+}
+
+// @Summary      Release Escrow
+// @Description  Pays a funded escrow out to its merchant. Only the buyer can release it; a disputed escrow only staff.
+// @Tags         escrow
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                        true  "Escrow ID"
+// @Param        request   body    model.EscrowActionRequest  true  "Escrow Action Request"
+// @Success      200  {object}  model.APIResponse{data=model.Escrow}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/escrows/{id}/release [post]
+func (h *EscrowHandler) Release(c echo.Context) error {
+	return h.act(c, h.walletService.ReleaseEscrow)
+}
+
+// @Summary      Refund Escrow
+// @Description  Returns a funded escrow to its buyer. Only the merchant's owner can refund it; a disputed escrow only staff.
+// @Tags         escrow
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                        true  "Escrow ID"
+// @Param        request   body    model.EscrowActionRequest  true  "Escrow Action Request"
+// @Success      200  {object}  model.APIResponse{data=model.Escrow}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/escrows/{id}/refund [post]
+func (h *EscrowHandler) Refund(c echo.Context) error {
+	return h.act(c, h.walletService.RefundEscrow)
+}
+
+// @Summary      Dispute Escrow
+// @Description  Stops a funded escrow from timing out and leaves it to staff to release or refund. Either the buyer or the merchant's owner can dispute it.
+// @Tags         escrow
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                        true  "Escrow ID"
+// @Param        request   body    model.EscrowActionRequest  true  "Escrow Action Request"
+// @Success      200  {object}  model.APIResponse{data=model.Escrow}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/escrows/{id}/dispute [post]
+func (h *EscrowHandler) Dispute(c echo.Context) error {
+	return h.act(c, h.walletService.DisputeEscrow)
+}
+
+func (h *EscrowHandler) act(c echo.Context, action func(ctx context.Context, escrowID int64, req model.EscrowActionRequest) (*model.Escrow, error)) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.EscrowActionRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.Requester = utils.GetUserByContext(c)
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	escrow, err := action(ctx, id, req)
+	if err != nil {
+		return operationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    escrow,
+	})
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type FeeHandler struct {
+	feeService service.FeeService
+}
+
+func NewFeeHandler(e *echo.Echo, feeService service.FeeService) {
+	handler := &FeeHandler{
+		feeService: feeService,
+	}
+
+	admin := m.RequireRole(model.RoleAdmin)
+
+	e.GET("/api/fee-rules", handler.ListRules, m.Auth(), admin)
+	e.POST("/api/fee-rules", handler.CreateRule, m.Auth(), admin)
+	e.PUT("/api/fee-rules/:id", handler.UpdateRule, m.Auth(), admin)
+}
+
+// @Summary      List Fee Rules
+// @Description  Lists every fee rule.
+// @Tags         fee
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.FeeRule}
+// @Failure      403  {object}  model.ResponseError
+// @Router       /api/fee-rules [get]
+func (h *FeeHandler) ListRules(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	rules, err := h.feeService.ListRules(ctx)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    rules,
+	})
+}
+
+// @Summary      Create Fee Rule
+// @Description  Creates a fee rule. flat rules charge flat, percentage rules a percentage of the amount and tiered rules the flat and percentage of the first tier the amount fits in. The fee is clamped to min_fee and, when set, max_fee; merchant_id makes the rule replace the global rule for payments to that merchant.
+// @Tags         fee
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.FeeRuleRequest  true  "Fee Rule Request"
+// @Success      200  {object}  model.APIResponse{data=model.FeeRule}
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/fee-rules [post]
+func (h *FeeHandler) CreateRule(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.FeeRuleRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	rule, err := h.feeService.CreateRule(ctx, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    rule,
+	})
+}
+
+// @Summary      Update Fee Rule
+// @Description  Replaces a fee rule, e.g. to change its rates or deactivate it.
+// @Tags         fee
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                   true  "Rule ID"
+// @Param        request   body    model.FeeRuleRequest  true  "Fee Rule Request"
+// @Success      200  {object}  model.APIResponse{data=model.FeeRule}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/fee-rules/{id} [put]
+func (h *FeeHandler) UpdateRule(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.FeeRuleRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	rule, err := h.feeService.UpdateRule(ctx, id, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    rule,
+	})
+}
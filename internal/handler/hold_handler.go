@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type HoldHandler struct {
+	walletService service.WalletService
+}
+
+func NewHoldHandler(e *echo.Echo, walletService service.WalletService) {
+	handler := &HoldHandler{
+		walletService: walletService,
+	}
+
+	e.GET("/api/wallet/holds", handler.ListHolds, m.Auth())
+	e.POST("/api/wallet/holds", handler.Authorize, m.Auth())
+	e.POST("/api/wallet/holds/:id/capture", handler.Capture, m.Auth())
+	e.POST("/api/wallet/holds/:id/void", handler.Void, m.Auth())
+}
+
+// @Summary      List Holds
+// @Description  Lists the holds placed on the caller's wallet, newest first.
+// @Tags         hold
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.Hold}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/wallet/holds [get]
+func (h *HoldHandler) ListHolds(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.CheckBalanceRequest{}
+	)
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	holds, err := h.walletService.ListHolds(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    holds,
+	})
+}
+
+// @Summary      Authorize
+// @Description  Reserves funds on the caller's wallet for an active merchant without moving money. The hold expires after expires_in_seconds, or 7 days when omitted.
+// @Tags         hold
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.AuthorizeRequest  true  "Authorize Request"
+// @Success      200  {object}  model.APIResponse{data=model.Hold}
+// @Success      202  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/wallet/holds [post]
+func (h *HoldHandler) Authorize(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.AuthorizeRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	hold, err := h.walletService.Authorize(ctx, req)
+	if err != nil {
+		return operationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    hold,
+	})
+}
+
+// @Summary      Capture
+// @Description  Settles an authorized hold with a payment to its merchant. amount captures part of the hold and releases the rest; omit it to capture the full hold. The payment is checked against the wallet's limits again. Either the payer or the merchant's owner can capture.
+// @Tags         hold
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                   true  "Hold ID"
+// @Param        request   body    model.CaptureRequest  true  "Capture Request"
+// @Success      200  {object}  model.APIResponse{data=model.Hold}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/wallet/holds/{id}/capture [post]
+func (h *HoldHandler) Capture(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.CaptureRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.UserID = utils.GetUserByContext(c).ID
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	hold, err := h.walletService.Capture(ctx, id, req)
+	if err != nil {
+		return operationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    hold,
+	})
+}
+
+// @Summary      Void
+// @Description  Releases an authorized hold without moving money. Either the payer or the merchant's owner can void.
+// @Tags         hold
+// @Produce      json
+// @Param        id   path    int  true  "Hold ID"
+// @Success      200  {object}  model.APIResponse{data=model.Hold}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/wallet/holds/{id}/void [post]
+func (h *HoldHandler) Void(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.VoidRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	req.UserID = utils.GetUserByContext(c).ID
+
+	hold, err := h.walletService.Void(ctx, id, req)
+	if err != nil {
+		return operationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    hold,
+	})
+}
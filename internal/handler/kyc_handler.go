@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// maxKYCDocumentSize is the largest identity document accepted, in bytes.
+const maxKYCDocumentSize = 5 << 20
+
+type KYCHandler struct {
+	kycService service.KYCService
+}
+
+func NewKYCHandler(e *echo.Echo, kycService service.KYCService) {
+	handler := &KYCHandler{
+		kycService: kycService,
+	}
+
+	reviewer := m.RequireRole(model.RoleSupport, model.RoleAdmin)
+
+	e.GET("/api/kyc", handler.Status, m.Auth())
+	e.POST("/api/kyc/documents", handler.SubmitDocument, m.Auth())
+	e.GET("/api/kyc/documents", handler.ListDocuments, m.Auth(), reviewer)
+	e.GET("/api/kyc/documents/:id/file", handler.DownloadDocument, m.Auth(), reviewer)
+	e.POST("/api/kyc/documents/:id/review", handler.Review, m.Auth(), reviewer)
+}
+
+// @Summary      KYC Status
+// @Description  Returns the caller's KYC level, the wallet limits that apply to it and the submitted documents.
+// @Tags         kyc
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=model.KYCStatusResponse}
+// @Failure      400  {object}  model.ResponseError
+// @Router       /api/kyc [get]
+func (h *KYCHandler) Status(c echo.Context) error {
+	var (
+		ctx  = c.Request().Context()
+		user = utils.GetUserByContext(c)
+	)
+
+	status, err := h.kycService.Status(ctx, user.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    status,
+	})
+}
+
+// @Summary      Submit KYC Document
+// @Description  Uploads an identity document (jpeg, png or pdf, max 5MB) to request a higher KYC level.
+// @Tags         kyc
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        document_type    formData  string  true  "id_card, passport, driving_license or selfie"
+// @Param        requested_level  formData  string  true  "basic or full"
+// @Param        file             formData  file    true  "Document"
+// @Success      200  {object}  model.APIResponse{data=model.KYCDocument}
+// @Failure      400  {object}  model.ResponseError
+// @Router       /api/kyc/documents [post]
+func (h *KYCHandler) SubmitDocument(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.KYCDocumentRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.UserID = utils.GetUserByContext(c).ID
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if header.Size > maxKYCDocumentSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, model.ResponseError{Message: "document must not be larger than 5MB"})
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: err.Error()})
+	}
+	defer file.Close()
+
+	doc, err := h.kycService.SubmitDocument(ctx, req, file)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    doc,
+	})
+}
+
+// @Summary      List KYC Documents
+// @Description  Lists KYC documents by review status for support staff, most recent first by default. Sortable by id, document_type, requested_level, created_at and updated_at.
+// @Tags         kyc
+// @Produce      json
+// @Param        status    query   string  false  "pending (default), approved or rejected"
+// @Param        page      query   int     false  "Page, from 1"
+// @Param        per_page  query   int     false  "Page size, up to 100"
+// @Param        sort      query   string  false  "Sort field"
+// @Param        order     query   string  false  "asc or desc"
+// @Success      200  {object}  model.APIResponse{data=model.Page{items=[]model.KYCDocument}}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/kyc/documents [get]
+func (h *KYCHandler) ListDocuments(c echo.Context) error {
+	var (
+		ctx    = c.Request().Context()
+		req    = model.PageRequest{}
+		status = c.QueryParam("status")
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if status == "" {
+		status = model.KYCDocumentPending
+	}
+
+	docs, err := h.kycService.ListDocuments(ctx, status, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    docs,
+	})
+}
+
+// @Summary      Download KYC Document
+// @Description  Streams the uploaded document file to support staff.
+// @Tags         kyc
+// @Produce      octet-stream
+// @Param        id   path    int  true  "Document ID"
+// @Success      200
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/kyc/documents/{id}/file [get]
+func (h *KYCHandler) DownloadDocument(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	doc, file, err := h.kycService.OpenDocument(ctx, id)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+	defer file.Close()
+
+	return c.Stream(http.StatusOK, doc.ContentType, file)
+}
+
+// @Summary      Review KYC Document
+// @Description  Approves or rejects a pending document. Approval raises the owner's KYC level to the requested level.
+// @Tags         kyc
+// @Accept       json
+// @Produce      json
+// @Param        id       path    int                     true  "Document ID"
+// @Param        request  body    model.KYCReviewRequest  true  "Review Request"
+// @Success      200  {object}  model.APIResponse{data=model.KYCDocument}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/kyc/documents/{id}/review [post]
+func (h *KYCHandler) Review(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.KYCReviewRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	req.DocumentID = id
+	req.ReviewerID = utils.GetUserByContext(c).ID
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	doc, err := h.kycService.Review(ctx, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    doc,
+	})
+}
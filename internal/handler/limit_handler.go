@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type LimitHandler struct {
+	limitService    service.LimitService
+	approvalService service.ApprovalService
+}
+
+func NewLimitHandler(e *echo.Echo, limitService service.LimitService, approvalService service.ApprovalService) {
+	handler := &LimitHandler{
+		limitService:    limitService,
+		approvalService: approvalService,
+	}
+
+	admin := m.RequireRole(model.RoleAdmin)
+
+	e.GET("/api/limit-rules", handler.ListRules, m.Auth(), admin)
+	e.POST("/api/limit-rules", handler.CreateRule, m.Auth(), admin)
+	e.PUT("/api/limit-rules/:id", handler.UpdateRule, m.Auth(), admin)
+}
+
+// @Summary      List Limit Rules
+// @Description  Lists every transaction limit and velocity rule.
+// @Tags         limit
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.LimitRule}
+// @Failure      403  {object}  model.ResponseError
+// @Router       /api/limit-rules [get]
+func (h *LimitHandler) ListRules(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	rules, err := h.limitService.ListRules(ctx)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    rules,
+	})
+}
+
+// @Summary      Create Limit Rule
+// @Description  Proposes a transaction limit rule, made once a second admin approves it under /api/admin/pending-actions. count and amount rules need a window_seconds; user_id makes the rule override the global rule of the same type and window for that user.
+// @Tags         limit
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.LimitRuleRequest  true  "Limit Rule Request"
+// @Success      202  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/limit-rules [post]
+func (h *LimitHandler) CreateRule(c echo.Context) error {
+	req := model.LimitRuleRequest{}
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	return h.proposeRule(c, 0, req)
+}
+
+// @Summary      Update Limit Rule
+// @Description  Proposes replacing a limit rule, e.g. to change its threshold or deactivate it. The change is made once a second admin approves it under /api/admin/pending-actions.
+// @Tags         limit
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                     true  "Rule ID"
+// @Param        request   body    model.LimitRuleRequest  true  "Limit Rule Request"
+// @Success      202  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/limit-rules/{id} [put]
+func (h *LimitHandler) UpdateRule(c echo.Context) error {
+	req := model.LimitRuleRequest{}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	return h.proposeRule(c, id, req)
+}
+
+// proposeRule queues a change to the limit rules for a second admin to
+// approve.
+func (h *LimitHandler) proposeRule(c echo.Context, ruleID int64, req model.LimitRuleRequest) error {
+	action, err := h.approvalService.ProposeLimitRule(c.Request().Context(), ruleID, req, utils.GetUserByContext(c))
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, model.APIResponse{
+		Code:    http.StatusAccepted,
+		Message: cs.MessageSuccess,
+		Data:    action,
+	})
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type MerchantHandler struct {
+	merchantService service.MerchantService
+}
+
+func NewMerchantHandler(e *echo.Echo, merchantService service.MerchantService) {
+	handler := &MerchantHandler{
+		merchantService: merchantService,
+	}
+
+	e.GET("/api/merchants", handler.List, m.Auth())
+	e.POST("/api/merchants", handler.Create, m.Auth())
+	e.GET("/api/merchants/:id", handler.Get, m.Auth())
+	e.GET("/api/merchants/:id/payments", handler.ListPayments, m.Auth())
+	e.GET("/api/merchants/:id/qr", handler.QRCode, m.Auth())
+	e.PUT("/api/merchants/:id/status", handler.UpdateStatus, m.Auth(), m.RequireRole(model.RoleAdmin))
+}
+
+// @Summary      List Merchants
+// @Description  Lists the caller's merchants. Staff see every merchant and can filter by status.
+// @Tags         merchant
+// @Produce      json
+// @Param        status   query    string  false  "pending, active or suspended"
+// @Success      200  {object}  model.APIResponse{data=[]model.Merchant}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/merchants [get]
+func (h *MerchantHandler) List(c echo.Context) error {
+	var (
+		ctx    = c.Request().Context()
+		user   = utils.GetUserByContext(c)
+		filter = model.MerchantFilter{Status: c.QueryParam("status")}
+	)
+
+	if !user.IsStaff() {
+		filter.UserID = user.ID
+	}
+
+	merchants, err := h.merchantService.List(ctx, filter)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    merchants,
+	})
+}
+
+// @Summary      Create Merchant
+// @Description  Onboards a merchant owned by the caller, with its own settlement wallet. The merchant can receive payments once an admin activates it.
+// @Tags         merchant
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.MerchantRequest  true  "Merchant Request"
+// @Success      200  {object}  model.APIResponse{data=model.Merchant}
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/merchants [post]
+func (h *MerchantHandler) Create(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.MerchantRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	merchant, err := h.merchantService.Create(ctx, utils.GetUserByContext(c).ID, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    merchant,
+	})
+}
+
+// @Summary      Get Merchant
+// @Description  Returns a merchant with its settlement balance, to its owner or to staff.
+// @Tags         merchant
+// @Produce      json
+// @Param        id   path    int  true  "Merchant ID"
+// @Success      200  {object}  model.APIResponse{data=model.Merchant}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/merchants/{id} [get]
+func (h *MerchantHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	merchant, err := h.merchantService.Get(ctx, id, utils.GetUserByContext(c))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    merchant,
+	})
+}
+
+// @Summary      List Merchant Payments
+// @Description  Lists the payments a merchant received, newest first, to its owner or to staff.
+// @Tags         merchant
+// @Produce      json
+// @Param        id   path    int  true  "Merchant ID"
+// @Success      200  {object}  model.APIResponse{data=[]model.MerchantPayment}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/merchants/{id}/payments [get]
+func (h *MerchantHandler) ListPayments(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	payments, err := h.merchantService.ListPayments(ctx, id, utils.GetUserByContext(c))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    payments,
+	})
+}
+
+// @Summary      Merchant QR Code
+// @Description  Issues an EMV QR code customers pay the merchant with. Without an amount the code is static and the payer enters the amount. The image is a base64 PNG, or the raw PNG with format=png.
+// @Tags         merchant
+// @Produce      json
+// @Produce      png
+// @Param        id       path     int     true   "Merchant ID"
+// @Param        amount   query    number  false  "Fixed amount for a dynamic code"
+// @Param        format   query    string  false  "png for the image alone"
+// @Success      200  {object}  model.APIResponse{data=model.MerchantQR}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/merchants/{id}/qr [get]
+func (h *MerchantHandler) QRCode(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.MerchantQRRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	qr, err := h.merchantService.QRCode(ctx, id, utils.GetUserByContext(c), req.Amount)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	if c.QueryParam("format") == "png" {
+		return c.Blob(http.StatusOK, "image/png", qr.Image)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    qr,
+	})
+}
+
+// @Summary      Update Merchant Status
+// @Description  Activates or suspends a merchant. Only active merchants receive payments.
+// @Tags         merchant
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                          true  "Merchant ID"
+// @Param        request   body    model.MerchantStatusRequest  true  "Merchant Status Request"
+// @Success      200  {object}  model.APIResponse{data=model.Merchant}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/merchants/{id}/status [put]
+func (h *MerchantHandler) UpdateStatus(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.MerchantStatusRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	merchant, err := h.merchantService.UpdateStatus(ctx, id, req.Status)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    merchant,
+	})
+}
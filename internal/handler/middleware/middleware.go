@@ -1,19 +1,170 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
 	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
 	"github.com/golang-jwt/jwt/v5"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"github.com/spf13/viper"
 )
 
+// AccountChecker tells whether the account a token was issued to can still
+// be used.
+type AccountChecker interface {
+	CheckActive(ctx context.Context, userID int64) error
+}
+
+// accounts is consulted by Auth on every request once set by CheckAccounts.
+var accounts AccountChecker
+
+// CheckAccounts makes Auth reject the tokens of the accounts checker no
+// longer accepts, such as purged ones. Until it is called, tokens are only
+// checked for their signature and expiry.
+func CheckAccounts(checker AccountChecker) {
+	accounts = checker
+}
+
 func Auth() echo.MiddlewareFunc {
 	config := echojwt.Config{
 		NewClaimsFunc: func(c echo.Context) jwt.Claims {
 			return new(model.JwtCustomClaims)
 		},
 		SigningKey: []byte(viper.GetString("APP_JWT_SECRET")),
+		SuccessHandler: func(c echo.Context) {
+			user := utils.GetUserByContext(c)
+			ctx := context.WithValue(c.Request().Context(), cs.CtxActorID, user.ID)
+			c.SetRequest(c.Request().WithContext(ctx))
+		},
+	}
+	verify := echojwt.WithConfig(config)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return verify(requireActiveAccount(next))
+	}
+}
+
+// requireActiveAccount refuses the valid tokens of accounts that have been
+// deleted since they were issued.
+func requireActiveAccount(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if accounts == nil {
+			return next(c)
+		}
+
+		user := utils.GetUserByContext(c)
+		if err := accounts.CheckActive(c.Request().Context(), user.ID); err != nil {
+			return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+		}
+
+		return next(c)
+	}
+}
+
+// RequireRole only lets through callers whose token carries one of roles. It
+// must be chained after Auth. The changes made through the routes it guards
+// are recorded by AuditAdminActions.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user := utils.GetUserByContext(c)
+			for _, role := range roles {
+				if user.Role == role {
+					c.Set(auditedRouteKey, true)
+					return next(c)
+				}
+			}
+
+			return c.JSON(http.StatusForbidden, model.ResponseError{Message: cs.ErrForbidden.Error()})
+		}
+	}
+}
+
+const (
+	// auditedRouteKey marks the requests AuditAdminActions records.
+	auditedRouteKey = "audited_route"
+	// maxAuditedBody is how much of a request body is recorded.
+	maxAuditedBody = 64 << 10
+)
+
+// RequestContext puts the caller's IP address and user agent in the request
+// context, for the audit log.
+func RequestContext() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := context.WithValue(c.Request().Context(), cs.CtxClientIP, c.RealIP())
+			ctx = context.WithValue(ctx, cs.CtxUserAgent, c.Request().UserAgent())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
+
+// AuditAdminActions records the successful requests that change something
+// through a route guarded by RequireRole, with their parameters and JSON
+// body.
+func AuditAdminActions(audit service.AuditLogger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+				return next(c)
+			}
+
+			var body []byte
+			if req.Body != nil && strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+				body, _ = io.ReadAll(io.LimitReader(req.Body, maxAuditedBody+1))
+				req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+			}
+
+			if err := next(c); err != nil {
+				return err
+			}
+
+			if audited, _ := c.Get(auditedRouteKey).(bool); !audited || c.Response().Status >= http.StatusBadRequest {
+				return nil
+			}
+
+			params := map[string]string{}
+			for _, name := range c.ParamNames() {
+				params[name] = c.Param(name)
+			}
+
+			action := map[string]interface{}{
+				"method": req.Method,
+				"route":  c.Path(),
+				"path":   req.URL.Path,
+				"params": params,
+				"status": c.Response().Status,
+			}
+			if len(body) > 0 && len(body) <= maxAuditedBody && json.Valid(body) {
+				action["body"] = json.RawMessage(body)
+			}
+
+			after, _ := json.Marshal(action)
+			targetID, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+
+			// The change is made by now, so a failure to record it is only
+			// logged.
+			audit.Log(c.Request().Context(), nil, model.AuditEntry{
+				Action:     model.AuditAdminAction,
+				TargetType: model.AuditTargetRoute,
+				TargetID:   targetID,
+				After:      after,
+			})
+
+			return nil
+		}
 	}
-	return echojwt.WithConfig(config)
 }
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type PaymentRequestHandler struct {
+	requestService service.PaymentRequestService
+	walletService  service.WalletService
+}
+
+func NewPaymentRequestHandler(e *echo.Echo, requestService service.PaymentRequestService, walletService service.WalletService) {
+	handler := &PaymentRequestHandler{
+		requestService: requestService,
+		walletService:  walletService,
+	}
+
+	e.GET("/api/payment-requests", handler.List, m.Auth())
+	e.POST("/api/payment-requests", handler.Create, m.Auth())
+	e.GET("/api/payment-requests/:code", handler.Get, m.Auth())
+	e.POST("/api/payment-requests/:code/pay", handler.Pay, m.Auth())
+	e.POST("/api/payment-requests/:code/cancel", handler.Cancel, m.Auth())
+}
+
+// @Summary      List Payment Requests
+// @Description  Lists the payment requests the caller made or was asked to pay, newest first.
+// @Tags         payment-request
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.PaymentRequest}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/payment-requests [get]
+func (h *PaymentRequestHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	requests, err := h.requestService.List(ctx, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	for i := range requests {
+		setPaymentLink(c, &requests[i])
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    requests,
+	})
+}
+
+// @Summary      Create Payment Request
+// @Description  Requests money into the caller's wallet. The response carries a short code and a link to share with the payer; payer, a username or email, restricts who may pay it.
+// @Tags         payment-request
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.CreatePaymentRequest  true  "Create Payment Request"
+// @Success      200  {object}  model.APIResponse{data=model.PaymentRequest}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/payment-requests [post]
+func (h *PaymentRequestHandler) Create(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.CreatePaymentRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	request, err := h.requestService.Create(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    setPaymentLink(c, request),
+	})
+}
+
+// @Summary      Get Payment Request
+// @Description  Shows a payment request to the payer before they pay it.
+// @Tags         payment-request
+// @Produce      json
+// @Param        code   path    string  true  "Payment Request Code"
+// @Success      200  {object}  model.APIResponse{data=model.PaymentRequest}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/payment-requests/{code} [get]
+func (h *PaymentRequestHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	request, err := h.requestService.Get(ctx, c.Param("code"), utils.GetUserByContext(c))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    setPaymentLink(c, request),
+	})
+}
+
+// @Summary      Pay Payment Request
+// @Description  Pays a pending payment request in full from the caller's wallet. It goes through the same risk, limit and fee checks as any other payment.
+// @Tags         payment-request
+// @Produce      json
+// @Param        code   path    string  true  "Payment Request Code"
+// @Success      200  {object}  model.APIResponse
+// @Success      202  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/payment-requests/{code}/pay [post]
+func (h *PaymentRequestHandler) Pay(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	user := utils.GetUserByContext(c)
+	payer := model.CheckBalanceRequest{
+		UserID:  user.ID,
+		Address: utils.GenerateEncryptedAddress(user.Username, user.Email),
+	}
+
+	ctx = context.WithValue(ctx, cs.CtxUserAgent, c.Request().UserAgent())
+
+	if err := h.walletService.PayPaymentRequest(ctx, c.Param("code"), payer); err != nil {
+		logger.Log.Error(err.Error())
+		return paymentRequestError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+	})
+}
+
+// @Summary      Cancel Payment Request
+// @Description  Withdraws a pending payment request. Only its requester can cancel it.
+// @Tags         payment-request
+// @Produce      json
+// @Param        code   path    string  true  "Payment Request Code"
+// @Success      200  {object}  model.APIResponse{data=model.PaymentRequest}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/payment-requests/{code}/cancel [post]
+func (h *PaymentRequestHandler) Cancel(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	request, err := h.requestService.Cancel(ctx, c.Param("code"), utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    setPaymentLink(c, request),
+	})
+}
+
+// setPaymentLink fills in the link a payment request is shared with, on the
+// host the request was made to.
+func setPaymentLink(c echo.Context, request *model.PaymentRequest) *model.PaymentRequest {
+	request.Link = c.Scheme() + "://" + c.Request().Host + "/api/payment-requests/" + request.Code
+	return request
+}
+
+// paymentRequestError maps the errors that identify the request itself by
+// their status and reports everything else as a failed payment.
+func paymentRequestError(c echo.Context, err error) error {
+	switch err {
+	case cs.ErrNotFound, cs.ErrForbidden, cs.ErrPaymentRequestNotActive:
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return walletError(c, err)
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/payout"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type PayoutHandler struct {
+	payoutService service.PayoutService
+}
+
+func NewPayoutHandler(e *echo.Echo, payoutService service.PayoutService) {
+	handler := &PayoutHandler{
+		payoutService: payoutService,
+	}
+
+	e.GET("/api/wallet/bank-accounts", handler.ListBankAccounts, m.Auth())
+	e.POST("/api/wallet/bank-accounts", handler.AddBankAccount, m.Auth())
+	e.DELETE("/api/wallet/bank-accounts/:id", handler.RemoveBankAccount, m.Auth())
+	e.POST("/api/wallet/withdraw", handler.Withdraw, m.Auth())
+	e.GET("/api/wallet/payouts", handler.List, m.Auth())
+	e.GET("/api/wallet/payouts/:id", handler.Get, m.Auth())
+
+	// Called by the payout provider, which signs the body instead.
+	e.POST("/api/payouts/callback", handler.Callback)
+}
+
+// @Summary      List Bank Accounts
+// @Description  Lists the bank accounts the caller has saved to withdraw to.
+// @Tags         payout
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.BankAccount}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/wallet/bank-accounts [get]
+func (h *PayoutHandler) ListBankAccounts(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	accounts, err := h.payoutService.ListBankAccounts(ctx, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    accounts,
+	})
+}
+
+// @Summary      Add Bank Account
+// @Tags         payout
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.BankAccountRequest  true  "Bank Account Request"
+// @Success      200  {object}  model.APIResponse{data=model.BankAccount}
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/wallet/bank-accounts [post]
+func (h *PayoutHandler) AddBankAccount(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.BankAccountRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.UserID = utils.GetUserByContext(c).ID
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	account, err := h.payoutService.AddBankAccount(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    account,
+	})
+}
+
+// @Summary      Remove Bank Account
+// @Description  Removes a saved bank account. Payouts already made to it are still settled.
+// @Tags         payout
+// @Produce      json
+// @Param        id   path    int  true  "Bank Account ID"
+// @Success      200  {object}  model.APIResponse
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/wallet/bank-accounts/{id} [delete]
+func (h *PayoutHandler) RemoveBankAccount(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err = h.payoutService.RemoveBankAccount(ctx, id, utils.GetUserByContext(c).ID); err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+	})
+}
+
+// @Summary      Withdraw
+// @Description  Withdraws from the caller's wallet, or from the settlement wallet of a merchant they own when merchant_id is given, to one of their saved bank accounts. The amount leaves the wallet straight away and the payout stays pending or processing until the bank settles it; a failed payout is credited back.
+// @Tags         payout
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.WithdrawRequest  true  "Withdraw Request"
+// @Success      200  {object}  model.APIResponse{data=model.Payout}
+// @Success      202  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/wallet/withdraw [post]
+func (h *PayoutHandler) Withdraw(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.WithdrawRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	p, err := h.payoutService.Withdraw(ctx, req)
+	if err != nil {
+		return operationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    p,
+	})
+}
+
+// @Summary      List Payouts
+// @Description  Lists the caller's withdrawals, newest first.
+// @Tags         payout
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.Payout}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/wallet/payouts [get]
+func (h *PayoutHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	payouts, err := h.payoutService.List(ctx, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    payouts,
+	})
+}
+
+// @Summary      Get Payout
+// @Description  Returns a withdrawal, to poll for its status.
+// @Tags         payout
+// @Produce      json
+// @Param        id   path    int  true  "Payout ID"
+// @Success      200  {object}  model.APIResponse{data=model.Payout}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/wallet/payouts/{id} [get]
+func (h *PayoutHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	p, err := h.payoutService.Get(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    p,
+	})
+}
+
+// @Summary      Payout Callback
+// @Description  Called by the payout provider when a payout succeeds or fails. The body is signed with the callback secret in the X-Payout-Signature header.
+// @Tags         payout
+// @Accept       json
+// @Produce      json
+// @Param        X-Payout-Signature  header  string         true  "HMAC-SHA256 of the body"
+// @Param        request             body    payout.Result  true  "Payout Result"
+// @Success      200  {object}  model.APIResponse{data=model.Payout}
+// @Failure      401  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/payouts/callback [post]
+func (h *PayoutHandler) Callback(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	p, err := h.payoutService.HandleCallback(ctx, body, c.Request().Header.Get(payout.SignatureHeader))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    p,
+	})
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type RefundHandler struct {
+	walletService service.WalletService
+}
+
+func NewRefundHandler(e *echo.Echo, walletService service.WalletService) {
+	handler := &RefundHandler{
+		walletService: walletService,
+	}
+
+	e.GET("/api/transactions/:id/refunds", handler.ListRefunds, m.Auth())
+	e.POST("/api/transactions/:id/refunds", handler.Refund, m.Auth())
+}
+
+// @Summary      List Refunds
+// @Description  Lists the refunds made on a payment. Available to staff and to the owner of the merchant that received the payment.
+// @Tags         refund
+// @Produce      json
+// @Param        id   path    int  true  "Transaction ID"
+// @Success      200  {object}  model.APIResponse{data=[]model.Refund}
+// @Failure      403  {object}  model.ResponseError
+// @Router       /api/transactions/{id}/refunds [get]
+func (h *RefundHandler) ListRefunds(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	refunds, err := h.walletService.ListRefunds(ctx, id, utils.GetUserByContext(c))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    refunds,
+	})
+}
+
+// @Summary      Refund
+// @Description  Refunds part or all of a payment to the payer, taking it back from the merchant or payment requester that received it. amount defaults to whatever has not been refunded yet; the refunds of a payment never exceed its amount. Available to staff and to the owner of the merchant that received the payment.
+// @Tags         refund
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                  true  "Transaction ID"
+// @Param        request   body    model.RefundRequest  true  "Refund Request"
+// @Success      200  {object}  model.APIResponse{data=model.Refund}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/transactions/{id}/refunds [post]
+func (h *RefundHandler) Refund(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.RefundRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.TransactionID = id
+	req.Requester = utils.GetUserByContext(c)
+
+	refund, err := h.walletService.Refund(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    refund,
+	})
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type ScheduleHandler struct {
+	scheduleService service.ScheduleService
+}
+
+func NewScheduleHandler(e *echo.Echo, scheduleService service.ScheduleService) {
+	handler := &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+
+	e.GET("/api/schedules", handler.List, m.Auth())
+	e.POST("/api/schedules", handler.Create, m.Auth())
+	e.GET("/api/schedules/:id", handler.Get, m.Auth())
+	e.PUT("/api/schedules/:id", handler.Update, m.Auth())
+	e.DELETE("/api/schedules/:id", handler.Cancel, m.Auth())
+	e.GET("/api/schedules/:id/runs", handler.ListRuns, m.Auth())
+}
+
+// @Summary      List Schedules
+// @Description  Lists the caller's scheduled and recurring payments, newest first.
+// @Tags         schedule
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.Schedule}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/schedules [get]
+func (h *ScheduleHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	schedules, err := h.scheduleService.List(ctx, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    schedules,
+	})
+}
+
+// @Summary      Create Schedule
+// @Description  Schedules a payment to a merchant, or a transfer to a user named by username or email, once at start_at or daily, weekly or monthly from then until end_at. Each occurrence is paid exactly once; failed attempts are retried with backoff.
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.ScheduleRequest  true  "Schedule Request"
+// @Success      200  {object}  model.APIResponse{data=model.Schedule}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/schedules [post]
+func (h *ScheduleHandler) Create(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.ScheduleRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.UserID = utils.GetUserByContext(c).ID
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	schedule, err := h.scheduleService.Create(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    schedule,
+	})
+}
+
+// @Summary      Get Schedule
+// @Tags         schedule
+// @Produce      json
+// @Param        id   path    int  true  "Schedule ID"
+// @Success      200  {object}  model.APIResponse{data=model.Schedule}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/schedules/{id} [get]
+func (h *ScheduleHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	schedule, err := h.scheduleService.Get(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    schedule,
+	})
+}
+
+// @Summary      Update Schedule
+// @Description  Changes the amount, description and end of an active schedule, from its next occurrence on.
+// @Tags         schedule
+// @Accept       json
+// @Produce      json
+// @Param        id        path    int                          true  "Schedule ID"
+// @Param        request   body    model.UpdateScheduleRequest  true  "Update Schedule Request"
+// @Success      200  {object}  model.APIResponse{data=model.Schedule}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/schedules/{id} [put]
+func (h *ScheduleHandler) Update(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.UpdateScheduleRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	schedule, err := h.scheduleService.Update(ctx, id, utils.GetUserByContext(c).ID, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    schedule,
+	})
+}
+
+// @Summary      Cancel Schedule
+// @Description  Stops an active schedule. Occurrences already paid are kept.
+// @Tags         schedule
+// @Produce      json
+// @Param        id   path    int  true  "Schedule ID"
+// @Success      200  {object}  model.APIResponse{data=model.Schedule}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/schedules/{id} [delete]
+func (h *ScheduleHandler) Cancel(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	schedule, err := h.scheduleService.Cancel(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    schedule,
+	})
+}
+
+// @Summary      List Schedule Runs
+// @Description  Lists every attempt at paying the schedule, successful or not, newest first.
+// @Tags         schedule
+// @Produce      json
+// @Param        id   path    int  true  "Schedule ID"
+// @Success      200  {object}  model.APIResponse{data=[]model.ScheduleRun}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/schedules/{id}/runs [get]
+func (h *ScheduleHandler) ListRuns(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	runs, err := h.scheduleService.ListRuns(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    runs,
+	})
+}
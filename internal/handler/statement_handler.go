@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/internal/statement"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// documentHashHeader carries the hash of a downloaded statement.
+const documentHashHeader = "X-Document-Hash"
+
+type StatementHandler struct {
+	statementService service.StatementService
+}
+
+func NewStatementHandler(e *echo.Echo, statementService service.StatementService) {
+	handler := &StatementHandler{
+		statementService: statementService,
+	}
+
+	e.GET("/api/wallet/statements", handler.Download, m.Auth())
+
+	// Anyone holding a statement, such as an accountant, can check it.
+	e.GET("/api/statements/verify/:hash", handler.Verify)
+}
+
+// @Summary      Download Statement
+// @Description  Downloads the statement of the caller's wallet for a completed month: opening balance, every transaction including fees, the totals and the closing balance. The document is signed with a hash, given in the X-Document-Hash header and in the document itself, that can be checked later.
+// @Tags         wallet
+// @Produce      text/csv
+// @Produce      application/pdf
+// @Param        month    query    string  true   "Month as YYYY-MM"
+// @Param        format   query    string  false  "pdf (default) or csv"
+// @Success      200
+// @Failure      400  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/wallet/statements [get]
+func (h *StatementHandler) Download(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.StatementRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	st, err := h.statementService.Generate(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	var (
+		document    []byte
+		contentType string
+	)
+
+	if req.Format == model.StatementFormatCSV {
+		document, err = statement.CSV(*st)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return c.JSON(http.StatusInternalServerError, model.ResponseError{Message: err.Error()})
+		}
+		contentType = "text/csv"
+	} else {
+		req.Format = model.StatementFormatPDF
+		document = statement.PDF(*st)
+		contentType = "application/pdf"
+	}
+
+	filename := fmt.Sprintf("statement-%s.%s", st.Month, req.Format)
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	c.Response().Header().Set(documentHashHeader, st.Hash)
+
+	return c.Blob(http.StatusOK, contentType, document)
+}
+
+// @Summary      Verify Statement
+// @Description  Checks a statement's document hash and returns what was issued with it.
+// @Tags         wallet
+// @Produce      json
+// @Param        hash   path    string  true  "Document hash"
+// @Success      200  {object}  model.APIResponse{data=model.StatementRecord}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/statements/verify/{hash} [get]
+func (h *StatementHandler) Verify(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	record, err := h.statementService.Verify(ctx, c.Param("hash"))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    record,
+	})
+}
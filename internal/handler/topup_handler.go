@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/gateway"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type TopUpHandler struct {
+	topUpService service.TopUpService
+}
+
+func NewTopUpHandler(e *echo.Echo, topUpService service.TopUpService) {
+	handler := &TopUpHandler{
+		topUpService: topUpService,
+	}
+
+	e.GET("/api/wallet/top-up/intents", handler.List, m.Auth())
+	e.POST("/api/wallet/top-up/intents", handler.Create, m.Auth())
+	e.GET("/api/wallet/top-up/intents/:id", handler.Get, m.Auth())
+
+	// Called by the payment gateway, which signs the body instead.
+	e.POST("/api/top-up/callback", handler.Callback)
+}
+
+// @Summary      List Top-Up Intents
+// @Description  Lists the caller's top-up intents, newest first.
+// @Tags         top-up
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.TopUpIntent}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/wallet/top-up/intents [get]
+func (h *TopUpHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	intents, err := h.topUpService.List(ctx, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    intents,
+	})
+}
+
+// @Summary      Create Top-Up Intent
+// @Description  Starts a top-up of the caller's wallet through the payment gateway. The caller pays at redirect_url; the wallet is credited once the gateway confirms the payment.
+// @Tags         top-up
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.TopUpIntentRequest  true  "Top-Up Intent Request"
+// @Success      200  {object}  model.APIResponse{data=model.TopUpIntent}
+// @Success      202  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/wallet/top-up/intents [post]
+func (h *TopUpHandler) Create(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.TopUpIntentRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	intent, err := h.topUpService.Create(ctx, req)
+	if err != nil {
+		return operationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    intent,
+	})
+}
+
+// @Summary      Get Top-Up Intent
+// @Description  Returns a top-up intent, to poll for its status.
+// @Tags         top-up
+// @Produce      json
+// @Param        id   path    int  true  "Top-Up Intent ID"
+// @Success      200  {object}  model.APIResponse{data=model.TopUpIntent}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/wallet/top-up/intents/{id} [get]
+func (h *TopUpHandler) Get(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	intent, err := h.topUpService.Get(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    intent,
+	})
+}
+
+// @Summary      Top-Up Callback
+// @Description  Called by the payment gateway when a charge is paid or fails. The body is signed with the callback secret in the X-Gateway-Signature header.
+// @Tags         top-up
+// @Accept       json
+// @Produce      json
+// @Param        X-Gateway-Signature  header  string          true  "HMAC-SHA256 of the body"
+// @Param        request              body    gateway.Result  true  "Charge Result"
+// @Success      200  {object}  model.APIResponse{data=model.TopUpIntent}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      401  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/top-up/callback [post]
+func (h *TopUpHandler) Callback(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	intent, err := h.topUpService.HandleCallback(ctx, body, c.Request().Header.Get(gateway.SignatureHeader))
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    intent,
+	})
+}
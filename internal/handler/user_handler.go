@@ -2,9 +2,12 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
 	"github.com/cecepsprd/starworks-test/internal/model"
 	"github.com/cecepsprd/starworks-test/internal/service"
 	"github.com/cecepsprd/starworks-test/utils"
@@ -25,6 +28,10 @@ func NewUserHandler(e *echo.Echo, userService service.UserService) {
 	e.Use(middleware.Recover())
 	e.POST("/api/auth/login", handler.Login)
 	e.POST("/api/auth/register", handler.Register)
+
+	e.GET("/api/users/me/export", handler.Export, m.Auth())
+	e.DELETE("/api/users/me", handler.RequestDeletion, m.Auth())
+	e.POST("/api/users/me/cancel-deletion", handler.CancelDeletion, m.Auth())
 }
 
 // @Description  Login endpoint
@@ -115,3 +122,96 @@ func (h *UserHandler) Register(c echo.Context) error {
 		Data:    res,
 	})
 }
+
+// @Summary      Export Personal Data
+// @Description  Returns every piece of data held about the caller as a zip archive of JSON files, or as a single JSON document when format=json.
+// @Tags         user
+// @Produce      application/zip
+// @Produce      json
+// @Param        format   query    string  false  "zip (default) or json"
+// @Success      200  {object}  model.UserExport
+// @Failure      400  {object}  model.ResponseError
+// @Router       /api/users/me/export [get]
+func (h *UserHandler) Export(c echo.Context) error {
+	var (
+		ctx  = c.Request().Context()
+		user = utils.GetUserByContext(c)
+	)
+
+	export, err := h.userService.Export(ctx, user.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	if c.QueryParam("format") == "json" {
+		return c.JSON(http.StatusOK, export)
+	}
+
+	archive, err := utils.ZipJSON(map[string]interface{}{
+		"profile.json":       export.Profile,
+		"wallets.json":       export.Wallets,
+		"transactions.json":  export.Transactions,
+		"login_history.json": export.LoginHistory,
+	})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusInternalServerError, model.ResponseError{Message: err.Error()})
+	}
+
+	filename := fmt.Sprintf("export-%d-%s.zip", user.ID, export.ExportedAt.Format("20060102"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	return c.Blob(http.StatusOK, "application/zip", archive)
+}
+
+// @Summary      Delete Account
+// @Description  Schedules the caller's account for anonymization after a grace period. All wallets must have a zero balance. Ledger entries are kept.
+// @Tags         user
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=model.AccountDeletion}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/users/me [delete]
+func (h *UserHandler) RequestDeletion(c echo.Context) error {
+	var (
+		ctx  = c.Request().Context()
+		user = utils.GetUserByContext(c)
+	)
+
+	deletion, err := h.userService.RequestDeletion(ctx, user.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: fmt.Sprintf("account will be deleted on %s", deletion.ScheduledAt.Format(time.RFC3339)),
+		Data:    deletion,
+	})
+}
+
+// @Summary      Cancel Account Deletion
+// @Description  Cancels a pending account deletion while it is still within its grace period.
+// @Tags         user
+// @Produce      json
+// @Success      200  {object}  model.APIResponse
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/users/me/cancel-deletion [post]
+func (h *UserHandler) CancelDeletion(c echo.Context) error {
+	var (
+		ctx  = c.Request().Context()
+		user = utils.GetUserByContext(c)
+	)
+
+	if err := h.userService.CancelDeletion(ctx, user.ID); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+	})
+}
@@ -1,9 +1,17 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/event"
 	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
 	"github.com/cecepsprd/starworks-test/internal/model"
 	"github.com/cecepsprd/starworks-test/internal/service"
@@ -12,28 +20,57 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// lastEventIDHeader is sent by Server-Sent Events clients when they
+	// reconnect.
+	lastEventIDHeader = "Last-Event-ID"
+	// eventKeepAlive is how often an idle event stream is written to, so
+	// proxies do not close it.
+	eventKeepAlive = 15 * time.Second
+)
+
 type WalletHandler struct {
-	walletService service.WalletService
+	walletService   service.WalletService
+	approvalService service.ApprovalService
+	topUpService    service.TopUpService
+	payoutService   service.PayoutService
 }
 
-func NewWalletHandler(e *echo.Echo, walletService service.WalletService) {
+func NewWalletHandler(e *echo.Echo, walletService service.WalletService, approvalService service.ApprovalService, topUpService service.TopUpService, payoutService service.PayoutService) {
 	handler := &WalletHandler{
-		walletService: walletService,
+		walletService:   walletService,
+		approvalService: approvalService,
+		topUpService:    topUpService,
+		payoutService:   payoutService,
 	}
 
 	e.GET("/api/wallet/check-balance", handler.CheckBalance, m.Auth())
-	e.POST("/api/wallet/top-up", handler.TopUp, m.Auth())
 	e.POST("/api/wallet/pay", handler.Pay, m.Auth())
+	e.POST("/api/wallet/pay/qr", handler.PayByQR, m.Auth())
+	e.POST("/api/wallet/transfer", handler.Transfer, m.Auth())
+	e.GET("/api/wallet/fees/preview", handler.PreviewFee, m.Auth())
+	e.GET("/api/wallet/transactions", handler.ListTransactions, m.Auth())
+	e.GET("/api/wallet/events", handler.Events, m.Auth())
+
+	admin := e.Group("/api/admin", m.Auth(), m.RequireRole(model.RoleAdmin))
 
+	admin.POST("/top-ups", handler.TopUp)
+	admin.GET("/held-transactions", handler.ListHeldTransactions)
+	admin.POST("/held-transactions/:id/approve", handler.ApproveHeldTransaction)
+	admin.POST("/held-transactions/:id/reject", handler.RejectHeldTransaction)
+	admin.POST("/wallets/:id/freeze", handler.FreezeWallet)
+	admin.POST("/wallets/:id/unfreeze", handler.UnfreezeWallet)
+	admin.POST("/wallets/:id/close", handler.CloseWallet)
+	admin.GET("/wallets/:id/status-history", handler.ListWalletStatusChanges)
 }
 
 // @Summary      Check Balance
-// @Description  Check Balance endpoint
+// @Description  Returns the ledger balance and the available balance, which excludes funds reserved by active holds.
 // @Tags         wallet
 // @Accept       json
 // @Produce      json
 // @Param        checkBalanceRequest   body    model.CheckBalanceRequest  true  "Check Balance Request"
-// @Success      200  {object}  model.APIResponse
+// @Success      200  {object}  model.APIResponse{data=model.CheckBalanceResponse}
 // @Failure      400  {object}  model.ResponseError
 // @Router       /api/wallet/check-balance [get]
 func (h *WalletHandler) CheckBalance(c echo.Context) error {
@@ -66,14 +103,17 @@ func (h *WalletHandler) CheckBalance(c echo.Context) error {
 }
 
 // @Summary      Top Up
-// @Description  Top Up endpoint
-// @Tags         wallet
+// @Description  Proposes crediting a wallet directly, without a payment: the wallet of the user named by recipient, username or email, or the caller's own. A second admin has to approve it under /api/admin/pending-actions before the wallet is credited; users top up through top-up intents.
+// @Tags         admin
 // @Accept       json
 // @Produce      json
 // @Param        topUpRequest   body    model.TopUpRequest  true  "Top Up Request"
-// @Success      200  {object}  model.APIResponse
-// @Failure      400  {object}  model.ResponseError
-// @Router       /api/wallet/top-up [post]
+// @Success      202  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/admin/top-ups [post]
 func (h *WalletHandler) TopUp(c echo.Context) error {
 	var (
 		ctx = c.Request().Context()
@@ -89,32 +129,37 @@ func (h *WalletHandler) TopUp(c echo.Context) error {
 	user := utils.GetUserByContext(c)
 	req.UserID = user.ID
 	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+	req.Actor = user
 
 	if err = c.Validate(req); err != nil {
 		logger.Log.Error(err.Error())
 		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
 	}
 
-	err = h.walletService.TopUp(ctx, req)
+	action, err := h.approvalService.ProposeTopUp(ctx, req)
 	if err != nil {
 		logger.Log.Error(err.Error())
-		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: err.Error()})
+		return operationError(c, err)
 	}
 
-	return c.JSON(http.StatusOK, model.APIResponse{
-		Code:    http.StatusOK,
+	return c.JSON(http.StatusAccepted, model.APIResponse{
+		Code:    http.StatusAccepted,
 		Message: constans.MessageSuccess,
+		Data:    action,
 	})
 }
 
 // @Summary      Pay
-// @Description  Pay endpoint
+// @Description  Pays an active merchant from the caller's wallet. The amount is credited to the merchant's settlement wallet.
 // @Tags         wallet
 // @Accept       json
 // @Produce      json
 // @Param        payRequest   body    model.PayRequest  true  "Pay Request"
 // @Success      200  {object}  model.APIResponse
+// @Success      202  {object}  model.APIResponse{data=model.HeldTransaction}
 // @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
 // @Router       /api/wallet/pay [post]
 func (h *WalletHandler) Pay(c echo.Context) error {
 	var (
@@ -137,14 +182,546 @@ func (h *WalletHandler) Pay(c echo.Context) error {
 		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
 	}
 
+	ctx = context.WithValue(ctx, constans.CtxUserAgent, c.Request().UserAgent())
+
 	err = h.walletService.Pay(ctx, req)
 	if err != nil {
 		logger.Log.Error(err.Error())
-		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: err.Error()})
+		return walletError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+	})
+}
+
+// @Summary      Pay By QR
+// @Description  Pays the merchant a scanned QR payload names. The payload is verified first; amount is required for static codes and must match, or be left out, for dynamic ones.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.QRPayRequest  true  "QR Pay Request"
+// @Success      200  {object}  model.APIResponse
+// @Success      202  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/wallet/pay/qr [post]
+func (h *WalletHandler) PayByQR(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.QRPayRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	ctx = context.WithValue(ctx, constans.CtxUserAgent, c.Request().UserAgent())
+
+	if err := h.walletService.PayByQR(ctx, req); err != nil {
+		logger.Log.Error(err.Error())
+		return walletError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+	})
+}
+
+// @Summary      Transfer
+// @Description  Sends money from the caller's wallet to another user's wallet, named by username or email. It goes through the same risk, limit and fee checks as a payment.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.TransferRequest  true  "Transfer Request"
+// @Success      200  {object}  model.APIResponse
+// @Success      202  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      429  {object}  model.LimitExceededResponse
+// @Router       /api/wallet/transfer [post]
+func (h *WalletHandler) Transfer(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.TransferRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	ctx = context.WithValue(ctx, constans.CtxUserAgent, c.Request().UserAgent())
+
+	if err := h.walletService.Transfer(ctx, req); err != nil {
+		logger.Log.Error(err.Error())
+		return walletError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+	})
+}
+
+// @Summary      Preview Fee
+// @Description  Quotes the fee an operation will be charged, so it can be shown before the user confirms. The fee is debited from the wallet as its own ledger entry; net is the total change of the balance.
+// @Tags         wallet
+// @Produce      json
+// @Param        operation     query    string  true   "top_up, payment or transfer"
+// @Param        amount        query    number  true   "Operation amount"
+// @Param        merchant_id   query    int     false  "Merchant paid, for merchant specific fees"
+// @Success      200  {object}  model.APIResponse{data=model.FeeQuote}
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/wallet/fees/preview [get]
+func (h *WalletHandler) PreviewFee(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.FeePreviewRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	quote, err := h.walletService.PreviewFee(ctx, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    quote,
+	})
+}
+
+// @Summary      List Transactions
+// @Description  Lists the ledger entries of the caller's wallet, oldest first. Fees appear as separate fee entries sharing the reference of the operation they were charged on.
+// @Tags         wallet
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.Transaction}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/wallet/transactions [get]
+func (h *WalletHandler) ListTransactions(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.CheckBalanceRequest{}
+	)
+
+	user := utils.GetUserByContext(c)
+	req.UserID = user.ID
+	req.Address = utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	transactions, err := h.walletService.ListTransactions(ctx, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, model.APIResponse{
 		Code:    http.StatusOK,
 		Message: constans.MessageSuccess,
+		Data:    transactions,
 	})
 }
+
+// @Summary      Wallet Events
+// @Description  Streams balance changes and new ledger entries of the caller's wallets as Server-Sent Events: "balance" events carry a model.BalanceChange and "transaction" events a model.Transaction. The stream opens with the current balance of every wallet. A client that reconnects with the Last-Event-ID header, or the last_event_id query parameter, is sent the events it missed instead.
+// @Tags         wallet
+// @Produce      text/event-stream
+// @Param        Last-Event-ID   header   int  false  "ID of the last event received"
+// @Param        last_event_id   query    int  false  "ID of the last event received"
+// @Success      200
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/wallet/events [get]
+func (h *WalletHandler) Events(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	lastEventID := c.Request().Header.Get(lastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+	since, _ := strconv.ParseInt(lastEventID, 10, 64)
+
+	sub, err := h.walletService.Subscribe(ctx, utils.GetUserByContext(c).ID, since)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+	defer sub.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	for _, e := range sub.Replay {
+		if err = writeEvent(res, e); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-sub.Events:
+			// A client that fell behind is cut off and replays what it
+			// missed when it reconnects.
+			if !ok {
+				return nil
+			}
+			err = writeEvent(res, e)
+		case <-keepAlive.C:
+			_, err = io.WriteString(res, ": keep-alive\n\n")
+		}
+
+		if err != nil {
+			return nil
+		}
+		res.Flush()
+	}
+}
+
+// @Summary      List Held Transactions
+// @Description  Lists wallet operations held by risk scoring, pending ones unless status is given, most recent first by default. Sortable by id, operation, amount, score and created_at.
+// @Tags         admin
+// @Produce      json
+// @Param        status    query   string  false  "pending, approved or rejected"
+// @Param        page      query   int     false  "Page, from 1"
+// @Param        per_page  query   int     false  "Page size, up to 100"
+// @Param        sort      query   string  false  "Sort field"
+// @Param        order     query   string  false  "asc or desc"
+// @Success      200  {object}  model.APIResponse{data=model.Page{items=[]model.HeldTransaction}}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      403  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/held-transactions [get]
+func (h *WalletHandler) ListHeldTransactions(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.PageRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	status := c.QueryParam("status")
+	if status == "" {
+		status = model.HeldTransactionPending
+	}
+
+	page, err := h.walletService.ListHeldTransactions(ctx, status, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    page,
+	})
+}
+
+// @Summary      Approve Held Transaction
+// @Description  Executes a held wallet operation as originally requested. Balance and limit checks still apply. An approved top-up is checked again and opened as a top-up intent for the user to pay; an approved withdrawal is debited and submitted to the bank.
+// @Tags         wallet
+// @Produce      json
+// @Param        id   path    int  true  "Held Transaction ID"
+// @Success      200  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/admin/held-transactions/{id}/approve [post]
+func (h *WalletHandler) ApproveHeldTransaction(c echo.Context) error {
+	return h.reviewHeldTransaction(c, h.approveHeldTransaction)
+}
+
+// approveHeldTransaction approves a held operation, then resumes the
+// operations whose money moves outside the wallet service: a top-up is
+// opened for the user to pay and a withdrawal is paid out.
+func (h *WalletHandler) approveHeldTransaction(ctx context.Context, heldID, reviewerID int64) (*model.HeldTransaction, error) {
+	held, err := h.walletService.ApproveHeldTransaction(ctx, heldID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch held.Operation {
+	case model.TransactionTypeTopUp:
+		_, err = h.topUpService.OpenHeld(ctx, *held)
+	case model.TransactionTypeWithdrawal:
+		_, err = h.payoutService.WithdrawHeld(ctx, *held)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return held, nil
+}
+
+// @Summary      Reject Held Transaction
+// @Description  Rejects a held wallet operation without moving any money.
+// @Tags         wallet
+// @Produce      json
+// @Param        id   path    int  true  "Held Transaction ID"
+// @Success      200  {object}  model.APIResponse{data=model.HeldTransaction}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Router       /api/admin/held-transactions/{id}/reject [post]
+func (h *WalletHandler) RejectHeldTransaction(c echo.Context) error {
+	return h.reviewHeldTransaction(c, h.walletService.RejectHeldTransaction)
+}
+
+func (h *WalletHandler) reviewHeldTransaction(c echo.Context, review func(ctx context.Context, heldID, reviewerID int64) (*model.HeldTransaction, error)) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: constans.ErrBadParamInput.Error()})
+	}
+
+	user := utils.GetUserByContext(c)
+
+	held, err := review(ctx, id, user.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return operationError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    held,
+	})
+}
+
+// @Summary      Freeze Wallet
+// @Description  Blocks debits from a wallet, and credits to it too when block_credits is set. Freezing a frozen wallet again changes block_credits; letting credits in again is only proposed, and made once a second admin approves it.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        id                    path    int                        true  "Wallet ID"
+// @Param        walletStatusRequest   body    model.WalletStatusRequest  true  "Reason"
+// @Success      200  {object}  model.APIResponse{data=model.Wallet}
+// @Success      202  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/wallets/{id}/freeze [post]
+func (h *WalletHandler) FreezeWallet(c echo.Context) error {
+	return h.changeWalletStatus(c, model.WalletStatusFrozen)
+}
+
+// @Summary      Unfreeze Wallet
+// @Description  Proposes letting money move in and out of a frozen wallet again. A second admin has to approve it under /api/admin/pending-actions before the wallet is unfrozen.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        id                    path    int                        true  "Wallet ID"
+// @Param        walletStatusRequest   body    model.WalletStatusRequest  true  "Reason"
+// @Success      202  {object}  model.APIResponse{data=model.PendingAction}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/wallets/{id}/unfreeze [post]
+func (h *WalletHandler) UnfreezeWallet(c echo.Context) error {
+	return h.changeWalletStatus(c, model.WalletStatusActive)
+}
+
+// @Summary      Close Wallet
+// @Description  Closes a wallet for good. Its balance must be zero, with nothing held on it.
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        id                    path    int                        true  "Wallet ID"
+// @Param        walletStatusRequest   body    model.WalletStatusRequest  true  "Reason"
+// @Success      200  {object}  model.APIResponse{data=model.Wallet}
+// @Failure      400  {object}  model.ResponseError
+// @Failure      404  {object}  model.ResponseError
+// @Failure      409  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/admin/wallets/{id}/close [post]
+func (h *WalletHandler) CloseWallet(c echo.Context) error {
+	return h.changeWalletStatus(c, model.WalletStatusClosed)
+}
+
+func (h *WalletHandler) changeWalletStatus(c echo.Context, status string) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.WalletStatusRequest{}
+	)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: constans.ErrBadParamInput.Error()})
+	}
+
+	if err = c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	if err = c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.WalletID = id
+	req.Status = status
+	req.Actor = utils.GetUserByContext(c)
+
+	wallet, err := h.walletService.ChangeStatus(ctx, req)
+	if err == constans.ErrApprovalRequired {
+		return h.proposeUnfreeze(c, req)
+	} else if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    wallet,
+	})
+}
+
+// proposeUnfreeze queues a change lifting restrictions on a frozen wallet
+// for a second admin to approve.
+func (h *WalletHandler) proposeUnfreeze(c echo.Context, req model.WalletStatusRequest) error {
+	action, err := h.approvalService.ProposeUnfreeze(c.Request().Context(), req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, model.APIResponse{
+		Code:    http.StatusAccepted,
+		Message: constans.MessageSuccess,
+		Data:    action,
+	})
+}
+
+// @Summary      Wallet Status History
+// @Description  Lists every freeze, unfreeze and close of a wallet, oldest first.
+// @Tags         wallet
+// @Produce      json
+// @Param        id   path    int  true  "Wallet ID"
+// @Success      200  {object}  model.APIResponse{data=[]model.WalletStatusChange}
+// @Failure      400  {object}  model.ResponseError
+// @Router       /api/admin/wallets/{id}/status-history [get]
+func (h *WalletHandler) ListWalletStatusChanges(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: constans.ErrBadParamInput.Error()})
+	}
+
+	changes, err := h.walletService.ListStatusChanges(ctx, id)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: constans.MessageSuccess,
+		Data:    changes,
+	})
+}
+
+// walletError writes the response for a failed money movement. Limit
+// violations carry the rule that was hit so clients can tell when to retry,
+// and operations held for review are reported as accepted.
+func walletError(c echo.Context, err error) error {
+	var heldErr *model.TransactionHeldError
+	if errors.As(err, &heldErr) {
+		return c.JSON(http.StatusAccepted, model.APIResponse{
+			Code:    http.StatusAccepted,
+			Message: err.Error(),
+			Data:    heldErr.HeldTransaction,
+		})
+	}
+
+	if errors.Is(err, constans.ErrTransactionDenied) || errors.Is(err, constans.ErrWalletFrozen) || errors.Is(err, constans.ErrWalletClosed) {
+		return c.JSON(http.StatusForbidden, model.ResponseError{Message: err.Error()})
+	}
+
+	var limitErr *model.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return c.JSON(http.StatusTooManyRequests, model.LimitExceededResponse{
+			Message: err.Error(),
+			Limit:   limitErr,
+		})
+	}
+
+	return c.JSON(http.StatusBadRequest, model.ResponseError{Message: err.Error()})
+}
+
+// operationError writes the response for a failed operation that may have
+// been held for review or rejected by a limit rule, mapping every other
+// error by its status.
+func operationError(c echo.Context, err error) error {
+	var (
+		heldErr  *model.TransactionHeldError
+		limitErr *model.LimitExceededError
+	)
+	if errors.As(err, &heldErr) || errors.As(err, &limitErr) {
+		return walletError(c, err)
+	}
+
+	return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+}
+
+// writeEvent writes e as a Server-Sent Event.
+func writeEvent(w io.Writer, e event.Event) error {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+
+	return err
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	m "github.com/cecepsprd/starworks-test/internal/handler/middleware"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/service"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+	"github.com/labstack/echo/v4"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(e *echo.Echo, webhookService service.WebhookService) {
+	handler := &WebhookHandler{
+		webhookService: webhookService,
+	}
+
+	e.GET("/api/webhooks/event-types", handler.ListEventTypes)
+	e.GET("/api/webhooks", handler.ListSubscriptions, m.Auth())
+	e.POST("/api/webhooks", handler.CreateSubscription, m.Auth())
+	e.DELETE("/api/webhooks/:id", handler.DeleteSubscription, m.Auth())
+	e.GET("/api/webhooks/:id/deliveries", handler.ListDeliveries, m.Auth())
+	e.POST("/api/webhooks/deliveries/:id/redeliver", handler.Redeliver, m.Auth())
+}
+
+// @Summary      List Webhook Event Types
+// @Tags         webhook
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]string}
+// @Router       /api/webhooks/event-types [get]
+func (h *WebhookHandler) ListEventTypes(c echo.Context) error {
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    model.WebhookEventTypes,
+	})
+}
+
+// @Summary      List Webhook Subscriptions
+// @Description  Lists the caller's active webhook subscriptions. Secrets are not shown again.
+// @Tags         webhook
+// @Produce      json
+// @Success      200  {object}  model.APIResponse{data=[]model.WebhookSubscription}
+// @Failure      500  {object}  model.ResponseError
+// @Router       /api/webhooks [get]
+func (h *WebhookHandler) ListSubscriptions(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	subs, err := h.webhookService.ListSubscriptions(ctx, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    subs,
+	})
+}
+
+// @Summary      Create Webhook Subscription
+// @Description  Subscribes url to events of the given types concerning the caller's wallets, or every user's with all_users, which only admins may set. Deliveries are POSTed as JSON and signed in the X-Webhook-Signature header with a hex HMAC-SHA256, under the returned secret, of the X-Webhook-Timestamp header, a dot and the body. The secret is only returned here.
+// @Tags         webhook
+// @Accept       json
+// @Produce      json
+// @Param        request   body    model.WebhookSubscriptionRequest  true  "Webhook Subscription Request"
+// @Success      200  {object}  model.APIResponse{data=model.WebhookSubscription}
+// @Failure      403  {object}  model.ResponseError
+// @Failure      422  {object}  model.ResponseError
+// @Router       /api/webhooks [post]
+func (h *WebhookHandler) CreateSubscription(c echo.Context) error {
+	var (
+		ctx = c.Request().Context()
+		req = model.WebhookSubscriptionRequest{}
+	)
+
+	if err := c.Bind(&req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	req.Requester = utils.GetUserByContext(c)
+
+	if err := c.Validate(req); err != nil {
+		logger.Log.Error(err.Error())
+		return c.JSON(http.StatusUnprocessableEntity, model.ResponseError{Message: err.Error()})
+	}
+
+	sub, err := h.webhookService.CreateSubscription(ctx, req)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusCreated,
+		Message: cs.MessageSuccess,
+		Data:    sub,
+	})
+}
+
+// @Summary      Delete Webhook Subscription
+// @Description  Stops a subscription. Its pending deliveries are given up on.
+// @Tags         webhook
+// @Produce      json
+// @Param        id   path    int  true  "Subscription ID"
+// @Success      200  {object}  model.APIResponse
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteSubscription(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	if err = h.webhookService.DeleteSubscription(ctx, id, utils.GetUserByContext(c).ID); err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+	})
+}
+
+// @Summary      List Webhook Deliveries
+// @Description  Lists the latest deliveries of one of the caller's subscriptions, newest first, with the outcome of their last attempt.
+// @Tags         webhook
+// @Produce      json
+// @Param        id   path    int  true  "Subscription ID"
+// @Success      200  {object}  model.APIResponse{data=[]model.WebhookDelivery}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    deliveries,
+	})
+}
+
+// @Summary      Redeliver Webhook
+// @Description  Sends a delivery again straight away, dead or not, and returns the outcome. If it fails, it is retried like a new delivery.
+// @Tags         webhook
+// @Produce      json
+// @Param        id   path    int  true  "Delivery ID"
+// @Success      200  {object}  model.APIResponse{data=model.WebhookDelivery}
+// @Failure      404  {object}  model.ResponseError
+// @Router       /api/webhooks/deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, model.ResponseError{Message: cs.ErrBadParamInput.Error()})
+	}
+
+	delivery, err := h.webhookService.Redeliver(ctx, id, utils.GetUserByContext(c).ID)
+	if err != nil {
+		return c.JSON(utils.SetHTTPStatusCode(err), model.ResponseError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, model.APIResponse{
+		Code:    http.StatusOK,
+		Message: cs.MessageSuccess,
+		Data:    delivery,
+	})
+}
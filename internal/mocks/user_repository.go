@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	sql "database/sql"
+
+	time "time"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -16,6 +18,20 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// Anonymize provides a mock function with given fields: ctx, tx, userID
+func (_m *UserRepository) Anonymize(ctx context.Context, tx *sql.Tx, userID int64) error {
+	ret := _m.Called(ctx, tx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64) error); ok {
+		r0 = rf(ctx, tx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BeginTx provides a mock function with given fields: ctx
 func (_m *UserRepository) BeginTx(ctx context.Context) *sql.Tx {
 	ret := _m.Called(ctx)
@@ -32,6 +48,34 @@ func (_m *UserRepository) BeginTx(ctx context.Context) *sql.Tx {
 	return r0
 }
 
+// CancelAccountDeletion provides a mock function with given fields: ctx, deletionID
+func (_m *UserRepository) CancelAccountDeletion(ctx context.Context, deletionID int64) error {
+	ret := _m.Called(ctx, deletionID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, deletionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CompleteAccountDeletion provides a mock function with given fields: ctx, tx, deletionID
+func (_m *UserRepository) CompleteAccountDeletion(ctx context.Context, tx *sql.Tx, deletionID int64) error {
+	ret := _m.Called(ctx, tx, deletionID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64) error); ok {
+		r0 = rf(ctx, tx, deletionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: ctx, tx, user
 func (_m *UserRepository) Create(ctx context.Context, tx *sql.Tx, user model.User) (int64, error) {
 	ret := _m.Called(ctx, tx, user)
@@ -56,6 +100,30 @@ func (_m *UserRepository) Create(ctx context.Context, tx *sql.Tx, user model.Use
 	return r0, r1
 }
 
+// IsUserActive provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) IsUserActive(ctx context.Context, userID int64) (bool, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // IsUserRegistered provides a mock function with given fields: ctx, username, email
 func (_m *UserRepository) IsUserRegistered(ctx context.Context, username string, email string) (bool, error) {
 	ret := _m.Called(ctx, username, email)
@@ -80,6 +148,32 @@ func (_m *UserRepository) IsUserRegistered(ctx context.Context, username string,
 	return r0, r1
 }
 
+// ReadByID provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) ReadByID(ctx context.Context, userID int64) (*model.User, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*model.User, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadByUsernameOrEmail provides a mock function with given fields: ctx, username, email
 func (_m *UserRepository) ReadByUsernameOrEmail(ctx context.Context, username string, email string) (*model.User, error) {
 	ret := _m.Called(ctx, username, email)
@@ -106,6 +200,58 @@ func (_m *UserRepository) ReadByUsernameOrEmail(ctx context.Context, username st
 	return r0, r1
 }
 
+// ReadDueAccountDeletions provides a mock function with given fields: ctx, now
+func (_m *UserRepository) ReadDueAccountDeletions(ctx context.Context, now time.Time) ([]model.AccountDeletion, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []model.AccountDeletion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]model.AccountDeletion, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []model.AccountDeletion); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.AccountDeletion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadLoginHistories provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) ReadLoginHistories(ctx context.Context, userID int64) ([]model.LoginHistory, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []model.LoginHistory
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]model.LoginHistory, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []model.LoginHistory); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.LoginHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadLoginHistory provides a mock function with given fields: ctx, req
 func (_m *UserRepository) ReadLoginHistory(ctx context.Context, req model.LoginHistory) (model.LoginHistory, error) {
 	ret := _m.Called(ctx, req)
@@ -130,6 +276,112 @@ func (_m *UserRepository) ReadLoginHistory(ctx context.Context, req model.LoginH
 	return r0, r1
 }
 
+// ReadLoginHistoryPage provides a mock function with given fields: ctx, userID, page
+func (_m *UserRepository) ReadLoginHistoryPage(ctx context.Context, userID int64, page model.PageRequest) ([]model.LoginHistory, int64, error) {
+	ret := _m.Called(ctx, userID, page)
+
+	var r0 []model.LoginHistory
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, model.PageRequest) ([]model.LoginHistory, int64, error)); ok {
+		return rf(ctx, userID, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, model.PageRequest) []model.LoginHistory); ok {
+		r0 = rf(ctx, userID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.LoginHistory)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, model.PageRequest) int64); ok {
+		r1 = rf(ctx, userID, page)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, model.PageRequest) error); ok {
+		r2 = rf(ctx, userID, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ReadPendingAccountDeletion provides a mock function with given fields: ctx, userID
+func (_m *UserRepository) ReadPendingAccountDeletion(ctx context.Context, userID int64) (*model.AccountDeletion, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 *model.AccountDeletion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*model.AccountDeletion, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.AccountDeletion); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AccountDeletion)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchUsers provides a mock function with given fields: ctx, req
+func (_m *UserRepository) SearchUsers(ctx context.Context, req model.UserSearchRequest) ([]model.User, int64, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 []model.User
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserSearchRequest) ([]model.User, int64, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.UserSearchRequest) []model.User); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.UserSearchRequest) int64); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, model.UserSearchRequest) error); ok {
+		r2 = rf(ctx, req)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateKYCLevel provides a mock function with given fields: ctx, tx, userID, level
+func (_m *UserRepository) UpdateKYCLevel(ctx context.Context, tx *sql.Tx, userID int64, level string) error {
+	ret := _m.Called(ctx, tx, userID, level)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64, string) error); ok {
+		r0 = rf(ctx, tx, userID, level)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateLoginHistory provides a mock function with given fields: ctx, req
 func (_m *UserRepository) UpdateLoginHistory(ctx context.Context, req model.LoginHistory) error {
 	ret := _m.Called(ctx, req)
@@ -144,6 +396,30 @@ func (_m *UserRepository) UpdateLoginHistory(ctx context.Context, req model.Logi
 	return r0
 }
 
+// WriteAccountDeletion provides a mock function with given fields: ctx, deletion
+func (_m *UserRepository) WriteAccountDeletion(ctx context.Context, deletion model.AccountDeletion) (int64, error) {
+	ret := _m.Called(ctx, deletion)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.AccountDeletion) (int64, error)); ok {
+		return rf(ctx, deletion)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.AccountDeletion) int64); ok {
+		r0 = rf(ctx, deletion)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.AccountDeletion) error); ok {
+		r1 = rf(ctx, deletion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WriteLoginHistory provides a mock function with given fields: ctx, req
 func (_m *UserRepository) WriteLoginHistory(ctx context.Context, req model.LoginHistory) error {
 	ret := _m.Called(ctx, req)
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	sql "database/sql"
+
+	time "time"
 )
 
 // WalletRepository is an autogenerated mock type for the WalletRepository type
@@ -17,14 +19,40 @@ type WalletRepository struct {
 }
 
 // AddWallet provides a mock function with given fields: ctx, tx, wallet
-func (_m *WalletRepository) AddWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
+func (_m *WalletRepository) AddWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) (int64, error) {
 	ret := _m.Called(ctx, tx, wallet)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Wallet) error); ok {
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Wallet) (int64, error)); ok {
+		return rf(ctx, tx, wallet)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Wallet) int64); ok {
 		r0 = rf(ctx, tx, wallet)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, model.Wallet) error); ok {
+		r1 = rf(ctx, tx, wallet)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BeginTx provides a mock function with given fields: ctx
+func (_m *WalletRepository) BeginTx(ctx context.Context) *sql.Tx {
+	ret := _m.Called(ctx)
+
+	var r0 *sql.Tx
+	if rf, ok := ret.Get(0).(func(context.Context) *sql.Tx); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sql.Tx)
+		}
 	}
 
 	return r0
@@ -56,13 +84,302 @@ func (_m *WalletRepository) ReadBalance(ctx context.Context, req model.CheckBala
 	return r0, r1
 }
 
-// UpdateBalance provides a mock function with given fields: _a0, _a1
-func (_m *WalletRepository) UpdateBalance(_a0 context.Context, _a1 model.Wallet) error {
-	ret := _m.Called(_a0, _a1)
+// ReadBalanceAt provides a mock function with given fields: ctx, walletID, at
+func (_m *WalletRepository) ReadBalanceAt(ctx context.Context, walletID int64, at time.Time) (float64, error) {
+	ret := _m.Called(ctx, walletID, at)
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) (float64, error)); ok {
+		return rf(ctx, walletID, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) float64); ok {
+		r0 = rf(ctx, walletID, at)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time) error); ok {
+		r1 = rf(ctx, walletID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadBalanceForUpdate provides a mock function with given fields: ctx, tx, req
+func (_m *WalletRepository) ReadBalanceForUpdate(ctx context.Context, tx *sql.Tx, req model.CheckBalanceRequest) (*model.Wallet, error) {
+	ret := _m.Called(ctx, tx, req)
+
+	var r0 *model.Wallet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.CheckBalanceRequest) (*model.Wallet, error)); ok {
+		return rf(ctx, tx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.CheckBalanceRequest) *model.Wallet); ok {
+		r0 = rf(ctx, tx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Wallet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, model.CheckBalanceRequest) error); ok {
+		r1 = rf(ctx, tx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadByIDForUpdate provides a mock function with given fields: ctx, tx, walletID
+func (_m *WalletRepository) ReadByIDForUpdate(ctx context.Context, tx *sql.Tx, walletID int64) (*model.Wallet, error) {
+	ret := _m.Called(ctx, tx, walletID)
+
+	var r0 *model.Wallet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64) (*model.Wallet, error)); ok {
+		return rf(ctx, tx, walletID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64) *model.Wallet); ok {
+		r0 = rf(ctx, tx, walletID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Wallet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, int64) error); ok {
+		r1 = rf(ctx, tx, walletID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadByUserID provides a mock function with given fields: ctx, userID, kind
+func (_m *WalletRepository) ReadByUserID(ctx context.Context, userID int64, kind string) ([]model.Wallet, error) {
+	ret := _m.Called(ctx, userID, kind)
+
+	var r0 []model.Wallet
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) ([]model.Wallet, error)); ok {
+		return rf(ctx, userID, kind)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []model.Wallet); ok {
+		r0 = rf(ctx, userID, kind)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Wallet)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, userID, kind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadLedgerBalances provides a mock function with given fields: ctx
+func (_m *WalletRepository) ReadLedgerBalances(ctx context.Context) ([]model.LedgerBalance, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []model.LedgerBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]model.LedgerBalance, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []model.LedgerBalance); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.LedgerBalance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadStatusChanges provides a mock function with given fields: ctx, walletID
+func (_m *WalletRepository) ReadStatusChanges(ctx context.Context, walletID int64) ([]model.WalletStatusChange, error) {
+	ret := _m.Called(ctx, walletID)
+
+	var r0 []model.WalletStatusChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]model.WalletStatusChange, error)); ok {
+		return rf(ctx, walletID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []model.WalletStatusChange); ok {
+		r0 = rf(ctx, walletID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.WalletStatusChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, walletID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadTransactionByID provides a mock function with given fields: ctx, trxID
+func (_m *WalletRepository) ReadTransactionByID(ctx context.Context, trxID int64) (*model.Transaction, error) {
+	ret := _m.Called(ctx, trxID)
+
+	var r0 *model.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*model.Transaction, error)); ok {
+		return rf(ctx, trxID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *model.Transaction); ok {
+		r0 = rf(ctx, trxID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, trxID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadTransactionPage provides a mock function with given fields: ctx, walletID, page
+func (_m *WalletRepository) ReadTransactionPage(ctx context.Context, walletID int64, page model.PageRequest) ([]model.Transaction, int64, error) {
+	ret := _m.Called(ctx, walletID, page)
+
+	var r0 []model.Transaction
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, model.PageRequest) ([]model.Transaction, int64, error)); ok {
+		return rf(ctx, walletID, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, model.PageRequest) []model.Transaction); ok {
+		r0 = rf(ctx, walletID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, model.PageRequest) int64); ok {
+		r1 = rf(ctx, walletID, page)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, model.PageRequest) error); ok {
+		r2 = rf(ctx, walletID, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ReadTransactions provides a mock function with given fields: ctx, walletID
+func (_m *WalletRepository) ReadTransactions(ctx context.Context, walletID int64) ([]model.Transaction, error) {
+	ret := _m.Called(ctx, walletID)
+
+	var r0 []model.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]model.Transaction, error)); ok {
+		return rf(ctx, walletID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []model.Transaction); ok {
+		r0 = rf(ctx, walletID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, walletID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadTransactionsBetween provides a mock function with given fields: ctx, walletID, from, to
+func (_m *WalletRepository) ReadTransactionsBetween(ctx context.Context, walletID int64, from time.Time, to time.Time) ([]model.Transaction, error) {
+	ret := _m.Called(ctx, walletID, from, to)
+
+	var r0 []model.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time, time.Time) ([]model.Transaction, error)); ok {
+		return rf(ctx, walletID, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time, time.Time) []model.Transaction); ok {
+		r0 = rf(ctx, walletID, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, walletID, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SumTransactionVolume provides a mock function with given fields: ctx, tx, walletID, since
+func (_m *WalletRepository) SumTransactionVolume(ctx context.Context, tx *sql.Tx, walletID int64, since time.Time) (float64, error) {
+	ret := _m.Called(ctx, tx, walletID, since)
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64, time.Time) (float64, error)); ok {
+		return rf(ctx, tx, walletID, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, int64, time.Time) float64); ok {
+		r0 = rf(ctx, tx, walletID, since)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, int64, time.Time) error); ok {
+		r1 = rf(ctx, tx, walletID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateBalance provides a mock function with given fields: ctx, tx, wallet
+func (_m *WalletRepository) UpdateBalance(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
+	ret := _m.Called(ctx, tx, wallet)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, model.Wallet) error); ok {
-		r0 = rf(_a0, _a1)
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Wallet) error); ok {
+		r0 = rf(ctx, tx, wallet)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -70,6 +387,68 @@ func (_m *WalletRepository) UpdateBalance(_a0 context.Context, _a1 model.Wallet)
 	return r0
 }
 
+// UpdateStatus provides a mock function with given fields: ctx, tx, wallet, change
+func (_m *WalletRepository) UpdateStatus(ctx context.Context, tx *sql.Tx, wallet model.Wallet, change model.WalletStatusChange) error {
+	ret := _m.Called(ctx, tx, wallet, change)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Wallet, model.WalletStatusChange) error); ok {
+		r0 = rf(ctx, tx, wallet, change)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteAdjustment provides a mock function with given fields: ctx, tx, adjustment
+func (_m *WalletRepository) WriteAdjustment(ctx context.Context, tx *sql.Tx, adjustment model.Adjustment) (int64, error) {
+	ret := _m.Called(ctx, tx, adjustment)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Adjustment) (int64, error)); ok {
+		return rf(ctx, tx, adjustment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Adjustment) int64); ok {
+		r0 = rf(ctx, tx, adjustment)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, model.Adjustment) error); ok {
+		r1 = rf(ctx, tx, adjustment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WriteTransaction provides a mock function with given fields: ctx, tx, trx
+func (_m *WalletRepository) WriteTransaction(ctx context.Context, tx *sql.Tx, trx model.Transaction) (int64, error) {
+	ret := _m.Called(ctx, tx, trx)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Transaction) (int64, error)); ok {
+		return rf(ctx, tx, trx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *sql.Tx, model.Transaction) int64); ok {
+		r0 = rf(ctx, tx, trx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *sql.Tx, model.Transaction) error); ok {
+		r1 = rf(ctx, tx, trx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewWalletRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,132 @@
+package model
+
+import "time"
+
+// Page sizes for admin lists.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Sort orders of admin lists.
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+// PageRequest selects a page of an admin list. Page counts from 1; Sort names
+// one of the fields the list can be sorted by, and Order defaults to desc.
+type PageRequest struct {
+	Page    int    `query:"page" validate:"gte=0"`
+	PerPage int    `query:"per_page" validate:"gte=0,lte=100"`
+	Sort    string `query:"sort"`
+	Order   string `query:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+// Normalize fills in the defaults of an empty page request.
+func (p PageRequest) Normalize() PageRequest {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = DefaultPageSize
+	}
+	if p.PerPage > MaxPageSize {
+		p.PerPage = MaxPageSize
+	}
+	if p.Order == "" {
+		p.Order = SortDesc
+	}
+
+	return p
+}
+
+// Offset is the number of items before the page.
+func (p PageRequest) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Page is a page of an admin list, with the total number of items in the
+// list.
+type Page struct {
+	Items   interface{} `json:"items"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"per_page"`
+	Total   int64       `json:"total"`
+}
+
+// UserSearchRequest finds users whose name, email, phone and username contain
+// the given parts. Empty parts match every user.
+type UserSearchRequest struct {
+	Name     string `query:"name"`
+	Email    string `query:"email"`
+	Phone    string `query:"phone"`
+	Username string `query:"username"`
+	PageRequest
+}
+
+// AdminUser is a user as admins see them, without credentials.
+type AdminUser struct {
+	ID            int64     `json:"id"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	BirthDate     string    `json:"birth_date"`
+	StreetAddress string    `json:"street_address"`
+	City          string    `json:"city"`
+	Province      string    `json:"province"`
+	Phone         string    `json:"phone"`
+	Email         string    `json:"email"`
+	Username      string    `json:"username"`
+	Role          string    `json:"role"`
+	KYCLevel      string    `json:"kyc_level"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NewAdminUser presents user to admins.
+func NewAdminUser(user User) AdminUser {
+	return AdminUser{
+		ID:            user.ID,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		BirthDate:     user.BirthDate,
+		StreetAddress: user.StreetAddress,
+		City:          user.City,
+		Province:      user.Province,
+		Phone:         user.Phone,
+		Email:         user.Email,
+		Username:      user.Username,
+		Role:          user.Role,
+		KYCLevel:      user.KYCLevel,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+}
+
+// AdminUserDetail is a user with their wallets and most recent logins.
+type AdminUserDetail struct {
+	User         AdminUser      `json:"user"`
+	Wallets      []Wallet       `json:"wallets"`
+	LoginHistory []LoginHistory `json:"login_history"`
+}
+
+// AdjustmentRequest credits a wallet by a positive Amount or debits it by a
+// negative one, outside of any payment. Only admins may make one, and the
+// reason is kept as the description of the ledger entry.
+type AdjustmentRequest struct {
+	WalletID int64   `json:"-"`
+	Amount   float64 `json:"amount" validate:"required,ne=0"`
+	Reason   string  `json:"reason" validate:"required,max=255"`
+	Actor    User    `json:"-"`
+}
+
+// Adjustment records who made a manual balance adjustment.
+type Adjustment struct {
+	ID            int64     `json:"id"`
+	WalletID      int64     `json:"wallet_id"`
+	TransactionID int64     `json:"transaction_id"`
+	Amount        float64   `json:"amount"`
+	Reason        string    `json:"reason"`
+	AdjustedBy    int64     `json:"adjusted_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
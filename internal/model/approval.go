@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Pending action types. Each is a change one admin proposes and a second
+// admin has to approve before it is made.
+const (
+	PendingActionAdjustment = "balance_adjustment"
+	PendingActionTopUp      = "wallet_top_up"
+	PendingActionUnfreeze   = "wallet_unfreeze"
+	PendingActionLimitRule  = "limit_rule"
+)
+
+// Pending action statuses.
+const (
+	PendingActionPending  = "pending"
+	PendingActionApproved = "approved"
+	PendingActionRejected = "rejected"
+	PendingActionExpired  = "expired"
+)
+
+// PendingAction is a proposed admin action waiting for a second admin.
+// TargetID is the wallet adjusted, topped up or unfrozen, or the limit rule changed,
+// zero for a new rule. Payload is the request as proposed, and Result what
+// the action made once approved.
+type PendingAction struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	TargetID   int64           `json:"target_id"`
+	Payload    json.RawMessage `json:"payload" swaggertype:"object"`
+	Status     string          `json:"status"`
+	ProposedBy int64           `json:"proposed_by"`
+	ReviewedBy *int64          `json:"reviewed_by,omitempty"`
+	ReviewNote string          `json:"review_note,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	ExpiresAt  time.Time       `json:"expires_at"`
+	ReviewedAt *time.Time      `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// Expired reports whether the action can no longer be approved at now.
+func (a PendingAction) Expired(now time.Time) bool {
+	return !now.Before(a.ExpiresAt)
+}
+
+// PendingActionReview approves or rejects a pending action.
+type PendingActionReview struct {
+	ActionID int64  `json:"-"`
+	Note     string `json:"note" validate:"max=255"`
+	Reviewer User   `json:"-"`
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit actions.
+const (
+	AuditLogin               = "auth.login"
+	AuditLoginFailed         = "auth.login_failed"
+	AuditUserRegistered      = "user.registered"
+	AuditProfileChanged      = "user.profile_changed"
+	AuditAdminAction         = "admin.action"
+	AuditBalanceChanged      = "wallet.balance_changed"
+	AuditWalletStatusChanged = "wallet.status_changed"
+	AuditActionProposed      = "approval.proposed"
+	AuditActionApproved      = "approval.approved"
+	AuditActionRejected      = "approval.rejected"
+	AuditActionExpired       = "approval.expired"
+)
+
+// Audit target types.
+const (
+	AuditTargetUser   = "user"
+	AuditTargetWallet = "wallet"
+	AuditTargetRoute  = "route"
+	// AuditTargetPendingAction is an admin action waiting for approval.
+	AuditTargetPendingAction = "pending_action"
+)
+
+// AuditEntry records who did what to what, from where, and the values before
+// and after. Entries are chained once sealed: Seq numbers them without gaps
+// and Hash covers the entry and the Hash of the one before, so an entry that
+// is changed or deleted breaks the chain. ActorID is zero for anonymous
+// callers and the system.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Seq        int64           `json:"seq"`
+	Action     string          `json:"action"`
+	ActorID    int64           `json:"actor_id"`
+	TargetType string          `json:"target_type"`
+	TargetID   int64           `json:"target_id"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	Before     json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditHead is the last sealed entry of the audit log.
+type AuditHead struct {
+	Seq  int64
+	Hash string
+}
+
+// AuditFilter selects audit entries. Zero fields match every entry.
+type AuditFilter struct {
+	Action     string    `query:"action"`
+	ActorID    int64     `query:"actor_id"`
+	TargetType string    `query:"target_type"`
+	TargetID   int64     `query:"target_id"`
+	From       time.Time `query:"from"`
+	To         time.Time `query:"to"`
+	PageRequest
+}
+
+// AuditVerification is the outcome of checking the audit log chain. When it
+// is broken, BrokenAt is the sequence number of the first entry that does
+// not check out, and Problem says why. Stale counts the unsealed entries
+// that should have been sealed by now; the log is not valid while there are
+// any, as they could have been changed without it showing.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Sealed   int64  `json:"sealed"`
+	Unsealed int64  `json:"unsealed"`
+	Stale    int64  `json:"stale"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Problem  string `json:"problem,omitempty"`
+}
@@ -7,6 +7,7 @@ type JwtCustomClaims struct {
 	Username string `json:"username"`
 	Exp      int64  `json:"exp"`
 	UserID   int64  `json:"user_id"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
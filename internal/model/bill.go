@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// Bill statuses. A bill is settled once none of its shares is left to pay
+// and expired when the shares left unpaid have passed their expiry.
+const (
+	BillStatusOpen      = "open"
+	BillStatusSettled   = "settled"
+	BillStatusExpired   = "expired"
+	BillStatusCancelled = "cancelled"
+)
+
+// Bill splits an expense between its organizer and other users. Each
+// participant's share is a payment request to the organizer; PaidAmount is
+// the part of the shares paid so far.
+type Bill struct {
+	ID          int64            `json:"id"`
+	OrganizerID int64            `json:"organizer_id"`
+	WalletID    int64            `json:"-"`
+	Title       string           `json:"title"`
+	Amount      float64          `json:"amount"`
+	PaidAmount  float64          `json:"paid_amount"`
+	Status      string           `json:"status"`
+	Shares      []PaymentRequest `json:"shares"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// CreateBillRequest splits Amount between the organizer and the listed
+// participants. When no share sets an amount the bill is split equally,
+// with the organizer covering what does not divide evenly; otherwise every
+// share must set one and the organizer covers the rest.
+type CreateBillRequest struct {
+	Title            string             `json:"title" validate:"required,max=128"`
+	Amount           float64            `json:"amount" validate:"gt=0"`
+	Shares           []BillShareRequest `json:"shares" validate:"required,min=1,max=20,dive"`
+	ExpiresInSeconds int64              `json:"expires_in_seconds" validate:"gte=0,lte=2592000"`
+	Address          string             `json:"address"`
+	UserID           int64              `json:"user_id"`
+}
+
+// BillShareRequest names a participant by username or email.
+type BillShareRequest struct {
+	Participant string  `json:"participant" validate:"required,max=64"`
+	Amount      float64 `json:"amount" validate:"gte=0"`
+}
+
+// BillReminder is the data of a bill.reminder event, sent to a participant
+// who has yet to pay their share of a bill. Code is the code to pay the
+// share's payment request with.
+type BillReminder struct {
+	BillID      int64     `json:"bill_id"`
+	Title       string    `json:"title"`
+	OrganizerID int64     `json:"organizer_id"`
+	Code        string    `json:"code"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Reminders   int       `json:"reminders"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// Escrow statuses. A funded escrow is released to the seller or refunded to
+// the buyer, by the party entitled to or by its timeout. A disputed escrow
+// no longer times out and is resolved by staff.
+const (
+	EscrowStatusFunded   = "funded"
+	EscrowStatusDisputed = "disputed"
+	EscrowStatusReleased = "released"
+	EscrowStatusRefunded = "refunded"
+)
+
+// Escrow resolutions, applied by a party or on timeout.
+const (
+	EscrowActionRelease = "release"
+	EscrowActionRefund  = "refund"
+)
+
+// Escrow locks a buyer's payment to a merchant in the platform's escrow
+// wallet until it is released to the merchant or refunded to the buyer.
+// Every ledger entry it causes carries its Reference.
+type Escrow struct {
+	ID             int64         `json:"id"`
+	BuyerID        int64         `json:"buyer_id"`
+	BuyerWalletID  int64         `json:"buyer_wallet_id"`
+	MerchantID     int64         `json:"merchant_id"`
+	EscrowWalletID int64         `json:"-"`
+	Amount         float64       `json:"amount"`
+	Status         string        `json:"status"`
+	TimeoutAction  string        `json:"timeout_action"`
+	Reference      string        `json:"reference"`
+	Description    string        `json:"description"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	ResolvedAt     *time.Time    `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Events         []EscrowEvent `json:"events,omitempty"`
+}
+
+// Resolved reports whether the escrow's funds have left the escrow wallet.
+func (e Escrow) Resolved() bool {
+	return e.Status == EscrowStatusReleased || e.Status == EscrowStatusRefunded
+}
+
+// EscrowEvent records a transition of an escrow. ActorID is empty for
+// transitions made on timeout; TransactionID is the ledger entry that moved
+// the funds, if any did.
+type EscrowEvent struct {
+	ID            int64     `json:"id"`
+	EscrowID      int64     `json:"escrow_id"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `json:"to_status"`
+	ActorID       *int64    `json:"actor_id,omitempty"`
+	TransactionID *int64    `json:"transaction_id,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// EscrowRequest funds an escrow for a merchant. Unless resolved earlier it
+// times out after ExpiresInSeconds and TimeoutAction is applied.
+type EscrowRequest struct {
+	MerchantID       int64   `json:"merchant_id" validate:"required"`
+	Amount           float64 `json:"amount" validate:"gt=0"`
+	ExpiresInSeconds int64   `json:"expires_in_seconds" validate:"gte=0,lte=2592000"`
+	TimeoutAction    string  `json:"timeout_action" validate:"omitempty,oneof=release refund"`
+	Description      string  `json:"description" validate:"max=255"`
+	Address          string  `json:"address"`
+	UserID           int64   `json:"user_id"`
+}
+
+// EscrowActionRequest releases, refunds or disputes an escrow.
+type EscrowActionRequest struct {
+	Note      string `json:"note" validate:"max=255"`
+	Requester User   `json:"-"`
+}
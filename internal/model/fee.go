@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// Fee rule types. Flat charges a fixed amount, Percentage a share of the
+// operation amount and Tiered picks the first tier the amount falls into.
+const (
+	FeeTypeFlat       = "flat"
+	FeeTypePercentage = "percentage"
+	FeeTypeTiered     = "tiered"
+)
+
+// FeeTier is one bracket of a tiered fee. It applies to amounts up to and
+// including UpTo; the last tier may leave UpTo at zero to cover any amount.
+type FeeTier struct {
+	UpTo       float64 `json:"up_to" validate:"gte=0"`
+	Flat       float64 `json:"flat" validate:"gte=0"`
+	Percentage float64 `json:"percentage" validate:"gte=0,lte=100"`
+}
+
+// FeeRule prices one operation type, which matches the ledger transaction
+// type. A rule with a MerchantID replaces the global rule for payments to
+// that merchant. The computed fee is clamped to MinFee and, when set, MaxFee.
+type FeeRule struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Operation  string    `json:"operation"`
+	Type       string    `json:"type"`
+	Flat       float64   `json:"flat"`
+	Percentage float64   `json:"percentage"`
+	Tiers      []FeeTier `json:"tiers"`
+	MinFee     float64   `json:"min_fee"`
+	MaxFee     float64   `json:"max_fee"`
+	MerchantID *int64    `json:"merchant_id,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type FeeRuleRequest struct {
+	Name       string    `json:"name" validate:"required,max=64"`
+	Operation  string    `json:"operation" validate:"required,oneof=top_up payment transfer"`
+	Type       string    `json:"type" validate:"required,oneof=flat percentage tiered"`
+	Flat       float64   `json:"flat" validate:"gte=0"`
+	Percentage float64   `json:"percentage" validate:"gte=0,lte=100"`
+	Tiers      []FeeTier `json:"tiers" validate:"dive"`
+	MinFee     float64   `json:"min_fee" validate:"gte=0"`
+	MaxFee     float64   `json:"max_fee" validate:"gte=0"`
+	MerchantID *int64    `json:"merchant_id"`
+	Active     bool      `json:"active"`
+}
+
+type FeePreviewRequest struct {
+	Operation  string  `query:"operation" validate:"required,oneof=top_up payment transfer"`
+	Amount     float64 `query:"amount" validate:"gt=0"`
+	MerchantID int64   `query:"merchant_id"`
+}
+
+// FeeQuote is the fee an operation will be charged. The fee is always a
+// separate debit on the caller's wallet, so Net is the total change of its
+// balance: the amount less the fee for a top up and the amount plus the fee,
+// negated, for a payment.
+type FeeQuote struct {
+	Operation       string  `json:"operation"`
+	Amount          float64 `json:"amount"`
+	Fee             float64 `json:"fee"`
+	Net             float64 `json:"net"`
+	RuleID          *int64  `json:"rule_id,omitempty"`
+	RevenueWalletID int64   `json:"-"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// Hold statuses. An authorized hold reserves funds until it is captured,
+// voided or reaches its expiry.
+const (
+	HoldStatusAuthorized = "authorized"
+	HoldStatusCaptured   = "captured"
+	HoldStatusVoided     = "voided"
+	HoldStatusExpired    = "expired"
+)
+
+// Hold reserves part of a wallet's balance without moving money. Capturing
+// it pays CapturedAmount to the merchant and releases the remainder.
+type Hold struct {
+	ID             int64     `json:"id"`
+	WalletID       int64     `json:"wallet_id"`
+	MerchantID     int64     `json:"merchant_id"`
+	Amount         float64   `json:"amount"`
+	CapturedAmount float64   `json:"captured_amount"`
+	Status         string    `json:"status"`
+	Reference      string    `json:"reference"`
+	Description    string    `json:"description"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Active reports whether the hold still reserves funds at the given time.
+func (h Hold) Active(at time.Time) bool {
+	return h.Status == HoldStatusAuthorized && h.ExpiresAt.After(at)
+}
+
+type AuthorizeRequest struct {
+	MerchantID       int64   `json:"merchant_id" validate:"required"`
+	Amount           float64 `json:"amount" validate:"gt=0"`
+	ExpiresInSeconds int64   `json:"expires_in_seconds" validate:"gte=0,lte=2592000"`
+	Description      string  `json:"description" validate:"max=255"`
+	Address          string  `json:"address"`
+	UserID           int64   `json:"user_id"`
+}
+
+// CaptureRequest settles a hold. A zero Amount captures the full hold.
+type CaptureRequest struct {
+	Amount float64 `json:"amount" validate:"gte=0"`
+	UserID int64   `json:"user_id"`
+}
+
+type VoidRequest struct {
+	UserID int64 `json:"user_id"`
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// KYC levels, from least to most verified.
+const (
+	KYCLevelUnverified = "unverified"
+	KYCLevelBasic      = "basic"
+	KYCLevelFull       = "full"
+)
+
+// KYC document review statuses.
+const (
+	KYCDocumentPending  = "pending"
+	KYCDocumentApproved = "approved"
+	KYCDocumentRejected = "rejected"
+)
+
+type KYCLimit struct {
+	MaxBalance       float64 `json:"max_balance"`
+	DailyTransaction float64 `json:"daily_transaction"`
+}
+
+// KYCLimits are the wallet limits for every KYC level. DailyTransaction caps
+// the total amount of money sent out of a wallet per calendar day.
+var KYCLimits = map[string]KYCLimit{
+	KYCLevelUnverified: {MaxBalance: 2000000, DailyTransaction: 2000000},
+	KYCLevelBasic:      {MaxBalance: 10000000, DailyTransaction: 20000000},
+	KYCLevelFull:       {MaxBalance: 20000000, DailyTransaction: 100000000},
+}
+
+// DailyLimitTypes are the ledger types counted toward the DailyTransaction
+// limit: the money a wallet's owner sends out. Fees, refunds and incoming
+// credits are not.
+var DailyLimitTypes = []string{
+	TransactionTypePayment,
+	TransactionTypeTransfer,
+	TransactionTypeWithdrawal,
+	TransactionTypeEscrowFund,
+}
+
+type KYCDocument struct {
+	ID             int64      `json:"id"`
+	UserID         int64      `json:"user_id"`
+	DocumentType   string     `json:"document_type"`
+	RequestedLevel string     `json:"requested_level"`
+	StorageKey     string     `json:"-"`
+	ContentType    string     `json:"content_type"`
+	Status         string     `json:"status"`
+	ReviewerID     *int64     `json:"reviewer_id,omitempty"`
+	ReviewNote     string     `json:"review_note,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+type KYCDocumentRequest struct {
+	DocumentType   string `form:"document_type" validate:"required,oneof=id_card passport driving_license selfie"`
+	RequestedLevel string `form:"requested_level" validate:"required,oneof=basic full"`
+	UserID         int64  `form:"-"`
+}
+
+type KYCReviewRequest struct {
+	Approve    bool   `json:"approve"`
+	Note       string `json:"note" validate:"max=255"`
+	DocumentID int64  `json:"-"`
+	ReviewerID int64  `json:"-"`
+}
+
+type KYCStatusResponse struct {
+	Level     string        `json:"level"`
+	Limit     KYCLimit      `json:"limit"`
+	Documents []KYCDocument `json:"documents"`
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limit rule types. Count caps the number of operations and Amount caps the
+// total amount moved within a sliding window; Single caps one operation.
+const (
+	LimitTypeCount  = "count"
+	LimitTypeAmount = "amount"
+	LimitTypeSingle = "single"
+)
+
+// LimitRule is a risk rule applied to one operation type, which matches the
+// ledger transaction type. Rules with a UserID override the global rule of
+// the same type and window for that user only.
+type LimitRule struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Operation     string    `json:"operation"`
+	Type          string    `json:"type"`
+	Threshold     float64   `json:"threshold"`
+	WindowSeconds int64     `json:"window_seconds"`
+	UserID        *int64    `json:"user_id,omitempty"`
+	Active        bool      `json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type LimitRuleRequest struct {
+	Name          string  `json:"name" validate:"required,max=64"`
+	Operation     string  `json:"operation" validate:"required,oneof=top_up payment transfer withdrawal"`
+	Type          string  `json:"type" validate:"required,oneof=count amount single"`
+	Threshold     float64 `json:"threshold" validate:"gt=0"`
+	WindowSeconds int64   `json:"window_seconds" validate:"gte=0"`
+	UserID        *int64  `json:"user_id"`
+	Active        bool    `json:"active"`
+}
+
+// limitedEntryTypes lists the ledger types counted toward the limits on
+// operations that are posted under more than one type. Funding an escrow
+// pays a merchant, so it uses up the payment limits.
+var limitedEntryTypes = map[string][]string{
+	TransactionTypePayment: {TransactionTypePayment, TransactionTypeEscrowFund},
+}
+
+// LimitedEntryTypes returns the ledger types counted toward the limits on
+// operation.
+func LimitedEntryTypes(operation string) []string {
+	if types, ok := limitedEntryTypes[operation]; ok {
+		return types
+	}
+	return []string{operation}
+}
+
+// LimitUsage summarizes a wallet's ledger entries counted toward one
+// operation's limits within a window.
+type LimitUsage struct {
+	Count  int64
+	Amount float64
+	Oldest *time.Time
+}
+
+// LimitExceededError reports which rule rejected an operation, how far the
+// wallet already got and, for windowed rules, when usage starts to free up.
+type LimitExceededError struct {
+	RuleID    int64      `json:"rule_id"`
+	Rule      string     `json:"rule"`
+	Operation string     `json:"operation"`
+	Type      string     `json:"type"`
+	Threshold float64    `json:"threshold"`
+	Current   float64    `json:"current"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"`
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("transaction limit %q exceeded", e.Rule)
+}
+
+type LimitExceededResponse struct {
+	Message string              `json:"errors"`
+	Limit   *LimitExceededError `json:"limit"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import "time"
+
+// Merchant statuses. Only active merchants can receive payments.
+const (
+	MerchantStatusPending   = "pending"
+	MerchantStatusActive    = "active"
+	MerchantStatusSuspended = "suspended"
+)
+
+// Merchant receives payments into its own settlement wallet, separate from
+// the personal wallet of the user who owns it.
+type Merchant struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	WalletID  int64     `json:"wallet_id"`
+	Balance   float64   `json:"balance"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type MerchantRequest struct {
+	Name string `json:"name" validate:"required,max=128"`
+}
+
+type MerchantStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active suspended"`
+}
+
+// MerchantFilter selects merchants. A zero UserID matches every owner and an
+// empty Status every status.
+type MerchantFilter struct {
+	UserID int64
+	Status string
+}
+
+// MerchantPayment links a payment to the merchant it was made to.
+// TransactionID is the payer's debit and CreditTransactionID the credit on
+// the merchant's settlement wallet.
+type MerchantPayment struct {
+	ID                  int64     `json:"id"`
+	MerchantID          int64     `json:"merchant_id"`
+	TransactionID       int64     `json:"transaction_id"`
+	CreditTransactionID int64     `json:"credit_transaction_id"`
+	Amount              float64   `json:"amount"`
+	Reference           string    `json:"reference"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// MerchantQR is a merchant-presented QR code. Payload is the EMV string the
+// code encodes and Image the code itself as a PNG.
+type MerchantQR struct {
+	MerchantID int64   `json:"merchant_id"`
+	Amount     float64 `json:"amount,omitempty"`
+	Payload    string  `json:"payload"`
+	Image      []byte  `json:"image"`
+}
+
+type MerchantQRRequest struct {
+	Amount float64 `query:"amount" validate:"gte=0"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is a domain event recorded in the same transaction as the
+// change it reports, waiting to be relayed to the message broker. Type is
+// one of the webhook event types; Key is the ID of the user the event is
+// about.
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	EventID       string          `json:"event_id"`
+	Type          string          `json:"type"`
+	Key           string          `json:"key"`
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// DomainEvent is the body of a relayed outbox event.
+type DomainEvent struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
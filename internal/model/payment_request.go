@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// Payment request statuses. A pending request can be paid once, until it is
+// cancelled by its requester or reaches its expiry.
+const (
+	PaymentRequestPending   = "pending"
+	PaymentRequestPaid      = "paid"
+	PaymentRequestExpired   = "expired"
+	PaymentRequestCancelled = "cancelled"
+)
+
+// CurrencyIDR is the currency every wallet is kept in.
+const CurrencyIDR = "IDR"
+
+// PaymentRequest asks for a payment into the requester's wallet. Anyone with
+// the code can pay it unless it names a payer; once paid, PayerID is the
+// user who paid. BillID is set on the requests for the shares of a bill.
+type PaymentRequest struct {
+	ID            int64      `json:"id"`
+	Code          string     `json:"code"`
+	Link          string     `json:"link,omitempty"`
+	RequesterID   int64      `json:"requester_id"`
+	WalletID      int64      `json:"-"`
+	PayerID       *int64     `json:"payer_id,omitempty"`
+	BillID        *int64     `json:"bill_id,omitempty"`
+	Amount        float64    `json:"amount"`
+	Currency      string     `json:"currency"`
+	Description   string     `json:"description"`
+	Status        string     `json:"status"`
+	TransactionID *int64     `json:"transaction_id,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	Reminders     int        `json:"reminders"`
+	RemindedAt    *time.Time `json:"reminded_at,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// Expired reports whether a pending request has passed its expiry at the
+// given time.
+func (r PaymentRequest) Expired(at time.Time) bool {
+	return r.Status == PaymentRequestPending && !r.ExpiresAt.After(at)
+}
+
+type CreatePaymentRequest struct {
+	Amount           float64 `json:"amount" validate:"gt=0"`
+	Currency         string  `json:"currency" validate:"omitempty,oneof=IDR"`
+	Description      string  `json:"description" validate:"max=255"`
+	Payer            string  `json:"payer" validate:"max=64"`
+	ExpiresInSeconds int64   `json:"expires_in_seconds" validate:"gte=0,lte=2592000"`
+	Address          string  `json:"address"`
+	UserID           int64   `json:"user_id"`
+}
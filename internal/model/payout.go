@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// Payout statuses. A pending payout has left the wallet but has not been
+// accepted by the bank yet; a processing one has. A failed payout is
+// credited back to the wallet.
+const (
+	PayoutStatusPending    = "pending"
+	PayoutStatusProcessing = "processing"
+	PayoutStatusSucceeded  = "succeeded"
+	PayoutStatusFailed     = "failed"
+)
+
+// BankAccount is an external account a user withdraws to. A removed
+// account is kept for the payouts already made to it.
+type BankAccount struct {
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"user_id"`
+	BankCode      string     `json:"bank_code"`
+	AccountNumber string     `json:"account_number"`
+	AccountName   string     `json:"account_name"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeletedAt     *time.Time `json:"-"`
+}
+
+type BankAccountRequest struct {
+	BankCode      string `json:"bank_code" validate:"required,max=16"`
+	AccountNumber string `json:"account_number" validate:"required,numeric,min=6,max=20"`
+	AccountName   string `json:"account_name" validate:"required,max=64"`
+	UserID        int64  `json:"user_id"`
+}
+
+// Payout withdraws funds from a wallet to a bank account. The wallet is
+// debited when the payout is requested, under Reference; a failed payout
+// is reverted by a credit under the same reference.
+type Payout struct {
+	ID                    int64     `json:"id"`
+	UserID                int64     `json:"user_id"`
+	WalletID              int64     `json:"-"`
+	BankAccountID         int64     `json:"bank_account_id"`
+	Amount                float64   `json:"amount"`
+	Currency              string    `json:"currency"`
+	Status                string    `json:"status"`
+	Reference             string    `json:"reference"`
+	ProviderReference     string    `json:"provider_reference,omitempty"`
+	FailureReason         string    `json:"failure_reason,omitempty"`
+	TransactionID         int64     `json:"transaction_id"`
+	ReversalTransactionID *int64    `json:"reversal_transaction_id,omitempty"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// Finalized reports whether the bank has settled the payout either way.
+func (p Payout) Finalized() bool {
+	return p.Status == PayoutStatusSucceeded || p.Status == PayoutStatusFailed
+}
+
+// WithdrawRequest pays out of the caller's wallet, or out of the settlement
+// wallet of a merchant they own when MerchantID is set.
+type WithdrawRequest struct {
+	BankAccountID int64   `json:"bank_account_id" validate:"required"`
+	MerchantID    int64   `json:"merchant_id"`
+	Amount        float64 `json:"amount" validate:"gt=0"`
+	Address       string  `json:"address"`
+	UserID        int64   `json:"user_id"`
+}
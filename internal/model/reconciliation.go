@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// Discrepancy kinds found by reconciliation.
+const (
+	// DiscrepancyBalanceMismatch is a wallet whose stored balance differs
+	// from the sum of its ledger entries.
+	DiscrepancyBalanceMismatch = "balance_mismatch"
+	// DiscrepancyUnknownCharge is a charge the gateway settled with no
+	// top-up intent behind it.
+	DiscrepancyUnknownCharge = "unknown_charge"
+	// DiscrepancyNotCredited is a charge the gateway settled as paid whose
+	// top-up was never credited.
+	DiscrepancyNotCredited = "not_credited"
+	// DiscrepancyAmountMismatch is a charge the gateway settled for another
+	// amount than its top-up intent.
+	DiscrepancyAmountMismatch = "amount_mismatch"
+	// DiscrepancyNotSettled is a credited top-up the gateway did not settle
+	// as paid.
+	DiscrepancyNotSettled = "not_settled"
+)
+
+// LedgerBalance is a wallet's stored balance next to the balance
+// recomputed from its Entries ledger entries.
+type LedgerBalance struct {
+	WalletID      int64
+	UserID        int64
+	Balance       float64
+	LedgerBalance float64
+	Entries       int
+}
+
+// Discrepancy is a difference between what the books say and what they
+// should say. Expected is the ledger's or the gateway's figure, Actual the
+// stored one.
+type Discrepancy struct {
+	Kind      string  `json:"kind"`
+	WalletID  int64   `json:"wallet_id,omitempty"`
+	Reference string  `json:"reference,omitempty"`
+	Expected  float64 `json:"expected"`
+	Actual    float64 `json:"actual"`
+	Detail    string  `json:"detail"`
+}
+
+type ReconciliationReport struct {
+	GeneratedAt       time.Time     `json:"generated_at"`
+	WalletsChecked    int           `json:"wallets_checked"`
+	SettlementRecords int           `json:"settlement_records"`
+	Discrepancies     []Discrepancy `json:"discrepancies"`
+}
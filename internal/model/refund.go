@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Refund returns part or all of a payment to the payer. RefundTransactionID
+// is the reversing ledger entry that credited the payer's wallet.
+type Refund struct {
+	ID                  int64     `json:"id"`
+	TransactionID       int64     `json:"transaction_id"`
+	RefundTransactionID int64     `json:"refund_transaction_id"`
+	Amount              float64   `json:"amount"`
+	Reason              string    `json:"reason"`
+	CreatedBy           int64     `json:"created_by"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// RefundRequest refunds a payment. A zero Amount refunds whatever has not
+// been refunded yet.
+type RefundRequest struct {
+	TransactionID int64   `json:"-"`
+	Amount        float64 `json:"amount" validate:"gte=0"`
+	Reason        string  `json:"reason" validate:"required,max=255"`
+	Requester     User    `json:"-"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// Risk decisions returned by a RiskEngine.
+const (
+	RiskDecisionAllow  = "allow"
+	RiskDecisionReview = "review"
+	RiskDecisionDeny   = "deny"
+)
+
+// Held transaction statuses.
+const (
+	HeldTransactionPending  = "pending"
+	HeldTransactionApproved = "approved"
+	HeldTransactionRejected = "rejected"
+)
+
+// HeldOperationAuthorization is the operation of a hold authorization held
+// for review. It moves no money itself, so it is scored as the payment it
+// becomes once captured.
+const HeldOperationAuthorization = "authorization"
+
+type RiskInput struct {
+	UserID      int64
+	WalletID    int64
+	Operation   string
+	Amount      float64
+	BrowserName string
+}
+
+type RiskAssessment struct {
+	Score    int      `json:"score"`
+	Decision string   `json:"decision"`
+	Reasons  []string `json:"reasons"`
+}
+
+// TransactionStats summarizes a wallet's ledger entries of one type.
+type TransactionStats struct {
+	Count int64
+	Total float64
+}
+
+// HeldTransaction is a wallet operation that risk scoring sent to manual
+// review. Payload keeps the original request so it can be executed as-is
+// once approved.
+type HeldTransaction struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	WalletID   int64      `json:"wallet_id"`
+	Operation  string     `json:"operation"`
+	Amount     float64    `json:"amount"`
+	Payload    string     `json:"-"`
+	Score      int        `json:"score"`
+	Reasons    []string   `json:"reasons"`
+	Status     string     `json:"status"`
+	ReviewerID *int64     `json:"reviewer_id,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TransactionHeldError is returned instead of executing an operation that
+// has been held for manual review.
+type TransactionHeldError struct {
+	HeldTransaction *HeldTransaction
+}
+
+func (e *TransactionHeldError) Error() string {
+	return "transaction is held for manual review"
+}
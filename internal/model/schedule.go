@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// Schedule statuses. Only active schedules are run; a schedule completes
+// after its last occurrence and fails when a one-off payment runs out of
+// retries.
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusFailed    = "failed"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// Schedule frequencies.
+const (
+	ScheduleOnce    = "once"
+	ScheduleDaily   = "daily"
+	ScheduleWeekly  = "weekly"
+	ScheduleMonthly = "monthly"
+)
+
+// Schedule run outcomes.
+const (
+	ScheduleRunSucceeded = "succeeded"
+	ScheduleRunFailed    = "failed"
+)
+
+// Schedule pays a merchant or transfers to another user on a future date, or
+// repeatedly from StartAt until EndAt. NextRunAt is the occurrence to be paid
+// next; DueAt is when the scheduler picks it up, later than NextRunAt while
+// a failed attempt is waiting to be retried. Occurrences counts the
+// occurrences settled so far, paid or given up on.
+type Schedule struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	MerchantID  *int64     `json:"merchant_id,omitempty"`
+	RecipientID *int64     `json:"recipient_id,omitempty"`
+	Amount      float64    `json:"amount"`
+	Description string     `json:"description"`
+	Frequency   string     `json:"frequency"`
+	StartAt     time.Time  `json:"start_at"`
+	EndAt       *time.Time `json:"end_at,omitempty"`
+	NextRunAt   time.Time  `json:"next_run_at"`
+	DueAt       time.Time  `json:"-"`
+	Occurrences int        `json:"occurrences"`
+	Attempts    int        `json:"attempts"`
+	LastError   string     `json:"last_error,omitempty"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ScheduleRun records one attempt at paying an occurrence of a schedule.
+type ScheduleRun struct {
+	ID            int64     `json:"id"`
+	ScheduleID    int64     `json:"schedule_id"`
+	ScheduledFor  time.Time `json:"scheduled_for"`
+	Attempt       int       `json:"attempt"`
+	Status        string    `json:"status"`
+	TransactionID *int64    `json:"transaction_id,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ScheduleRequest sets up a schedule paying either a merchant or a user,
+// named by username or email.
+type ScheduleRequest struct {
+	MerchantID  int64      `json:"merchant_id"`
+	Recipient   string     `json:"recipient" validate:"required_without=MerchantID,max=255"`
+	Amount      float64    `json:"amount" validate:"gt=0"`
+	Description string     `json:"description" validate:"max=255"`
+	Frequency   string     `json:"frequency" validate:"required,oneof=once daily weekly monthly"`
+	StartAt     time.Time  `json:"start_at" validate:"required"`
+	EndAt       *time.Time `json:"end_at"`
+	UserID      int64      `json:"user_id"`
+}
+
+type UpdateScheduleRequest struct {
+	Amount      float64    `json:"amount" validate:"gt=0"`
+	Description string     `json:"description" validate:"max=255"`
+	EndAt       *time.Time `json:"end_at"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+// StatementMonthLayout is the layout of the month a statement covers.
+const StatementMonthLayout = "2006-01"
+
+// Statement formats.
+const (
+	StatementFormatCSV = "csv"
+	StatementFormatPDF = "pdf"
+)
+
+// Statement is a wallet's account statement for a calendar month, built from
+// its ledger entries. Fees are listed among the transactions and totalled on
+// their own; TotalDebits leaves them out.
+type Statement struct {
+	UserID         int64         `json:"user_id"`
+	WalletID       int64         `json:"wallet_id"`
+	Address        string        `json:"address"`
+	Month          string        `json:"month"`
+	From           time.Time     `json:"from"`
+	To             time.Time     `json:"to"`
+	Currency       string        `json:"currency"`
+	OpeningBalance float64       `json:"opening_balance"`
+	TotalCredits   float64       `json:"total_credits"`
+	TotalDebits    float64       `json:"total_debits"`
+	TotalFees      float64       `json:"total_fees"`
+	ClosingBalance float64       `json:"closing_balance"`
+	Transactions   []Transaction `json:"transactions"`
+	Hash           string        `json:"hash"`
+	GeneratedAt    time.Time     `json:"generated_at"`
+}
+
+// StatementRecord is what is kept of an issued statement, so a document
+// can be checked against its hash later.
+type StatementRecord struct {
+	ID               int64     `json:"id"`
+	UserID           int64     `json:"-"`
+	WalletID         int64     `json:"wallet_id"`
+	Month            string    `json:"month"`
+	OpeningBalance   float64   `json:"opening_balance"`
+	ClosingBalance   float64   `json:"closing_balance"`
+	TransactionCount int       `json:"transaction_count"`
+	Hash             string    `json:"hash"`
+	IssuedAt         time.Time `json:"issued_at"`
+}
+
+type StatementRequest struct {
+	Month   string `query:"month" validate:"required"`
+	Format  string `query:"format" validate:"omitempty,oneof=csv pdf"`
+	UserID  int64
+	Address string
+}
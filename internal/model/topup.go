@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Top-up intent statuses. A pending intent is waiting for the user to pay
+// at the gateway; the wallet is only credited once the gateway confirms the
+// payment.
+const (
+	TopUpIntentPending   = "pending"
+	TopUpIntentSucceeded = "succeeded"
+	TopUpIntentFailed    = "failed"
+)
+
+// TopUpIntent tops up a wallet through a payment gateway. The user pays at
+// RedirectURL and the wallet is credited under Reference when the gateway
+// calls back.
+type TopUpIntent struct {
+	ID               int64     `json:"id"`
+	UserID           int64     `json:"user_id"`
+	WalletID         int64     `json:"-"`
+	Amount           float64   `json:"amount"`
+	Currency         string    `json:"currency"`
+	Status           string    `json:"status"`
+	Reference        string    `json:"reference"`
+	GatewayReference string    `json:"gateway_reference,omitempty"`
+	RedirectURL      string    `json:"redirect_url,omitempty"`
+	FailureReason    string    `json:"failure_reason,omitempty"`
+	TransactionID    *int64    `json:"transaction_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Finalized reports whether the gateway has settled the intent either way.
+func (i TopUpIntent) Finalized() bool {
+	return i.Status == TopUpIntentSucceeded || i.Status == TopUpIntentFailed
+}
+
+type TopUpIntentRequest struct {
+	Amount  float64 `json:"amount" validate:"gt=0"`
+	Address string  `json:"address"`
+	UserID  int64   `json:"user_id"`
+}
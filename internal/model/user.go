@@ -15,10 +15,25 @@ type User struct {
 	Username      string    `json:"username" validate:"required"`
 	Password      string    `json:"password" validate:"required"`
 	Token         string    `json:"token"`
+	Role          string    `json:"role"`
+	KYCLevel      string    `json:"kyc_level"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// User roles. Support staff review KYC documents; admins can do everything
+// support can.
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// IsStaff reports whether the user is support staff or an admin.
+func (u User) IsStaff() bool {
+	return u.Role == RoleSupport || u.Role == RoleAdmin
+}
+
 type LoginHistory struct {
 	BrowserName  string    `json:"browser_name"`
 	LoginSucceed int       `json:"login_succeed"`
@@ -27,3 +42,28 @@ type LoginHistory struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+// Account deletion statuses. A pending deletion can be cancelled until its
+// grace period ends, after which the account is anonymized.
+const (
+	AccountDeletionPending   = "pending"
+	AccountDeletionCancelled = "cancelled"
+	AccountDeletionCompleted = "completed"
+)
+
+type AccountDeletion struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	Status      string    `json:"status"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type UserExport struct {
+	Profile      UserPresenter  `json:"profile"`
+	Wallets      []Wallet       `json:"wallets"`
+	Transactions []Transaction  `json:"transactions"`
+	LoginHistory []LoginHistory `json:"login_history"`
+	ExportedAt   time.Time      `json:"exported_at"`
+}
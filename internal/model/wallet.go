@@ -6,29 +6,165 @@ type Wallet struct {
 	ID        int64     `json:"id"`
 	Address   string    `json:"address"`
 	Balance   float64   `json:"balance"`
+	Available float64   `json:"available_balance,omitempty"`
 	UserID    int64     `json:"user_id"`
+	Kind      string    `json:"kind"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Status is one of the WalletStatus constants. BlockCredits is only
+	// meaningful while the wallet is frozen.
+	Status       string `json:"status"`
+	BlockCredits bool   `json:"block_credits,omitempty"`
+	// Version is the version of the wallet's event stream it was read at,
+	// when wallets are event sourced.
+	Version int64 `json:"-"`
 }
 
+// Wallet kinds. Personal wallets are the ones users pay and get paid with. A
+// settlement wallet holds what a merchant is paid and belongs to the
+// merchant's owner, but is only reached through the merchant. System wallets
+// belong to no one.
+const (
+	WalletKindPersonal   = "personal"
+	WalletKindSettlement = "settlement"
+	WalletKindSystem     = "system"
+)
+
+// Wallet statuses. A frozen wallet cannot be debited, nor credited when
+// BlockCredits is set; a closed wallet can be neither and never reopens.
+const (
+	WalletStatusActive = "active"
+	WalletStatusFrozen = "frozen"
+	WalletStatusClosed = "closed"
+)
+
+// Reason codes recorded with every wallet status change.
+const (
+	WalletReasonFraudSuspected   = "fraud_suspected"
+	WalletReasonComplianceReview = "compliance_review"
+	WalletReasonLegalOrder       = "legal_order"
+	WalletReasonCustomerRequest  = "customer_request"
+	WalletReasonResolved         = "resolved"
+	WalletReasonOther            = "other"
+)
+
+// WalletStatusRequest asks for a wallet to move to Status. Only admins may
+// make one, and must give a reason code. Status is set from the route, and
+// only kept in the payload of a pending action.
+type WalletStatusRequest struct {
+	WalletID     int64  `json:"-"`
+	Status       string `json:"status,omitempty" swaggerignore:"true"`
+	Reason       string `json:"reason" validate:"required,oneof=fraud_suspected compliance_review legal_order customer_request resolved other"`
+	Note         string `json:"note" validate:"max=255"`
+	BlockCredits bool   `json:"block_credits"`
+	Actor        User   `json:"-"`
+}
+
+// WalletStatusChange records a move of a wallet from one status to another.
+type WalletStatusChange struct {
+	ID           int64     `json:"id"`
+	WalletID     int64     `json:"wallet_id"`
+	FromStatus   string    `json:"from_status"`
+	ToStatus     string    `json:"to_status"`
+	BlockCredits bool      `json:"block_credits"`
+	Reason       string    `json:"reason"`
+	Note         string    `json:"note"`
+	ChangedBy    int64     `json:"changed_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Transaction struct {
+	ID           int64     `json:"id"`
+	WalletID     int64     `json:"wallet_id"`
+	Type         string    `json:"type"`
+	Amount       float64   `json:"amount"`
+	BalanceAfter float64   `json:"balance_after"`
+	Reference    string    `json:"reference"`
+	Description  string    `json:"description"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Transaction types recorded in the wallet ledger. Amount is signed:
+// credits are positive and debits are negative.
+const (
+	TransactionTypeTopUp              = "top_up"
+	TransactionTypePayment            = "payment"
+	TransactionTypePaymentReceived    = "payment_received"
+	TransactionTypeRefund             = "refund"
+	TransactionTypeTransfer           = "transfer"
+	TransactionTypeTransferReceived   = "transfer_received"
+	TransactionTypeFee                = "fee"
+	TransactionTypeFeeRevenue         = "fee_revenue"
+	TransactionTypeWithdrawal         = "withdrawal"
+	TransactionTypeWithdrawalReversal = "withdrawal_reversal"
+	TransactionTypeAdjustment         = "adjustment"
+	// Escrow entries use the same type on both legs of a move.
+	TransactionTypeEscrowFund    = "escrow_fund"
+	TransactionTypeEscrowRelease = "escrow_release"
+	TransactionTypeEscrowRefund  = "escrow_refund"
+)
+
 type CheckBalanceRequest struct {
 	UserID  int64  `json:"user_id"`
 	Address string `json:"address"`
 }
 
+// CheckBalanceResponse reports the ledger balance and the part of it that is
+// not reserved by authorized holds.
 type CheckBalanceResponse struct {
-	UserID  int64   `json:"user_id"`
-	Balance float64 `json:"balance"`
+	UserID           int64   `json:"user_id"`
+	Balance          float64 `json:"balance"`
+	AvailableBalance float64 `json:"available_balance"`
 }
 
+// BalanceChange is a wallet's balance after a ledger entry, as streamed to
+// its owner. TransactionID is empty when the balance is sent as a snapshot.
+type BalanceChange struct {
+	WalletID      int64   `json:"wallet_id"`
+	Address       string  `json:"address"`
+	Balance       float64 `json:"balance"`
+	TransactionID int64   `json:"transaction_id,omitempty"`
+}
+
+// TopUpRequest is a top-up of the caller's wallet through the payment
+// gateway, checked before the caller is charged. Admins may also credit a
+// wallet directly, without a payment: that of the user named by Recipient,
+// username or email, or their own when it is empty. A direct top-up is
+// proposed by one admin and made once a second admin approves it.
 type TopUpRequest struct {
-	Nominal float64 `json:"nominal"`
-	Address string  `json:"address"`
-	UserID  int64   `json:"user_id"`
+	Nominal   float64 `json:"nominal" validate:"required,gt=0"`
+	Recipient string  `json:"recipient,omitempty"`
+	Address   string  `json:"address"`
+	UserID    int64   `json:"user_id"`
+	WalletID  int64   `json:"-"`
+	Actor     User    `json:"-"`
 }
 
+// PayRequest pays either a merchant or, when PaymentRequestCode is set, a
+// user's payment request.
 type PayRequest struct {
-	NominalPayment float64 `json:"nominal_payment"`
-	Address        string  `json:"address"`
-	UserID         int64   `json:"user_id"`
+	NominalPayment     float64 `json:"nominal_payment"`
+	MerchantID         int64   `json:"merchant_id" validate:"required_without=PaymentRequestCode"`
+	PaymentRequestCode string  `json:"payment_request_code,omitempty"`
+	Address            string  `json:"address"`
+	UserID             int64   `json:"user_id"`
+}
+
+// TransferRequest moves money from the caller's wallet to the personal
+// wallet of another user, named by username or email.
+type TransferRequest struct {
+	Amount      float64 `json:"amount" validate:"gt=0"`
+	Recipient   string  `json:"recipient" validate:"required"`
+	Description string  `json:"description" validate:"max=255"`
+	Address     string  `json:"address"`
+	UserID      int64   `json:"user_id"`
+}
+
+// QRPayRequest pays the merchant a scanned QR payload names. Amount is only
+// read for static codes, which leave it to the payer.
+type QRPayRequest struct {
+	Payload string  `json:"payload" validate:"required"`
+	Amount  float64 `json:"amount" validate:"gte=0"`
+	Address string  `json:"address"`
+	UserID  int64   `json:"user_id"`
 }
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Wallet event types. A wallet's stream starts with WalletOpened; every
+// ledger entry posted to it follows as Credited or Debited, and every status
+// change as Frozen, Unfrozen or Closed.
+const (
+	WalletEventOpened   = "WalletOpened"
+	WalletEventCredited = "Credited"
+	WalletEventDebited  = "Debited"
+	WalletEventFrozen   = "Frozen"
+	WalletEventUnfrozen = "Unfrozen"
+	WalletEventClosed   = "Closed"
+)
+
+// WalletEvent is an event in a wallet's stream. Version numbers the events
+// of a stream from 1 without gaps; ID orders the events of every stream.
+type WalletEvent struct {
+	ID        int64           `json:"id"`
+	WalletID  int64           `json:"wallet_id"`
+	Version   int64           `json:"version"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// WalletOpened is the data of a WalletOpened event. Kind is empty in the
+// streams opened before it was recorded.
+type WalletOpened struct {
+	UserID  int64  `json:"user_id"`
+	Address string `json:"address"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// WalletEntry is the data of a Credited or Debited event: the ledger entry
+// posted, with Amount unsigned.
+type WalletEntry struct {
+	TransactionID int64   `json:"transaction_id"`
+	Type          string  `json:"type"`
+	Amount        float64 `json:"amount"`
+	Reference     string  `json:"reference"`
+	Description   string  `json:"description"`
+}
+
+// WalletStatusChanged is the data of a Frozen, Unfrozen or Closed event.
+type WalletStatusChanged struct {
+	Reason       string `json:"reason"`
+	Note         string `json:"note,omitempty"`
+	BlockCredits bool   `json:"block_credits,omitempty"`
+	ChangedBy    int64  `json:"changed_by,omitempty"`
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types. Ledger events carry the Transaction posted to the
+// subscriber's wallet; user.registered carries a RegisteredUser and
+// bill.reminder a BillReminder.
+const (
+	WebhookEventUserRegistered  = "user.registered"
+	WebhookEventTopUpCompleted  = "top_up.completed"
+	WebhookEventPaymentSent     = "payment.sent"
+	WebhookEventPaymentReceived = "payment.received"
+	WebhookEventRefundIssued    = "refund.issued"
+	WebhookEventRefundReceived  = "refund.received"
+	WebhookEventBillReminder    = "bill.reminder"
+)
+
+// WebhookEventTypes lists every event type a subscription can ask for.
+var WebhookEventTypes = []string{
+	WebhookEventUserRegistered,
+	WebhookEventTopUpCompleted,
+	WebhookEventPaymentSent,
+	WebhookEventPaymentReceived,
+	WebhookEventRefundIssued,
+	WebhookEventRefundReceived,
+	WebhookEventBillReminder,
+}
+
+// Webhook delivery statuses. A delivery that keeps failing is given up on
+// as dead and is only sent again when redelivered by hand.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription sends the events of the given types to URL. Events
+// are those concerning the subscriber's own wallets, or every user's when
+// AllUsers is set, which only admins may do. Secret signs the deliveries and
+// is only shown when the subscription is created.
+type WebhookSubscription struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	AllUsers   bool      `json:"all_users"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=user.registered top_up.completed payment.sent payment.received refund.issued refund.received bill.reminder"`
+	AllUsers   bool     `json:"all_users"`
+	Requester  User     `json:"-"`
+}
+
+// WebhookEvent is the body of a webhook delivery.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// RegisteredUser is the data of a user.registered event.
+type RegisteredUser struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is an event queued for a subscription, and the outcome of
+// the last attempt to send it.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastError      string          `json:"last_error,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
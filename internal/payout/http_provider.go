@@ -0,0 +1,82 @@
+package payout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const httpProviderTimeout = 10 * time.Second
+
+type httpProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPProvider returns a PayoutProvider for a bank exposing
+// POST /payouts to submit instructions and GET /payouts/{reference} to
+// report on them, such as the one served by NewMockBank.
+func NewHTTPProvider(baseURL string) PayoutProvider {
+	return &httpProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: httpProviderTimeout},
+	}
+}
+
+// Submit sends an instruction. The bank answers a reference it already has
+// with the payout it holds for it, so resubmitting is safe.
+func (p *httpProvider) Submit(ctx context.Context, instruction Instruction) (*Result, error) {
+	body, err := json.Marshal(instruction)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/payouts", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return p.do(req)
+}
+
+func (p *httpProvider) Status(ctx context.Context, reference string) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/payouts/"+url.PathEscape(reference), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.do(req)
+}
+
+func (p *httpProvider) do(req *http.Request) (*Result, error) {
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var result Result
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, ErrUnknownPayout
+	case res.StatusCode == http.StatusBadRequest || res.StatusCode == http.StatusUnprocessableEntity:
+		if err = json.NewDecoder(res.Body).Decode(&result); err == nil && result.Reason != "" {
+			return nil, fmt.Errorf("%w: %s", ErrRejected, result.Reason)
+		}
+		return nil, ErrRejected
+	case res.StatusCode >= 300:
+		return nil, fmt.Errorf("payout provider responded %s", res.Status)
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,148 @@
+package payout
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Account numbers the mock bank treats specially, so every outcome can be
+// tried locally.
+const (
+	// MockRejectedPrefix marks accounts whose payouts are rejected outright.
+	MockRejectedPrefix = "999"
+	// MockFailedPrefix marks accounts whose payouts are accepted but fail.
+	MockFailedPrefix = "000"
+)
+
+type mockBank struct {
+	secret string
+	delay  time.Duration
+	client *http.Client
+
+	mu      sync.Mutex
+	payouts map[string]*Result
+	seq     int
+}
+
+// NewMockBank returns a handler standing in for a bank: it accepts payouts
+// on POST /payouts, settles them after delay and reports the outcome to
+// their callback URL, signed with secret, and on GET /payouts/{reference}.
+// Payouts succeed unless the account number starts with MockRejectedPrefix
+// or MockFailedPrefix.
+func NewMockBank(secret string, delay time.Duration) http.Handler {
+	return &mockBank{
+		secret:  secret,
+		delay:   delay,
+		client:  &http.Client{Timeout: httpProviderTimeout},
+		payouts: map[string]*Result{},
+	}
+}
+
+func (b *mockBank) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/payouts":
+		b.submit(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/payouts/"):
+		b.status(w, strings.TrimPrefix(r.URL.Path, "/payouts/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (b *mockBank) submit(w http.ResponseWriter, r *http.Request) {
+	var instruction Instruction
+	if err := json.NewDecoder(r.Body).Decode(&instruction); err != nil || instruction.Reference == "" || instruction.Amount <= 0 {
+		writeJSON(w, http.StatusBadRequest, Result{Reference: instruction.Reference, Status: StatusFailed, Reason: "malformed instruction"})
+		return
+	}
+
+	if strings.HasPrefix(instruction.AccountNumber, MockRejectedPrefix) {
+		writeJSON(w, http.StatusUnprocessableEntity, Result{Reference: instruction.Reference, Status: StatusFailed, Reason: "account is closed"})
+		return
+	}
+
+	b.mu.Lock()
+	result, ok := b.payouts[instruction.Reference]
+	if !ok {
+		b.seq++
+		result = &Result{
+			Reference:         instruction.Reference,
+			ProviderReference: fmt.Sprintf("MB%08d", b.seq),
+			Status:            StatusPending,
+		}
+		b.payouts[instruction.Reference] = result
+	}
+	accepted := *result
+	b.mu.Unlock()
+
+	if !ok {
+		go b.settle(instruction)
+	}
+
+	writeJSON(w, http.StatusAccepted, accepted)
+}
+
+func (b *mockBank) status(w http.ResponseWriter, reference string) {
+	b.mu.Lock()
+	result, ok := b.payouts[reference]
+	var current Result
+	if ok {
+		current = *result
+	}
+	b.mu.Unlock()
+
+	if !ok {
+		writeJSON(w, http.StatusNotFound, Result{Reference: reference, Reason: "unknown payout"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, current)
+}
+
+// settle decides a payout after the bank's delay and sends the callback.
+// A failed callback is not retried; the payout can still be polled.
+func (b *mockBank) settle(instruction Instruction) {
+	time.Sleep(b.delay)
+
+	b.mu.Lock()
+	result := b.payouts[instruction.Reference]
+	result.Status = StatusSucceeded
+	if strings.HasPrefix(instruction.AccountNumber, MockFailedPrefix) {
+		result.Status = StatusFailed
+		result.Reason = "beneficiary bank declined the transfer"
+	}
+	settled := *result
+	b.mu.Unlock()
+
+	if instruction.CallbackURL == "" {
+		return
+	}
+
+	body, err := json.Marshal(settled)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, instruction.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(b.secret, body))
+
+	if res, err := b.client.Do(req); err == nil {
+		res.Body.Close()
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,71 @@
+// Package payout sends withdrawals to banks. A PayoutProvider accepts payout
+// instructions and reports their outcome, both on request and through
+// signed callbacks to the URL given with each instruction.
+package payout
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+// Statuses a provider reports for a payout.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// SignatureHeader carries the signature of a callback body.
+const SignatureHeader = "X-Payout-Signature"
+
+var (
+	// ErrRejected is returned when the provider refuses an instruction
+	// outright, so the payout will never be made.
+	ErrRejected = errors.New("payout rejected by provider")
+	// ErrUnknownPayout is returned when the provider has no payout with the
+	// reference asked about.
+	ErrUnknownPayout = errors.New("payout unknown to provider")
+)
+
+// Instruction asks a provider to pay Amount into a bank account. Reference
+// identifies the payout on both sides; submitting it again must not pay
+// twice.
+type Instruction struct {
+	Reference     string  `json:"reference"`
+	Amount        float64 `json:"amount"`
+	Currency      string  `json:"currency"`
+	BankCode      string  `json:"bank_code"`
+	AccountNumber string  `json:"account_number"`
+	AccountName   string  `json:"account_name"`
+	CallbackURL   string  `json:"callback_url"`
+}
+
+// Result is a provider's view of a payout. It is also the body of the
+// callbacks providers send once a payout succeeds or fails.
+type Result struct {
+	Reference         string `json:"reference"`
+	ProviderReference string `json:"provider_reference"`
+	Status            string `json:"status"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+type PayoutProvider interface {
+	Submit(ctx context.Context, instruction Instruction) (*Result, error)
+	Status(ctx context.Context, reference string) (*Result, error)
+}
+
+// Sign returns the signature of a callback body under secret, a hex encoded
+// HMAC-SHA256.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body under secret.
+func Verify(secret string, body []byte, signature string) bool {
+	return secret != "" && hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
@@ -0,0 +1,105 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPProvider_MockBank(t *testing.T) {
+	const secret = "secret"
+
+	callbacks := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		callbacks <- r
+		bodies <- body
+	}))
+	defer receiver.Close()
+
+	bank := httptest.NewServer(NewMockBank(secret, 10*time.Millisecond))
+	defer bank.Close()
+
+	var (
+		ctx      = context.Background()
+		provider = NewHTTPProvider(bank.URL + "/")
+	)
+
+	instruction := Instruction{Reference: "REF1", Amount: 50000, Currency: "IDR", BankCode: "BCA", AccountNumber: "1234567890", AccountName: "Andi", CallbackURL: receiver.URL}
+
+	first, err := provider.Submit(ctx, instruction)
+	if err != nil || first.Status != StatusPending || first.ProviderReference == "" {
+		t.Fatalf("Submit() = %+v, %v", first, err)
+	}
+
+	again, err := provider.Submit(ctx, instruction)
+	if err != nil || again.ProviderReference != first.ProviderReference {
+		t.Errorf("Submit() again = %+v, %v, want %s", again, err, first.ProviderReference)
+	}
+
+	select {
+	case r := <-callbacks:
+		body := <-bodies
+		if !Verify(secret, body, r.Header.Get(SignatureHeader)) {
+			t.Errorf("callback signature %q does not verify", r.Header.Get(SignatureHeader))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no callback received")
+	}
+
+	status, err := provider.Status(ctx, "REF1")
+	if err != nil || status.Status != StatusSucceeded {
+		t.Errorf("Status() = %+v, %v, want %s", status, err, StatusSucceeded)
+	}
+
+	instruction.Reference, instruction.AccountNumber = "REF2", MockRejectedPrefix+"1234"
+	if _, err = provider.Submit(ctx, instruction); !errors.Is(err, ErrRejected) {
+		t.Errorf("Submit() rejected account error = %v, want %v", err, ErrRejected)
+	}
+
+	if _, err = provider.Status(ctx, "REF3"); err != ErrUnknownPayout {
+		t.Errorf("Status() unknown error = %v, want %v", err, ErrUnknownPayout)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"reference":"REF1","status":"succeeded"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      bool
+	}{
+		{
+			name:      "valid",
+			secret:    "secret",
+			signature: Sign("secret", body),
+			want:      true,
+		},
+		{
+			name:      "other secret",
+			secret:    "secret",
+			signature: Sign("other", body),
+			want:      false,
+		},
+		{
+			name:      "no secret configured",
+			secret:    "",
+			signature: Sign("", body),
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, body, tt.signature); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
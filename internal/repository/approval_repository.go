@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type ApprovalRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
+	WritePendingAction(ctx context.Context, action model.PendingAction) (actionID int64, err error)
+	ReadPendingActionByID(ctx context.Context, actionID int64) (*model.PendingAction, error)
+	ReadPendingActionPage(ctx context.Context, status string, page model.PageRequest) (actions []model.PendingAction, total int64, err error)
+	ReadExpiredPendingActions(ctx context.Context, now time.Time) ([]model.PendingAction, error)
+	UpdatePendingAction(ctx context.Context, tx *sql.Tx, action model.PendingAction) error
+}
+
+type mysqlApprovalRepository struct {
+	db *sql.DB
+}
+
+func NewApprovalRepository(db *sql.DB) ApprovalRepository {
+	return &mysqlApprovalRepository{
+		db: db,
+	}
+}
+
+// pendingActionSorts are the fields pending actions can be sorted by.
+var pendingActionSorts = map[string]string{
+	"id":         "id",
+	"type":       "type",
+	"expires_at": "expires_at",
+	"created_at": "created_at",
+}
+
+const pendingActionColumns = `id, type, target_id, payload, status, proposed_by, reviewed_by, review_note, result, expires_at, reviewed_at, created_at, updated_at`
+
+func (m *mysqlApprovalRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+func (m *mysqlApprovalRepository) WritePendingAction(ctx context.Context, action model.PendingAction) (actionID int64, err error) {
+	query := `INSERT INTO pending_action (type, target_id, payload, status, proposed_by, expires_at) VALUES (?,?,?,?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, action.Type, action.TargetID, string(action.Payload), action.Status, action.ProposedBy, action.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlApprovalRepository) ReadPendingActionByID(ctx context.Context, actionID int64) (*model.PendingAction, error) {
+	query := `SELECT ` + pendingActionColumns + ` FROM pending_action WHERE id=?`
+
+	action, err := scanPendingAction(m.db.QueryRowContext(ctx, query, actionID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return action, nil
+}
+
+// ReadPendingActionPage returns a page of the actions with the given status,
+// and how many there are in all.
+func (m *mysqlApprovalRepository) ReadPendingActionPage(ctx context.Context, status string, page model.PageRequest) ([]model.PendingAction, int64, error) {
+	order, limit, err := orderBy(page, pendingActionSorts, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err = m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM pending_action WHERE status=?`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + pendingActionColumns + ` FROM pending_action WHERE status=?` + order
+
+	actions, err := m.readPendingActions(ctx, query, append([]interface{}{status}, limit...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return actions, total, nil
+}
+
+// ReadExpiredPendingActions returns the actions still pending at their
+// expiry.
+func (m *mysqlApprovalRepository) ReadExpiredPendingActions(ctx context.Context, now time.Time) ([]model.PendingAction, error) {
+	query := `SELECT ` + pendingActionColumns + ` FROM pending_action WHERE status=? AND expires_at<=? ORDER BY id`
+
+	return m.readPendingActions(ctx, query, model.PendingActionPending, now)
+}
+
+// UpdatePendingAction records the review outcome. It only updates a pending
+// action and returns sql.ErrNoRows otherwise, so an action can never be
+// executed twice.
+func (m *mysqlApprovalRepository) UpdatePendingAction(ctx context.Context, tx *sql.Tx, action model.PendingAction) error {
+	query := `UPDATE pending_action SET status=?, reviewed_by=?, review_note=?, result=?, reviewed_at=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var result interface{}
+	if len(action.Result) > 0 {
+		result = string(action.Result)
+	}
+
+	res, err := stmt.ExecContext(ctx, action.Status, action.ReviewedBy, action.ReviewNote, result, action.ReviewedAt, time.Now(), action.ID, model.PendingActionPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *mysqlApprovalRepository) readPendingActions(ctx context.Context, query string, args ...interface{}) ([]model.PendingAction, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := []model.PendingAction{}
+	for rows.Next() {
+		action, err := scanPendingAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, *action)
+	}
+
+	return actions, rows.Err()
+}
+
+func scanPendingAction(row rowScanner) (*model.PendingAction, error) {
+	var (
+		action     model.PendingAction
+		payload    string
+		result     sql.NullString
+		reviewedBy sql.NullInt64
+		reviewedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&action.ID,
+		&action.Type,
+		&action.TargetID,
+		&payload,
+		&action.Status,
+		&action.ProposedBy,
+		&reviewedBy,
+		&action.ReviewNote,
+		&result,
+		&action.ExpiresAt,
+		&reviewedAt,
+		&action.CreatedAt,
+		&action.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	action.Payload = []byte(payload)
+	if result.Valid && result.String != "" {
+		action.Result = []byte(result.String)
+	}
+	if reviewedBy.Valid {
+		action.ReviewedBy = &reviewedBy.Int64
+	}
+	if reviewedAt.Valid {
+		action.ReviewedAt = &reviewedAt.Time
+	}
+
+	return &action, nil
+}
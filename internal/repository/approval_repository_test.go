@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+var pendingActionRowColumns = []string{"id", "type", "target_id", "payload", "status", "proposed_by", "reviewed_by", "review_note", "result", "expires_at", "reviewed_at", "created_at", "updated_at"}
+
+func Test_mysqlApprovalRepository_ReadExpiredPendingActions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewApprovalRepository(db)
+		query = "SELECT (.+) FROM pending_action WHERE status=\\? AND expires_at<=\\? ORDER BY id"
+		now   = time.Now()
+	)
+
+	rows := sqlmock.NewRows(pendingActionRowColumns).
+		AddRow(4, model.PendingActionUnfreeze, 5, `{"reason":"resolved"}`, model.PendingActionPending, 9, nil, "", nil, now.Add(-time.Hour), nil, now.Add(-25*time.Hour), now.Add(-25*time.Hour))
+
+	mock.ExpectQuery(query).WithArgs(model.PendingActionPending, now).WillReturnRows(rows)
+
+	got, err := repo.ReadExpiredPendingActions(ctx, now)
+	if err != nil {
+		t.Fatalf("mysqlApprovalRepository.ReadExpiredPendingActions() error = %v", err)
+	}
+
+	want := []model.PendingAction{
+		{ID: 4, Type: model.PendingActionUnfreeze, TargetID: 5, Payload: []byte(`{"reason":"resolved"}`), Status: model.PendingActionPending, ProposedBy: 9, ExpiresAt: now.Add(-time.Hour), CreatedAt: now.Add(-25 * time.Hour), UpdatedAt: now.Add(-25 * time.Hour)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mysqlApprovalRepository.ReadExpiredPendingActions() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_mysqlApprovalRepository_UpdatePendingAction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewApprovalRepository(db)
+		query      = "UPDATE pending_action SET status=\\?, reviewed_by=\\?, review_note=\\?, result=\\?, reviewed_at=\\?, updated_at=\\? WHERE id=\\? AND status=\\?"
+		reviewerID = int64(2)
+		now        = time.Now()
+		action     = model.PendingAction{ID: 4, Status: model.PendingActionApproved, ReviewedBy: &reviewerID, ReviewNote: "checked", Result: []byte(`{"id":11}`), ReviewedAt: &now}
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "reviewed already",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			prep := mock.ExpectPrepare(query)
+			prep.ExpectExec().
+				WithArgs(action.Status, action.ReviewedBy, action.ReviewNote, `{"id":11}`, action.ReviewedAt, sqlmock.AnyArg(), action.ID, model.PendingActionPending).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			tx := repo.BeginTx(ctx)
+			if err := repo.UpdatePendingAction(ctx, tx, action); err != tt.wantErr {
+				t.Errorf("mysqlApprovalRepository.UpdatePendingAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
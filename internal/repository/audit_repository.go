@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type AuditRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
+	WriteEntry(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) (entryID int64, err error)
+	ReadHeadForUpdate(ctx context.Context, tx *sql.Tx) (*model.AuditHead, error)
+	ReadUnsealedEntries(ctx context.Context, tx *sql.Tx, limit int) ([]model.AuditEntry, error)
+	SealEntry(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error
+	UpdateHead(ctx context.Context, tx *sql.Tx, head model.AuditHead) error
+	ReadHead(ctx context.Context) (*model.AuditHead, error)
+	ReadSealedEntries(ctx context.Context, afterSeq int64, limit int) ([]model.AuditEntry, error)
+	CountUnsealed(ctx context.Context) (int64, error)
+	CountUnsealedBefore(ctx context.Context, before time.Time) (int64, error)
+	ReadEntries(ctx context.Context, filter model.AuditFilter) (entries []model.AuditEntry, total int64, err error)
+}
+
+type mysqlAuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &mysqlAuditRepository{
+		db: db,
+	}
+}
+
+const auditEntryColumns = `id, COALESCE(seq, 0), action, actor_id, target_type, target_id, ip, user_agent, before_value, after_value, prev_hash, hash, created_at`
+
+// auditSorts are the fields audit entries can be sorted by.
+var auditSorts = map[string]string{
+	"id":         "id",
+	"created_at": "created_at",
+}
+
+func (m *mysqlAuditRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+// WriteEntry adds an unsealed entry to the audit log.
+func (m *mysqlAuditRepository) WriteEntry(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) (entryID int64, err error) {
+	query := `INSERT INTO audit_log (action, actor_id, target_type, target_id, ip, user_agent, before_value, after_value, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := tx.ExecContext(ctx, query, entry.Action, entry.ActorID, entry.TargetType, entry.TargetID, entry.IP, entry.UserAgent, string(entry.Before), string(entry.After), entry.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// ReadHeadForUpdate locks the head of the chain until tx ends, so only one
+// transaction seals entries at a time.
+func (m *mysqlAuditRepository) ReadHeadForUpdate(ctx context.Context, tx *sql.Tx) (*model.AuditHead, error) {
+	if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO audit_head (id) VALUES (1)`); err != nil {
+		return nil, err
+	}
+
+	var head model.AuditHead
+	if err := tx.QueryRowContext(ctx, `SELECT seq, hash FROM audit_head WHERE id = 1 FOR UPDATE`).Scan(&head.Seq, &head.Hash); err != nil {
+		return nil, err
+	}
+
+	return &head, nil
+}
+
+// ReadUnsealedEntries returns up to limit entries that are not chained yet,
+// oldest first.
+func (m *mysqlAuditRepository) ReadUnsealedEntries(ctx context.Context, tx *sql.Tx, limit int) ([]model.AuditEntry, error) {
+	query := `SELECT ` + auditEntryColumns + ` FROM audit_log WHERE seq IS NULL ORDER BY id LIMIT ?`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAuditEntries(rows)
+}
+
+// SealEntry chains an entry, unless it is chained already.
+func (m *mysqlAuditRepository) SealEntry(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error {
+	query := `UPDATE audit_log SET seq=?, prev_hash=?, hash=? WHERE id=? AND seq IS NULL`
+
+	res, err := tx.ExecContext(ctx, query, entry.Seq, entry.PrevHash, entry.Hash, entry.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *mysqlAuditRepository) UpdateHead(ctx context.Context, tx *sql.Tx, head model.AuditHead) error {
+	_, err := tx.ExecContext(ctx, `UPDATE audit_head SET seq=?, hash=?, updated_at=NOW() WHERE id = 1`, head.Seq, head.Hash)
+
+	return err
+}
+
+// ReadHead returns the head of the chain, which is empty before anything is
+// sealed.
+func (m *mysqlAuditRepository) ReadHead(ctx context.Context) (*model.AuditHead, error) {
+	var head model.AuditHead
+
+	err := m.db.QueryRowContext(ctx, `SELECT seq, hash FROM audit_head WHERE id = 1`).Scan(&head.Seq, &head.Hash)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return &head, nil
+}
+
+// ReadSealedEntries returns up to limit chained entries after afterSeq, in
+// chain order.
+func (m *mysqlAuditRepository) ReadSealedEntries(ctx context.Context, afterSeq int64, limit int) ([]model.AuditEntry, error) {
+	query := `SELECT ` + auditEntryColumns + ` FROM audit_log WHERE seq > ? ORDER BY seq LIMIT ?`
+
+	rows, err := m.db.QueryContext(ctx, query, afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAuditEntries(rows)
+}
+
+func (m *mysqlAuditRepository) CountUnsealed(ctx context.Context) (int64, error) {
+	var count int64
+	if err := m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log WHERE seq IS NULL`).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountUnsealedBefore counts the entries not chained yet that were written
+// before the given time.
+func (m *mysqlAuditRepository) CountUnsealedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var count int64
+	if err := m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log WHERE seq IS NULL AND created_at < ?`, before).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ReadEntries returns a page of the audit entries matching filter, and how
+// many match in all.
+func (m *mysqlAuditRepository) ReadEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, int64, error) {
+	order, limit, err := orderBy(filter.PageRequest, auditSorts, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where := ` FROM audit_log WHERE (?='' OR action=?) AND (?=0 OR actor_id=?) AND (?='' OR target_type=?) AND (?=0 OR target_id=?) AND (? OR created_at >= ?) AND (? OR created_at < ?)`
+	args := []interface{}{
+		filter.Action, filter.Action,
+		filter.ActorID, filter.ActorID,
+		filter.TargetType, filter.TargetType,
+		filter.TargetID, filter.TargetID,
+		filter.From.IsZero(), filter.From,
+		filter.To.IsZero(), filter.To,
+	}
+
+	var total int64
+	if err = m.db.QueryRowContext(ctx, `SELECT COUNT(*)`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT `+auditEntryColumns+where+order, append(args, limit...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries, err := scanAuditEntries(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+func scanAuditEntries(rows *sql.Rows) ([]model.AuditEntry, error) {
+	entries := []model.AuditEntry{}
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func scanAuditEntry(row rowScanner) (*model.AuditEntry, error) {
+	var (
+		entry         model.AuditEntry
+		before, after string
+	)
+
+	err := row.Scan(
+		&entry.ID,
+		&entry.Seq,
+		&entry.Action,
+		&entry.ActorID,
+		&entry.TargetType,
+		&entry.TargetID,
+		&entry.IP,
+		&entry.UserAgent,
+		&before,
+		&after,
+		&entry.PrevHash,
+		&entry.Hash,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if before != "" {
+		entry.Before = []byte(before)
+	}
+	if after != "" {
+		entry.After = []byte(after)
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+var auditColumns = []string{"id", "seq", "action", "actor_id", "target_type", "target_id", "ip", "user_agent", "before_value", "after_value", "prev_hash", "hash", "created_at"}
+
+func Test_mysqlAuditRepository_ReadUnsealedEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewAuditRepository(db)
+		query = "SELECT (.+) FROM audit_log WHERE seq IS NULL ORDER BY id LIMIT \\?"
+		now   = time.Now()
+	)
+
+	rows := sqlmock.NewRows(auditColumns).
+		AddRow(7, 0, model.AuditLogin, 3, model.AuditTargetUser, 3, "10.0.0.1", "curl", "", "", "", "", now).
+		AddRow(8, 0, model.AuditBalanceChanged, 0, model.AuditTargetWallet, 4, "", "", `{"balance":0}`, `{"balance":50}`, "", "", now)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).WithArgs(100).WillReturnRows(rows)
+
+	tx := repo.BeginTx(ctx)
+	got, err := repo.ReadUnsealedEntries(ctx, tx, 100)
+	if err != nil {
+		t.Fatalf("mysqlAuditRepository.ReadUnsealedEntries() error = %v", err)
+	}
+
+	want := []model.AuditEntry{
+		{ID: 7, Action: model.AuditLogin, ActorID: 3, TargetType: model.AuditTargetUser, TargetID: 3, IP: "10.0.0.1", UserAgent: "curl", CreatedAt: now},
+		{ID: 8, Action: model.AuditBalanceChanged, TargetType: model.AuditTargetWallet, TargetID: 4, Before: []byte(`{"balance":0}`), After: []byte(`{"balance":50}`), CreatedAt: now},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mysqlAuditRepository.ReadUnsealedEntries() = %v, want %v", got, want)
+	}
+}
+
+func Test_mysqlAuditRepository_SealEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewAuditRepository(db)
+		query = "UPDATE audit_log SET seq=\\?, prev_hash=\\?, hash=\\? WHERE id=\\? AND seq IS NULL"
+		entry = model.AuditEntry{ID: 7, Seq: 2, PrevHash: "aa", Hash: "bb"}
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "sealed already",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectExec(query).WithArgs(entry.Seq, entry.PrevHash, entry.Hash, entry.ID).WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			tx := repo.BeginTx(ctx)
+			if err := repo.SealEntry(ctx, tx, entry); err != tt.wantErr {
+				t.Errorf("mysqlAuditRepository.SealEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_mysqlAuditRepository_CountUnsealedBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx    = context.Background()
+		repo   = NewAuditRepository(db)
+		query  = "SELECT COUNT\\(\\*\\) FROM audit_log WHERE seq IS NULL AND created_at < \\?"
+		before = time.Now().Add(-time.Minute)
+	)
+
+	mock.ExpectQuery(query).WithArgs(before).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	got, err := repo.CountUnsealedBefore(ctx, before)
+	if err != nil || got != 2 {
+		t.Errorf("mysqlAuditRepository.CountUnsealedBefore() = %d, %v, want 2", got, err)
+	}
+}
+
+func Test_mysqlAuditRepository_ReadEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx    = context.Background()
+		repo   = NewAuditRepository(db)
+		now    = time.Now()
+		from   = now.Add(-time.Hour)
+		filter = model.AuditFilter{
+			Action:      model.AuditLogin,
+			From:        from,
+			PageRequest: model.PageRequest{Sort: "created_at", Order: model.SortAsc}.Normalize(),
+		}
+		args = []driver.Value{model.AuditLogin, model.AuditLogin, int64(0), int64(0), "", "", int64(0), int64(0), false, from, true, time.Time{}}
+	)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM audit_log WHERE").WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT (.+) FROM audit_log WHERE (.+) ORDER BY created_at ASC, id ASC LIMIT \\? OFFSET \\?").
+		WithArgs(append(args, 20, 0)...).
+		WillReturnRows(sqlmock.NewRows(auditColumns).AddRow(7, 1, model.AuditLogin, 3, model.AuditTargetUser, 3, "", "", "", "", "", "h1", now))
+
+	got, total, err := repo.ReadEntries(ctx, filter)
+	if err != nil {
+		t.Fatalf("mysqlAuditRepository.ReadEntries() error = %v", err)
+	}
+
+	want := []model.AuditEntry{{ID: 7, Seq: 1, Action: model.AuditLogin, ActorID: 3, TargetType: model.AuditTargetUser, TargetID: 3, Hash: "h1", CreatedAt: now}}
+	if total != 1 || !reflect.DeepEqual(got, want) {
+		t.Errorf("mysqlAuditRepository.ReadEntries() = %v, %d, want %v, 1", got, total, want)
+	}
+
+	if _, _, err = repo.ReadEntries(ctx, model.AuditFilter{PageRequest: model.PageRequest{Sort: "ip"}.Normalize()}); err == nil {
+		t.Errorf("mysqlAuditRepository.ReadEntries() sorted by ip, want error")
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type BillRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
+	WriteBill(ctx context.Context, tx *sql.Tx, bill model.Bill) (billID int64, err error)
+	ReadBillByID(ctx context.Context, billID int64) (*model.Bill, error)
+	ReadBillsByUser(ctx context.Context, userID int64) ([]model.Bill, error)
+	CancelBill(ctx context.Context, tx *sql.Tx, billID int64) error
+}
+
+type mysqlBillRepository struct {
+	db *sql.DB
+}
+
+func NewBillRepository(db *sql.DB) BillRepository {
+	return &mysqlBillRepository{
+		db: db,
+	}
+}
+
+const billColumns = `id, organizer_id, wallet_id, title, amount, status, created_at, updated_at`
+
+func (m *mysqlBillRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+func (m *mysqlBillRepository) WriteBill(ctx context.Context, tx *sql.Tx, bill model.Bill) (billID int64, err error) {
+	query := `INSERT INTO bill (organizer_id, wallet_id, title, amount, status) VALUES (?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, bill.OrganizerID, bill.WalletID, bill.Title, bill.Amount, bill.Status)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlBillRepository) ReadBillByID(ctx context.Context, billID int64) (*model.Bill, error) {
+	query := `SELECT ` + billColumns + ` FROM bill WHERE id=?`
+
+	bill, err := scanBill(m.db.QueryRowContext(ctx, query, billID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return bill, nil
+}
+
+// ReadBillsByUser returns the bills a user organized or has a share in,
+// newest first. Shares are not loaded.
+func (m *mysqlBillRepository) ReadBillsByUser(ctx context.Context, userID int64) ([]model.Bill, error) {
+	query := `SELECT ` + billColumns + ` FROM bill WHERE organizer_id=? OR id IN (SELECT bill_id FROM payment_request WHERE payer_id=?) ORDER BY id DESC`
+
+	rows, err := m.db.QueryContext(ctx, query, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bills := []model.Bill{}
+	for rows.Next() {
+		bill, err := scanBill(rows)
+		if err != nil {
+			return nil, err
+		}
+		bills = append(bills, *bill)
+	}
+
+	return bills, rows.Err()
+}
+
+// CancelBill cancels an open bill. It returns sql.ErrNoRows when the bill
+// is not open.
+func (m *mysqlBillRepository) CancelBill(ctx context.Context, tx *sql.Tx, billID int64) error {
+	query := `UPDATE bill SET status=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, model.BillStatusCancelled, time.Now(), billID, model.BillStatusOpen)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanBill(row rowScanner) (*model.Bill, error) {
+	var bill model.Bill
+
+	err := row.Scan(
+		&bill.ID,
+		&bill.OrganizerID,
+		&bill.WalletID,
+		&bill.Title,
+		&bill.Amount,
+		&bill.Status,
+		&bill.CreatedAt,
+		&bill.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bill, nil
+}
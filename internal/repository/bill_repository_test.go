@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlBillRepository_ReadBillsByUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewBillRepository(db)
+		query   = "SELECT (.+) FROM bill WHERE organizer_id=\\? OR id IN \\(SELECT bill_id FROM payment_request WHERE payer_id=\\?\\) ORDER BY id DESC"
+		columns = []string{"id", "organizer_id", "wallet_id", "title", "amount", "status", "created_at", "updated_at"}
+		now     = time.Now()
+	)
+
+	bill := model.Bill{
+		ID: 1, OrganizerID: 1, WalletID: 3, Title: "dinner", Amount: 300000,
+		Status: model.BillStatusOpen, CreatedAt: now, UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name    string
+		want    []model.Bill
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    []model.Bill{bill},
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(int64(2), int64(2)).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				rows := sqlmock.NewRows(columns).AddRow(bill.ID, bill.OrganizerID, bill.WalletID, bill.Title, bill.Amount, bill.Status, now, now)
+				mock.ExpectQuery(query).WithArgs(int64(2), int64(2)).WillReturnRows(rows)
+			}
+
+			got, err := repo.ReadBillsByUser(ctx, 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlBillRepository.ReadBillsByUser() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlBillRepository.ReadBillsByUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlBillRepository_CancelBill(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewBillRepository(db)
+		query = "UPDATE bill SET status=\\?, updated_at=\\? WHERE id=\\? AND status=\\?"
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "not open",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(model.BillStatusCancelled, sqlmock.AnyArg(), int64(1), model.BillStatusOpen).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			if err := repo.CancelBill(ctx, tx, 1); err != tt.wantErr {
+				t.Errorf("mysqlBillRepository.CancelBill() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type EscrowRepository interface {
+	WriteEscrow(ctx context.Context, tx *sql.Tx, escrow model.Escrow) (escrowID int64, err error)
+	ReadEscrowByID(ctx context.Context, escrowID int64) (*model.Escrow, error)
+	ReadEscrowForUpdate(ctx context.Context, tx *sql.Tx, escrowID int64) (*model.Escrow, error)
+	ReadEscrowsByUser(ctx context.Context, userID int64) ([]model.Escrow, error)
+	UpdateEscrowStatus(ctx context.Context, tx *sql.Tx, escrow model.Escrow) error
+	ReadTimedOutEscrowIDs(ctx context.Context, at time.Time, limit int) ([]int64, error)
+	WriteEscrowEvent(ctx context.Context, tx *sql.Tx, event model.EscrowEvent) (eventID int64, err error)
+	ReadEscrowEvents(ctx context.Context, escrowID int64) ([]model.EscrowEvent, error)
+}
+
+type mysqlEscrowRepository struct {
+	db *sql.DB
+}
+
+func NewEscrowRepository(db *sql.DB) EscrowRepository {
+	return &mysqlEscrowRepository{
+		db: db,
+	}
+}
+
+const escrowColumns = `id, buyer_id, buyer_wallet_id, merchant_id, escrow_wallet_id, amount, status, timeout_action, reference, description, expires_at, resolved_at, created_at, updated_at`
+
+func (m *mysqlEscrowRepository) WriteEscrow(ctx context.Context, tx *sql.Tx, escrow model.Escrow) (escrowID int64, err error) {
+	query := `INSERT INTO escrow (buyer_id, buyer_wallet_id, merchant_id, escrow_wallet_id, amount, status, timeout_action, reference, description, expires_at) VALUES (?,?,?,?,?,?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, escrow.BuyerID, escrow.BuyerWalletID, escrow.MerchantID, escrow.EscrowWalletID, escrow.Amount, escrow.Status,
+		escrow.TimeoutAction, escrow.Reference, escrow.Description, escrow.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlEscrowRepository) ReadEscrowByID(ctx context.Context, escrowID int64) (*model.Escrow, error) {
+	query := `SELECT ` + escrowColumns + ` FROM escrow WHERE id=?`
+
+	escrow, err := scanEscrow(m.db.QueryRowContext(ctx, query, escrowID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return escrow, nil
+}
+
+func (m *mysqlEscrowRepository) ReadEscrowForUpdate(ctx context.Context, tx *sql.Tx, escrowID int64) (*model.Escrow, error) {
+	query := `SELECT ` + escrowColumns + ` FROM escrow WHERE id=? FOR UPDATE`
+
+	escrow, err := scanEscrow(tx.QueryRowContext(ctx, query, escrowID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return escrow, nil
+}
+
+// ReadEscrowsByUser returns the escrows a user funded or is the seller in,
+// through a merchant they own, newest first.
+func (m *mysqlEscrowRepository) ReadEscrowsByUser(ctx context.Context, userID int64) ([]model.Escrow, error) {
+	query := `SELECT ` + escrowColumns + ` FROM escrow WHERE buyer_id=? OR merchant_id IN (SELECT id FROM merchant WHERE user_id=?) ORDER BY id DESC`
+
+	rows, err := m.db.QueryContext(ctx, query, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	escrows := []model.Escrow{}
+	for rows.Next() {
+		escrow, err := scanEscrow(rows)
+		if err != nil {
+			return nil, err
+		}
+		escrows = append(escrows, *escrow)
+	}
+
+	return escrows, rows.Err()
+}
+
+// UpdateEscrowStatus records the new status of an escrow locked in tx.
+func (m *mysqlEscrowRepository) UpdateEscrowStatus(ctx context.Context, tx *sql.Tx, escrow model.Escrow) error {
+	query := `UPDATE escrow SET status=?, resolved_at=?, updated_at=? WHERE id=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, escrow.Status, escrow.ResolvedAt, time.Now(), escrow.ID)
+
+	return err
+}
+
+// ReadTimedOutEscrowIDs returns up to limit funded escrows past their
+// expiry at the given time, longest overdue first. They are not locked.
+func (m *mysqlEscrowRepository) ReadTimedOutEscrowIDs(ctx context.Context, at time.Time, limit int) ([]int64, error) {
+	query := `SELECT id FROM escrow WHERE status=? AND expires_at<=? ORDER BY expires_at LIMIT ?`
+
+	rows, err := m.db.QueryContext(ctx, query, model.EscrowStatusFunded, at, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (m *mysqlEscrowRepository) WriteEscrowEvent(ctx context.Context, tx *sql.Tx, event model.EscrowEvent) (eventID int64, err error) {
+	query := `INSERT INTO escrow_event (escrow_id, from_status, to_status, actor_id, transaction_id, note) VALUES (?,?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, event.EscrowID, event.FromStatus, event.ToStatus, event.ActorID, event.TransactionID, event.Note)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlEscrowRepository) ReadEscrowEvents(ctx context.Context, escrowID int64) ([]model.EscrowEvent, error) {
+	query := `SELECT id, escrow_id, from_status, to_status, actor_id, transaction_id, note, created_at FROM escrow_event WHERE escrow_id=? ORDER BY id`
+
+	rows, err := m.db.QueryContext(ctx, query, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []model.EscrowEvent{}
+	for rows.Next() {
+		var (
+			event         model.EscrowEvent
+			actorID       sql.NullInt64
+			transactionID sql.NullInt64
+		)
+
+		err := rows.Scan(&event.ID, &event.EscrowID, &event.FromStatus, &event.ToStatus, &actorID, &transactionID, &event.Note, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if actorID.Valid {
+			event.ActorID = &actorID.Int64
+		}
+
+		if transactionID.Valid {
+			event.TransactionID = &transactionID.Int64
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func scanEscrow(row rowScanner) (*model.Escrow, error) {
+	var (
+		escrow     model.Escrow
+		resolvedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&escrow.ID,
+		&escrow.BuyerID,
+		&escrow.BuyerWalletID,
+		&escrow.MerchantID,
+		&escrow.EscrowWalletID,
+		&escrow.Amount,
+		&escrow.Status,
+		&escrow.TimeoutAction,
+		&escrow.Reference,
+		&escrow.Description,
+		&escrow.ExpiresAt,
+		&resolvedAt,
+		&escrow.CreatedAt,
+		&escrow.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolvedAt.Valid {
+		escrow.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &escrow, nil
+}
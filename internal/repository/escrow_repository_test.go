@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlEscrowRepository_ReadTimedOutEscrowIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewEscrowRepository(db)
+		query = "SELECT id FROM escrow WHERE status=\\? AND expires_at<=\\? ORDER BY expires_at LIMIT \\?"
+		now   = time.Now()
+	)
+
+	tests := []struct {
+		name    string
+		want    []int64
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    []int64{4, 7},
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(model.EscrowStatusFunded, now, 100).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				rows := sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(7)
+				mock.ExpectQuery(query).WithArgs(model.EscrowStatusFunded, now, 100).WillReturnRows(rows)
+			}
+
+			got, err := repo.ReadTimedOutEscrowIDs(ctx, now, 100)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlEscrowRepository.ReadTimedOutEscrowIDs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlEscrowRepository.ReadTimedOutEscrowIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
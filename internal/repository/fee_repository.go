@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type FeeRepository interface {
+	ReadRules(ctx context.Context) ([]model.FeeRule, error)
+	ReadApplicableRule(ctx context.Context, operation string, merchantID int64) (*model.FeeRule, error)
+	ReadRuleByID(ctx context.Context, ruleID int64) (*model.FeeRule, error)
+	WriteRule(ctx context.Context, rule model.FeeRule) (ruleID int64, err error)
+	UpdateRule(ctx context.Context, rule model.FeeRule) error
+}
+
+type mysqlFeeRepository struct {
+	db *sql.DB
+}
+
+func NewFeeRepository(db *sql.DB) FeeRepository {
+	return &mysqlFeeRepository{
+		db: db,
+	}
+}
+
+const feeRuleColumns = `id, name, operation, type, flat, percentage, tiers, min_fee, max_fee, merchant_id, active, created_at, updated_at`
+
+func (m *mysqlFeeRepository) ReadRules(ctx context.Context) ([]model.FeeRule, error) {
+	query := `SELECT ` + feeRuleColumns + ` FROM fee_rule ORDER BY id`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.FeeRule{}
+	for rows.Next() {
+		rule, err := scanFeeRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// ReadApplicableRule returns the active rule that prices operation: the
+// newest rule for merchantID if there is one, otherwise the newest global
+// rule. It returns nil when the operation is free.
+func (m *mysqlFeeRepository) ReadApplicableRule(ctx context.Context, operation string, merchantID int64) (*model.FeeRule, error) {
+	query := `SELECT ` + feeRuleColumns + ` FROM fee_rule WHERE operation=? AND active=1 AND (merchant_id IS NULL OR merchant_id=?) ORDER BY merchant_id IS NULL, id DESC LIMIT 1`
+	return m.readRule(ctx, query, operation, merchantID)
+}
+
+func (m *mysqlFeeRepository) ReadRuleByID(ctx context.Context, ruleID int64) (*model.FeeRule, error) {
+	query := `SELECT ` + feeRuleColumns + ` FROM fee_rule WHERE id=?`
+	return m.readRule(ctx, query, ruleID)
+}
+
+func (m *mysqlFeeRepository) WriteRule(ctx context.Context, rule model.FeeRule) (ruleID int64, err error) {
+	query := `INSERT INTO fee_rule (name, operation, type, flat, percentage, tiers, min_fee, max_fee, merchant_id, active) VALUES (?,?,?,?,?,?,?,?,?,?)`
+
+	tiers, err := json.Marshal(rule.Tiers)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, rule.Name, rule.Operation, rule.Type, rule.Flat, rule.Percentage, string(tiers), rule.MinFee, rule.MaxFee, rule.MerchantID, rule.Active)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlFeeRepository) UpdateRule(ctx context.Context, rule model.FeeRule) error {
+	query := `UPDATE fee_rule SET name=?, operation=?, type=?, flat=?, percentage=?, tiers=?, min_fee=?, max_fee=?, merchant_id=?, active=?, updated_at=? WHERE id=?`
+
+	tiers, err := json.Marshal(rule.Tiers)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, rule.Name, rule.Operation, rule.Type, rule.Flat, rule.Percentage, string(tiers), rule.MinFee, rule.MaxFee, rule.MerchantID, rule.Active, time.Now(), rule.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *mysqlFeeRepository) readRule(ctx context.Context, query string, args ...interface{}) (*model.FeeRule, error) {
+	rule, err := scanFeeRule(m.db.QueryRowContext(ctx, query, args...))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return rule, nil
+}
+
+func scanFeeRule(row rowScanner) (*model.FeeRule, error) {
+	var (
+		rule       model.FeeRule
+		tiers      sql.NullString
+		merchantID sql.NullInt64
+	)
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Operation,
+		&rule.Type,
+		&rule.Flat,
+		&rule.Percentage,
+		&tiers,
+		&rule.MinFee,
+		&rule.MaxFee,
+		&merchantID,
+		&rule.Active,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Tiers = []model.FeeTier{}
+	if tiers.Valid && tiers.String != "" {
+		if err = json.Unmarshal([]byte(tiers.String), &rule.Tiers); err != nil {
+			return nil, err
+		}
+	}
+
+	if merchantID.Valid {
+		rule.MerchantID = &merchantID.Int64
+	}
+
+	return &rule, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlFeeRepository_ReadApplicableRule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewFeeRepository(db)
+		query      = "SELECT (.+) FROM fee_rule WHERE operation=\\? AND active=1 AND \\(merchant_id IS NULL OR merchant_id=\\?\\) ORDER BY merchant_id IS NULL, id DESC LIMIT 1"
+		columns    = []string{"id", "name", "operation", "type", "flat", "percentage", "tiers", "min_fee", "max_fee", "merchant_id", "active", "created_at", "updated_at"}
+		now        = time.Now()
+		merchantID = int64(4)
+	)
+
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		err     error
+		want    *model.FeeRule
+		wantErr bool
+	}{
+		{
+			name: "merchant rule",
+			rows: sqlmock.NewRows(columns).
+				AddRow(2, "merchant tiers", "payment", "tiered", 0, 0, `[{"up_to":10000,"flat":500,"percentage":0},{"up_to":0,"flat":0,"percentage":1}]`, 0, 5000, merchantID, true, now, now),
+			want: &model.FeeRule{
+				ID: 2, Name: "merchant tiers", Operation: "payment", Type: "tiered",
+				Tiers:  []model.FeeTier{{UpTo: 10000, Flat: 500}, {Percentage: 1}},
+				MaxFee: 5000, MerchantID: &merchantID, Active: true, CreatedAt: now, UpdatedAt: now,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "no rule",
+			rows:    sqlmock.NewRows(columns),
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			err:     fmt.Errorf("some error"),
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err != nil {
+				mock.ExpectQuery(query).WillReturnError(tt.err)
+			} else {
+				mock.ExpectQuery(query).WithArgs("payment", merchantID).WillReturnRows(tt.rows)
+			}
+
+			got, err := repo.ReadApplicableRule(ctx, "payment", merchantID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlFeeRepository.ReadApplicableRule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlFeeRepository.ReadApplicableRule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlFeeRepository_WriteRule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewFeeRepository(db)
+		query = "INSERT INTO fee_rule \\(name, operation, type, flat, percentage, tiers, min_fee, max_fee, merchant_id, active\\) VALUES \\(\\?,\\?,\\?,\\?,\\?,\\?,\\?,\\?,\\?,\\?\\)"
+		rule  = model.FeeRule{Name: "top up", Operation: "top_up", Type: "percentage", Percentage: 1.5, Tiers: []model.FeeTier{}, MinFee: 1000, Active: true}
+	)
+
+	tests := []struct {
+		name    string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    7,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prep := mock.ExpectPrepare(query)
+			if tt.wantErr {
+				prep.ExpectExec().WillReturnError(fmt.Errorf("some error"))
+			} else {
+				prep.ExpectExec().
+					WithArgs(rule.Name, rule.Operation, rule.Type, rule.Flat, rule.Percentage, "[]", rule.MinFee, rule.MaxFee, rule.MerchantID, rule.Active).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+			}
+
+			got, err := repo.WriteRule(ctx, rule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlFeeRepository.WriteRule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("mysqlFeeRepository.WriteRule() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type HoldRepository interface {
+	WriteHold(ctx context.Context, tx *sql.Tx, hold model.Hold) (holdID int64, err error)
+	ReadHoldByID(ctx context.Context, holdID int64) (*model.Hold, error)
+	ReadHoldForUpdate(ctx context.Context, tx *sql.Tx, holdID int64) (*model.Hold, error)
+	ReadHoldsByWallet(ctx context.Context, walletID int64) ([]model.Hold, error)
+	UpdateHold(ctx context.Context, tx *sql.Tx, hold model.Hold) error
+	SumActiveHolds(ctx context.Context, walletID int64, at time.Time) (float64, error)
+	ExpireHolds(ctx context.Context, at time.Time) (int64, error)
+}
+
+type mysqlHoldRepository struct {
+	db *sql.DB
+}
+
+func NewHoldRepository(db *sql.DB) HoldRepository {
+	return &mysqlHoldRepository{
+		db: db,
+	}
+}
+
+const holdColumns = `id, wallet_id, merchant_id, amount, captured_amount, status, reference, description, expires_at, created_at, updated_at`
+
+func (m *mysqlHoldRepository) WriteHold(ctx context.Context, tx *sql.Tx, hold model.Hold) (holdID int64, err error) {
+	query := `INSERT INTO wallet_hold (wallet_id, merchant_id, amount, status, reference, description, expires_at) VALUES (?,?,?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, hold.WalletID, hold.MerchantID, hold.Amount, hold.Status, hold.Reference, hold.Description, hold.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlHoldRepository) ReadHoldByID(ctx context.Context, holdID int64) (*model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM wallet_hold WHERE id=?`
+
+	hold, err := scanHold(m.db.QueryRowContext(ctx, query, holdID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return hold, nil
+}
+
+func (m *mysqlHoldRepository) ReadHoldForUpdate(ctx context.Context, tx *sql.Tx, holdID int64) (*model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM wallet_hold WHERE id=? FOR UPDATE`
+
+	hold, err := scanHold(tx.QueryRowContext(ctx, query, holdID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return hold, nil
+}
+
+func (m *mysqlHoldRepository) ReadHoldsByWallet(ctx context.Context, walletID int64) ([]model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM wallet_hold WHERE wallet_id=? ORDER BY id DESC`
+
+	rows, err := m.db.QueryContext(ctx, query, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []model.Hold{}
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *hold)
+	}
+
+	return holds, rows.Err()
+}
+
+func (m *mysqlHoldRepository) UpdateHold(ctx context.Context, tx *sql.Tx, hold model.Hold) error {
+	query := `UPDATE wallet_hold SET captured_amount=?, status=?, updated_at=? WHERE id=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, hold.CapturedAmount, hold.Status, time.Now(), hold.ID)
+
+	return err
+}
+
+// SumActiveHolds returns the amount reserved on a wallet by holds that are
+// still authorized and not yet expired at the given time. Expired holds stop
+// counting immediately, whether or not ExpireHolds has marked them yet.
+func (m *mysqlHoldRepository) SumActiveHolds(ctx context.Context, walletID int64, at time.Time) (float64, error) {
+	query := `SELECT COALESCE(SUM(amount), 0) FROM wallet_hold WHERE wallet_id=? AND status=? AND expires_at>?`
+
+	var total float64
+	if err := m.db.QueryRowContext(ctx, query, walletID, model.HoldStatusAuthorized, at).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ExpireHolds marks every authorized hold past its expiry as expired and
+// returns how many were updated.
+func (m *mysqlHoldRepository) ExpireHolds(ctx context.Context, at time.Time) (int64, error) {
+	query := `UPDATE wallet_hold SET status=?, updated_at=? WHERE status=? AND expires_at<=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, model.HoldStatusExpired, time.Now(), model.HoldStatusAuthorized, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func scanHold(row rowScanner) (*model.Hold, error) {
+	var hold model.Hold
+
+	err := row.Scan(
+		&hold.ID,
+		&hold.WalletID,
+		&hold.MerchantID,
+		&hold.Amount,
+		&hold.CapturedAmount,
+		&hold.Status,
+		&hold.Reference,
+		&hold.Description,
+		&hold.ExpiresAt,
+		&hold.CreatedAt,
+		&hold.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
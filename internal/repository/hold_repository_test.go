@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlHoldRepository_ReadHoldForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewHoldRepository(db)
+		query   = "SELECT id, wallet_id, merchant_id, amount, captured_amount, status, reference, description, expires_at, created_at, updated_at FROM wallet_hold WHERE id=\\? FOR UPDATE"
+		columns = []string{"id", "wallet_id", "merchant_id", "amount", "captured_amount", "status", "reference", "description", "expires_at", "created_at", "updated_at"}
+		now     = time.Now()
+	)
+
+	hold := model.Hold{
+		ID:         5,
+		WalletID:   3,
+		MerchantID: 8,
+		Amount:     3000,
+		Status:     model.HoldStatusAuthorized,
+		Reference:  "ref",
+		ExpiresAt:  now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	tests := []struct {
+		name    string
+		want    *model.Hold
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    &hold,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(hold.ID).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				rows := sqlmock.NewRows(columns).AddRow(hold.ID, hold.WalletID, hold.MerchantID, hold.Amount, hold.CapturedAmount, hold.Status, hold.Reference, hold.Description, now, now, now)
+				mock.ExpectQuery(query).WithArgs(hold.ID).WillReturnRows(rows)
+			}
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			got, err := repo.ReadHoldForUpdate(ctx, tx, hold.ID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlHoldRepository.ReadHoldForUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlHoldRepository.ReadHoldForUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlHoldRepository_SumActiveHolds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewHoldRepository(db)
+		query = "SELECT COALESCE\\(SUM\\(amount\\), 0\\) FROM wallet_hold WHERE wallet_id=\\? AND status=\\? AND expires_at>\\?"
+		now   = time.Now()
+	)
+
+	mock.ExpectQuery(query).WithArgs(3, model.HoldStatusAuthorized, now).WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(4500))
+
+	got, err := repo.SumActiveHolds(ctx, 3, now)
+	if err != nil {
+		t.Fatalf("mysqlHoldRepository.SumActiveHolds() error = %v", err)
+	}
+	if got != 4500 {
+		t.Errorf("mysqlHoldRepository.SumActiveHolds() = %v, want %v", got, 4500)
+	}
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type KYCRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
+	WriteDocument(ctx context.Context, doc model.KYCDocument) (documentID int64, err error)
+	ReadDocumentByID(ctx context.Context, documentID int64) (*model.KYCDocument, error)
+	ReadDocumentPage(ctx context.Context, status string, page model.PageRequest) (docs []model.KYCDocument, total int64, err error)
+	ReadDocumentsByUserID(ctx context.Context, userID int64) ([]model.KYCDocument, error)
+	UpdateReview(ctx context.Context, tx *sql.Tx, doc model.KYCDocument) error
+	DeleteDocumentsByUserID(ctx context.Context, tx *sql.Tx, userID int64) error
+}
+
+type mysqlKYCRepository struct {
+	db *sql.DB
+}
+
+func NewKYCRepository(db *sql.DB) KYCRepository {
+	return &mysqlKYCRepository{
+		db: db,
+	}
+}
+
+// kycDocumentSorts are the fields KYC documents can be sorted by.
+var kycDocumentSorts = map[string]string{
+	"id":              "id",
+	"document_type":   "document_type",
+	"requested_level": "requested_level",
+	"created_at":      "created_at",
+	"updated_at":      "updated_at",
+}
+
+const kycDocumentColumns = `id, user_id, document_type, requested_level, storage_key, content_type, status, reviewer_id, review_note, reviewed_at, created_at, updated_at`
+
+func (m *mysqlKYCRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+func (m *mysqlKYCRepository) WriteDocument(ctx context.Context, doc model.KYCDocument) (documentID int64, err error) {
+	query := `INSERT INTO kyc_document (user_id, document_type, requested_level, storage_key, content_type, status) VALUES (?,?,?,?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, doc.UserID, doc.DocumentType, doc.RequestedLevel, doc.StorageKey, doc.ContentType, doc.Status)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlKYCRepository) ReadDocumentByID(ctx context.Context, documentID int64) (*model.KYCDocument, error) {
+	query := `SELECT ` + kycDocumentColumns + ` FROM kyc_document WHERE id=?`
+
+	doc, err := scanKYCDocument(m.db.QueryRowContext(ctx, query, documentID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return doc, nil
+}
+
+// ReadDocumentPage returns a page of the documents with the given review
+// status, and how many there are in all.
+func (m *mysqlKYCRepository) ReadDocumentPage(ctx context.Context, status string, page model.PageRequest) ([]model.KYCDocument, int64, error) {
+	order, limit, err := orderBy(page, kycDocumentSorts, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err = m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM kyc_document WHERE status=?`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + kycDocumentColumns + ` FROM kyc_document WHERE status=?` + order
+
+	docs, err := m.readDocuments(ctx, query, append([]interface{}{status}, limit...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return docs, total, nil
+}
+
+func (m *mysqlKYCRepository) ReadDocumentsByUserID(ctx context.Context, userID int64) ([]model.KYCDocument, error) {
+	query := `SELECT ` + kycDocumentColumns + ` FROM kyc_document WHERE user_id=? ORDER BY id`
+	return m.readDocuments(ctx, query, userID)
+}
+
+func (m *mysqlKYCRepository) UpdateReview(ctx context.Context, tx *sql.Tx, doc model.KYCDocument) error {
+	query := `UPDATE kyc_document SET status=?, reviewer_id=?, review_note=?, reviewed_at=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, doc.Status, doc.ReviewerID, doc.ReviewNote, doc.ReviewedAt, time.Now(), doc.ID, model.KYCDocumentPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *mysqlKYCRepository) DeleteDocumentsByUserID(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `DELETE FROM kyc_document WHERE user_id=?`
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *mysqlKYCRepository) readDocuments(ctx context.Context, query string, args ...interface{}) ([]model.KYCDocument, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []model.KYCDocument{}
+	for rows.Next() {
+		doc, err := scanKYCDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *doc)
+	}
+
+	return docs, rows.Err()
+}
+
+func scanKYCDocument(row rowScanner) (*model.KYCDocument, error) {
+	var (
+		doc        model.KYCDocument
+		reviewerID sql.NullInt64
+		reviewedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&doc.ID,
+		&doc.UserID,
+		&doc.DocumentType,
+		&doc.RequestedLevel,
+		&doc.StorageKey,
+		&doc.ContentType,
+		&doc.Status,
+		&reviewerID,
+		&doc.ReviewNote,
+		&reviewedAt,
+		&doc.CreatedAt,
+		&doc.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviewerID.Valid {
+		doc.ReviewerID = &reviewerID.Int64
+	}
+
+	if reviewedAt.Valid {
+		doc.ReviewedAt = &reviewedAt.Time
+	}
+
+	return &doc, nil
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+var kycDocumentRows = []string{"id", "user_id", "document_type", "requested_level", "storage_key", "content_type", "status", "reviewer_id", "review_note", "reviewed_at", "created_at", "updated_at"}
+
+func Test_mysqlKYCRepository_WriteDocument(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewKYCRepository(db)
+		query = "INSERT INTO kyc_document (user_id, document_type, requested_level, storage_key, content_type, status) VALUES (?,?,?,?,?,?)"
+	)
+
+	doc := model.KYCDocument{
+		UserID:         1,
+		DocumentType:   "id_card",
+		RequestedLevel: model.KYCLevelBasic,
+		StorageKey:     "kyc/1/abc.png",
+		ContentType:    "image/png",
+		Status:         model.KYCDocumentPending,
+	}
+
+	tests := []struct {
+		name    string
+		repo    KYCRepository
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			repo:    repo,
+			want:    4,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			repo:    repo,
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectPrepare(query).ExpectExec().WillReturnError(fmt.Errorf("err"))
+			} else {
+				mock.ExpectPrepare(query).ExpectExec().
+					WithArgs(doc.UserID, doc.DocumentType, doc.RequestedLevel, doc.StorageKey, doc.ContentType, doc.Status).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+			}
+
+			got, err := tt.repo.WriteDocument(ctx, doc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlKYCRepository.WriteDocument() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("mysqlKYCRepository.WriteDocument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlKYCRepository_ReadDocumentByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewKYCRepository(db)
+		query      = "SELECT (.+) FROM kyc_document WHERE id=\\?"
+		now        = time.Now()
+		reviewerID = int64(2)
+	)
+
+	doc := model.KYCDocument{
+		ID:             4,
+		UserID:         1,
+		DocumentType:   "id_card",
+		RequestedLevel: model.KYCLevelBasic,
+		StorageKey:     "kyc/1/abc.png",
+		ContentType:    "image/png",
+		Status:         model.KYCDocumentApproved,
+		ReviewerID:     &reviewerID,
+		ReviewedAt:     &now,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	tests := []struct {
+		name    string
+		repo    KYCRepository
+		want    *model.KYCDocument
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			repo:    repo,
+			want:    &doc,
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			repo:    repo,
+			want:    nil,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.want == nil {
+				mock.ExpectQuery(query).WithArgs(int64(4)).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows(kycDocumentRows).AddRow(doc.ID, doc.UserID, doc.DocumentType, doc.RequestedLevel, doc.StorageKey, doc.ContentType, doc.Status, reviewerID, doc.ReviewNote, now, now, now)
+				mock.ExpectQuery(query).WithArgs(int64(4)).WillReturnRows(rows)
+			}
+
+			got, err := tt.repo.ReadDocumentByID(ctx, 4)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlKYCRepository.ReadDocumentByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlKYCRepository.ReadDocumentByID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlKYCRepository_ReadDocumentPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewKYCRepository(db)
+		countQuery = "SELECT COUNT\\(\\*\\) FROM kyc_document WHERE status=\\?"
+		query      = "SELECT (.+) FROM kyc_document WHERE status=\\? ORDER BY created_at ASC, id ASC LIMIT \\? OFFSET \\?"
+		now        = time.Now()
+	)
+
+	tests := []struct {
+		name      string
+		page      model.PageRequest
+		want      []model.KYCDocument
+		wantTotal int64
+		wantErr   error
+	}{
+		{
+			name: "success",
+			page: model.PageRequest{Page: 2, PerPage: 1, Sort: "created_at", Order: model.SortAsc},
+			want: []model.KYCDocument{
+				{ID: 2, UserID: 1, DocumentType: "id_card", RequestedLevel: model.KYCLevelBasic, StorageKey: "kyc/1/b.png", ContentType: "image/png", Status: model.KYCDocumentPending, CreatedAt: now, UpdatedAt: now},
+			},
+			wantTotal: 3,
+		},
+		{
+			name:    "unknown sort",
+			page:    model.PageRequest{Page: 1, PerPage: 20, Sort: "storage_key"},
+			wantErr: cs.ErrBadParamInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr == nil {
+				rows := sqlmock.NewRows(kycDocumentRows)
+				for _, doc := range tt.want {
+					rows.AddRow(doc.ID, doc.UserID, doc.DocumentType, doc.RequestedLevel, doc.StorageKey, doc.ContentType, doc.Status, nil, doc.ReviewNote, nil, doc.CreatedAt, doc.UpdatedAt)
+				}
+				mock.ExpectQuery(countQuery).WithArgs(model.KYCDocumentPending).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.wantTotal))
+				mock.ExpectQuery(query).WithArgs(model.KYCDocumentPending, 1, 1).WillReturnRows(rows)
+			}
+
+			got, total, err := repo.ReadDocumentPage(ctx, model.KYCDocumentPending, tt.page)
+			if err != tt.wantErr {
+				t.Fatalf("mysqlKYCRepository.ReadDocumentPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("mysqlKYCRepository.ReadDocumentPage() = %v, %d, want %v, %d", got, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func Test_mysqlKYCRepository_UpdateReview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewKYCRepository(db)
+		query = "UPDATE kyc_document SET status=\\?, reviewer_id=\\?, review_note=\\?, reviewed_at=\\?, updated_at=\\? WHERE id=\\? AND status=\\?"
+	)
+
+	tests := []struct {
+		name     string
+		repo     KYCRepository
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			repo:     repo,
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "already reviewed",
+			repo:     repo,
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := tt.repo.UpdateReview(ctx, tt.repo.BeginTx(ctx), model.KYCDocument{ID: 4, Status: model.KYCDocumentApproved})
+			if err != tt.wantErr {
+				t.Errorf("mysqlKYCRepository.UpdateReview() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_mysqlKYCRepository_DeleteDocumentsByUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewKYCRepository(db)
+		query = "DELETE FROM kyc_document WHERE user_id=\\?"
+	)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(query).WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err := repo.DeleteDocumentsByUserID(ctx, repo.BeginTx(ctx), 7); err != nil {
+		t.Errorf("mysqlKYCRepository.DeleteDocumentsByUserID() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
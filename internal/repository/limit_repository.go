@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type LimitRepository interface {
+	ReadRules(ctx context.Context) ([]model.LimitRule, error)
+	ReadActiveRules(ctx context.Context, operation string, userID int64) ([]model.LimitRule, error)
+	ReadRuleByID(ctx context.Context, ruleID int64) (*model.LimitRule, error)
+	WriteRule(ctx context.Context, tx *sql.Tx, rule model.LimitRule) (ruleID int64, err error)
+	UpdateRule(ctx context.Context, tx *sql.Tx, rule model.LimitRule) error
+	ReadUsage(ctx context.Context, tx *sql.Tx, walletID int64, operation string, since time.Time) (model.LimitUsage, error)
+}
+
+type mysqlLimitRepository struct {
+	db *sql.DB
+}
+
+func NewLimitRepository(db *sql.DB) LimitRepository {
+	return &mysqlLimitRepository{
+		db: db,
+	}
+}
+
+const limitRuleColumns = `id, name, operation, type, threshold, window_seconds, user_id, active, created_at, updated_at`
+
+func (m *mysqlLimitRepository) ReadRules(ctx context.Context) ([]model.LimitRule, error) {
+	query := `SELECT ` + limitRuleColumns + ` FROM limit_rule ORDER BY id`
+	return m.readRules(ctx, query)
+}
+
+// ReadActiveRules returns the active global rules for operation together
+// with the active rules that target userID.
+func (m *mysqlLimitRepository) ReadActiveRules(ctx context.Context, operation string, userID int64) ([]model.LimitRule, error) {
+	query := `SELECT ` + limitRuleColumns + ` FROM limit_rule WHERE operation=? AND active=1 AND (user_id IS NULL OR user_id=?) ORDER BY id`
+	return m.readRules(ctx, query, operation, userID)
+}
+
+func (m *mysqlLimitRepository) ReadRuleByID(ctx context.Context, ruleID int64) (*model.LimitRule, error) {
+	query := `SELECT ` + limitRuleColumns + ` FROM limit_rule WHERE id=?`
+
+	rule, err := scanLimitRule(m.db.QueryRowContext(ctx, query, ruleID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return rule, nil
+}
+
+func (m *mysqlLimitRepository) WriteRule(ctx context.Context, tx *sql.Tx, rule model.LimitRule) (ruleID int64, err error) {
+	query := `INSERT INTO limit_rule (name, operation, type, threshold, window_seconds, user_id, active) VALUES (?,?,?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, rule.Name, rule.Operation, rule.Type, rule.Threshold, rule.WindowSeconds, rule.UserID, rule.Active)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlLimitRepository) UpdateRule(ctx context.Context, tx *sql.Tx, rule model.LimitRule) error {
+	query := `UPDATE limit_rule SET name=?, operation=?, type=?, threshold=?, window_seconds=?, user_id=?, active=?, updated_at=? WHERE id=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, rule.Name, rule.Operation, rule.Type, rule.Threshold, rule.WindowSeconds, rule.UserID, rule.Active, time.Now(), rule.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReadUsage summarizes the ledger entries counted toward operation's limits
+// on a wallet since the given time. It runs in tx so it sees the same state the balance change is
+// applied to.
+func (m *mysqlLimitRepository) ReadUsage(ctx context.Context, tx *sql.Tx, walletID int64, operation string, since time.Time) (model.LimitUsage, error) {
+	types := model.LimitedEntryTypes(operation)
+
+	query := `SELECT COUNT(id), COALESCE(SUM(ABS(amount)), 0), MIN(created_at) FROM wallet_transaction WHERE wallet_id=? AND type IN (?` + strings.Repeat(",?", len(types)-1) + `) AND created_at>=?`
+
+	args := []interface{}{walletID}
+	for _, t := range types {
+		args = append(args, t)
+	}
+	args = append(args, since)
+
+	var (
+		usage  model.LimitUsage
+		oldest sql.NullTime
+	)
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&usage.Count, &usage.Amount, &oldest)
+	if err != nil {
+		return usage, err
+	}
+
+	if oldest.Valid {
+		usage.Oldest = &oldest.Time
+	}
+
+	return usage, nil
+}
+
+func (m *mysqlLimitRepository) readRules(ctx context.Context, query string, args ...interface{}) ([]model.LimitRule, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []model.LimitRule{}
+	for rows.Next() {
+		rule, err := scanLimitRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func scanLimitRule(row rowScanner) (*model.LimitRule, error) {
+	var (
+		rule   model.LimitRule
+		userID sql.NullInt64
+	)
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Operation,
+		&rule.Type,
+		&rule.Threshold,
+		&rule.WindowSeconds,
+		&userID,
+		&rule.Active,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID.Valid {
+		rule.UserID = &userID.Int64
+	}
+
+	return &rule, nil
+}
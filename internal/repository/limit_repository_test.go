@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlLimitRepository_ReadActiveRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx    = context.Background()
+		repo   = NewLimitRepository(db)
+		query  = "SELECT (.+) FROM limit_rule WHERE operation=\\? AND active=1 AND \\(user_id IS NULL OR user_id=\\?\\) ORDER BY id"
+		now    = time.Now()
+		userID = int64(1)
+	)
+
+	tests := []struct {
+		name    string
+		repo    LimitRepository
+		want    []model.LimitRule
+		wantErr bool
+	}{
+		{
+			name: "success",
+			repo: repo,
+			want: []model.LimitRule{
+				{ID: 1, Name: "per minute", Operation: "payment", Type: "count", Threshold: 5, WindowSeconds: 60, Active: true, CreatedAt: now, UpdatedAt: now},
+				{ID: 2, Name: "vip", Operation: "payment", Type: "count", Threshold: 50, WindowSeconds: 60, UserID: &userID, Active: true, CreatedAt: now, UpdatedAt: now},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			repo:    repo,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				rows := sqlmock.NewRows([]string{"id", "name", "operation", "type", "threshold", "window_seconds", "user_id", "active", "created_at", "updated_at"}).
+					AddRow(1, "per minute", "payment", "count", 5, 60, nil, true, now, now).
+					AddRow(2, "vip", "payment", "count", 50, 60, userID, true, now, now)
+				mock.ExpectQuery(query).WithArgs("payment", userID).WillReturnRows(rows)
+			}
+
+			got, err := tt.repo.ReadActiveRules(ctx, "payment", userID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlLimitRepository.ReadActiveRules() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlLimitRepository.ReadActiveRules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlLimitRepository_ReadUsage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewLimitRepository(db)
+		query = "SELECT COUNT\\(id\\), COALESCE\\(SUM\\(ABS\\(amount\\)\\), 0\\), MIN\\(created_at\\) FROM wallet_transaction WHERE wallet_id=\\? AND type IN \\(\\?,\\?\\) AND created_at>=\\?"
+		now   = time.Now()
+	)
+
+	tests := []struct {
+		name    string
+		repo    LimitRepository
+		rows    *sqlmock.Rows
+		want    model.LimitUsage
+		wantErr bool
+	}{
+		{
+			name:    "with usage",
+			repo:    repo,
+			rows:    sqlmock.NewRows([]string{"count", "sum", "min"}).AddRow(2, 3000, now),
+			want:    model.LimitUsage{Count: 2, Amount: 3000, Oldest: &now},
+			wantErr: false,
+		},
+		{
+			name:    "without usage",
+			repo:    repo,
+			rows:    sqlmock.NewRows([]string{"count", "sum", "min"}).AddRow(0, 0, nil),
+			want:    model.LimitUsage{},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectQuery(query).WithArgs(int64(3), "payment", "escrow_fund", now).WillReturnRows(tt.rows)
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			got, err := tt.repo.ReadUsage(ctx, tx, 3, "payment", now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlLimitRepository.ReadUsage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlLimitRepository.ReadUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
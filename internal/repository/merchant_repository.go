@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type MerchantRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
+	WriteMerchant(ctx context.Context, tx *sql.Tx, merchant model.Merchant) (merchantID int64, err error)
+	ReadMerchantByID(ctx context.Context, merchantID int64) (*model.Merchant, error)
+	ReadMerchants(ctx context.Context, filter model.MerchantFilter) ([]model.Merchant, error)
+	UpdateMerchantStatus(ctx context.Context, merchantID int64, status string) error
+	WriteMerchantPayment(ctx context.Context, tx *sql.Tx, payment model.MerchantPayment) (paymentID int64, err error)
+	ReadMerchantPaymentByTransaction(ctx context.Context, trxID int64) (*model.MerchantPayment, error)
+	ReadMerchantPayments(ctx context.Context, merchantID int64) ([]model.MerchantPayment, error)
+}
+
+type mysqlMerchantRepository struct {
+	db *sql.DB
+}
+
+func NewMerchantRepository(db *sql.DB) MerchantRepository {
+	return &mysqlMerchantRepository{
+		db: db,
+	}
+}
+
+const merchantColumns = `m.id, m.user_id, m.name, m.wallet_id, w.balance, m.status, m.created_at, m.updated_at`
+
+const merchantPaymentColumns = `id, merchant_id, transaction_id, credit_transaction_id, amount, reference, created_at`
+
+func (m *mysqlMerchantRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+func (m *mysqlMerchantRepository) WriteMerchant(ctx context.Context, tx *sql.Tx, merchant model.Merchant) (merchantID int64, err error) {
+	query := `INSERT INTO merchant (user_id, name, wallet_id, status) VALUES (?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, merchant.UserID, merchant.Name, merchant.WalletID, merchant.Status)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlMerchantRepository) ReadMerchantByID(ctx context.Context, merchantID int64) (*model.Merchant, error) {
+	query := `SELECT ` + merchantColumns + ` FROM merchant m JOIN wallet w ON w.id = m.wallet_id WHERE m.id=?`
+
+	merchant, err := scanMerchant(m.db.QueryRowContext(ctx, query, merchantID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return merchant, nil
+}
+
+func (m *mysqlMerchantRepository) ReadMerchants(ctx context.Context, filter model.MerchantFilter) ([]model.Merchant, error) {
+	query := `SELECT ` + merchantColumns + ` FROM merchant m JOIN wallet w ON w.id = m.wallet_id WHERE (?=0 OR m.user_id=?) AND (?='' OR m.status=?) ORDER BY m.id`
+
+	rows, err := m.db.QueryContext(ctx, query, filter.UserID, filter.UserID, filter.Status, filter.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	merchants := []model.Merchant{}
+	for rows.Next() {
+		merchant, err := scanMerchant(rows)
+		if err != nil {
+			return nil, err
+		}
+		merchants = append(merchants, *merchant)
+	}
+
+	return merchants, rows.Err()
+}
+
+func (m *mysqlMerchantRepository) UpdateMerchantStatus(ctx context.Context, merchantID int64, status string) error {
+	query := `UPDATE merchant SET status=?, updated_at=? WHERE id=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, status, time.Now(), merchantID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *mysqlMerchantRepository) WriteMerchantPayment(ctx context.Context, tx *sql.Tx, payment model.MerchantPayment) (paymentID int64, err error) {
+	query := `INSERT INTO merchant_payment (merchant_id, transaction_id, credit_transaction_id, amount, reference) VALUES (?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, payment.MerchantID, payment.TransactionID, payment.CreditTransactionID, payment.Amount, payment.Reference)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlMerchantRepository) ReadMerchantPaymentByTransaction(ctx context.Context, trxID int64) (*model.MerchantPayment, error) {
+	query := `SELECT ` + merchantPaymentColumns + ` FROM merchant_payment WHERE transaction_id=?`
+
+	payment, err := scanMerchantPayment(m.db.QueryRowContext(ctx, query, trxID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return payment, nil
+}
+
+func (m *mysqlMerchantRepository) ReadMerchantPayments(ctx context.Context, merchantID int64) ([]model.MerchantPayment, error) {
+	query := `SELECT ` + merchantPaymentColumns + ` FROM merchant_payment WHERE merchant_id=? ORDER BY id DESC`
+
+	rows, err := m.db.QueryContext(ctx, query, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []model.MerchantPayment{}
+	for rows.Next() {
+		payment, err := scanMerchantPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, *payment)
+	}
+
+	return payments, rows.Err()
+}
+
+func scanMerchant(row rowScanner) (*model.Merchant, error) {
+	var merchant model.Merchant
+
+	err := row.Scan(
+		&merchant.ID,
+		&merchant.UserID,
+		&merchant.Name,
+		&merchant.WalletID,
+		&merchant.Balance,
+		&merchant.Status,
+		&merchant.CreatedAt,
+		&merchant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &merchant, nil
+}
+
+func scanMerchantPayment(row rowScanner) (*model.MerchantPayment, error) {
+	var payment model.MerchantPayment
+
+	err := row.Scan(
+		&payment.ID,
+		&payment.MerchantID,
+		&payment.TransactionID,
+		&payment.CreditTransactionID,
+		&payment.Amount,
+		&payment.Reference,
+		&payment.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlMerchantRepository_ReadMerchantByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewMerchantRepository(db)
+		query   = "SELECT m.id, m.user_id, m.name, m.wallet_id, w.balance, m.status, m.created_at, m.updated_at FROM merchant m JOIN wallet w ON w.id = m.wallet_id WHERE m.id=\\?"
+		columns = []string{"id", "user_id", "name", "wallet_id", "balance", "status", "created_at", "updated_at"}
+		now     = time.Now()
+	)
+
+	merchant := model.Merchant{ID: 8, UserID: 5, Name: "Ojek", WalletID: 20, Balance: 1500, Status: model.MerchantStatusActive, CreatedAt: now, UpdatedAt: now}
+
+	tests := []struct {
+		name    string
+		want    *model.Merchant
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    &merchant,
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(merchant.ID).WillReturnError(fmt.Errorf("some error"))
+			} else if tt.want == nil {
+				mock.ExpectQuery(query).WithArgs(merchant.ID).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows(columns).AddRow(merchant.ID, merchant.UserID, merchant.Name, merchant.WalletID, merchant.Balance, merchant.Status, now, now)
+				mock.ExpectQuery(query).WithArgs(merchant.ID).WillReturnRows(rows)
+			}
+
+			got, err := repo.ReadMerchantByID(ctx, merchant.ID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlMerchantRepository.ReadMerchantByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlMerchantRepository.ReadMerchantByID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlMerchantRepository_UpdateMerchantStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewMerchantRepository(db)
+		query = "UPDATE merchant SET status=\\?, updated_at=\\? WHERE id=\\?"
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "not found",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(model.MerchantStatusActive, sqlmock.AnyArg(), 8).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			if err := repo.UpdateMerchantStatus(ctx, 8, model.MerchantStatusActive); err != tt.wantErr {
+				t.Errorf("mysqlMerchantRepository.UpdateMerchantStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type OutboxRepository interface {
+	WriteEvent(ctx context.Context, tx *sql.Tx, event model.OutboxEvent) (eventID int64, err error)
+	ReadPendingEvents(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error)
+	ClaimEvent(ctx context.Context, event model.OutboxEvent, now, leaseUntil time.Time) error
+	UpdateEvent(ctx context.Context, event model.OutboxEvent) error
+}
+
+type mysqlOutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &mysqlOutboxRepository{
+		db: db,
+	}
+}
+
+const outboxColumns = `id, event_id, event_type, event_key, payload, attempts, next_attempt_at, last_error, published_at, created_at`
+
+// WriteEvent records an event in the transaction of the change it reports.
+func (m *mysqlOutboxRepository) WriteEvent(ctx context.Context, tx *sql.Tx, event model.OutboxEvent) (eventID int64, err error) {
+	query := `INSERT INTO outbox (event_id, event_type, event_key, payload, next_attempt_at) VALUES (?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, event.EventID, event.Type, event.Key, string(event.Payload), event.NextAttemptAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// ReadPendingEvents returns the unpublished events whose next attempt is
+// due, in the order they were recorded.
+func (m *mysqlOutboxRepository) ReadPendingEvents(ctx context.Context, now time.Time, limit int) ([]model.OutboxEvent, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE published_at IS NULL AND next_attempt_at<=? ORDER BY id LIMIT ?`
+
+	rows, err := m.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []model.OutboxEvent{}
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+
+	return events, rows.Err()
+}
+
+// ClaimEvent pushes the next attempt of a due event back to leaseUntil, so
+// no other relay publishes it in the meantime. It returns sql.ErrNoRows when
+// the event was claimed or attempted since it was read.
+func (m *mysqlOutboxRepository) ClaimEvent(ctx context.Context, event model.OutboxEvent, now, leaseUntil time.Time) error {
+	query := `UPDATE outbox SET next_attempt_at=? WHERE id=? AND published_at IS NULL AND attempts=? AND next_attempt_at<=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, leaseUntil, event.ID, event.Attempts, now)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateEvent records the outcome of an attempt to publish an event.
+func (m *mysqlOutboxRepository) UpdateEvent(ctx context.Context, event model.OutboxEvent) error {
+	query := `UPDATE outbox SET attempts=?, next_attempt_at=?, last_error=?, published_at=? WHERE id=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, event.Attempts, event.NextAttemptAt, event.LastError, event.PublishedAt, event.ID)
+
+	return err
+}
+
+func scanOutboxEvent(row rowScanner) (*model.OutboxEvent, error) {
+	var (
+		event       model.OutboxEvent
+		payload     string
+		publishedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&event.ID,
+		&event.EventID,
+		&event.Type,
+		&event.Key,
+		&payload,
+		&event.Attempts,
+		&event.NextAttemptAt,
+		&event.LastError,
+		&publishedAt,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Payload = []byte(payload)
+	if publishedAt.Valid {
+		event.PublishedAt = &publishedAt.Time
+	}
+
+	return &event, nil
+}
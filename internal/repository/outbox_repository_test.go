@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlOutboxRepository_ReadPendingEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewOutboxRepository(db)
+		query   = "SELECT (.+) FROM outbox WHERE published_at IS NULL AND next_attempt_at<=\\? ORDER BY id LIMIT \\?"
+		columns = []string{"id", "event_id", "event_type", "event_key", "payload", "attempts", "next_attempt_at", "last_error", "published_at", "created_at"}
+		now     = time.Now()
+	)
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "E1", model.WebhookEventUserRegistered, "1", `{"id":"E1"}`, 0, now, "", nil, now).
+		AddRow(2, "E2", model.WebhookEventTopUpCompleted, "1", `{"id":"E2"}`, 2, now, "broker unavailable", nil, now)
+	mock.ExpectQuery(query).WithArgs(now, 100).WillReturnRows(rows)
+
+	got, err := repo.ReadPendingEvents(ctx, now, 100)
+	if err != nil {
+		t.Fatalf("mysqlOutboxRepository.ReadPendingEvents() error = %v", err)
+	}
+
+	want := []model.OutboxEvent{
+		{ID: 1, EventID: "E1", Type: model.WebhookEventUserRegistered, Key: "1", Payload: []byte(`{"id":"E1"}`), NextAttemptAt: now, CreatedAt: now},
+		{ID: 2, EventID: "E2", Type: model.WebhookEventTopUpCompleted, Key: "1", Payload: []byte(`{"id":"E2"}`), Attempts: 2, NextAttemptAt: now, LastError: "broker unavailable", CreatedAt: now},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mysqlOutboxRepository.ReadPendingEvents() = %v, want %v", got, want)
+	}
+}
+
+func Test_mysqlOutboxRepository_ClaimEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewOutboxRepository(db)
+		query = "UPDATE outbox SET next_attempt_at=\\? WHERE id=\\? AND published_at IS NULL AND attempts=\\? AND next_attempt_at<=\\?"
+		now   = time.Now()
+		lease = now.Add(time.Minute)
+		event = model.OutboxEvent{ID: 5, Attempts: 1}
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "claimed elsewhere",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(lease, event.ID, event.Attempts, now).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			if err := repo.ClaimEvent(ctx, event, now, lease); err != tt.wantErr {
+				t.Errorf("mysqlOutboxRepository.ClaimEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"strings"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+// orderBy builds the ORDER BY and LIMIT clauses of a page of a list. sorts
+// maps the fields the list can be sorted by to their columns, and sorting by
+// an empty field sorts by fallback. Ties are broken by id, so pages do not
+// overlap.
+func orderBy(page model.PageRequest, sorts map[string]string, fallback string) (string, []interface{}, error) {
+	column := fallback
+	if page.Sort != "" {
+		var ok bool
+		if column, ok = sorts[page.Sort]; !ok {
+			return "", nil, cs.ErrBadParamInput
+		}
+	}
+
+	order := "DESC"
+	if page.Order == model.SortAsc {
+		order = "ASC"
+	}
+
+	clause := ` ORDER BY ` + column + ` ` + order + `, id ` + order + ` LIMIT ? OFFSET ?`
+
+	return clause, []interface{}{page.PerPage, page.Offset()}, nil
+}
+
+// contains is the LIKE pattern matching the values that contain s.
+func contains(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+
+	return "%" + s + "%"
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type PaymentRequestRepository interface {
+	WritePaymentRequest(ctx context.Context, tx *sql.Tx, request model.PaymentRequest) (requestID int64, err error)
+	ReadPaymentRequestByCode(ctx context.Context, code string) (*model.PaymentRequest, error)
+	ReadPaymentRequestForUpdate(ctx context.Context, tx *sql.Tx, code string) (*model.PaymentRequest, error)
+	ReadPaymentRequestByTransaction(ctx context.Context, trxID int64) (*model.PaymentRequest, error)
+	ReadPaymentRequestsByUser(ctx context.Context, userID int64) ([]model.PaymentRequest, error)
+	MarkPaymentRequestPaid(ctx context.Context, tx *sql.Tx, request model.PaymentRequest) error
+	CancelPaymentRequest(ctx context.Context, requestID int64) error
+	ReadPaymentRequestsByBill(ctx context.Context, billID int64) ([]model.PaymentRequest, error)
+	CancelBillPaymentRequests(ctx context.Context, tx *sql.Tx, billID int64) error
+	RemindPaymentRequest(ctx context.Context, tx *sql.Tx, requestID int64, at time.Time) error
+}
+
+type mysqlPaymentRequestRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentRequestRepository(db *sql.DB) PaymentRequestRepository {
+	return &mysqlPaymentRequestRepository{
+		db: db,
+	}
+}
+
+const paymentRequestColumns = `id, code, requester_id, wallet_id, payer_id, bill_id, amount, currency, description, status, transaction_id, paid_at, reminders, reminded_at, expires_at, created_at, updated_at`
+
+func (m *mysqlPaymentRequestRepository) WritePaymentRequest(ctx context.Context, tx *sql.Tx, request model.PaymentRequest) (requestID int64, err error) {
+	query := `INSERT INTO payment_request (code, requester_id, wallet_id, payer_id, bill_id, amount, currency, description, status, expires_at) VALUES (?,?,?,?,?,?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, request.Code, request.RequesterID, request.WalletID, request.PayerID, request.BillID, request.Amount, request.Currency, request.Description, request.Status, request.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlPaymentRequestRepository) ReadPaymentRequestByCode(ctx context.Context, code string) (*model.PaymentRequest, error) {
+	query := `SELECT ` + paymentRequestColumns + ` FROM payment_request WHERE code=?`
+
+	request, err := scanPaymentRequest(m.db.QueryRowContext(ctx, query, code))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return request, nil
+}
+
+func (m *mysqlPaymentRequestRepository) ReadPaymentRequestForUpdate(ctx context.Context, tx *sql.Tx, code string) (*model.PaymentRequest, error) {
+	query := `SELECT ` + paymentRequestColumns + ` FROM payment_request WHERE code=? FOR UPDATE`
+
+	request, err := scanPaymentRequest(tx.QueryRowContext(ctx, query, code))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return request, nil
+}
+
+// ReadPaymentRequestByTransaction returns the request paid by the payer's
+// debit trxID, if any.
+func (m *mysqlPaymentRequestRepository) ReadPaymentRequestByTransaction(ctx context.Context, trxID int64) (*model.PaymentRequest, error) {
+	query := `SELECT ` + paymentRequestColumns + ` FROM payment_request WHERE transaction_id=?`
+
+	request, err := scanPaymentRequest(m.db.QueryRowContext(ctx, query, trxID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return request, nil
+}
+
+// ReadPaymentRequestsByUser returns the requests a user made or was asked to
+// pay, newest first.
+func (m *mysqlPaymentRequestRepository) ReadPaymentRequestsByUser(ctx context.Context, userID int64) ([]model.PaymentRequest, error) {
+	query := `SELECT ` + paymentRequestColumns + ` FROM payment_request WHERE requester_id=? OR payer_id=? ORDER BY id DESC`
+
+	rows, err := m.db.QueryContext(ctx, query, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []model.PaymentRequest{}
+	for rows.Next() {
+		request, err := scanPaymentRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	return requests, rows.Err()
+}
+
+// MarkPaymentRequestPaid records who paid a pending request and with which
+// ledger entry. It returns sql.ErrNoRows when the request is not pending.
+func (m *mysqlPaymentRequestRepository) MarkPaymentRequestPaid(ctx context.Context, tx *sql.Tx, request model.PaymentRequest) error {
+	query := `UPDATE payment_request SET status=?, payer_id=?, transaction_id=?, paid_at=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, model.PaymentRequestPaid, request.PayerID, request.TransactionID, request.PaidAt, time.Now(), request.ID, model.PaymentRequestPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CancelPaymentRequest cancels a pending request. It returns sql.ErrNoRows
+// when the request is not pending.
+func (m *mysqlPaymentRequestRepository) CancelPaymentRequest(ctx context.Context, requestID int64) error {
+	query := `UPDATE payment_request SET status=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, model.PaymentRequestCancelled, time.Now(), requestID, model.PaymentRequestPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *mysqlPaymentRequestRepository) ReadPaymentRequestsByBill(ctx context.Context, billID int64) ([]model.PaymentRequest, error) {
+	query := `SELECT ` + paymentRequestColumns + ` FROM payment_request WHERE bill_id=? ORDER BY id`
+
+	rows, err := m.db.QueryContext(ctx, query, billID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []model.PaymentRequest{}
+	for rows.Next() {
+		request, err := scanPaymentRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	return requests, rows.Err()
+}
+
+// CancelBillPaymentRequests cancels the requests of a bill that are still
+// pending. Paid requests are left as they are.
+func (m *mysqlPaymentRequestRepository) CancelBillPaymentRequests(ctx context.Context, tx *sql.Tx, billID int64) error {
+	query := `UPDATE payment_request SET status=?, updated_at=? WHERE bill_id=? AND status=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, model.PaymentRequestCancelled, time.Now(), billID, model.PaymentRequestPending)
+
+	return err
+}
+
+// RemindPaymentRequest records that the payer of a pending request was
+// reminded of it. It returns sql.ErrNoRows when the request is not pending.
+func (m *mysqlPaymentRequestRepository) RemindPaymentRequest(ctx context.Context, tx *sql.Tx, requestID int64, at time.Time) error {
+	query := `UPDATE payment_request SET reminders=reminders+1, reminded_at=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, at, time.Now(), requestID, model.PaymentRequestPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanPaymentRequest(row rowScanner) (*model.PaymentRequest, error) {
+	var (
+		request       model.PaymentRequest
+		payerID       sql.NullInt64
+		billID        sql.NullInt64
+		transactionID sql.NullInt64
+		paidAt        sql.NullTime
+		remindedAt    sql.NullTime
+	)
+
+	err := row.Scan(
+		&request.ID,
+		&request.Code,
+		&request.RequesterID,
+		&request.WalletID,
+		&payerID,
+		&billID,
+		&request.Amount,
+		&request.Currency,
+		&request.Description,
+		&request.Status,
+		&transactionID,
+		&paidAt,
+		&request.Reminders,
+		&remindedAt,
+		&request.ExpiresAt,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if payerID.Valid {
+		request.PayerID = &payerID.Int64
+	}
+
+	if billID.Valid {
+		request.BillID = &billID.Int64
+	}
+
+	if transactionID.Valid {
+		request.TransactionID = &transactionID.Int64
+	}
+
+	if paidAt.Valid {
+		request.PaidAt = &paidAt.Time
+	}
+
+	if remindedAt.Valid {
+		request.RemindedAt = &remindedAt.Time
+	}
+
+	return &request, nil
+}
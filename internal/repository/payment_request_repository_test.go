@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlPaymentRequestRepository_ReadPaymentRequestByCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewPaymentRequestRepository(db)
+		query   = "SELECT (.+) FROM payment_request WHERE code=\\?"
+		columns = []string{"id", "code", "requester_id", "wallet_id", "payer_id", "bill_id", "amount", "currency", "description", "status", "transaction_id", "paid_at", "reminders", "reminded_at", "expires_at", "created_at", "updated_at"}
+		now     = time.Now()
+		payerID = int64(2)
+		trxID   = int64(40)
+	)
+
+	paid := model.PaymentRequest{
+		ID: 1, Code: "K7PQ2MZX", RequesterID: 1, WalletID: 3, PayerID: &payerID, Amount: 25000, Currency: model.CurrencyIDR,
+		Description: "dinner", Status: model.PaymentRequestPaid, TransactionID: &trxID, PaidAt: &now, ExpiresAt: now, CreatedAt: now, UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name    string
+		want    *model.PaymentRequest
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    &paid,
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(paid.Code).WillReturnError(fmt.Errorf("some error"))
+			} else if tt.want == nil {
+				mock.ExpectQuery(query).WithArgs(paid.Code).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows(columns).AddRow(paid.ID, paid.Code, paid.RequesterID, paid.WalletID, payerID, nil, paid.Amount, paid.Currency, paid.Description, paid.Status, trxID, now, 0, nil, now, now, now)
+				mock.ExpectQuery(query).WithArgs(paid.Code).WillReturnRows(rows)
+			}
+
+			got, err := repo.ReadPaymentRequestByCode(ctx, paid.Code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlPaymentRequestRepository.ReadPaymentRequestByCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlPaymentRequestRepository.ReadPaymentRequestByCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlPaymentRequestRepository_MarkPaymentRequestPaid(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewPaymentRequestRepository(db)
+		query   = "UPDATE payment_request SET status=\\?, payer_id=\\?, transaction_id=\\?, paid_at=\\?, updated_at=\\? WHERE id=\\? AND status=\\?"
+		now     = time.Now()
+		payerID = int64(2)
+		trxID   = int64(40)
+	)
+
+	request := model.PaymentRequest{ID: 1, PayerID: &payerID, TransactionID: &trxID, PaidAt: &now}
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "not pending",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(model.PaymentRequestPaid, &payerID, &trxID, &now, sqlmock.AnyArg(), request.ID, model.PaymentRequestPending).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			if err := repo.MarkPaymentRequestPaid(ctx, tx, request); err != tt.wantErr {
+				t.Errorf("mysqlPaymentRequestRepository.MarkPaymentRequestPaid() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
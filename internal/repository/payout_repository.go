@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type PayoutRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
+	WriteBankAccount(ctx context.Context, account model.BankAccount) (accountID int64, err error)
+	ReadBankAccountByID(ctx context.Context, accountID int64) (*model.BankAccount, error)
+	ReadBankAccountsByUser(ctx context.Context, userID int64) ([]model.BankAccount, error)
+	DeleteBankAccount(ctx context.Context, accountID, userID int64) error
+	WritePayout(ctx context.Context, tx *sql.Tx, payout model.Payout) (payoutID int64, err error)
+	ReadPayoutByID(ctx context.Context, payoutID int64) (*model.Payout, error)
+	ReadPayoutForUpdate(ctx context.Context, tx *sql.Tx, reference string) (*model.Payout, error)
+	ReadPayoutsByUser(ctx context.Context, userID int64) ([]model.Payout, error)
+	ReadPayoutsByStatus(ctx context.Context, status string, before time.Time, limit int) ([]model.Payout, error)
+	MarkPayoutProcessing(ctx context.Context, payoutID int64, providerReference string) error
+	UpdatePayout(ctx context.Context, tx *sql.Tx, payout model.Payout) error
+}
+
+type mysqlPayoutRepository struct {
+	db *sql.DB
+}
+
+func NewPayoutRepository(db *sql.DB) PayoutRepository {
+	return &mysqlPayoutRepository{
+		db: db,
+	}
+}
+
+const (
+	bankAccountColumns = `id, user_id, bank_code, account_number, account_name, created_at, deleted_at`
+	payoutColumns      = `id, user_id, wallet_id, bank_account_id, amount, currency, status, reference, provider_reference, failure_reason, transaction_id, reversal_transaction_id, created_at, updated_at`
+)
+
+func (m *mysqlPayoutRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+func (m *mysqlPayoutRepository) WriteBankAccount(ctx context.Context, account model.BankAccount) (accountID int64, err error) {
+	query := `INSERT INTO bank_account (user_id, bank_code, account_number, account_name) VALUES (?,?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, account.UserID, account.BankCode, account.AccountNumber, account.AccountName)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// ReadBankAccountByID returns a bank account, including one its owner has
+// since removed, so the payouts made to it can still be settled.
+func (m *mysqlPayoutRepository) ReadBankAccountByID(ctx context.Context, accountID int64) (*model.BankAccount, error) {
+	query := `SELECT ` + bankAccountColumns + ` FROM bank_account WHERE id=?`
+
+	account, err := scanBankAccount(m.db.QueryRowContext(ctx, query, accountID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return account, nil
+}
+
+func (m *mysqlPayoutRepository) ReadBankAccountsByUser(ctx context.Context, userID int64) ([]model.BankAccount, error) {
+	query := `SELECT ` + bankAccountColumns + ` FROM bank_account WHERE user_id=? AND deleted_at IS NULL ORDER BY id`
+
+	rows, err := m.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []model.BankAccount{}
+	for rows.Next() {
+		account, err := scanBankAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// DeleteBankAccount removes a user's bank account from their saved
+// accounts. It returns sql.ErrNoRows when the user has no such account.
+func (m *mysqlPayoutRepository) DeleteBankAccount(ctx context.Context, accountID, userID int64) error {
+	query := `UPDATE bank_account SET deleted_at=? WHERE id=? AND user_id=? AND deleted_at IS NULL`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, time.Now(), accountID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *mysqlPayoutRepository) WritePayout(ctx context.Context, tx *sql.Tx, payout model.Payout) (payoutID int64, err error) {
+	query := `INSERT INTO payout (user_id, wallet_id, bank_account_id, amount, currency, status, reference, transaction_id) VALUES (?,?,?,?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, payout.UserID, payout.WalletID, payout.BankAccountID, payout.Amount, payout.Currency, payout.Status, payout.Reference, payout.TransactionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlPayoutRepository) ReadPayoutByID(ctx context.Context, payoutID int64) (*model.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payout WHERE id=?`
+
+	payout, err := scanPayout(m.db.QueryRowContext(ctx, query, payoutID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return payout, nil
+}
+
+func (m *mysqlPayoutRepository) ReadPayoutForUpdate(ctx context.Context, tx *sql.Tx, reference string) (*model.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payout WHERE reference=? FOR UPDATE`
+
+	payout, err := scanPayout(tx.QueryRowContext(ctx, query, reference))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return payout, nil
+}
+
+func (m *mysqlPayoutRepository) ReadPayoutsByUser(ctx context.Context, userID int64) ([]model.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payout WHERE user_id=? ORDER BY id DESC`
+
+	return m.readPayouts(ctx, query, userID)
+}
+
+// ReadPayoutsByStatus returns up to limit payouts in a status that were
+// last updated before the given time, oldest first.
+func (m *mysqlPayoutRepository) ReadPayoutsByStatus(ctx context.Context, status string, before time.Time, limit int) ([]model.Payout, error) {
+	query := `SELECT ` + payoutColumns + ` FROM payout WHERE status=? AND updated_at<? ORDER BY updated_at LIMIT ?`
+
+	return m.readPayouts(ctx, query, status, before, limit)
+}
+
+// MarkPayoutProcessing records that the bank accepted a pending payout. It
+// returns sql.ErrNoRows when the payout is no longer pending.
+func (m *mysqlPayoutRepository) MarkPayoutProcessing(ctx context.Context, payoutID int64, providerReference string) error {
+	query := `UPDATE payout SET status=?, provider_reference=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, model.PayoutStatusProcessing, providerReference, time.Now(), payoutID, model.PayoutStatusPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdatePayout records the outcome of a payout locked in tx.
+func (m *mysqlPayoutRepository) UpdatePayout(ctx context.Context, tx *sql.Tx, payout model.Payout) error {
+	query := `UPDATE payout SET status=?, provider_reference=?, failure_reason=?, reversal_transaction_id=?, updated_at=? WHERE id=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, payout.Status, payout.ProviderReference, payout.FailureReason, payout.ReversalTransactionID, time.Now(), payout.ID)
+
+	return err
+}
+
+func (m *mysqlPayoutRepository) readPayouts(ctx context.Context, query string, args ...interface{}) ([]model.Payout, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payouts := []model.Payout{}
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, *payout)
+	}
+
+	return payouts, rows.Err()
+}
+
+func scanBankAccount(row rowScanner) (*model.BankAccount, error) {
+	var (
+		account   model.BankAccount
+		deletedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&account.ID,
+		&account.UserID,
+		&account.BankCode,
+		&account.AccountNumber,
+		&account.AccountName,
+		&account.CreatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if deletedAt.Valid {
+		account.DeletedAt = &deletedAt.Time
+	}
+
+	return &account, nil
+}
+
+func scanPayout(row rowScanner) (*model.Payout, error) {
+	var (
+		payout                model.Payout
+		reversalTransactionID sql.NullInt64
+	)
+
+	err := row.Scan(
+		&payout.ID,
+		&payout.UserID,
+		&payout.WalletID,
+		&payout.BankAccountID,
+		&payout.Amount,
+		&payout.Currency,
+		&payout.Status,
+		&payout.Reference,
+		&payout.ProviderReference,
+		&payout.FailureReason,
+		&payout.TransactionID,
+		&reversalTransactionID,
+		&payout.CreatedAt,
+		&payout.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if reversalTransactionID.Valid {
+		payout.ReversalTransactionID = &reversalTransactionID.Int64
+	}
+
+	return &payout, nil
+}
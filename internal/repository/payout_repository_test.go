@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlPayoutRepository_ReadPayoutForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewPayoutRepository(db)
+		query      = "SELECT (.+) FROM payout WHERE reference=\\? FOR UPDATE"
+		columns    = []string{"id", "user_id", "wallet_id", "bank_account_id", "amount", "currency", "status", "reference", "provider_reference", "failure_reason", "transaction_id", "reversal_transaction_id", "created_at", "updated_at"}
+		now        = time.Now()
+		reversalID = int64(51)
+	)
+
+	payout := model.Payout{
+		ID: 9, UserID: 1, WalletID: 3, BankAccountID: 7, Amount: 50000, Currency: model.CurrencyIDR, Status: model.PayoutStatusFailed,
+		Reference: "WD-1", ProviderReference: "MB-1", FailureReason: "account closed", TransactionID: 50, ReversalTransactionID: &reversalID,
+		CreatedAt: now, UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name    string
+		want    *model.Payout
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    &payout,
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(payout.Reference).WillReturnError(fmt.Errorf("some error"))
+			} else if tt.want == nil {
+				mock.ExpectQuery(query).WithArgs(payout.Reference).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows(columns).AddRow(payout.ID, payout.UserID, payout.WalletID, payout.BankAccountID, payout.Amount, payout.Currency, payout.Status,
+					payout.Reference, payout.ProviderReference, payout.FailureReason, payout.TransactionID, reversalID, now, now)
+				mock.ExpectQuery(query).WithArgs(payout.Reference).WillReturnRows(rows)
+			}
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			got, err := repo.ReadPayoutForUpdate(ctx, tx, payout.Reference)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlPayoutRepository.ReadPayoutForUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlPayoutRepository.ReadPayoutForUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlPayoutRepository_MarkPayoutProcessing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewPayoutRepository(db)
+		query = "UPDATE payout SET status=\\?, provider_reference=\\?, updated_at=\\? WHERE id=\\? AND status=\\?"
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "not pending",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(model.PayoutStatusProcessing, "MB-1", sqlmock.AnyArg(), int64(9), model.PayoutStatusPending).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			if err := repo.MarkPayoutProcessing(ctx, 9, "MB-1"); err != tt.wantErr {
+				t.Errorf("mysqlPayoutRepository.MarkPayoutProcessing() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_mysqlPayoutRepository_DeleteBankAccount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewPayoutRepository(db)
+		query = "UPDATE bank_account SET deleted_at=\\? WHERE id=\\? AND user_id=\\? AND deleted_at IS NULL"
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "not the user's account",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(sqlmock.AnyArg(), int64(7), int64(1)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			if err := repo.DeleteBankAccount(ctx, 7, 1); err != tt.wantErr {
+				t.Errorf("mysqlPayoutRepository.DeleteBankAccount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type RefundRepository interface {
+	WriteRefund(ctx context.Context, tx *sql.Tx, refund model.Refund) (refundID int64, err error)
+	SumRefunds(ctx context.Context, tx *sql.Tx, trxID int64) (float64, error)
+	ReadRefundsByTransaction(ctx context.Context, trxID int64) ([]model.Refund, error)
+}
+
+type mysqlRefundRepository struct {
+	db *sql.DB
+}
+
+func NewRefundRepository(db *sql.DB) RefundRepository {
+	return &mysqlRefundRepository{
+		db: db,
+	}
+}
+
+func (m *mysqlRefundRepository) WriteRefund(ctx context.Context, tx *sql.Tx, refund model.Refund) (refundID int64, err error) {
+	query := `INSERT INTO refund (transaction_id, refund_transaction_id, amount, reason, created_by) VALUES (?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, refund.TransactionID, refund.RefundTransactionID, refund.Amount, refund.Reason, refund.CreatedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// SumRefunds returns the total already refunded on a payment. It reads within
+// tx so that refunds serialized by the payer's wallet lock see each other.
+func (m *mysqlRefundRepository) SumRefunds(ctx context.Context, tx *sql.Tx, trxID int64) (float64, error) {
+	query := `SELECT COALESCE(SUM(amount), 0) FROM refund WHERE transaction_id=?`
+
+	var total float64
+	if err := tx.QueryRowContext(ctx, query, trxID).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (m *mysqlRefundRepository) ReadRefundsByTransaction(ctx context.Context, trxID int64) ([]model.Refund, error) {
+	query := `SELECT id, transaction_id, refund_transaction_id, amount, reason, created_by, created_at FROM refund WHERE transaction_id=? ORDER BY id`
+
+	rows, err := m.db.QueryContext(ctx, query, trxID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := []model.Refund{}
+	for rows.Next() {
+		var refund model.Refund
+		err := rows.Scan(
+			&refund.ID,
+			&refund.TransactionID,
+			&refund.RefundTransactionID,
+			&refund.Amount,
+			&refund.Reason,
+			&refund.CreatedBy,
+			&refund.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlRefundRepository_WriteRefund(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewRefundRepository(db)
+		query = "INSERT INTO refund (transaction_id, refund_transaction_id, amount, reason, created_by) VALUES (?,?,?,?,?)"
+	)
+
+	refund := model.Refund{
+		TransactionID:       11,
+		RefundTransactionID: 12,
+		Amount:              300,
+		Reason:              "duplicate",
+		CreatedBy:           2,
+	}
+
+	tests := []struct {
+		name    string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    4,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			if tt.wantErr {
+				mock.ExpectPrepare(query).ExpectExec().WillReturnError(fmt.Errorf("some error"))
+			} else {
+				mock.ExpectPrepare(query).ExpectExec().
+					WithArgs(refund.TransactionID, refund.RefundTransactionID, refund.Amount, refund.Reason, refund.CreatedBy).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+			}
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			got, err := repo.WriteRefund(ctx, tx, refund)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlRefundRepository.WriteRefund() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("mysqlRefundRepository.WriteRefund() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
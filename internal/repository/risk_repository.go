@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type RiskRepository interface {
+	ReadTransactionStats(ctx context.Context, walletID int64, trxType string, since time.Time) (model.TransactionStats, error)
+	WriteHeldTransaction(ctx context.Context, held model.HeldTransaction) (heldID int64, err error)
+	ReadHeldTransactionByID(ctx context.Context, heldID int64) (*model.HeldTransaction, error)
+	ReadHeldTransactionPage(ctx context.Context, status string, page model.PageRequest) (helds []model.HeldTransaction, total int64, err error)
+	UpdateHeldTransaction(ctx context.Context, tx *sql.Tx, held model.HeldTransaction) error
+}
+
+type mysqlRiskRepository struct {
+	db *sql.DB
+}
+
+func NewRiskRepository(db *sql.DB) RiskRepository {
+	return &mysqlRiskRepository{
+		db: db,
+	}
+}
+
+// heldTransactionSorts are the fields held transactions can be sorted by.
+var heldTransactionSorts = map[string]string{
+	"id":         "id",
+	"operation":  "operation",
+	"amount":     "amount",
+	"score":      "score",
+	"created_at": "created_at",
+}
+
+const heldTransactionColumns = `id, user_id, wallet_id, operation, amount, payload, score, reasons, status, reviewer_id, reviewed_at, created_at, updated_at`
+
+func (m *mysqlRiskRepository) ReadTransactionStats(ctx context.Context, walletID int64, trxType string, since time.Time) (model.TransactionStats, error) {
+	query := `SELECT COUNT(id), COALESCE(SUM(ABS(amount)), 0) FROM wallet_transaction WHERE wallet_id=? AND type=? AND created_at>=?`
+
+	var stats model.TransactionStats
+	err := m.db.QueryRowContext(ctx, query, walletID, trxType, since).Scan(&stats.Count, &stats.Total)
+
+	return stats, err
+}
+
+func (m *mysqlRiskRepository) WriteHeldTransaction(ctx context.Context, held model.HeldTransaction) (heldID int64, err error) {
+	query := `INSERT INTO held_transaction (user_id, wallet_id, operation, amount, payload, score, reasons, status) VALUES (?,?,?,?,?,?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, held.UserID, held.WalletID, held.Operation, held.Amount, held.Payload, held.Score, strings.Join(held.Reasons, ","), held.Status)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlRiskRepository) ReadHeldTransactionByID(ctx context.Context, heldID int64) (*model.HeldTransaction, error) {
+	query := `SELECT ` + heldTransactionColumns + ` FROM held_transaction WHERE id=?`
+
+	held, err := scanHeldTransaction(m.db.QueryRowContext(ctx, query, heldID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return held, nil
+}
+
+// ReadHeldTransactionPage returns a page of the held transactions with the
+// given status, and how many there are in all.
+func (m *mysqlRiskRepository) ReadHeldTransactionPage(ctx context.Context, status string, page model.PageRequest) ([]model.HeldTransaction, int64, error) {
+	order, limit, err := orderBy(page, heldTransactionSorts, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err = m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM held_transaction WHERE status=?`, status).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + heldTransactionColumns + ` FROM held_transaction WHERE status=?` + order
+
+	rows, err := m.db.QueryContext(ctx, query, append([]interface{}{status}, limit...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	helds := []model.HeldTransaction{}
+	for rows.Next() {
+		held, err := scanHeldTransaction(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		helds = append(helds, *held)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return helds, total, nil
+}
+
+// UpdateHeldTransaction records the review outcome. It only updates a
+// pending transaction and returns sql.ErrNoRows otherwise, so a held
+// transaction can never be executed twice.
+func (m *mysqlRiskRepository) UpdateHeldTransaction(ctx context.Context, tx *sql.Tx, held model.HeldTransaction) error {
+	query := `UPDATE held_transaction SET status=?, reviewer_id=?, reviewed_at=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, held.Status, held.ReviewerID, held.ReviewedAt, time.Now(), held.ID, model.HeldTransactionPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanHeldTransaction(row rowScanner) (*model.HeldTransaction, error) {
+	var (
+		held       model.HeldTransaction
+		reasons    string
+		reviewerID sql.NullInt64
+		reviewedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&held.ID,
+		&held.UserID,
+		&held.WalletID,
+		&held.Operation,
+		&held.Amount,
+		&held.Payload,
+		&held.Score,
+		&reasons,
+		&held.Status,
+		&reviewerID,
+		&reviewedAt,
+		&held.CreatedAt,
+		&held.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	held.Reasons = []string{}
+	if reasons != "" {
+		held.Reasons = strings.Split(reasons, ",")
+	}
+
+	if reviewerID.Valid {
+		held.ReviewerID = &reviewerID.Int64
+	}
+
+	if reviewedAt.Valid {
+		held.ReviewedAt = &reviewedAt.Time
+	}
+
+	return &held, nil
+}
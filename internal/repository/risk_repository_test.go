@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlRiskRepository_ReadHeldTransactionByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewRiskRepository(db)
+		query   = "SELECT id, user_id, wallet_id, operation, amount, payload, score, reasons, status, reviewer_id, reviewed_at, created_at, updated_at FROM held_transaction WHERE id=\\?"
+		columns = []string{"id", "user_id", "wallet_id", "operation", "amount", "payload", "score", "reasons", "status", "reviewer_id", "reviewed_at", "created_at", "updated_at"}
+		now     = time.Now()
+	)
+
+	held := model.HeldTransaction{
+		ID:        9,
+		UserID:    1,
+		WalletID:  3,
+		Operation: model.TransactionTypePayment,
+		Amount:    1000,
+		Payload:   "{}",
+		Score:     50,
+		Reasons:   []string{"new_browser", "new_account"},
+		Status:    model.HeldTransactionPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name    string
+		want    *model.HeldTransaction
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    &held,
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(held.ID).WillReturnError(fmt.Errorf("some error"))
+			} else if tt.want == nil {
+				mock.ExpectQuery(query).WithArgs(held.ID).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows(columns).AddRow(held.ID, held.UserID, held.WalletID, held.Operation, held.Amount, held.Payload, held.Score, "new_browser,new_account", held.Status, nil, nil, now, now)
+				mock.ExpectQuery(query).WithArgs(held.ID).WillReturnRows(rows)
+			}
+
+			got, err := repo.ReadHeldTransactionByID(ctx, held.ID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlRiskRepository.ReadHeldTransactionByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlRiskRepository.ReadHeldTransactionByID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlRiskRepository_ReadHeldTransactionPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewRiskRepository(db)
+		countQuery = "SELECT COUNT\\(\\*\\) FROM held_transaction WHERE status=\\?"
+		query      = "SELECT (.+) FROM held_transaction WHERE status=\\? ORDER BY score DESC, id DESC LIMIT \\? OFFSET \\?"
+		columns    = []string{"id", "user_id", "wallet_id", "operation", "amount", "payload", "score", "reasons", "status", "reviewer_id", "reviewed_at", "created_at", "updated_at"}
+		now        = time.Now()
+		page       = model.PageRequest{Page: 1, PerPage: 20, Sort: "score", Order: model.SortDesc}
+	)
+
+	tests := []struct {
+		name      string
+		want      []model.HeldTransaction
+		wantTotal int64
+		wantErr   bool
+	}{
+		{
+			name: "success",
+			want: []model.HeldTransaction{
+				{ID: 4, UserID: 1, WalletID: 3, Operation: model.TransactionTypeTransfer, Amount: 5000, Payload: "{}", Score: 70, Reasons: []string{"large_amount"}, Status: model.HeldTransactionPending, CreatedAt: now, UpdatedAt: now},
+				{ID: 9, UserID: 2, WalletID: 5, Operation: model.TransactionTypePayment, Amount: 1000, Payload: "{}", Score: 50, Reasons: []string{"new_account"}, Status: model.HeldTransactionPending, CreatedAt: now, UpdatedAt: now},
+			},
+			wantTotal: 2,
+		},
+		{
+			name:    "failed",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(countQuery).WithArgs(model.HeldTransactionPending).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				rows := sqlmock.NewRows(columns)
+				for _, held := range tt.want {
+					rows.AddRow(held.ID, held.UserID, held.WalletID, held.Operation, held.Amount, held.Payload, held.Score, held.Reasons[0], held.Status, nil, nil, now, now)
+				}
+				mock.ExpectQuery(countQuery).WithArgs(model.HeldTransactionPending).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.wantTotal))
+				mock.ExpectQuery(query).WithArgs(model.HeldTransactionPending, 20, 0).WillReturnRows(rows)
+			}
+
+			got, total, err := repo.ReadHeldTransactionPage(ctx, model.HeldTransactionPending, page)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mysqlRiskRepository.ReadHeldTransactionPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("mysqlRiskRepository.ReadHeldTransactionPage() = %v, %d, want %v, %d", got, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func Test_mysqlRiskRepository_UpdateHeldTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewRiskRepository(db)
+		query      = "UPDATE held_transaction SET status=\\?, reviewer_id=\\?, reviewed_at=\\?, updated_at=\\? WHERE id=\\? AND status=\\?"
+		reviewerID = int64(2)
+		now        = time.Now()
+	)
+
+	held := model.HeldTransaction{
+		ID:         9,
+		Status:     model.HeldTransactionApproved,
+		ReviewerID: &reviewerID,
+		ReviewedAt: &now,
+	}
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "already reviewed",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(held.Status, held.ReviewerID, held.ReviewedAt, sqlmock.AnyArg(), held.ID, model.HeldTransactionPending).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			if err := repo.UpdateHeldTransaction(ctx, tx, held); err != tt.wantErr {
+				t.Errorf("mysqlRiskRepository.UpdateHeldTransaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package repository
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows, so a single scan
+// function can serve single-row and multi-row reads.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
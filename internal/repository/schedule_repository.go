@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type ScheduleRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
+	WriteSchedule(ctx context.Context, schedule model.Schedule) (scheduleID int64, err error)
+	ReadScheduleByID(ctx context.Context, scheduleID int64) (*model.Schedule, error)
+	ReadSchedulesByUser(ctx context.Context, userID int64) ([]model.Schedule, error)
+	UpdateSchedule(ctx context.Context, schedule model.Schedule) error
+	CancelSchedule(ctx context.Context, scheduleID int64) error
+	ReadDueScheduleIDs(ctx context.Context, at time.Time, limit int) ([]int64, error)
+	ReadScheduleForUpdate(ctx context.Context, tx *sql.Tx, scheduleID int64) (*model.Schedule, error)
+	UpdateScheduleProgress(ctx context.Context, tx *sql.Tx, schedule model.Schedule) error
+	WriteScheduleRun(ctx context.Context, tx *sql.Tx, run model.ScheduleRun) (runID int64, err error)
+	ReadScheduleRuns(ctx context.Context, scheduleID int64) ([]model.ScheduleRun, error)
+}
+
+type mysqlScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewScheduleRepository(db *sql.DB) ScheduleRepository {
+	return &mysqlScheduleRepository{
+		db: db,
+	}
+}
+
+const scheduleColumns = `id, user_id, merchant_id, recipient_id, amount, description, frequency, start_at, end_at, next_run_at, due_at, occurrences, attempts, last_error, status, created_at, updated_at`
+
+func (m *mysqlScheduleRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+func (m *mysqlScheduleRepository) WriteSchedule(ctx context.Context, schedule model.Schedule) (scheduleID int64, err error) {
+	query := `INSERT INTO payment_schedule (user_id, merchant_id, recipient_id, amount, description, frequency, start_at, end_at, next_run_at, due_at, status) VALUES (?,?,?,?,?,?,?,?,?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, schedule.UserID, schedule.MerchantID, schedule.RecipientID, schedule.Amount, schedule.Description, schedule.Frequency,
+		schedule.StartAt, schedule.EndAt, schedule.NextRunAt, schedule.DueAt, schedule.Status)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlScheduleRepository) ReadScheduleByID(ctx context.Context, scheduleID int64) (*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM payment_schedule WHERE id=?`
+
+	schedule, err := scanSchedule(m.db.QueryRowContext(ctx, query, scheduleID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return schedule, nil
+}
+
+func (m *mysqlScheduleRepository) ReadSchedulesByUser(ctx context.Context, userID int64) ([]model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM payment_schedule WHERE user_id=? ORDER BY id DESC`
+
+	rows, err := m.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []model.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+// UpdateSchedule changes the amount, description and end of an active
+// schedule. It returns sql.ErrNoRows when the schedule is not active.
+func (m *mysqlScheduleRepository) UpdateSchedule(ctx context.Context, schedule model.Schedule) error {
+	query := `UPDATE payment_schedule SET amount=?, description=?, end_at=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, schedule.Amount, schedule.Description, schedule.EndAt, time.Now(), schedule.ID, model.ScheduleStatusActive)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CancelSchedule stops an active schedule. It returns sql.ErrNoRows when the
+// schedule is not active.
+func (m *mysqlScheduleRepository) CancelSchedule(ctx context.Context, scheduleID int64) error {
+	query := `UPDATE payment_schedule SET status=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, model.ScheduleStatusCancelled, time.Now(), scheduleID, model.ScheduleStatusActive)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReadDueScheduleIDs returns up to limit active schedules due at the given
+// time, longest overdue first. They are not locked; each must be claimed
+// with ReadScheduleForUpdate before it is run.
+func (m *mysqlScheduleRepository) ReadDueScheduleIDs(ctx context.Context, at time.Time, limit int) ([]int64, error) {
+	query := `SELECT id FROM payment_schedule WHERE status=? AND due_at<=? ORDER BY due_at LIMIT ?`
+
+	rows, err := m.db.QueryContext(ctx, query, model.ScheduleStatusActive, at, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ReadScheduleForUpdate locks a schedule until tx ends. A schedule already
+// locked by another scheduler is skipped rather than waited for, and nil is
+// returned as if it did not exist.
+func (m *mysqlScheduleRepository) ReadScheduleForUpdate(ctx context.Context, tx *sql.Tx, scheduleID int64) (*model.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM payment_schedule WHERE id=? FOR UPDATE SKIP LOCKED`
+
+	schedule, err := scanSchedule(tx.QueryRowContext(ctx, query, scheduleID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return schedule, nil
+}
+
+// UpdateScheduleProgress records the outcome of a run on a schedule locked
+// in tx.
+func (m *mysqlScheduleRepository) UpdateScheduleProgress(ctx context.Context, tx *sql.Tx, schedule model.Schedule) error {
+	query := `UPDATE payment_schedule SET next_run_at=?, due_at=?, occurrences=?, attempts=?, last_error=?, status=?, updated_at=? WHERE id=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, schedule.NextRunAt, schedule.DueAt, schedule.Occurrences, schedule.Attempts, schedule.LastError, schedule.Status, time.Now(), schedule.ID)
+
+	return err
+}
+
+func (m *mysqlScheduleRepository) WriteScheduleRun(ctx context.Context, tx *sql.Tx, run model.ScheduleRun) (runID int64, err error) {
+	query := `INSERT INTO payment_schedule_run (schedule_id, scheduled_for, attempt, status, transaction_id, error) VALUES (?,?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, run.ScheduleID, run.ScheduledFor, run.Attempt, run.Status, run.TransactionID, run.Error)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlScheduleRepository) ReadScheduleRuns(ctx context.Context, scheduleID int64) ([]model.ScheduleRun, error) {
+	query := `SELECT id, schedule_id, scheduled_for, attempt, status, transaction_id, error, created_at FROM payment_schedule_run WHERE schedule_id=? ORDER BY id DESC`
+
+	rows, err := m.db.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []model.ScheduleRun{}
+	for rows.Next() {
+		var (
+			run           model.ScheduleRun
+			transactionID sql.NullInt64
+		)
+
+		err := rows.Scan(&run.ID, &run.ScheduleID, &run.ScheduledFor, &run.Attempt, &run.Status, &transactionID, &run.Error, &run.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if transactionID.Valid {
+			run.TransactionID = &transactionID.Int64
+		}
+
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+func scanSchedule(row rowScanner) (*model.Schedule, error) {
+	var (
+		schedule    model.Schedule
+		merchantID  sql.NullInt64
+		recipientID sql.NullInt64
+		endAt       sql.NullTime
+	)
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.UserID,
+		&merchantID,
+		&recipientID,
+		&schedule.Amount,
+		&schedule.Description,
+		&schedule.Frequency,
+		&schedule.StartAt,
+		&endAt,
+		&schedule.NextRunAt,
+		&schedule.DueAt,
+		&schedule.Occurrences,
+		&schedule.Attempts,
+		&schedule.LastError,
+		&schedule.Status,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if merchantID.Valid {
+		schedule.MerchantID = &merchantID.Int64
+	}
+
+	if recipientID.Valid {
+		schedule.RecipientID = &recipientID.Int64
+	}
+
+	if endAt.Valid {
+		schedule.EndAt = &endAt.Time
+	}
+
+	return &schedule, nil
+}
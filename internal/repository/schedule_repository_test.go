@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlScheduleRepository_ReadScheduleForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewScheduleRepository(db)
+		query      = "SELECT (.+) FROM payment_schedule WHERE id=\\? FOR UPDATE SKIP LOCKED"
+		columns    = []string{"id", "user_id", "merchant_id", "recipient_id", "amount", "description", "frequency", "start_at", "end_at", "next_run_at", "due_at", "occurrences", "attempts", "last_error", "status", "created_at", "updated_at"}
+		now        = time.Now()
+		merchantID = int64(8)
+	)
+
+	schedule := model.Schedule{
+		ID: 1, UserID: 1, MerchantID: &merchantID, Amount: 50000, Description: "rent", Frequency: model.ScheduleMonthly,
+		StartAt: now, NextRunAt: now, DueAt: now, Occurrences: 2, Attempts: 1, LastError: "your current balance is insufficient",
+		Status: model.ScheduleStatusActive, CreatedAt: now, UpdatedAt: now,
+	}
+
+	tests := []struct {
+		name    string
+		want    *model.Schedule
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    &schedule,
+			wantErr: false,
+		},
+		{
+			name:    "locked by another scheduler",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(schedule.ID).WillReturnError(fmt.Errorf("some error"))
+			} else if tt.want == nil {
+				mock.ExpectQuery(query).WithArgs(schedule.ID).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows(columns).AddRow(schedule.ID, schedule.UserID, merchantID, nil, schedule.Amount, schedule.Description, schedule.Frequency,
+					now, nil, now, now, schedule.Occurrences, schedule.Attempts, schedule.LastError, schedule.Status, now, now)
+				mock.ExpectQuery(query).WithArgs(schedule.ID).WillReturnRows(rows)
+			}
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			got, err := repo.ReadScheduleForUpdate(ctx, tx, schedule.ID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlScheduleRepository.ReadScheduleForUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlScheduleRepository.ReadScheduleForUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlScheduleRepository_CancelSchedule(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewScheduleRepository(db)
+		query = "UPDATE payment_schedule SET status=\\?, updated_at=\\? WHERE id=\\? AND status=\\?"
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "not active",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(model.ScheduleStatusCancelled, sqlmock.AnyArg(), int64(1), model.ScheduleStatusActive).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			if err := repo.CancelSchedule(ctx, 1); err != tt.wantErr {
+				t.Errorf("mysqlScheduleRepository.CancelSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
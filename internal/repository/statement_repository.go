@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type StatementRepository interface {
+	WriteStatement(ctx context.Context, record model.StatementRecord) error
+	ReadStatementByHash(ctx context.Context, hash string) (*model.StatementRecord, error)
+}
+
+type mysqlStatementRepository struct {
+	db *sql.DB
+}
+
+func NewStatementRepository(db *sql.DB) StatementRepository {
+	return &mysqlStatementRepository{
+		db: db,
+	}
+}
+
+// WriteStatement records an issued statement. Issuing the same statement
+// again keeps the first record.
+func (m *mysqlStatementRepository) WriteStatement(ctx context.Context, record model.StatementRecord) error {
+	query := `INSERT IGNORE INTO statement (user_id, wallet_id, month, opening_balance, closing_balance, transaction_count, hash) VALUES (?,?,?,?,?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, record.UserID, record.WalletID, record.Month, record.OpeningBalance, record.ClosingBalance, record.TransactionCount, record.Hash)
+
+	return err
+}
+
+func (m *mysqlStatementRepository) ReadStatementByHash(ctx context.Context, hash string) (*model.StatementRecord, error) {
+	query := `SELECT id, user_id, wallet_id, month, opening_balance, closing_balance, transaction_count, hash, issued_at FROM statement WHERE hash=?`
+
+	var record model.StatementRecord
+	err := m.db.QueryRowContext(ctx, query, hash).Scan(
+		&record.ID,
+		&record.UserID,
+		&record.WalletID,
+		&record.Month,
+		&record.OpeningBalance,
+		&record.ClosingBalance,
+		&record.TransactionCount,
+		&record.Hash,
+		&record.IssuedAt,
+	)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &record, nil
+}
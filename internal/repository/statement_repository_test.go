@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlStatementRepository_ReadStatementByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewStatementRepository(db)
+		query   = "SELECT (.+) FROM statement WHERE hash=\\?"
+		columns = []string{"id", "user_id", "wallet_id", "month", "opening_balance", "closing_balance", "transaction_count", "hash", "issued_at"}
+		now     = time.Now()
+	)
+
+	record := model.StatementRecord{
+		ID: 1, UserID: 1, WalletID: 3, Month: "2026-03", OpeningBalance: 100000, ClosingBalance: 129000, TransactionCount: 3, Hash: "abc", IssuedAt: now,
+	}
+
+	tests := []struct {
+		name    string
+		want    *model.StatementRecord
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			want:    &record,
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(record.Hash).WillReturnError(fmt.Errorf("some error"))
+			} else if tt.want == nil {
+				mock.ExpectQuery(query).WithArgs(record.Hash).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows(columns).AddRow(record.ID, record.UserID, record.WalletID, record.Month, record.OpeningBalance,
+					record.ClosingBalance, record.TransactionCount, record.Hash, now)
+				mock.ExpectQuery(query).WithArgs(record.Hash).WillReturnRows(rows)
+			}
+
+			got, err := repo.ReadStatementByHash(ctx, record.Hash)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlStatementRepository.ReadStatementByHash() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlStatementRepository.ReadStatementByHash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
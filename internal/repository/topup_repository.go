@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type TopUpRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
+	WriteTopUpIntent(ctx context.Context, intent model.TopUpIntent) (intentID int64, err error)
+	ReadTopUpIntentByID(ctx context.Context, intentID int64) (*model.TopUpIntent, error)
+	ReadTopUpIntentsByUser(ctx context.Context, userID int64) ([]model.TopUpIntent, error)
+	ReadTopUpIntentForUpdate(ctx context.Context, tx *sql.Tx, reference string) (*model.TopUpIntent, error)
+	UpdateTopUpIntentCharge(ctx context.Context, intent model.TopUpIntent) error
+	UpdateTopUpIntent(ctx context.Context, tx *sql.Tx, intent model.TopUpIntent) error
+	ReadTopUpIntentsByReferences(ctx context.Context, references []string) ([]model.TopUpIntent, error)
+	ReadTopUpIntentsSucceededBetween(ctx context.Context, from, to time.Time) ([]model.TopUpIntent, error)
+}
+
+type mysqlTopUpRepository struct {
+	db *sql.DB
+}
+
+func NewTopUpRepository(db *sql.DB) TopUpRepository {
+	return &mysqlTopUpRepository{
+		db: db,
+	}
+}
+
+const topUpIntentColumns = `id, user_id, wallet_id, amount, currency, status, reference, gateway_reference, redirect_url, failure_reason, transaction_id, created_at, updated_at`
+
+func (m *mysqlTopUpRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+func (m *mysqlTopUpRepository) WriteTopUpIntent(ctx context.Context, intent model.TopUpIntent) (intentID int64, err error) {
+	query := `INSERT INTO topup_intent (user_id, wallet_id, amount, currency, status, reference) VALUES (?,?,?,?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, intent.UserID, intent.WalletID, intent.Amount, intent.Currency, intent.Status, intent.Reference)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlTopUpRepository) ReadTopUpIntentByID(ctx context.Context, intentID int64) (*model.TopUpIntent, error) {
+	query := `SELECT ` + topUpIntentColumns + ` FROM topup_intent WHERE id=?`
+
+	intent, err := scanTopUpIntent(m.db.QueryRowContext(ctx, query, intentID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return intent, nil
+}
+
+func (m *mysqlTopUpRepository) ReadTopUpIntentsByUser(ctx context.Context, userID int64) ([]model.TopUpIntent, error) {
+	query := `SELECT ` + topUpIntentColumns + ` FROM topup_intent WHERE user_id=? ORDER BY id DESC`
+
+	return m.readTopUpIntents(ctx, query, userID)
+}
+
+// ReadTopUpIntentsByReferences returns the intents with the given
+// references, in no particular order.
+func (m *mysqlTopUpRepository) ReadTopUpIntentsByReferences(ctx context.Context, references []string) ([]model.TopUpIntent, error) {
+	if len(references) == 0 {
+		return []model.TopUpIntent{}, nil
+	}
+
+	query := `SELECT ` + topUpIntentColumns + ` FROM topup_intent WHERE reference IN (?` + strings.Repeat(",?", len(references)-1) + `)`
+
+	args := make([]interface{}, len(references))
+	for i, reference := range references {
+		args[i] = reference
+	}
+
+	return m.readTopUpIntents(ctx, query, args...)
+}
+
+// ReadTopUpIntentsSucceededBetween returns the intents credited between
+// from and to, both included.
+func (m *mysqlTopUpRepository) ReadTopUpIntentsSucceededBetween(ctx context.Context, from, to time.Time) ([]model.TopUpIntent, error) {
+	query := `SELECT ` + topUpIntentColumns + ` FROM topup_intent WHERE status=? AND updated_at BETWEEN ? AND ? ORDER BY id`
+
+	return m.readTopUpIntents(ctx, query, model.TopUpIntentSucceeded, from, to)
+}
+
+func (m *mysqlTopUpRepository) ReadTopUpIntentForUpdate(ctx context.Context, tx *sql.Tx, reference string) (*model.TopUpIntent, error) {
+	query := `SELECT ` + topUpIntentColumns + ` FROM topup_intent WHERE reference=? FOR UPDATE`
+
+	intent, err := scanTopUpIntent(tx.QueryRowContext(ctx, query, reference))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return intent, nil
+}
+
+// UpdateTopUpIntentCharge records the gateway's answer to the charge of a
+// pending intent. It returns sql.ErrNoRows when the intent is no longer
+// pending.
+func (m *mysqlTopUpRepository) UpdateTopUpIntentCharge(ctx context.Context, intent model.TopUpIntent) error {
+	query := `UPDATE topup_intent SET status=?, gateway_reference=?, redirect_url=?, failure_reason=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, intent.Status, intent.GatewayReference, intent.RedirectURL, intent.FailureReason, time.Now(), intent.ID, model.TopUpIntentPending)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateTopUpIntent records the outcome of an intent locked in tx.
+func (m *mysqlTopUpRepository) UpdateTopUpIntent(ctx context.Context, tx *sql.Tx, intent model.TopUpIntent) error {
+	query := `UPDATE topup_intent SET status=?, gateway_reference=?, failure_reason=?, transaction_id=?, updated_at=? WHERE id=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, intent.Status, intent.GatewayReference, intent.FailureReason, intent.TransactionID, time.Now(), intent.ID)
+
+	return err
+}
+
+func (m *mysqlTopUpRepository) readTopUpIntents(ctx context.Context, query string, args ...interface{}) ([]model.TopUpIntent, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	intents := []model.TopUpIntent{}
+	for rows.Next() {
+		intent, err := scanTopUpIntent(rows)
+		if err != nil {
+			return nil, err
+		}
+		intents = append(intents, *intent)
+	}
+
+	return intents, rows.Err()
+}
+
+func scanTopUpIntent(row rowScanner) (*model.TopUpIntent, error) {
+	var (
+		intent        model.TopUpIntent
+		transactionID sql.NullInt64
+	)
+
+	err := row.Scan(
+		&intent.ID,
+		&intent.UserID,
+		&intent.WalletID,
+		&intent.Amount,
+		&intent.Currency,
+		&intent.Status,
+		&intent.Reference,
+		&intent.GatewayReference,
+		&intent.RedirectURL,
+		&intent.FailureReason,
+		&transactionID,
+		&intent.CreatedAt,
+		&intent.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if transactionID.Valid {
+		intent.TransactionID = &transactionID.Int64
+	}
+
+	return &intent, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlTopUpRepository_UpdateTopUpIntentCharge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx    = context.Background()
+		repo   = NewTopUpRepository(db)
+		query  = "UPDATE topup_intent SET status=\\?, gateway_reference=\\?, redirect_url=\\?, failure_reason=\\?, updated_at=\\? WHERE id=\\? AND status=\\?"
+		intent = model.TopUpIntent{ID: 4, Status: model.TopUpIntentPending, GatewayReference: "MG-1", RedirectURL: "http://gateway/checkout/MG-1"}
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "already settled",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(intent.Status, intent.GatewayReference, intent.RedirectURL, "", sqlmock.AnyArg(), intent.ID, model.TopUpIntentPending).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			if err := repo.UpdateTopUpIntentCharge(ctx, intent); err != tt.wantErr {
+				t.Errorf("mysqlTopUpRepository.UpdateTopUpIntentCharge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -13,9 +13,37 @@ type UserRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, user model.User) (userID int64, err error)
 	ReadByUsernameOrEmail(ctx context.Context, username, email string) (*model.User, error)
 	IsUserRegistered(ctx context.Context, username, email string) (bool, error)
+	IsUserActive(ctx context.Context, userID int64) (bool, error)
 	ReadLoginHistory(ctx context.Context, req model.LoginHistory) (history model.LoginHistory, err error)
 	WriteLoginHistory(ctx context.Context, req model.LoginHistory) error
 	UpdateLoginHistory(ctx context.Context, req model.LoginHistory) error
+	ReadByID(ctx context.Context, userID int64) (*model.User, error)
+	ReadLoginHistories(ctx context.Context, userID int64) ([]model.LoginHistory, error)
+	WriteAccountDeletion(ctx context.Context, deletion model.AccountDeletion) (deletionID int64, err error)
+	ReadPendingAccountDeletion(ctx context.Context, userID int64) (*model.AccountDeletion, error)
+	ReadDueAccountDeletions(ctx context.Context, now time.Time) ([]model.AccountDeletion, error)
+	CancelAccountDeletion(ctx context.Context, deletionID int64) error
+	CompleteAccountDeletion(ctx context.Context, tx *sql.Tx, deletionID int64) error
+	Anonymize(ctx context.Context, tx *sql.Tx, userID int64) error
+	UpdateKYCLevel(ctx context.Context, tx *sql.Tx, userID int64, level string) error
+	SearchUsers(ctx context.Context, req model.UserSearchRequest) (users []model.User, total int64, err error)
+	ReadLoginHistoryPage(ctx context.Context, userID int64, page model.PageRequest) (histories []model.LoginHistory, total int64, err error)
+}
+
+// userSorts are the fields user searches can be sorted by.
+var userSorts = map[string]string{
+	"id":         "id",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"email":      "email",
+	"username":   "username",
+	"created_at": "created_at",
+}
+
+// loginHistorySorts are the fields login histories can be sorted by.
+var loginHistorySorts = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
 }
 
 type mysqlUserRepository struct {
@@ -62,9 +90,21 @@ func (m *mysqlUserRepository) IsUserRegistered(ctx context.Context, username, em
 	return total > 0, nil
 }
 
+// IsUserActive reports whether the user exists and has not been anonymized.
+func (m *mysqlUserRepository) IsUserActive(ctx context.Context, userID int64) (bool, error) {
+	total := 0
+	query := `SELECT COUNT(id) FROM user WHERE id=? AND deleted_at IS NULL`
+	err := m.db.QueryRowContext(ctx, query, userID).Scan(&total)
+	if err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
 func (m *mysqlUserRepository) ReadByUsernameOrEmail(ctx context.Context, username, email string) (*model.User, error) {
 	var user model.User
-	query := `SELECT id, first_name, last_name, birth_date, street_address, city, province, phone, email, username, password FROM user WHERE username=? OR email=?`
+	query := `SELECT id, first_name, last_name, birth_date, street_address, city, province, phone, email, username, password, role, kyc_level FROM user WHERE username=? OR email=?`
 	err := m.db.QueryRowContext(ctx, query, username, email).Scan(
 		&user.ID,
 		&user.FirstName,
@@ -77,6 +117,8 @@ func (m *mysqlUserRepository) ReadByUsernameOrEmail(ctx context.Context, usernam
 		&user.Email,
 		&user.Username,
 		&user.Password,
+		&user.Role,
+		&user.KYCLevel,
 	)
 
 	if err != nil && err != sql.ErrNoRows {
@@ -144,3 +186,296 @@ func (m *mysqlUserRepository) UpdateLoginHistory(ctx context.Context, req model.
 
 	return nil
 }
+
+func (m *mysqlUserRepository) ReadByID(ctx context.Context, userID int64) (*model.User, error) {
+	var user model.User
+	query := `SELECT id, first_name, last_name, birth_date, street_address, city, province, phone, email, username, role, kyc_level, created_at, updated_at FROM user WHERE id=?`
+	err := m.db.QueryRowContext(ctx, query, userID).Scan(
+		&user.ID,
+		&user.FirstName,
+		&user.LastName,
+		&user.BirthDate,
+		&user.StreetAddress,
+		&user.City,
+		&user.Province,
+		&user.Phone,
+		&user.Email,
+		&user.Username,
+		&user.Role,
+		&user.KYCLevel,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &user, nil
+}
+
+func (m *mysqlUserRepository) ReadLoginHistories(ctx context.Context, userID int64) ([]model.LoginHistory, error) {
+	query := `SELECT browser_name, login_succeed, login_failed, user_id, created_at, updated_at FROM login_history WHERE user_id=? ORDER BY id`
+
+	rows, err := m.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	histories := []model.LoginHistory{}
+	for rows.Next() {
+		var history model.LoginHistory
+		err := rows.Scan(
+			&history.BrowserName,
+			&history.LoginSucceed,
+			&history.LoginFailed,
+			&history.UserID,
+			&history.CreatedAt,
+			&history.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+
+	return histories, rows.Err()
+}
+
+func (m *mysqlUserRepository) WriteAccountDeletion(ctx context.Context, deletion model.AccountDeletion) (deletionID int64, err error) {
+	query := `INSERT INTO account_deletion (user_id, status, scheduled_at) VALUES (?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, deletion.UserID, deletion.Status, deletion.ScheduledAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlUserRepository) ReadPendingAccountDeletion(ctx context.Context, userID int64) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	query := `SELECT id, user_id, status, scheduled_at, created_at, updated_at FROM account_deletion WHERE user_id=? AND status=?`
+	err := m.db.QueryRowContext(ctx, query, userID, model.AccountDeletionPending).Scan(
+		&deletion.ID,
+		&deletion.UserID,
+		&deletion.Status,
+		&deletion.ScheduledAt,
+		&deletion.CreatedAt,
+		&deletion.UpdatedAt,
+	)
+
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &deletion, nil
+}
+
+func (m *mysqlUserRepository) ReadDueAccountDeletions(ctx context.Context, now time.Time) ([]model.AccountDeletion, error) {
+	query := `SELECT id, user_id, status, scheduled_at, created_at, updated_at FROM account_deletion WHERE status=? AND scheduled_at<=?`
+
+	rows, err := m.db.QueryContext(ctx, query, model.AccountDeletionPending, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deletions := []model.AccountDeletion{}
+	for rows.Next() {
+		var deletion model.AccountDeletion
+		err := rows.Scan(
+			&deletion.ID,
+			&deletion.UserID,
+			&deletion.Status,
+			&deletion.ScheduledAt,
+			&deletion.CreatedAt,
+			&deletion.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, deletion)
+	}
+
+	return deletions, rows.Err()
+}
+
+func (m *mysqlUserRepository) CancelAccountDeletion(ctx context.Context, deletionID int64) error {
+	query := `UPDATE account_deletion SET status=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, model.AccountDeletionCancelled, time.Now(), deletionID, model.AccountDeletionPending)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *mysqlUserRepository) CompleteAccountDeletion(ctx context.Context, tx *sql.Tx, deletionID int64) error {
+	query := `UPDATE account_deletion SET status=?, updated_at=? WHERE id=? AND status=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, model.AccountDeletionCompleted, time.Now(), deletionID, model.AccountDeletionPending)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Anonymize scrubs personal data from the user and its login history. The
+// rows themselves are kept so wallet and ledger references stay intact.
+func (m *mysqlUserRepository) Anonymize(ctx context.Context, tx *sql.Tx, userID int64) error {
+	userQuery := `UPDATE user SET first_name='deleted', last_name='user', birth_date='', street_address='', city='', province='', phone='', email=CONCAT('deleted-', id, '@anonymized.invalid'), username=CONCAT('deleted-', id), password='', token=NULL, deleted_at=?, updated_at=? WHERE id=?`
+	historyQuery := `UPDATE login_history SET browser_name='anonymized', updated_at=? WHERE user_id=?`
+
+	now := time.Now()
+
+	if _, err := tx.ExecContext(ctx, userQuery, now, now, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, historyQuery, now, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *mysqlUserRepository) UpdateKYCLevel(ctx context.Context, tx *sql.Tx, userID int64, level string) error {
+	query := `UPDATE user SET kyc_level=?, updated_at=? WHERE id=?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, level, time.Now(), userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SearchUsers returns a page of the users matching req, and how many match
+// in all.
+func (m *mysqlUserRepository) SearchUsers(ctx context.Context, req model.UserSearchRequest) ([]model.User, int64, error) {
+	order, limit, err := orderBy(req.PageRequest, userSorts, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where := ` FROM user WHERE (?='' OR CONCAT(first_name, ' ', last_name) LIKE ?) AND (?='' OR email LIKE ?) AND (?='' OR phone LIKE ?) AND (?='' OR username LIKE ?)`
+	args := []interface{}{
+		req.Name, contains(req.Name),
+		req.Email, contains(req.Email),
+		req.Phone, contains(req.Phone),
+		req.Username, contains(req.Username),
+	}
+
+	var total int64
+	if err = m.db.QueryRowContext(ctx, `SELECT COUNT(*)`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, first_name, last_name, birth_date, street_address, city, province, phone, email, username, role, kyc_level, created_at, updated_at` + where + order
+
+	rows, err := m.db.QueryContext(ctx, query, append(args, limit...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID,
+			&user.FirstName,
+			&user.LastName,
+			&user.BirthDate,
+			&user.StreetAddress,
+			&user.City,
+			&user.Province,
+			&user.Phone,
+			&user.Email,
+			&user.Username,
+			&user.Role,
+			&user.KYCLevel,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
+// ReadLoginHistoryPage returns a page of the login history of a user, and
+// how long it is in all.
+func (m *mysqlUserRepository) ReadLoginHistoryPage(ctx context.Context, userID int64, page model.PageRequest) ([]model.LoginHistory, int64, error) {
+	order, limit, err := orderBy(page, loginHistorySorts, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err = m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM login_history WHERE user_id=?`, userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT browser_name, login_succeed, login_failed, user_id, created_at, updated_at FROM login_history WHERE user_id=?` + order
+
+	rows, err := m.db.QueryContext(ctx, query, append([]interface{}{userID}, limit...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	histories := []model.LoginHistory{}
+	for rows.Next() {
+		var history model.LoginHistory
+		err := rows.Scan(
+			&history.BrowserName,
+			&history.LoginSucceed,
+			&history.LoginFailed,
+			&history.UserID,
+			&history.CreatedAt,
+			&history.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		histories = append(histories, history)
+	}
+
+	return histories, total, rows.Err()
+}
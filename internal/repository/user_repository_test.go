@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/cecepsprd/starworks-test/internal/model"
@@ -127,6 +129,51 @@ func Test_mysqlUserRepository_IsUserRegistered(t *testing.T) {
 	}
 }
 
+func Test_mysqlUserRepository_IsUserActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewUserRepository(db)
+		query = "SELECT COUNT\\(id\\) FROM user WHERE id=\\? AND deleted_at IS NULL"
+	)
+
+	tests := []struct {
+		name  string
+		count int
+		want  bool
+	}{
+		{
+			name:  "active",
+			count: 1,
+			want:  true,
+		},
+		{
+			name:  "anonymized",
+			count: 0,
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(query).WithArgs(int64(1)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.count))
+
+			got, err := repo.IsUserActive(ctx, 1)
+			if err != nil {
+				t.Errorf("mysqlUserRepository.IsUserActive() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("mysqlUserRepository.IsUserActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
 func Test_mysqlUserRepository_ReadByUsernameOrEmail(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -137,7 +184,7 @@ func Test_mysqlUserRepository_ReadByUsernameOrEmail(t *testing.T) {
 	var (
 		ctx   = context.Background()
 		repo  = NewUserRepository(db)
-		query = "SELECT id, first_name, last_name, birth_date, street_address, city, province, phone, email, username, password FROM user WHERE username=\\? OR email=\\?"
+		query = "SELECT id, first_name, last_name, birth_date, street_address, city, province, phone, email, username, password, role, kyc_level FROM user WHERE username=\\? OR email=\\?"
 	)
 
 	user := model.User{}
@@ -178,8 +225,8 @@ func Test_mysqlUserRepository_ReadByUsernameOrEmail(t *testing.T) {
 			} else if !tt.wantErr && tt.want == nil {
 				mock.ExpectQuery(query).WithArgs(tt.args[0], tt.args[1]).WillReturnError(sql.ErrNoRows)
 			} else {
-				rows := sqlmock.NewRows([]string{"id", "first_name", "last_name", "birth_date", "street_address", "city", "province", "phone", "email", "username", "password"})
-				rows.AddRow(user.ID, user.FirstName, user.LastName, user.BirthDate, user.StreetAddress, user.City, user.Province, user.Phone, user.Email, user.Username, user.Password)
+				rows := sqlmock.NewRows([]string{"id", "first_name", "last_name", "birth_date", "street_address", "city", "province", "phone", "email", "username", "password", "role", "kyc_level"})
+				rows.AddRow(user.ID, user.FirstName, user.LastName, user.BirthDate, user.StreetAddress, user.City, user.Province, user.Phone, user.Email, user.Username, user.Password, user.Role, user.KYCLevel)
 				mock.ExpectQuery(query).WithArgs(tt.args[0], tt.args[1]).WillReturnRows(rows)
 			}
 
@@ -194,3 +241,237 @@ func Test_mysqlUserRepository_ReadByUsernameOrEmail(t *testing.T) {
 		})
 	}
 }
+
+func Test_mysqlUserRepository_ReadByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewUserRepository(db)
+		query = "SELECT id, first_name, last_name, birth_date, street_address, city, province, phone, email, username, role, kyc_level, created_at, updated_at FROM user WHERE id=\\?"
+	)
+
+	user := model.User{ID: 1, Username: "starworks", Email: "starworks@gmail.com", Role: model.RoleUser, KYCLevel: model.KYCLevelBasic}
+
+	tests := []struct {
+		name    string
+		fields  UserRepository
+		args    int64
+		want    *model.User
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			fields:  repo,
+			args:    1,
+			want:    &user,
+			wantErr: false,
+		},
+		{
+			name:    "user not found",
+			fields:  repo,
+			args:    1,
+			want:    nil,
+			wantErr: false,
+		},
+		{
+			name:    "failed get data",
+			fields:  repo,
+			args:    1,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(tt.args).WillReturnError(fmt.Errorf("some error"))
+			} else if tt.want == nil {
+				mock.ExpectQuery(query).WithArgs(tt.args).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows([]string{"id", "first_name", "last_name", "birth_date", "street_address", "city", "province", "phone", "email", "username", "role", "kyc_level", "created_at", "updated_at"})
+				rows.AddRow(user.ID, user.FirstName, user.LastName, user.BirthDate, user.StreetAddress, user.City, user.Province, user.Phone, user.Email, user.Username, user.Role, user.KYCLevel, user.CreatedAt, user.UpdatedAt)
+				mock.ExpectQuery(query).WithArgs(tt.args).WillReturnRows(rows)
+			}
+
+			got, err := tt.fields.ReadByID(ctx, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlUserRepository.ReadByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlUserRepository.ReadByID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlUserRepository_WriteAccountDeletion(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewUserRepository(db)
+		query = "INSERT INTO account_deletion (user_id, status, scheduled_at) VALUES (?,?,?)"
+	)
+
+	deletion := model.AccountDeletion{
+		UserID:      1,
+		Status:      model.AccountDeletionPending,
+		ScheduledAt: time.Now(),
+	}
+
+	tests := []struct {
+		name    string
+		repo    UserRepository
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			repo:    repo,
+			want:    7,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			repo:    repo,
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectPrepare(query).ExpectExec().WillReturnError(fmt.Errorf("err"))
+			} else {
+				mock.ExpectPrepare(query).ExpectExec().
+					WithArgs(deletion.UserID, deletion.Status, deletion.ScheduledAt).
+					WillReturnResult(sqlmock.NewResult(7, 1))
+			}
+
+			got, err := tt.repo.WriteAccountDeletion(ctx, deletion)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlUserRepository.WriteAccountDeletion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("mysqlUserRepository.WriteAccountDeletion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlUserRepository_Anonymize(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx          = context.Background()
+		repo         = NewUserRepository(db)
+		userQuery    = "UPDATE user SET first_name='deleted'"
+		historyQuery = "UPDATE login_history SET browser_name='anonymized'"
+	)
+
+	tests := []struct {
+		name    string
+		repo    UserRepository
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			repo:    repo,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			repo:    repo,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			if tt.wantErr {
+				mock.ExpectExec(userQuery).WillReturnError(fmt.Errorf("err"))
+			} else {
+				mock.ExpectExec(userQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(historyQuery).WithArgs(sqlmock.AnyArg(), 1).WillReturnResult(sqlmock.NewResult(0, 2))
+			}
+
+			if err := tt.repo.Anonymize(ctx, tt.repo.BeginTx(ctx), 1); (err != nil) != tt.wantErr {
+				t.Errorf("mysqlUserRepository.Anonymize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_mysqlUserRepository_SearchUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewUserRepository(db)
+		countQuery = "SELECT COUNT\\(\\*\\) FROM user WHERE"
+		query      = "SELECT id, first_name, (.+) FROM user WHERE (.+) ORDER BY username ASC, id ASC LIMIT \\? OFFSET \\?"
+		columns    = []string{"id", "first_name", "last_name", "birth_date", "street_address", "city", "province", "phone", "email", "username", "role", "kyc_level", "created_at", "updated_at"}
+		now        = time.Now()
+		budi       = model.User{ID: 2, FirstName: "budi", LastName: "santoso", Phone: "0812", Email: "budi@mail.com", Username: "budi", Role: model.RoleUser, KYCLevel: model.KYCLevelUnverified, CreatedAt: now, UpdatedAt: now}
+	)
+
+	tests := []struct {
+		name      string
+		req       model.UserSearchRequest
+		want      []model.User
+		wantTotal int64
+		wantErr   bool
+	}{
+		{
+			name:      "success",
+			req:       model.UserSearchRequest{Name: "bud", Email: "50%", PageRequest: model.PageRequest{Page: 2, PerPage: 10, Sort: "username", Order: model.SortAsc}},
+			want:      []model.User{budi},
+			wantTotal: 11,
+		},
+		{
+			name:    "unknown sort",
+			req:     model.UserSearchRequest{PageRequest: model.PageRequest{Page: 1, PerPage: 10, Sort: "password"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				args := []driver.Value{"bud", "%bud%", "50%", "%50\\%%", "", "%%", "", "%%"}
+				mock.ExpectQuery(countQuery).WithArgs(args...).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.wantTotal))
+				mock.ExpectQuery(query).WithArgs(append(args, 10, 10)...).WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(budi.ID, budi.FirstName, budi.LastName, "", "", "", "", budi.Phone, budi.Email, budi.Username, budi.Role, budi.KYCLevel, now, now))
+			}
+
+			got, total, err := repo.SearchUsers(ctx, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mysqlUserRepository.SearchUsers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("mysqlUserRepository.SearchUsers() = %v, %d, want %v, %d", got, total, tt.want, tt.wantTotal)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
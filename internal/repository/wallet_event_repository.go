@@ -0,0 +1,669 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDuplicateEntry is the MySQL error number for a duplicate key.
+const mysqlDuplicateEntry = 1062
+
+// WalletEventStore keeps wallets as append-only streams of events. The
+// wallet and wallet_transaction tables become projections of the streams:
+// they are updated in the same transaction as every append, so whatever
+// reads them is unchanged, and can be rebuilt from the streams.
+type WalletEventStore interface {
+	WalletRepository
+	ReadEvents(ctx context.Context, walletID, afterVersion int64) ([]model.WalletEvent, error)
+	ImportLedger(ctx context.Context) (imported int, err error)
+	RebuildProjections(ctx context.Context) (rebuilt int, err error)
+	TakeSnapshots(ctx context.Context) (taken int, err error)
+}
+
+// eventSourcedWalletRepository reads the projections like
+// mysqlWalletRepository does, but loads wallets for update from their
+// streams. A balance change is appended when its ledger entry is written:
+// UpdateBalance only claims the next version of the stream, failing with
+// cs.ErrConcurrentUpdate if the wallet changed since it was loaded, so it
+// must be followed by WriteTransaction in the same transaction.
+type eventSourcedWalletRepository struct {
+	*mysqlWalletRepository
+	snapshotInterval int64
+}
+
+// NewEventSourcedWalletRepository returns a WalletEventStore that snapshots
+// a wallet every snapshotInterval events, or never when it is zero.
+func NewEventSourcedWalletRepository(db *sql.DB, snapshotInterval int64) WalletEventStore {
+	return &eventSourcedWalletRepository{
+		mysqlWalletRepository: &mysqlWalletRepository{db: db},
+		snapshotInterval:      snapshotInterval,
+	}
+}
+
+// dbtx is what the event store needs of a *sql.DB or *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// walletAggregate is a wallet as its events leave it. It is also the state
+// kept in snapshots.
+type walletAggregate struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"user_id"`
+	Address      string    `json:"address"`
+	Kind         string    `json:"kind"`
+	Balance      float64   `json:"balance"`
+	Status       string    `json:"status"`
+	BlockCredits bool      `json:"block_credits"`
+	Version      int64     `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// apply moves the wallet on by the next event of its stream.
+func (a *walletAggregate) apply(e model.WalletEvent) error {
+	if e.Version != a.Version+1 {
+		return fmt.Errorf("wallet %d: event version %d does not follow %d", e.WalletID, e.Version, a.Version)
+	}
+
+	if a.Version == 0 && e.Type != model.WalletEventOpened {
+		return fmt.Errorf("wallet %d: %s before it was opened", e.WalletID, e.Type)
+	}
+
+	switch e.Type {
+	case model.WalletEventOpened:
+		if a.Version != 0 {
+			return fmt.Errorf("wallet %d: opened twice", e.WalletID)
+		}
+		var opened model.WalletOpened
+		if err := json.Unmarshal(e.Data, &opened); err != nil {
+			return err
+		}
+		a.ID = e.WalletID
+		a.UserID = opened.UserID
+		a.Address = opened.Address
+		a.Kind = opened.Kind
+		a.Status = model.WalletStatusActive
+		a.CreatedAt = e.CreatedAt
+	case model.WalletEventCredited, model.WalletEventDebited:
+		var entry model.WalletEntry
+		if err := json.Unmarshal(e.Data, &entry); err != nil {
+			return err
+		}
+		if e.Type == model.WalletEventCredited {
+			a.Balance += entry.Amount
+		} else {
+			a.Balance -= entry.Amount
+		}
+	case model.WalletEventFrozen, model.WalletEventUnfrozen, model.WalletEventClosed:
+		if a.Status == model.WalletStatusClosed {
+			return fmt.Errorf("wallet %d: %s after it was closed", e.WalletID, e.Type)
+		}
+		var changed model.WalletStatusChanged
+		if err := json.Unmarshal(e.Data, &changed); err != nil {
+			return err
+		}
+		a.Status = walletEventStatus[e.Type]
+		a.BlockCredits = e.Type == model.WalletEventFrozen && changed.BlockCredits
+	default:
+		return fmt.Errorf("wallet %d: unknown event %s", e.WalletID, e.Type)
+	}
+
+	a.Version = e.Version
+	a.UpdatedAt = e.CreatedAt
+
+	return nil
+}
+
+func (a *walletAggregate) wallet() *model.Wallet {
+	return &model.Wallet{
+		ID:           a.ID,
+		Address:      a.Address,
+		Balance:      a.Balance,
+		UserID:       a.UserID,
+		Kind:         a.Kind,
+		CreatedAt:    a.CreatedAt,
+		UpdatedAt:    a.UpdatedAt,
+		Status:       a.Status,
+		BlockCredits: a.BlockCredits,
+		Version:      a.Version,
+	}
+}
+
+// walletEventStatus is the status each status change event moves a wallet
+// to, and walletStatusEvent the other way round.
+var (
+	walletEventStatus = map[string]string{
+		model.WalletEventFrozen:   model.WalletStatusFrozen,
+		model.WalletEventUnfrozen: model.WalletStatusActive,
+		model.WalletEventClosed:   model.WalletStatusClosed,
+	}
+	walletStatusEvent = map[string]string{
+		model.WalletStatusFrozen: model.WalletEventFrozen,
+		model.WalletStatusActive: model.WalletEventUnfrozen,
+		model.WalletStatusClosed: model.WalletEventClosed,
+	}
+)
+
+// AddWallet opens a wallet. Wallets are opened empty; money only comes in
+// through the ledger.
+func (m *eventSourcedWalletRepository) AddWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) (walletID int64, err error) {
+	if wallet.Balance != 0 {
+		return 0, errors.New("wallets are opened empty")
+	}
+
+	query := `INSERT INTO wallet (address, balance, user_id, kind, version) VALUES (?, 0, ?, ?, 1)`
+
+	res, err := tx.ExecContext(ctx, query, wallet.Address, wallet.UserID, wallet.Kind)
+	if err != nil {
+		return 0, err
+	}
+
+	walletID, err = res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	err = m.append(ctx, tx, walletID, 1, model.WalletEventOpened, model.WalletOpened{UserID: wallet.UserID, Address: wallet.Address, Kind: wallet.Kind}, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return walletID, nil
+}
+
+// ReadBalanceForUpdate locks the wallet and loads it from its stream.
+func (m *eventSourcedWalletRepository) ReadBalanceForUpdate(ctx context.Context, tx *sql.Tx, req model.CheckBalanceRequest) (*model.Wallet, error) {
+	query := `SELECT id, version FROM wallet WHERE address = ? and user_id = ? FOR UPDATE`
+
+	return m.loadForUpdate(ctx, tx, query, req.Address, req.UserID)
+}
+
+// ReadByIDForUpdate locks the wallet and loads it from its stream.
+func (m *eventSourcedWalletRepository) ReadByIDForUpdate(ctx context.Context, tx *sql.Tx, walletID int64) (*model.Wallet, error) {
+	query := `SELECT id, version FROM wallet WHERE id = ? FOR UPDATE`
+
+	return m.loadForUpdate(ctx, tx, query, walletID)
+}
+
+func (m *eventSourcedWalletRepository) loadForUpdate(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*model.Wallet, error) {
+	var walletID, version int64
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&walletID, &version)
+	if err == sql.ErrNoRows {
+		return nil, cs.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	agg, err := m.load(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if agg == nil {
+		return nil, fmt.Errorf("wallet %d has no event stream, import the ledger first", walletID)
+	}
+
+	if agg.Version != version {
+		return nil, fmt.Errorf("wallet %d projection is at version %d but its stream at %d, rebuild the projections", walletID, version, agg.Version)
+	}
+
+	return agg.wallet(), nil
+}
+
+// UpdateBalance claims the next version of the wallet's stream for the
+// ledger entry about to be written, and projects the new balance.
+func (m *eventSourcedWalletRepository) UpdateBalance(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
+	query := `UPDATE wallet SET balance=?, version=version+1, updated_at=? WHERE id=? AND version=?`
+
+	res, err := tx.ExecContext(ctx, query, wallet.Balance, time.Now(), wallet.ID, wallet.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return cs.ErrConcurrentUpdate
+	}
+
+	return nil
+}
+
+// UpdateStatus appends the status change to the wallet's stream at the
+// version after the one it was loaded at, failing with cs.ErrConcurrentUpdate
+// if the wallet changed since, and projects it.
+func (m *eventSourcedWalletRepository) UpdateStatus(ctx context.Context, tx *sql.Tx, wallet model.Wallet, change model.WalletStatusChange) error {
+	now := time.Now()
+
+	query := `UPDATE wallet SET status=?, block_credits=?, version=version+1, updated_at=? WHERE id=? AND version=?`
+
+	res, err := tx.ExecContext(ctx, query, change.ToStatus, change.BlockCredits, now, wallet.ID, wallet.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return cs.ErrConcurrentUpdate
+	}
+
+	if err = writeStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	changed := model.WalletStatusChanged{
+		Reason:       change.Reason,
+		Note:         change.Note,
+		BlockCredits: change.BlockCredits,
+		ChangedBy:    change.ChangedBy,
+	}
+
+	return m.append(ctx, tx, wallet.ID, wallet.Version+1, walletStatusEvent[change.ToStatus], changed, now)
+}
+
+// WriteTransaction projects a ledger entry and appends it to the wallet's
+// stream as Credited or Debited, at the version UpdateBalance claimed.
+func (m *eventSourcedWalletRepository) WriteTransaction(ctx context.Context, tx *sql.Tx, trx model.Transaction) (trxID int64, err error) {
+	now := time.Now()
+
+	query := `INSERT INTO wallet_transaction (wallet_id, type, amount, balance_after, reference, description, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := tx.ExecContext(ctx, query, trx.WalletID, trx.Type, trx.Amount, trx.BalanceAfter, trx.Reference, trx.Description, now)
+	if err != nil {
+		return 0, err
+	}
+
+	if trxID, err = res.LastInsertId(); err != nil {
+		return 0, err
+	}
+
+	var version int64
+	if err = tx.QueryRowContext(ctx, `SELECT version FROM wallet WHERE id = ?`, trx.WalletID).Scan(&version); err != nil {
+		return 0, err
+	}
+
+	eventType, entry := walletEntryEvent(trxID, trx)
+	if err = m.append(ctx, tx, trx.WalletID, version, eventType, entry, now); err != nil {
+		return 0, err
+	}
+
+	if m.snapshotInterval > 0 && version%m.snapshotInterval == 0 {
+		agg, err := m.load(ctx, tx, trx.WalletID)
+		if err != nil {
+			return 0, err
+		}
+		if err = writeSnapshot(ctx, tx, agg); err != nil {
+			return 0, err
+		}
+	}
+
+	return trxID, nil
+}
+
+// walletEntryEvent is the event a ledger entry is appended as.
+func walletEntryEvent(trxID int64, trx model.Transaction) (string, model.WalletEntry) {
+	entry := model.WalletEntry{
+		TransactionID: trxID,
+		Type:          trx.Type,
+		Amount:        trx.Amount,
+		Reference:     trx.Reference,
+		Description:   trx.Description,
+	}
+
+	if trx.Amount < 0 {
+		entry.Amount = -trx.Amount
+		return model.WalletEventDebited, entry
+	}
+
+	return model.WalletEventCredited, entry
+}
+
+// append adds an event to a wallet's stream at version, failing with
+// cs.ErrConcurrentUpdate if another one got there first.
+func (m *eventSourcedWalletRepository) append(ctx context.Context, tx *sql.Tx, walletID, version int64, eventType string, data interface{}, at time.Time) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO wallet_event (wallet_id, version, type, data, created_at) VALUES (?, ?, ?, ?, ?)`
+
+	_, err = tx.ExecContext(ctx, query, walletID, version, eventType, string(payload), at)
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return cs.ErrConcurrentUpdate
+	}
+
+	return err
+}
+
+// ReadEvents returns the events of a wallet's stream after afterVersion.
+func (m *eventSourcedWalletRepository) ReadEvents(ctx context.Context, walletID, afterVersion int64) ([]model.WalletEvent, error) {
+	return readWalletEvents(ctx, m.db, walletID, afterVersion)
+}
+
+// load replays a wallet's stream from its latest snapshot. It returns nil
+// if the wallet has no stream.
+func (m *eventSourcedWalletRepository) load(ctx context.Context, q dbtx, walletID int64) (*walletAggregate, error) {
+	agg := &walletAggregate{}
+
+	var state string
+	err := q.QueryRowContext(ctx, `SELECT state FROM wallet_snapshot WHERE wallet_id = ?`, walletID).Scan(&state)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == nil {
+		if err = json.Unmarshal([]byte(state), agg); err != nil {
+			return nil, err
+		}
+	}
+
+	events, err := readWalletEvents(ctx, q, walletID, agg.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range events {
+		if err = agg.apply(e); err != nil {
+			return nil, err
+		}
+	}
+
+	if agg.Version == 0 {
+		return nil, nil
+	}
+
+	return agg, nil
+}
+
+// ImportLedger starts the streams of the wallets that have none from their
+// ledger entries, for switching over from plain MySQL storage.
+func (m *eventSourcedWalletRepository) ImportLedger(ctx context.Context) (imported int, err error) {
+	walletIDs, err := m.readWalletIDs(ctx, `SELECT id FROM wallet w WHERE NOT EXISTS (SELECT 1 FROM wallet_event e WHERE e.wallet_id = w.id) ORDER BY id`)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, walletID := range walletIDs {
+		if err = m.importWallet(ctx, walletID); err != nil {
+			return imported, fmt.Errorf("importing wallet %d: %w", walletID, err)
+		}
+		imported++
+	}
+
+	return imported, nil
+}
+
+func (m *eventSourcedWalletRepository) importWallet(ctx context.Context, walletID int64) (err error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var (
+		opened       model.WalletOpened
+		status       string
+		blockCredits bool
+		createdAt    time.Time
+		updatedAt    time.Time
+	)
+
+	query := `SELECT COALESCE(user_id, 0), address, kind, status, block_credits, created_at, updated_at FROM wallet WHERE id = ? FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, walletID).Scan(&opened.UserID, &opened.Address, &opened.Kind, &status, &blockCredits, &createdAt, &updatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err = m.append(ctx, tx, walletID, 1, model.WalletEventOpened, opened, createdAt); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, wallet_id, type, amount, balance_after, reference, description, created_at FROM wallet_transaction WHERE wallet_id = ? ORDER BY id`, walletID)
+	if err != nil {
+		return err
+	}
+
+	entries := []model.Transaction{}
+	for rows.Next() {
+		var trx model.Transaction
+		if err = rows.Scan(&trx.ID, &trx.WalletID, &trx.Type, &trx.Amount, &trx.BalanceAfter, &trx.Reference, &trx.Description, &trx.CreatedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		entries = append(entries, trx)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	version := int64(1)
+	for _, trx := range entries {
+		version++
+		eventType, entry := walletEntryEvent(trx.ID, trx)
+		if err = m.append(ctx, tx, walletID, version, eventType, entry, trx.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	// Only the wallet's current status is known, not how it got there.
+	if status != model.WalletStatusActive {
+		version++
+		changed := model.WalletStatusChanged{Reason: model.WalletReasonOther, Note: "imported", BlockCredits: blockCredits}
+		if err = m.append(ctx, tx, walletID, version, walletStatusEvent[status], changed, updatedAt); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE wallet SET version=? WHERE id=?`, version, walletID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RebuildProjections replays every stream from the start and writes the
+// wallets and ledger entries back as they leave them.
+func (m *eventSourcedWalletRepository) RebuildProjections(ctx context.Context) (rebuilt int, err error) {
+	walletIDs, err := m.readWalletIDs(ctx, `SELECT DISTINCT wallet_id FROM wallet_event ORDER BY wallet_id`)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, walletID := range walletIDs {
+		if err = m.rebuildWallet(ctx, walletID); err != nil {
+			return rebuilt, fmt.Errorf("rebuilding wallet %d: %w", walletID, err)
+		}
+		rebuilt++
+	}
+
+	return rebuilt, nil
+}
+
+func (m *eventSourcedWalletRepository) rebuildWallet(ctx context.Context, walletID int64) (err error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Appending to a stream takes this lock first, so the stream stays put
+	// while it is replayed.
+	var locked int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM wallet WHERE id = ? FOR UPDATE`, walletID).Scan(&locked)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	events, err := readWalletEvents(ctx, tx, walletID, 0)
+	if err != nil {
+		return err
+	}
+
+	agg := &walletAggregate{}
+	entries := []model.Transaction{}
+	for _, e := range events {
+		if err = agg.apply(e); err != nil {
+			return err
+		}
+
+		if e.Type != model.WalletEventCredited && e.Type != model.WalletEventDebited {
+			continue
+		}
+
+		var entry model.WalletEntry
+		if err = json.Unmarshal(e.Data, &entry); err != nil {
+			return err
+		}
+
+		amount := entry.Amount
+		if e.Type == model.WalletEventDebited {
+			amount = -amount
+		}
+
+		entries = append(entries, model.Transaction{
+			ID:           entry.TransactionID,
+			WalletID:     walletID,
+			Type:         entry.Type,
+			Amount:       amount,
+			BalanceAfter: agg.Balance,
+			Reference:    entry.Reference,
+			Description:  entry.Description,
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+
+	userID := sql.NullInt64{Int64: agg.UserID, Valid: agg.UserID != 0}
+
+	// A stream opened before the kind was recorded leaves the projected
+	// kind as it is, or the column default for a wallet not projected yet.
+	kind := sql.NullString{String: agg.Kind, Valid: agg.Kind != ""}
+
+	query := `INSERT INTO wallet (id, address, balance, user_id, kind, status, block_credits, version, created_at, updated_at) VALUES (?, ?, ?, ?, COALESCE(?, DEFAULT(kind)), ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE address=VALUES(address), balance=VALUES(balance), user_id=VALUES(user_id), kind=COALESCE(?, kind), status=VALUES(status), block_credits=VALUES(block_credits), version=VALUES(version), updated_at=VALUES(updated_at)`
+	_, err = tx.ExecContext(ctx, query, walletID, agg.Address, agg.Balance, userID, kind, agg.Status, agg.BlockCredits, agg.Version, agg.CreatedAt, agg.UpdatedAt, kind)
+	if err != nil {
+		return err
+	}
+
+	query = `INSERT INTO wallet_transaction (id, wallet_id, type, amount, balance_after, reference, description, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE wallet_id=VALUES(wallet_id), type=VALUES(type), amount=VALUES(amount), balance_after=VALUES(balance_after), reference=VALUES(reference), description=VALUES(description), created_at=VALUES(created_at)`
+	for _, trx := range entries {
+		if _, err = tx.ExecContext(ctx, query, trx.ID, trx.WalletID, trx.Type, trx.Amount, trx.BalanceAfter, trx.Reference, trx.Description, trx.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// TakeSnapshots snapshots every stream at its current version.
+func (m *eventSourcedWalletRepository) TakeSnapshots(ctx context.Context) (taken int, err error) {
+	walletIDs, err := m.readWalletIDs(ctx, `SELECT DISTINCT wallet_id FROM wallet_event ORDER BY wallet_id`)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, walletID := range walletIDs {
+		agg, err := m.load(ctx, m.db, walletID)
+		if err != nil {
+			return taken, fmt.Errorf("loading wallet %d: %w", walletID, err)
+		}
+		if err = writeSnapshot(ctx, m.db, agg); err != nil {
+			return taken, fmt.Errorf("snapshotting wallet %d: %w", walletID, err)
+		}
+		taken++
+	}
+
+	return taken, nil
+}
+
+// writeSnapshot keeps the state of a wallet, unless a later one is kept
+// already.
+func writeSnapshot(ctx context.Context, q dbtx, agg *walletAggregate) error {
+	state, err := json.Marshal(agg)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO wallet_snapshot (wallet_id, version, state) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE state=IF(VALUES(version) > version, VALUES(state), state), version=GREATEST(version, VALUES(version))`
+
+	_, err = q.ExecContext(ctx, query, agg.ID, agg.Version, string(state))
+
+	return err
+}
+
+func (m *eventSourcedWalletRepository) readWalletIDs(ctx context.Context, query string) ([]int64, error) {
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	walletIDs := []int64{}
+	for rows.Next() {
+		var walletID int64
+		if err := rows.Scan(&walletID); err != nil {
+			return nil, err
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+
+	return walletIDs, rows.Err()
+}
+
+func readWalletEvents(ctx context.Context, q dbtx, walletID, afterVersion int64) ([]model.WalletEvent, error) {
+	query := `SELECT id, wallet_id, version, type, data, created_at FROM wallet_event WHERE wallet_id = ? AND version > ? ORDER BY version`
+
+	rows, err := q.QueryContext(ctx, query, walletID, afterVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []model.WalletEvent{}
+	for rows.Next() {
+		var (
+			e    model.WalletEvent
+			data string
+		)
+		if err := rows.Scan(&e.ID, &e.WalletID, &e.Version, &e.Type, &data, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.Data = []byte(data)
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
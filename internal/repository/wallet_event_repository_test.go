@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/go-sql-driver/mysql"
+)
+
+func walletEvent(version int64, typ, data string) model.WalletEvent {
+	return model.WalletEvent{WalletID: 3, Version: version, Type: typ, Data: []byte(data)}
+}
+
+func Test_walletAggregate_apply(t *testing.T) {
+	opened := walletEvent(1, model.WalletEventOpened, `{"user_id":1,"address":"addr","kind":"settlement"}`)
+
+	tests := []struct {
+		name        string
+		events      []model.WalletEvent
+		wantBalance float64
+		wantStatus  string
+		wantErr     bool
+	}{
+		{
+			name: "positif",
+			events: []model.WalletEvent{
+				opened,
+				walletEvent(2, model.WalletEventCredited, `{"transaction_id":1,"type":"top_up","amount":100000}`),
+				walletEvent(3, model.WalletEventDebited, `{"transaction_id":2,"type":"payment","amount":25000}`),
+				walletEvent(4, model.WalletEventFrozen, `{"reason":"fraud_suspected","block_credits":true}`),
+			},
+			wantBalance: 75000,
+			wantStatus:  model.WalletStatusFrozen,
+		},
+		{
+			name: "positif: unfrozen and closed",
+			events: []model.WalletEvent{
+				opened,
+				walletEvent(2, model.WalletEventFrozen, `{"reason":"legal_order"}`),
+				walletEvent(3, model.WalletEventUnfrozen, `{"reason":"resolved"}`),
+				walletEvent(4, model.WalletEventClosed, `{"reason":"customer_request"}`),
+			},
+			wantStatus: model.WalletStatusClosed,
+		},
+		{
+			name: "negatif: version gap",
+			events: []model.WalletEvent{
+				opened,
+				walletEvent(3, model.WalletEventCredited, `{"amount":100000}`),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "negatif: credited before opened",
+			events:  []model.WalletEvent{walletEvent(1, model.WalletEventCredited, `{"amount":100000}`)},
+			wantErr: true,
+		},
+		{
+			name: "negatif: changed after closed",
+			events: []model.WalletEvent{
+				opened,
+				walletEvent(2, model.WalletEventClosed, `{"reason":"customer_request"}`),
+				walletEvent(3, model.WalletEventUnfrozen, `{"reason":"resolved"}`),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "negatif: opened twice",
+			events:  []model.WalletEvent{opened, walletEvent(2, model.WalletEventOpened, `{}`)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agg := &walletAggregate{}
+
+			var err error
+			for _, e := range tt.events {
+				if err = agg.apply(e); err != nil {
+					break
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("walletAggregate.apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if agg.ID != 3 || agg.UserID != 1 || agg.Address != "addr" || agg.Kind != model.WalletKindSettlement || agg.Balance != tt.wantBalance || agg.Status != tt.wantStatus || agg.BlockCredits != (tt.wantStatus == model.WalletStatusFrozen) || agg.Version != int64(len(tt.events)) {
+				t.Errorf("walletAggregate.apply() = %+v", agg)
+			}
+		})
+	}
+}
+
+func Test_eventSourcedWalletRepository_AddWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx         = context.Background()
+		repo        = NewEventSourcedWalletRepository(db, 100)
+		insertQuery = "INSERT INTO wallet \\(address, balance, user_id, kind, version\\) VALUES \\(\\?, 0, \\?, \\?, 1\\)"
+		eventQuery  = "INSERT INTO wallet_event \\(wallet_id, version, type, data, created_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?\\)"
+	)
+
+	mock.ExpectBegin()
+	tx, _ := db.Begin()
+
+	mock.ExpectExec(insertQuery).WithArgs("settlement", int64(5), model.WalletKindSettlement).WillReturnResult(sqlmock.NewResult(20, 1))
+	mock.ExpectExec(eventQuery).WithArgs(int64(20), int64(1), model.WalletEventOpened, `{"user_id":5,"address":"settlement","kind":"settlement"}`, sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	got, err := repo.AddWallet(ctx, tx, model.Wallet{Address: "settlement", UserID: 5, Kind: model.WalletKindSettlement})
+	if err != nil || got != 20 {
+		t.Fatalf("eventSourcedWalletRepository.AddWallet() = %d, %v, want 20", got, err)
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_eventSourcedWalletRepository_ReadByIDForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx           = context.Background()
+		repo          = NewEventSourcedWalletRepository(db, 100)
+		lockQuery     = "SELECT id, version FROM wallet WHERE id = \\? FOR UPDATE"
+		snapshotQuery = "SELECT state FROM wallet_snapshot WHERE wallet_id = \\?"
+		eventsQuery   = "SELECT (.+) FROM wallet_event WHERE wallet_id = \\? AND version > \\? ORDER BY version"
+		eventColumns  = []string{"id", "wallet_id", "version", "type", "data", "created_at"}
+		now           = time.Now().UTC().Truncate(time.Second)
+		created       = now.Add(-time.Hour)
+	)
+
+	state := `{"id":3,"user_id":1,"address":"addr","kind":"personal","balance":100000,"status":"active","version":2,"created_at":"` + created.Format(time.RFC3339) + `"}`
+
+	tests := []struct {
+		name              string
+		projectionVersion int64
+		want              *model.Wallet
+		wantErr           bool
+	}{
+		{
+			name:              "success",
+			projectionVersion: 4,
+			want:              &model.Wallet{ID: 3, UserID: 1, Address: "addr", Kind: model.WalletKindPersonal, Balance: 120000, Status: model.WalletStatusActive, Version: 4, CreatedAt: created, UpdatedAt: now},
+		},
+		{
+			name:              "projection behind",
+			projectionVersion: 3,
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			tx, _ := db.Begin()
+
+			mock.ExpectQuery(lockQuery).WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(3, tt.projectionVersion))
+			mock.ExpectQuery(snapshotQuery).WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"state"}).AddRow(state))
+			mock.ExpectQuery(eventsQuery).WithArgs(int64(3), int64(2)).WillReturnRows(sqlmock.NewRows(eventColumns).
+				AddRow(7, 3, 3, model.WalletEventCredited, `{"transaction_id":5,"type":"top_up","amount":50000}`, now).
+				AddRow(8, 3, 4, model.WalletEventDebited, `{"transaction_id":6,"type":"payment","amount":30000}`, now))
+
+			got, err := repo.ReadByIDForUpdate(ctx, tx, 3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("eventSourcedWalletRepository.ReadByIDForUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("eventSourcedWalletRepository.ReadByIDForUpdate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_eventSourcedWalletRepository_UpdateBalance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewEventSourcedWalletRepository(db, 100)
+		query = "UPDATE wallet SET balance=\\?, version=version\\+1, updated_at=\\? WHERE id=\\? AND version=\\?"
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "changed since loaded",
+			affected: 0,
+			wantErr:  cs.ErrConcurrentUpdate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			tx, _ := db.Begin()
+
+			mock.ExpectExec(query).WithArgs(float64(70000), sqlmock.AnyArg(), int64(3), int64(4)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			if err := repo.UpdateBalance(ctx, tx, model.Wallet{ID: 3, Balance: 70000, Version: 4}); err != tt.wantErr {
+				t.Errorf("eventSourcedWalletRepository.UpdateBalance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_eventSourcedWalletRepository_WriteTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx          = context.Background()
+		repo         = NewEventSourcedWalletRepository(db, 100)
+		entryQuery   = "INSERT INTO wallet_transaction"
+		versionQuery = "SELECT version FROM wallet WHERE id = \\?"
+		eventQuery   = "INSERT INTO wallet_event"
+		trx          = model.Transaction{WalletID: 3, Type: model.TransactionTypePayment, Amount: -30000, BalanceAfter: 70000, Reference: "REF"}
+	)
+
+	tests := []struct {
+		name     string
+		eventErr error
+		want     int64
+		wantErr  error
+	}{
+		{
+			name: "success",
+			want: 9,
+		},
+		{
+			name:     "appended elsewhere",
+			eventErr: &mysql.MySQLError{Number: mysqlDuplicateEntry, Message: "Duplicate entry"},
+			wantErr:  cs.ErrConcurrentUpdate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			tx, _ := db.Begin()
+
+			mock.ExpectExec(entryQuery).WillReturnResult(sqlmock.NewResult(9, 1))
+			mock.ExpectQuery(versionQuery).WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+			event := mock.ExpectExec(eventQuery).
+				WithArgs(int64(3), int64(5), model.WalletEventDebited, `{"transaction_id":9,"type":"payment","amount":30000,"reference":"REF","description":""}`, sqlmock.AnyArg())
+			if tt.eventErr != nil {
+				event.WillReturnError(tt.eventErr)
+			} else {
+				event.WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			got, err := repo.WriteTransaction(ctx, tx, trx)
+			if err != tt.wantErr {
+				t.Fatalf("eventSourcedWalletRepository.WriteTransaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("eventSourcedWalletRepository.WriteTransaction() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_eventSourcedWalletRepository_UpdateStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx          = context.Background()
+		repo         = NewEventSourcedWalletRepository(db, 100)
+		projectQuery = "UPDATE wallet SET status=\\?, block_credits=\\?, version=version\\+1, updated_at=\\? WHERE id=\\? AND version=\\?"
+		historyQuery = "INSERT INTO wallet_status_change"
+		eventQuery   = "INSERT INTO wallet_event"
+		change       = model.WalletStatusChange{WalletID: 3, FromStatus: model.WalletStatusActive, ToStatus: model.WalletStatusFrozen, BlockCredits: true, Reason: model.WalletReasonLegalOrder, ChangedBy: 7}
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+		},
+		{
+			name:     "changed since loaded",
+			affected: 0,
+			wantErr:  cs.ErrConcurrentUpdate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			tx, _ := db.Begin()
+
+			mock.ExpectExec(projectQuery).WithArgs(model.WalletStatusFrozen, true, sqlmock.AnyArg(), int64(3), int64(4)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.wantErr == nil {
+				mock.ExpectExec(historyQuery).WithArgs(int64(3), model.WalletStatusActive, model.WalletStatusFrozen, true, model.WalletReasonLegalOrder, "", int64(7)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(eventQuery).
+					WithArgs(int64(3), int64(5), model.WalletEventFrozen, `{"reason":"legal_order","block_credits":true,"changed_by":7}`, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			if err := repo.UpdateStatus(ctx, tx, model.Wallet{ID: 3, Version: 4}, change); err != tt.wantErr {
+				t.Errorf("eventSourcedWalletRepository.UpdateStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
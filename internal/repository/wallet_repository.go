@@ -3,14 +3,40 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
+	cs "github.com/cecepsprd/starworks-test/constans"
 	"github.com/cecepsprd/starworks-test/internal/model"
 )
 
 type WalletRepository interface {
+	BeginTx(ctx context.Context) *sql.Tx
 	ReadBalance(ctx context.Context, req model.CheckBalanceRequest) (*model.Wallet, error)
-	UpdateBalance(context.Context, model.Wallet) error
-	AddWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error
+	ReadBalanceForUpdate(ctx context.Context, tx *sql.Tx, req model.CheckBalanceRequest) (*model.Wallet, error)
+	ReadByIDForUpdate(ctx context.Context, tx *sql.Tx, walletID int64) (*model.Wallet, error)
+	ReadByUserID(ctx context.Context, userID int64, kind string) ([]model.Wallet, error)
+	UpdateBalance(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error
+	AddWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) (walletID int64, err error)
+	WriteTransaction(ctx context.Context, tx *sql.Tx, trx model.Transaction) (trxID int64, err error)
+	ReadTransactionByID(ctx context.Context, trxID int64) (*model.Transaction, error)
+	ReadTransactions(ctx context.Context, walletID int64) ([]model.Transaction, error)
+	ReadTransactionsBetween(ctx context.Context, walletID int64, from, to time.Time) ([]model.Transaction, error)
+	ReadBalanceAt(ctx context.Context, walletID int64, at time.Time) (float64, error)
+	SumTransactionVolume(ctx context.Context, tx *sql.Tx, walletID int64, since time.Time) (float64, error)
+	ReadLedgerBalances(ctx context.Context) ([]model.LedgerBalance, error)
+	UpdateStatus(ctx context.Context, tx *sql.Tx, wallet model.Wallet, change model.WalletStatusChange) error
+	ReadStatusChanges(ctx context.Context, walletID int64) ([]model.WalletStatusChange, error)
+	ReadTransactionPage(ctx context.Context, walletID int64, page model.PageRequest) (transactions []model.Transaction, total int64, err error)
+	WriteAdjustment(ctx context.Context, tx *sql.Tx, adjustment model.Adjustment) (adjustmentID int64, err error)
+}
+
+// transactionSorts are the fields ledger entries can be sorted by.
+var transactionSorts = map[string]string{
+	"id":         "id",
+	"type":       "type",
+	"amount":     "amount",
+	"created_at": "created_at",
 }
 
 type mysqlWalletRepository struct {
@@ -23,32 +49,40 @@ func NewWalletRepository(db *sql.DB) WalletRepository {
 	}
 }
 
-func (m *mysqlWalletRepository) AddWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
-	query := `INSERT INTO wallet (address, balance, user_id) VALUES (?, ?, ?)`
+func (m *mysqlWalletRepository) BeginTx(ctx context.Context) *sql.Tx {
+	tx, _ := m.db.BeginTx(ctx, nil)
+	return tx
+}
+
+func (m *mysqlWalletRepository) AddWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) (walletID int64, err error) {
+	query := `INSERT INTO wallet (address, balance, user_id, kind) VALUES (?, ?, ?, ?)`
 
 	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, wallet.Address, wallet.Balance, wallet.UserID)
+	res, err := stmt.ExecContext(ctx, wallet.Address, wallet.Balance, wallet.UserID, wallet.Kind)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return res.LastInsertId()
 }
 
 func (m *mysqlWalletRepository) ReadBalance(ctx context.Context, req model.CheckBalanceRequest) (*model.Wallet, error) {
-	query := `SELECT user_id, address, balance FROM wallet WHERE address = ? and user_id = ?`
+	query := `SELECT id, user_id, address, balance, status, block_credits FROM wallet WHERE address = ? and user_id = ?`
 
 	var wallet model.Wallet
 
 	err := m.db.QueryRowContext(ctx, query, req.Address, req.UserID).Scan(
+		&wallet.ID,
 		&wallet.UserID,
 		&wallet.Address,
 		&wallet.Balance,
+		&wallet.Status,
+		&wallet.BlockCredits,
 	)
 
 	if err != nil && err != sql.ErrNoRows {
@@ -58,18 +92,334 @@ func (m *mysqlWalletRepository) ReadBalance(ctx context.Context, req model.Check
 	return &wallet, nil
 }
 
-func (m *mysqlWalletRepository) UpdateBalance(ctx context.Context, wallet model.Wallet) error {
-	query := `UPDATE wallet SET balance=? WHERE address = ? AND user_id = ?`
+// ReadBalanceForUpdate locks the wallet row until tx ends, so the balance it
+// returns stays valid for the rest of the transaction.
+func (m *mysqlWalletRepository) ReadBalanceForUpdate(ctx context.Context, tx *sql.Tx, req model.CheckBalanceRequest) (*model.Wallet, error) {
+	query := `SELECT id, user_id, address, balance, status, block_credits FROM wallet WHERE address = ? and user_id = ? FOR UPDATE`
+
+	var wallet model.Wallet
+
+	err := tx.QueryRowContext(ctx, query, req.Address, req.UserID).Scan(
+		&wallet.ID,
+		&wallet.UserID,
+		&wallet.Address,
+		&wallet.Balance,
+		&wallet.Status,
+		&wallet.BlockCredits,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, cs.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// ReadByIDForUpdate locks a wallet by its id, for operations that start from
+// a ledger entry rather than from the wallet owner. System wallets have no
+// owner and are returned with a zero UserID.
+func (m *mysqlWalletRepository) ReadByIDForUpdate(ctx context.Context, tx *sql.Tx, walletID int64) (*model.Wallet, error) {
+	query := `SELECT id, COALESCE(user_id, 0), address, balance, status, block_credits FROM wallet WHERE id = ? FOR UPDATE`
+
+	var wallet model.Wallet
+
+	err := tx.QueryRowContext(ctx, query, walletID).Scan(
+		&wallet.ID,
+		&wallet.UserID,
+		&wallet.Address,
+		&wallet.Balance,
+		&wallet.Status,
+		&wallet.BlockCredits,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, cs.ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// ReadByUserID returns the wallets of one kind a user owns.
+func (m *mysqlWalletRepository) ReadByUserID(ctx context.Context, userID int64, kind string) ([]model.Wallet, error) {
+	query := `SELECT id, user_id, kind, address, balance, status, block_credits, created_at, updated_at FROM wallet WHERE user_id = ? AND kind = ?`
 
-	stmt, err := m.db.PrepareContext(ctx, query)
+	rows, err := m.db.QueryContext(ctx, query, userID, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := []model.Wallet{}
+	for rows.Next() {
+		var wallet model.Wallet
+		err := rows.Scan(
+			&wallet.ID,
+			&wallet.UserID,
+			&wallet.Kind,
+			&wallet.Address,
+			&wallet.Balance,
+			&wallet.Status,
+			&wallet.BlockCredits,
+			&wallet.CreatedAt,
+			&wallet.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	return wallets, rows.Err()
+}
+
+func (m *mysqlWalletRepository) UpdateBalance(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
+	query := `UPDATE wallet SET balance=?, updated_at=? WHERE id = ?`
+
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return err
 	}
+	defer stmt.Close()
 
-	_, err = stmt.ExecContext(ctx, wallet.Balance, wallet.Address, wallet.UserID)
+	_, err = stmt.ExecContext(ctx, wallet.Balance, time.Now(), wallet.ID)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (m *mysqlWalletRepository) WriteTransaction(ctx context.Context, tx *sql.Tx, trx model.Transaction) (trxID int64, err error) {
+	query := `INSERT INTO wallet_transaction (wallet_id, type, amount, balance_after, reference, description) VALUES (?, ?, ?, ?, ?, ?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, trx.WalletID, trx.Type, trx.Amount, trx.BalanceAfter, trx.Reference, trx.Description)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlWalletRepository) ReadTransactionByID(ctx context.Context, trxID int64) (*model.Transaction, error) {
+	query := `SELECT id, wallet_id, type, amount, balance_after, reference, description, created_at FROM wallet_transaction WHERE id = ?`
+
+	var trx model.Transaction
+
+	err := m.db.QueryRowContext(ctx, query, trxID).Scan(
+		&trx.ID,
+		&trx.WalletID,
+		&trx.Type,
+		&trx.Amount,
+		&trx.BalanceAfter,
+		&trx.Reference,
+		&trx.Description,
+		&trx.CreatedAt,
+	)
+
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return &trx, nil
+}
+
+func (m *mysqlWalletRepository) ReadTransactions(ctx context.Context, walletID int64) ([]model.Transaction, error) {
+	query := `SELECT id, wallet_id, type, amount, balance_after, reference, description, created_at FROM wallet_transaction WHERE wallet_id = ? ORDER BY id`
+
+	return m.readTransactions(ctx, query, walletID)
+}
+
+// ReadTransactionsBetween returns the ledger entries of a wallet made from
+// from up to but not including to, oldest first.
+func (m *mysqlWalletRepository) ReadTransactionsBetween(ctx context.Context, walletID int64, from, to time.Time) ([]model.Transaction, error) {
+	query := `SELECT id, wallet_id, type, amount, balance_after, reference, description, created_at FROM wallet_transaction WHERE wallet_id = ? AND created_at >= ? AND created_at < ? ORDER BY id`
+
+	return m.readTransactions(ctx, query, walletID, from, to)
+}
+
+// ReadBalanceAt returns the balance of a wallet after the last ledger entry
+// made before at, or zero if there is none.
+func (m *mysqlWalletRepository) ReadBalanceAt(ctx context.Context, walletID int64, at time.Time) (float64, error) {
+	query := `SELECT balance_after FROM wallet_transaction WHERE wallet_id = ? AND created_at < ? ORDER BY id DESC LIMIT 1`
+
+	var balance float64
+	err := m.db.QueryRowContext(ctx, query, walletID, at).Scan(&balance)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (m *mysqlWalletRepository) readTransactions(ctx context.Context, query string, args ...interface{}) ([]model.Transaction, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []model.Transaction{}
+	for rows.Next() {
+		var trx model.Transaction
+		err := rows.Scan(
+			&trx.ID,
+			&trx.WalletID,
+			&trx.Type,
+			&trx.Amount,
+			&trx.BalanceAfter,
+			&trx.Reference,
+			&trx.Description,
+			&trx.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, trx)
+	}
+
+	return transactions, rows.Err()
+}
+
+// SumTransactionVolume returns the total amount sent out of a wallet since
+// the given time, counting only the ledger types in model.DailyLimitTypes.
+func (m *mysqlWalletRepository) SumTransactionVolume(ctx context.Context, tx *sql.Tx, walletID int64, since time.Time) (float64, error) {
+	query := `SELECT COALESCE(SUM(ABS(amount)), 0) FROM wallet_transaction WHERE wallet_id = ? AND amount < 0 AND type IN (?` + strings.Repeat(",?", len(model.DailyLimitTypes)-1) + `) AND created_at >= ?`
+
+	args := []interface{}{walletID}
+	for _, t := range model.DailyLimitTypes {
+		args = append(args, t)
+	}
+	args = append(args, since)
+
+	var volume float64
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&volume); err != nil {
+		return 0, err
+	}
+
+	return volume, nil
+}
+
+// ReadLedgerBalances returns the stored balance of every wallet next to the
+// balance recomputed from its ledger entries.
+func (m *mysqlWalletRepository) ReadLedgerBalances(ctx context.Context) ([]model.LedgerBalance, error) {
+	query := `SELECT w.id, COALESCE(w.user_id, 0), COALESCE(w.balance, 0), COALESCE(SUM(t.amount), 0), COUNT(t.id)
+		FROM wallet w LEFT JOIN wallet_transaction t ON t.wallet_id = w.id GROUP BY w.id ORDER BY w.id`
+
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []model.LedgerBalance{}
+	for rows.Next() {
+		var balance model.LedgerBalance
+		if err := rows.Scan(&balance.WalletID, &balance.UserID, &balance.Balance, &balance.LedgerBalance, &balance.Entries); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, rows.Err()
+}
+
+// UpdateStatus moves a wallet locked in tx to the status of change, and
+// records the change in its history.
+func (m *mysqlWalletRepository) UpdateStatus(ctx context.Context, tx *sql.Tx, wallet model.Wallet, change model.WalletStatusChange) error {
+	query := `UPDATE wallet SET status=?, block_credits=?, updated_at=? WHERE id=?`
+
+	if _, err := tx.ExecContext(ctx, query, change.ToStatus, change.BlockCredits, time.Now(), wallet.ID); err != nil {
+		return err
+	}
+
+	return writeStatusChange(ctx, tx, change)
+}
+
+func writeStatusChange(ctx context.Context, tx *sql.Tx, change model.WalletStatusChange) error {
+	query := `INSERT INTO wallet_status_change (wallet_id, from_status, to_status, block_credits, reason, note, changed_by) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := tx.ExecContext(ctx, query, change.WalletID, change.FromStatus, change.ToStatus, change.BlockCredits, change.Reason, change.Note, change.ChangedBy)
+
+	return err
+}
+
+// ReadStatusChanges returns the status history of a wallet, oldest first.
+func (m *mysqlWalletRepository) ReadStatusChanges(ctx context.Context, walletID int64) ([]model.WalletStatusChange, error) {
+	query := `SELECT id, wallet_id, from_status, to_status, block_credits, reason, note, changed_by, created_at FROM wallet_status_change WHERE wallet_id = ? ORDER BY id`
+
+	rows, err := m.db.QueryContext(ctx, query, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []model.WalletStatusChange{}
+	for rows.Next() {
+		var change model.WalletStatusChange
+		err := rows.Scan(
+			&change.ID,
+			&change.WalletID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.BlockCredits,
+			&change.Reason,
+			&change.Note,
+			&change.ChangedBy,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// ReadTransactionPage returns a page of the ledger entries of a wallet, and
+// how many there are in all.
+func (m *mysqlWalletRepository) ReadTransactionPage(ctx context.Context, walletID int64, page model.PageRequest) ([]model.Transaction, int64, error) {
+	order, limit, err := orderBy(page, transactionSorts, "id")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err = m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM wallet_transaction WHERE wallet_id = ?`, walletID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, wallet_id, type, amount, balance_after, reference, description, created_at FROM wallet_transaction WHERE wallet_id = ?` + order
+
+	transactions, err := m.readTransactions(ctx, query, append([]interface{}{walletID}, limit...)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
+// WriteAdjustment records who made the manual adjustment posted as a ledger
+// entry in tx.
+func (m *mysqlWalletRepository) WriteAdjustment(ctx context.Context, tx *sql.Tx, adjustment model.Adjustment) (adjustmentID int64, err error) {
+	query := `INSERT INTO wallet_adjustment (wallet_id, transaction_id, amount, reason, adjusted_by) VALUES (?, ?, ?, ?, ?)`
+
+	res, err := tx.ExecContext(ctx, query, adjustment.WalletID, adjustment.TransactionID, adjustment.Amount, adjustment.Reason, adjustment.AdjustedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/cecepsprd/starworks-test/internal/model"
@@ -21,7 +23,7 @@ func Test_mysqlWalletRepository_AddWallet(t *testing.T) {
 	var (
 		ctx   = context.Background()
 		repo  = NewWalletRepository(db)
-		query = "INSERT INTO wallet \\(address, balance, user_id\\) VALUES \\(\\?, \\?, \\?\\)"
+		query = "INSERT INTO wallet \\(address, balance, user_id, kind\\) VALUES \\(\\?, \\?, \\?, \\?\\)"
 	)
 
 	tests := []struct {
@@ -37,6 +39,7 @@ func Test_mysqlWalletRepository_AddWallet(t *testing.T) {
 				Address: "abcde",
 				Balance: 1000,
 				UserID:  5,
+				Kind:    model.WalletKindPersonal,
 			},
 			wantErr: false,
 		},
@@ -58,12 +61,12 @@ func Test_mysqlWalletRepository_AddWallet(t *testing.T) {
 				mock.ExpectPrepare(query).ExpectExec().WillReturnError(fmt.Errorf("some error"))
 			} else {
 				mock.ExpectBegin()
-				mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectPrepare(query).ExpectExec().WithArgs(tt.args.Address, tt.args.Balance, tt.args.UserID, tt.args.Kind).WillReturnResult(sqlmock.NewResult(1, 1))
 			}
 
 			tx, _ := db.BeginTx(ctx, nil)
 
-			if err := tt.fields.AddWallet(ctx, tx, tt.args); (err != nil) != tt.wantErr {
+			if _, err := tt.fields.AddWallet(ctx, tx, tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("mysqlUserRepository.AddWallet() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -80,7 +83,7 @@ func Test_mysqlWalletRepository_ReadBalance(t *testing.T) {
 	var (
 		ctx   = context.Background()
 		repo  = NewWalletRepository(db)
-		query = "SELECT user_id, address, balance FROM wallet WHERE address = \\? and user_id = \\?"
+		query = "SELECT id, user_id, address, balance, status, block_credits FROM wallet WHERE address = \\? and user_id = \\?"
 	)
 
 	tests := []struct {
@@ -98,9 +101,12 @@ func Test_mysqlWalletRepository_ReadBalance(t *testing.T) {
 				UserID:  1,
 			},
 			want: &model.Wallet{
-				UserID:  1,
-				Address: "abcde",
-				Balance: 1000,
+				ID:           3,
+				UserID:       1,
+				Address:      "abcde",
+				Balance:      1000,
+				Status:       model.WalletStatusFrozen,
+				BlockCredits: true,
 			},
 			wantErr: false,
 		},
@@ -110,7 +116,7 @@ func Test_mysqlWalletRepository_ReadBalance(t *testing.T) {
 			if tt.wantErr {
 				mock.ExpectQuery(query).WithArgs(tt.args.Address, tt.args.UserID).WillReturnError(fmt.Errorf("some error"))
 			} else {
-				rows := sqlmock.NewRows([]string{"user_id", "address", "balance"}).AddRow(1, "abcde", 1000)
+				rows := sqlmock.NewRows([]string{"id", "user_id", "address", "balance", "status", "block_credits"}).AddRow(3, 1, "abcde", 1000, "frozen", true)
 				mock.ExpectQuery(query).WithArgs(tt.args.Address, tt.args.UserID).WillReturnRows(rows)
 			}
 			got, err := tt.fields.ReadBalance(ctx, tt.args)
@@ -135,7 +141,7 @@ func Test_mysqlWalletRepository_UpdateBalance(t *testing.T) {
 	var (
 		ctx   = context.Background()
 		repo  = NewWalletRepository(db)
-		query = "UPDATE wallet SET balance=\\? WHERE address = \\? AND user_id = \\?"
+		query = "UPDATE wallet SET balance=\\?, updated_at=\\? WHERE id = \\?"
 	)
 
 	tests := []struct {
@@ -148,6 +154,7 @@ func Test_mysqlWalletRepository_UpdateBalance(t *testing.T) {
 			name:   "positif",
 			fields: repo,
 			args: model.Wallet{
+				ID:      3,
 				Address: "addressx",
 				UserID:  1,
 			},
@@ -157,6 +164,7 @@ func Test_mysqlWalletRepository_UpdateBalance(t *testing.T) {
 			name:   "negatif",
 			fields: repo,
 			args: model.Wallet{
+				ID:      3,
 				Address: "addressx",
 				UserID:  1,
 			},
@@ -165,15 +173,414 @@ func Test_mysqlWalletRepository_UpdateBalance(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
 			if tt.wantErr {
 				mock.ExpectPrepare(query).ExpectExec().WillReturnError(fmt.Errorf("somer error"))
 			} else {
-				mock.ExpectPrepare(query).ExpectExec().WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectPrepare(query).ExpectExec().WithArgs(tt.args.Balance, sqlmock.AnyArg(), tt.args.ID).WillReturnResult(sqlmock.NewResult(1, 1))
 			}
 
-			if err := tt.fields.UpdateBalance(ctx, tt.args); (err != nil) != tt.wantErr {
+			tx, _ := db.BeginTx(ctx, nil)
+
+			if err := tt.fields.UpdateBalance(ctx, tx, tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("mysqlWalletRepository.UpdateBalance() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func Test_mysqlWalletRepository_ReadBalanceForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewWalletRepository(db)
+		query = "SELECT id, user_id, address, balance, status, block_credits FROM wallet WHERE address = \\? and user_id = \\? FOR UPDATE"
+	)
+
+	tests := []struct {
+		name    string
+		fields  WalletRepository
+		args    model.CheckBalanceRequest
+		want    *model.Wallet
+		wantErr bool
+	}{
+		{
+			name:   "success",
+			fields: repo,
+			args: model.CheckBalanceRequest{
+				Address: "abcde",
+				UserID:  1,
+			},
+			want: &model.Wallet{
+				ID:           3,
+				UserID:       1,
+				Address:      "abcde",
+				Balance:      1000,
+				Status:       model.WalletStatusFrozen,
+				BlockCredits: true,
+			},
+			wantErr: false,
+		},
+		{
+			name:   "wallet not found",
+			fields: repo,
+			args: model.CheckBalanceRequest{
+				Address: "abcde",
+				UserID:  1,
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(tt.args.Address, tt.args.UserID).WillReturnError(sql.ErrNoRows)
+			} else {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "address", "balance", "status", "block_credits"}).AddRow(3, 1, "abcde", 1000, "frozen", true)
+				mock.ExpectQuery(query).WithArgs(tt.args.Address, tt.args.UserID).WillReturnRows(rows)
+			}
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			got, err := tt.fields.ReadBalanceForUpdate(ctx, tx, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlWalletRepository.ReadBalanceForUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlWalletRepository.ReadBalanceForUpdate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlWalletRepository_WriteTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewWalletRepository(db)
+		query = "INSERT INTO wallet_transaction \\(wallet_id, type, amount, balance_after, reference, description\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)"
+	)
+
+	tests := []struct {
+		name    string
+		fields  WalletRepository
+		args    model.Transaction
+		wantErr bool
+	}{
+		{
+			name:   "success",
+			fields: repo,
+			args: model.Transaction{
+				WalletID:     3,
+				Type:         model.TransactionTypeTopUp,
+				Amount:       1000,
+				BalanceAfter: 1000,
+				Reference:    "ref",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "failed",
+			fields: repo,
+			args: model.Transaction{
+				WalletID: 3,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			if tt.wantErr {
+				mock.ExpectPrepare(query).ExpectExec().WillReturnError(fmt.Errorf("some error"))
+			} else {
+				mock.ExpectPrepare(query).ExpectExec().
+					WithArgs(tt.args.WalletID, tt.args.Type, tt.args.Amount, tt.args.BalanceAfter, tt.args.Reference, tt.args.Description).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+
+			tx, _ := db.BeginTx(ctx, nil)
+
+			if _, err := tt.fields.WriteTransaction(ctx, tx, tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("mysqlWalletRepository.WriteTransaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_mysqlWalletRepository_ReadByUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewWalletRepository(db)
+		query = "SELECT id, user_id, kind, address, balance, status, block_credits, created_at, updated_at FROM wallet WHERE user_id = \\? AND kind = \\?"
+		now   = time.Now()
+	)
+
+	tests := []struct {
+		name    string
+		fields  WalletRepository
+		args    int64
+		want    []model.Wallet
+		wantErr bool
+	}{
+		{
+			name:   "success",
+			fields: repo,
+			args:   1,
+			want: []model.Wallet{
+				{ID: 3, UserID: 1, Kind: model.WalletKindPersonal, Address: "abcde", Balance: 1000, Status: model.WalletStatusActive, CreatedAt: now, UpdatedAt: now},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			fields:  repo,
+			args:    1,
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(tt.args, model.WalletKindPersonal).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "kind", "address", "balance", "status", "block_credits", "created_at", "updated_at"}).AddRow(3, 1, "personal", "abcde", 1000, "active", false, now, now)
+				mock.ExpectQuery(query).WithArgs(tt.args, model.WalletKindPersonal).WillReturnRows(rows)
+			}
+
+			got, err := tt.fields.ReadByUserID(ctx, tt.args, model.WalletKindPersonal)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlWalletRepository.ReadByUserID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlWalletRepository.ReadByUserID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlWalletRepository_ReadLedgerBalances(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewWalletRepository(db)
+		query   = "SELECT (.+) FROM wallet w LEFT JOIN wallet_transaction t ON t.wallet_id = w.id GROUP BY w.id"
+		columns = []string{"id", "user_id", "balance", "ledger_balance", "entries"}
+	)
+
+	tests := []struct {
+		name    string
+		want    []model.LedgerBalance
+		wantErr bool
+	}{
+		{
+			name: "success",
+			want: []model.LedgerBalance{
+				{WalletID: 1, UserID: 1, Balance: 50000, LedgerBalance: 50000, Entries: 3},
+				{WalletID: 2, UserID: 0, Balance: 1000, LedgerBalance: 0, Entries: 0},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				rows := sqlmock.NewRows(columns)
+				for _, b := range tt.want {
+					rows.AddRow(b.WalletID, b.UserID, b.Balance, b.LedgerBalance, b.Entries)
+				}
+				mock.ExpectQuery(query).WillReturnRows(rows)
+			}
+
+			got, err := repo.ReadLedgerBalances(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlWalletRepository.ReadLedgerBalances() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mysqlWalletRepository.ReadLedgerBalances() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlWalletRepository_ReadBalanceAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewWalletRepository(db)
+		query = "SELECT balance_after FROM wallet_transaction WHERE wallet_id = \\? AND created_at < \\? ORDER BY id DESC LIMIT 1"
+		at    = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		want    float64
+		wantErr bool
+	}{
+		{
+			name:    "success",
+			rows:    sqlmock.NewRows([]string{"balance_after"}).AddRow(75000),
+			want:    75000,
+			wantErr: false,
+		},
+		{
+			name:    "no entries before",
+			rows:    sqlmock.NewRows([]string{"balance_after"}),
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name:    "failed",
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(query).WithArgs(int64(3), at).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				mock.ExpectQuery(query).WithArgs(int64(3), at).WillReturnRows(tt.rows)
+			}
+
+			got, err := repo.ReadBalanceAt(ctx, 3, at)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("mysqlWalletRepository.ReadBalanceAt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("mysqlWalletRepository.ReadBalanceAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_mysqlWalletRepository_SumTransactionVolume(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx   = context.Background()
+		repo  = NewWalletRepository(db)
+		query = "SELECT COALESCE\\(SUM\\(ABS\\(amount\\)\\), 0\\) FROM wallet_transaction WHERE wallet_id = \\? AND amount < 0 AND type IN \\(\\?,\\?,\\?,\\?\\) AND created_at >= \\?"
+		since = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(query).
+		WithArgs(int64(3), model.TransactionTypePayment, model.TransactionTypeTransfer, model.TransactionTypeWithdrawal, model.TransactionTypeEscrowFund, since).
+		WillReturnRows(sqlmock.NewRows([]string{"volume"}).AddRow(4500))
+
+	tx, _ := db.BeginTx(ctx, nil)
+
+	got, err := repo.SumTransactionVolume(ctx, tx, 3, since)
+	if err != nil {
+		t.Fatalf("mysqlWalletRepository.SumTransactionVolume() error = %v", err)
+	}
+	if got != 4500 {
+		t.Errorf("mysqlWalletRepository.SumTransactionVolume() = %v, want 4500", got)
+	}
+}
+
+func Test_mysqlWalletRepository_ReadTransactionPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx        = context.Background()
+		repo       = NewWalletRepository(db)
+		countQuery = "SELECT COUNT\\(\\*\\) FROM wallet_transaction WHERE wallet_id = \\?"
+		query      = "SELECT (.+) FROM wallet_transaction WHERE wallet_id = \\? ORDER BY id DESC, id DESC LIMIT \\? OFFSET \\?"
+		columns    = []string{"id", "wallet_id", "type", "amount", "balance_after", "reference", "description", "created_at"}
+		now        = time.Now()
+		page       = model.PageRequest{Page: 1, PerPage: 20, Order: model.SortDesc}
+	)
+
+	tests := []struct {
+		name      string
+		want      []model.Transaction
+		wantTotal int64
+		wantErr   bool
+	}{
+		{
+			name: "success",
+			want: []model.Transaction{
+				{ID: 2, WalletID: 3, Type: model.TransactionTypeAdjustment, Amount: -500, BalanceAfter: 500, Reference: "REF2", Description: "duplicate top-up", CreatedAt: now},
+				{ID: 1, WalletID: 3, Type: model.TransactionTypeTopUp, Amount: 1000, BalanceAfter: 1000, Reference: "REF1", CreatedAt: now},
+			},
+			wantTotal: 2,
+		},
+		{
+			name:    "failed",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.wantErr {
+				mock.ExpectQuery(countQuery).WithArgs(int64(3)).WillReturnError(fmt.Errorf("some error"))
+			} else {
+				rows := sqlmock.NewRows(columns)
+				for _, trx := range tt.want {
+					rows.AddRow(trx.ID, trx.WalletID, trx.Type, trx.Amount, trx.BalanceAfter, trx.Reference, trx.Description, trx.CreatedAt)
+				}
+				mock.ExpectQuery(countQuery).WithArgs(int64(3)).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.wantTotal))
+				mock.ExpectQuery(query).WithArgs(int64(3), 20, 0).WillReturnRows(rows)
+			}
+
+			got, total, err := repo.ReadTransactionPage(ctx, 3, page)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mysqlWalletRepository.ReadTransactionPage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != tt.wantTotal {
+				t.Errorf("mysqlWalletRepository.ReadTransactionPage() = %v, %d, want %v, %d", got, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+type WebhookRepository interface {
+	WriteSubscription(ctx context.Context, sub model.WebhookSubscription) (subID int64, err error)
+	ReadSubscriptionByID(ctx context.Context, subID int64) (*model.WebhookSubscription, error)
+	ReadSubscriptionsByUser(ctx context.Context, userID int64) ([]model.WebhookSubscription, error)
+	ReadSubscriptionsForEvent(ctx context.Context, tx *sql.Tx, eventType string, userID int64) ([]model.WebhookSubscription, error)
+	DeactivateSubscription(ctx context.Context, subID, userID int64) error
+	WriteDelivery(ctx context.Context, tx *sql.Tx, delivery model.WebhookDelivery) (deliveryID int64, err error)
+	ReadDeliveryByID(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error)
+	ReadDeliveriesBySubscription(ctx context.Context, subID int64, limit int) ([]model.WebhookDelivery, error)
+	ReadDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error)
+	ClaimDelivery(ctx context.Context, delivery model.WebhookDelivery, now, leaseUntil time.Time) error
+	UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+}
+
+type mysqlWebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &mysqlWebhookRepository{
+		db: db,
+	}
+}
+
+const (
+	webhookSubscriptionColumns = `id, user_id, url, event_types, all_users, secret, active, created_at`
+	webhookDeliveryColumns     = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_error, response_status, delivered_at, created_at, updated_at`
+)
+
+func (m *mysqlWebhookRepository) WriteSubscription(ctx context.Context, sub model.WebhookSubscription) (subID int64, err error) {
+	query := `INSERT INTO webhook_subscription (user_id, url, event_types, all_users, secret, active) VALUES (?,?,?,?,?,?)`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, sub.UserID, sub.URL, strings.Join(sub.EventTypes, ","), sub.AllUsers, sub.Secret, sub.Active)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+// ReadSubscriptionByID returns a subscription, deactivated ones included.
+func (m *mysqlWebhookRepository) ReadSubscriptionByID(ctx context.Context, subID int64) (*model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscription WHERE id=?`
+
+	sub, err := scanWebhookSubscription(m.db.QueryRowContext(ctx, query, subID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return sub, nil
+}
+
+// ReadSubscriptionsByUser returns the active subscriptions of a user.
+func (m *mysqlWebhookRepository) ReadSubscriptionsByUser(ctx context.Context, userID int64) ([]model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscription WHERE user_id=? AND active=1 ORDER BY id`
+
+	rows, err := m.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return readWebhookSubscriptions(rows)
+}
+
+// ReadSubscriptionsForEvent returns the active subscriptions an event of
+// eventType concerning userID is sent to: the user's own and those for
+// every user.
+func (m *mysqlWebhookRepository) ReadSubscriptionsForEvent(ctx context.Context, tx *sql.Tx, eventType string, userID int64) ([]model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscription WHERE active=1 AND FIND_IN_SET(?, event_types) AND (user_id=? OR all_users=1) ORDER BY id`
+
+	rows, err := tx.QueryContext(ctx, query, eventType, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return readWebhookSubscriptions(rows)
+}
+
+// DeactivateSubscription stops a user's subscription. Its deliveries are
+// kept. It returns sql.ErrNoRows when the user has no such active
+// subscription.
+func (m *mysqlWebhookRepository) DeactivateSubscription(ctx context.Context, subID, userID int64) error {
+	query := `UPDATE webhook_subscription SET active=0 WHERE id=? AND user_id=? AND active=1`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, subID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (m *mysqlWebhookRepository) WriteDelivery(ctx context.Context, tx *sql.Tx, delivery model.WebhookDelivery) (deliveryID int64, err error) {
+	query := `INSERT INTO webhook_delivery (subscription_id, event_id, event_type, payload, status, next_attempt_at) VALUES (?,?,?,?,?,?)`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, delivery.SubscriptionID, delivery.EventID, delivery.EventType, string(delivery.Payload), delivery.Status, delivery.NextAttemptAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (m *mysqlWebhookRepository) ReadDeliveryByID(ctx context.Context, deliveryID int64) (*model.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_delivery WHERE id=?`
+
+	delivery, err := scanWebhookDelivery(m.db.QueryRowContext(ctx, query, deliveryID))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	} else if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return delivery, nil
+}
+
+// ReadDeliveriesBySubscription returns the latest deliveries of a
+// subscription, newest first.
+func (m *mysqlWebhookRepository) ReadDeliveriesBySubscription(ctx context.Context, subID int64, limit int) ([]model.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_delivery WHERE subscription_id=? ORDER BY id DESC LIMIT ?`
+
+	return m.readWebhookDeliveries(ctx, query, subID, limit)
+}
+
+// ReadDueDeliveries returns the pending deliveries whose next attempt is
+// due, oldest first.
+func (m *mysqlWebhookRepository) ReadDueDeliveries(ctx context.Context, now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_delivery WHERE status=? AND next_attempt_at<=? ORDER BY next_attempt_at, id LIMIT ?`
+
+	return m.readWebhookDeliveries(ctx, query, model.WebhookDeliveryPending, now, limit)
+}
+
+// ClaimDelivery pushes the next attempt of a due delivery back to
+// leaseUntil, so no other worker sends it in the meantime. It returns
+// sql.ErrNoRows when the delivery was claimed or attempted since it was
+// read.
+func (m *mysqlWebhookRepository) ClaimDelivery(ctx context.Context, delivery model.WebhookDelivery, now, leaseUntil time.Time) error {
+	query := `UPDATE webhook_delivery SET next_attempt_at=? WHERE id=? AND status=? AND attempts=? AND next_attempt_at<=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, leaseUntil, delivery.ID, model.WebhookDeliveryPending, delivery.Attempts, now)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateDelivery records the outcome of an attempt, or queues a delivery
+// again.
+func (m *mysqlWebhookRepository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	query := `UPDATE webhook_delivery SET status=?, attempts=?, next_attempt_at=?, last_error=?, response_status=?, delivered_at=?, updated_at=? WHERE id=?`
+
+	stmt, err := m.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.ResponseStatus,
+		delivery.DeliveredAt, time.Now(), delivery.ID)
+
+	return err
+}
+
+func (m *mysqlWebhookRepository) readWebhookDeliveries(ctx context.Context, query string, args ...interface{}) ([]model.WebhookDelivery, error) {
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func readWebhookSubscriptions(rows *sql.Rows) ([]model.WebhookSubscription, error) {
+	subs := []model.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func scanWebhookSubscription(row rowScanner) (*model.WebhookSubscription, error) {
+	var (
+		sub        model.WebhookSubscription
+		eventTypes string
+	)
+
+	err := row.Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.URL,
+		&eventTypes,
+		&sub.AllUsers,
+		&sub.Secret,
+		&sub.Active,
+		&sub.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.EventTypes = strings.Split(eventTypes, ",")
+
+	return &sub, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*model.WebhookDelivery, error) {
+	var (
+		delivery    model.WebhookDelivery
+		payload     string
+		deliveredAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastError,
+		&delivery.ResponseStatus,
+		&deliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = []byte(payload)
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &delivery, nil
+}
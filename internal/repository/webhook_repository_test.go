@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_mysqlWebhookRepository_ReadSubscriptionsForEvent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx     = context.Background()
+		repo    = NewWebhookRepository(db)
+		query   = "SELECT (.+) FROM webhook_subscription WHERE active=1 AND FIND_IN_SET\\(\\?, event_types\\) AND \\(user_id=\\? OR all_users=1\\)"
+		columns = []string{"id", "user_id", "url", "event_types", "all_users", "secret", "active", "created_at"}
+		now     = time.Now()
+	)
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, 1, "https://example.com/hook", "top_up.completed,payment.sent", false, "s1", true, now).
+		AddRow(2, 9, "https://example.com/all", "top_up.completed", true, "s2", true, now)
+	mock.ExpectQuery(query).WithArgs(model.WebhookEventTopUpCompleted, int64(1)).WillReturnRows(rows)
+
+	got, err := repo.ReadSubscriptionsForEvent(ctx, tx, model.WebhookEventTopUpCompleted, 1)
+	if err != nil {
+		t.Fatalf("mysqlWebhookRepository.ReadSubscriptionsForEvent() error = %v", err)
+	}
+
+	want := []model.WebhookSubscription{
+		{ID: 1, UserID: 1, URL: "https://example.com/hook", EventTypes: []string{"top_up.completed", "payment.sent"}, Secret: "s1", Active: true, CreatedAt: now},
+		{ID: 2, UserID: 9, URL: "https://example.com/all", EventTypes: []string{"top_up.completed"}, AllUsers: true, Secret: "s2", Active: true, CreatedAt: now},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mysqlWebhookRepository.ReadSubscriptionsForEvent() = %v, want %v", got, want)
+	}
+}
+
+func Test_mysqlWebhookRepository_ClaimDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatal("error creating mock database\n")
+	}
+	defer db.Close()
+
+	var (
+		ctx      = context.Background()
+		repo     = NewWebhookRepository(db)
+		query    = "UPDATE webhook_delivery SET next_attempt_at=\\? WHERE id=\\? AND status=\\? AND attempts=\\? AND next_attempt_at<=\\?"
+		now      = time.Now()
+		lease    = now.Add(time.Minute)
+		delivery = model.WebhookDelivery{ID: 5, Status: model.WebhookDeliveryPending, Attempts: 2}
+	)
+
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "success",
+			affected: 1,
+			wantErr:  nil,
+		},
+		{
+			name:     "claimed elsewhere",
+			affected: 0,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectPrepare(query).ExpectExec().
+				WithArgs(lease, delivery.ID, model.WebhookDeliveryPending, delivery.Attempts, now).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			if err := repo.ClaimDelivery(ctx, delivery, now, lease); err != tt.wantErr {
+				t.Errorf("mysqlWebhookRepository.ClaimDelivery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
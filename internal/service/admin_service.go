@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// AdminService backs the admin back office: finding users and looking into
+// their wallets. Balances are adjusted by hand through ApprovalService.
+type AdminService interface {
+	SearchUsers(ctx context.Context, req model.UserSearchRequest) (*model.Page, error)
+	GetUser(ctx context.Context, userID int64) (*model.AdminUserDetail, error)
+	ListLoginHistory(ctx context.Context, userID int64, page model.PageRequest) (*model.Page, error)
+	ListTransactions(ctx context.Context, walletID int64, page model.PageRequest) (*model.Page, error)
+}
+
+type adminService struct {
+	userRepo   repository.UserRepository
+	walletRepo repository.WalletRepository
+}
+
+func NewAdminService(userRepo repository.UserRepository, walletRepo repository.WalletRepository) AdminService {
+	return &adminService{
+		userRepo:   userRepo,
+		walletRepo: walletRepo,
+	}
+}
+
+func (s *adminService) SearchUsers(ctx context.Context, req model.UserSearchRequest) (*model.Page, error) {
+	req.PageRequest = req.PageRequest.Normalize()
+
+	users, total, err := s.userRepo.SearchUsers(ctx, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	items := make([]model.AdminUser, 0, len(users))
+	for _, user := range users {
+		items = append(items, model.NewAdminUser(user))
+	}
+
+	return newPage(items, req.PageRequest, total), nil
+}
+
+// GetUser returns a user with their wallets and the first page of their
+// login history.
+func (s *adminService) GetUser(ctx context.Context, userID int64) (*model.AdminUserDetail, error) {
+	user, err := s.userRepo.ReadByID(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	detail := &model.AdminUserDetail{User: model.NewAdminUser(*user)}
+
+	detail.Wallets, err = s.walletRepo.ReadByUserID(ctx, userID, model.WalletKindPersonal)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	detail.LoginHistory, _, err = s.userRepo.ReadLoginHistoryPage(ctx, userID, model.PageRequest{}.Normalize())
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return detail, nil
+}
+
+func (s *adminService) ListLoginHistory(ctx context.Context, userID int64, page model.PageRequest) (*model.Page, error) {
+	page = page.Normalize()
+
+	histories, total, err := s.userRepo.ReadLoginHistoryPage(ctx, userID, page)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return newPage(histories, page, total), nil
+}
+
+func (s *adminService) ListTransactions(ctx context.Context, walletID int64, page model.PageRequest) (*model.Page, error) {
+	page = page.Normalize()
+
+	transactions, total, err := s.walletRepo.ReadTransactionPage(ctx, walletID, page)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return newPage(transactions, page, total), nil
+}
+
+func newPage(items interface{}, page model.PageRequest, total int64) *model.Page {
+	return &model.Page{
+		Items:   items,
+		Page:    page.Page,
+		PerPage: page.PerPage,
+		Total:   total,
+	}
+}
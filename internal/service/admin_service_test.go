@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_adminService_SearchUsers(t *testing.T) {
+	ctx := context.Background()
+
+	user := model.User{ID: 2, FirstName: "budi", Username: "budi", Password: "hashed", Token: "token"}
+
+	mockUserRepo := mocks.UserRepository{}
+	mockUserRepo.On("SearchUsers", ctx, mock.MatchedBy(func(req model.UserSearchRequest) bool {
+		return req.Name == "bud" && req.Page == 1 && req.PerPage == model.DefaultPageSize && req.Order == model.SortDesc
+	})).Return([]model.User{user}, int64(1), nil)
+
+	s := NewAdminService(&mockUserRepo, &mocks.WalletRepository{})
+
+	got, err := s.SearchUsers(ctx, model.UserSearchRequest{Name: "bud"})
+	if err != nil {
+		t.Fatalf("adminService.SearchUsers() error = %v", err)
+	}
+
+	want := &model.Page{Items: []model.AdminUser{model.NewAdminUser(user)}, Page: 1, PerPage: model.DefaultPageSize, Total: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("adminService.SearchUsers() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_adminService_GetUser(t *testing.T) {
+	ctx := context.Background()
+
+	user := model.User{ID: 2, FirstName: "budi", Username: "budi"}
+	wallets := []model.Wallet{{ID: 5, UserID: 2, Balance: 1000, Status: model.WalletStatusActive}}
+	logins := []model.LoginHistory{{BrowserName: "firefox", LoginSucceed: 3, UserID: 2}}
+
+	tests := []struct {
+		name    string
+		userID  int64
+		want    *model.AdminUserDetail
+		wantErr error
+	}{
+		{
+			name:   "positif",
+			userID: 2,
+			want:   &model.AdminUserDetail{User: model.NewAdminUser(user), Wallets: wallets, LoginHistory: logins},
+		},
+		{
+			name:    "negatif: not found",
+			userID:  3,
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := mocks.UserRepository{}
+			mockWalletRepo := mocks.WalletRepository{}
+
+			mockUserRepo.On("ReadByID", ctx, int64(2)).Return(&user, nil)
+			mockUserRepo.On("ReadByID", ctx, int64(3)).Return(nil, nil)
+			mockUserRepo.On("ReadLoginHistoryPage", ctx, int64(2), model.PageRequest{}.Normalize()).Return(logins, int64(1), nil)
+			mockWalletRepo.On("ReadByUserID", ctx, int64(2), model.WalletKindPersonal).Return(wallets, nil)
+
+			s := NewAdminService(&mockUserRepo, &mockWalletRepo)
+
+			got, err := s.GetUser(ctx, tt.userID)
+			if err != tt.wantErr {
+				t.Fatalf("adminService.GetUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("adminService.GetUser() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
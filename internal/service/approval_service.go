@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// defaultPendingActionTTL applies when no expiry is configured.
+const defaultPendingActionTTL = 24 * time.Hour
+
+// ApprovalService keeps the queue of sensitive admin actions: manual balance
+// adjustments, direct top-ups, unfreezes and changes to limit rules. One admin proposes an action
+// and a different admin approves or rejects it before it expires. Approved
+// actions are executed in the same transaction as the approval, so they run
+// exactly once.
+type ApprovalService interface {
+	ProposeAdjustment(ctx context.Context, req model.AdjustmentRequest) (*model.PendingAction, error)
+	ProposeTopUp(ctx context.Context, req model.TopUpRequest) (*model.PendingAction, error)
+	ProposeUnfreeze(ctx context.Context, req model.WalletStatusRequest) (*model.PendingAction, error)
+	ProposeLimitRule(ctx context.Context, ruleID int64, req model.LimitRuleRequest, proposer model.User) (*model.PendingAction, error)
+	ListActions(ctx context.Context, status string, page model.PageRequest) (*model.Page, error)
+	GetAction(ctx context.Context, actionID int64) (*model.PendingAction, error)
+	Approve(ctx context.Context, review model.PendingActionReview) (*model.PendingAction, error)
+	Reject(ctx context.Context, review model.PendingActionReview) (*model.PendingAction, error)
+	ExpireActions(ctx context.Context) (int64, error)
+}
+
+type approvalService struct {
+	repo          repository.ApprovalRepository
+	walletService WalletService
+	limitService  LimitService
+	audit         AuditLogger
+	ttl           time.Duration
+}
+
+func NewApprovalService(approvalRepo repository.ApprovalRepository, walletService WalletService, limitService LimitService, audit AuditLogger, ttl time.Duration) ApprovalService {
+	if ttl <= 0 {
+		ttl = defaultPendingActionTTL
+	}
+
+	return &approvalService{
+		repo:          approvalRepo,
+		walletService: walletService,
+		limitService:  limitService,
+		audit:         audit,
+		ttl:           ttl,
+	}
+}
+
+// ProposeAdjustment queues a manual balance adjustment. Once approved it is
+// recorded as made by the admin who proposed it.
+func (s *approvalService) ProposeAdjustment(ctx context.Context, req model.AdjustmentRequest) (*model.PendingAction, error) {
+	return s.propose(ctx, model.PendingActionAdjustment, req.WalletID, req, req.Actor)
+}
+
+// ProposeTopUp queues a direct top-up of the wallet the request names. The
+// wallet is resolved now, so the approving admin sees which one is credited.
+func (s *approvalService) ProposeTopUp(ctx context.Context, req model.TopUpRequest) (*model.PendingAction, error) {
+	resolved, err := s.walletService.ResolveTopUp(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.propose(ctx, model.PendingActionTopUp, resolved.WalletID, resolved, req.Actor)
+}
+
+// ProposeUnfreeze queues a change lifting restrictions on a frozen wallet:
+// unfreezing it, or letting credits into it while it stays frozen.
+func (s *approvalService) ProposeUnfreeze(ctx context.Context, req model.WalletStatusRequest) (*model.PendingAction, error) {
+	return s.propose(ctx, model.PendingActionUnfreeze, req.WalletID, req, req.Actor)
+}
+
+// ProposeLimitRule queues a change to a limit rule, global or for a single
+// user, or a new one when ruleID is zero.
+func (s *approvalService) ProposeLimitRule(ctx context.Context, ruleID int64, req model.LimitRuleRequest, proposer model.User) (*model.PendingAction, error) {
+	if err := s.limitService.ValidateRule(ctx, ruleID, req); err != nil {
+		return nil, err
+	}
+
+	return s.propose(ctx, model.PendingActionLimitRule, ruleID, req, proposer)
+}
+
+func (s *approvalService) propose(ctx context.Context, actionType string, targetID int64, req interface{}, proposer model.User) (*model.PendingAction, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	now := time.Now()
+	action := model.PendingAction{
+		Type:       actionType,
+		TargetID:   targetID,
+		Payload:    payload,
+		Status:     model.PendingActionPending,
+		ProposedBy: proposer.ID,
+		ExpiresAt:  now.Add(s.ttl),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	action.ID, err = s.repo.WritePendingAction(ctx, action)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	// The action cannot run before it is approved, and the approval is
+	// logged with it, so a failure to log the proposal is only logged.
+	s.audit.Log(ctx, nil, model.AuditEntry{
+		Action:     model.AuditActionProposed,
+		ActorID:    proposer.ID,
+		TargetType: model.AuditTargetPendingAction,
+		TargetID:   action.ID,
+		After:      auditValue(action),
+	})
+
+	return &action, nil
+}
+
+func (s *approvalService) ListActions(ctx context.Context, status string, page model.PageRequest) (*model.Page, error) {
+	page = page.Normalize()
+
+	actions, total, err := s.repo.ReadPendingActionPage(ctx, status, page)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return newPage(actions, page, total), nil
+}
+
+func (s *approvalService) GetAction(ctx context.Context, actionID int64) (*model.PendingAction, error) {
+	action, err := s.repo.ReadPendingActionByID(ctx, actionID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if action == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	return action, nil
+}
+
+// Approve executes a pending action exactly as it was proposed and records
+// what it made as its result. An action that fails to execute stays pending.
+func (s *approvalService) Approve(ctx context.Context, review model.PendingActionReview) (*model.PendingAction, error) {
+	return s.review(ctx, review, model.PendingActionApproved, model.AuditActionApproved, s.execute)
+}
+
+func (s *approvalService) Reject(ctx context.Context, review model.PendingActionReview) (*model.PendingAction, error) {
+	return s.review(ctx, review, model.PendingActionRejected, model.AuditActionRejected, nil)
+}
+
+func (s *approvalService) review(ctx context.Context, review model.PendingActionReview, status, auditAction string, execute func(ctx context.Context, tx *sql.Tx, action *model.PendingAction) error) (*model.PendingAction, error) {
+	action, err := s.GetAction(ctx, review.ActionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if action.Status != model.PendingActionPending {
+		return nil, cs.ErrPendingActionReviewed
+	}
+
+	if action.ProposedBy == review.Reviewer.ID {
+		return nil, cs.ErrSelfApproval
+	}
+
+	now := time.Now()
+	if execute != nil && action.Expired(now) {
+		return nil, cs.ErrPendingActionExpired
+	}
+
+	action.Status = status
+	action.ReviewedBy = &review.Reviewer.ID
+	action.ReviewNote = review.Note
+	action.ReviewedAt = &now
+
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if execute != nil {
+			if err := execute(ctx, tx, action); err != nil {
+				return err
+			}
+		}
+
+		return s.close(ctx, tx, *action, review.Reviewer.ID, auditAction)
+	})
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return action, nil
+}
+
+// execute makes the change a pending action proposes in tx, on behalf of the
+// admin who proposed it.
+func (s *approvalService) execute(ctx context.Context, tx *sql.Tx, action *model.PendingAction) error {
+	proposer := model.User{ID: action.ProposedBy}
+
+	var (
+		result interface{}
+		err    error
+	)
+
+	switch action.Type {
+	case model.PendingActionAdjustment:
+		var req model.AdjustmentRequest
+		if err = json.Unmarshal(action.Payload, &req); err != nil {
+			return err
+		}
+		req.WalletID = action.TargetID
+		req.Actor = proposer
+		result, err = s.walletService.Adjust(ctx, tx, req)
+	case model.PendingActionTopUp:
+		var req model.TopUpRequest
+		if err = json.Unmarshal(action.Payload, &req); err != nil {
+			return err
+		}
+		req.WalletID = action.TargetID
+		req.Actor = proposer
+		result, err = s.walletService.TopUp(ctx, tx, req)
+	case model.PendingActionUnfreeze:
+		var req model.WalletStatusRequest
+		if err = json.Unmarshal(action.Payload, &req); err != nil {
+			return err
+		}
+		req.WalletID = action.TargetID
+		req.Actor = proposer
+		result, err = s.walletService.Unfreeze(ctx, tx, req)
+	case model.PendingActionLimitRule:
+		var req model.LimitRuleRequest
+		if err = json.Unmarshal(action.Payload, &req); err != nil {
+			return err
+		}
+		result, err = s.limitService.ApplyRule(ctx, tx, action.TargetID, req)
+	default:
+		return fmt.Errorf("unknown pending action type %q", action.Type)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	action.Result, err = json.Marshal(result)
+
+	return err
+}
+
+// ExpireActions marks the actions still pending at their expiry as expired
+// and returns how many it marked. Expired actions cannot be approved either
+// way; this only updates their status.
+func (s *approvalService) ExpireActions(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	actions, err := s.repo.ReadExpiredPendingActions(ctx, now)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	var expired int64
+	for _, action := range actions {
+		action.Status = model.PendingActionExpired
+		action.ReviewedAt = &now
+
+		err = s.withTx(ctx, func(tx *sql.Tx) error {
+			return s.close(ctx, tx, action, 0, model.AuditActionExpired)
+		})
+
+		if err == cs.ErrPendingActionReviewed {
+			continue
+		} else if err != nil {
+			logger.Log.Error(err.Error())
+			return expired, err
+		}
+
+		expired++
+	}
+
+	return expired, nil
+}
+
+// close records the outcome of a pending action in tx, together with its
+// audit entry.
+func (s *approvalService) close(ctx context.Context, tx *sql.Tx, action model.PendingAction, actorID int64, auditAction string) error {
+	if err := s.repo.UpdatePendingAction(ctx, tx, action); err == sql.ErrNoRows {
+		return cs.ErrPendingActionReviewed
+	} else if err != nil {
+		return err
+	}
+
+	return s.audit.Log(ctx, tx, model.AuditEntry{
+		Action:     auditAction,
+		ActorID:    actorID,
+		TargetType: model.AuditTargetPendingAction,
+		TargetID:   action.ID,
+		Before:     auditValue(map[string]string{"status": model.PendingActionPending}),
+		After:      auditValue(action),
+	})
+}
+
+// withTx runs fn in a database transaction, committing when it succeeds and
+// rolling back otherwise. The wallet changes fn makes are published once it
+// is committed.
+func (s *approvalService) withTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+		s.walletService.AfterTx(tx, err == nil)
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// approvalWallet stands in for the wallet service when executing approved
+// actions, recording the adjustment or top-up it was asked to make.
+type approvalWallet struct {
+	WalletService
+	adjusted *model.AdjustmentRequest
+	toppedUp *model.TopUpRequest
+}
+
+func (w approvalWallet) ResolveTopUp(ctx context.Context, req model.TopUpRequest) (*model.TopUpRequest, error) {
+	req.WalletID = 5
+	return &req, nil
+}
+
+func (w approvalWallet) TopUp(ctx context.Context, tx *sql.Tx, req model.TopUpRequest) (*model.Transaction, error) {
+	*w.toppedUp = req
+	return &model.Transaction{ID: 12, WalletID: req.WalletID, Amount: req.Nominal}, nil
+}
+
+func (w approvalWallet) Adjust(ctx context.Context, tx *sql.Tx, req model.AdjustmentRequest) (*model.Transaction, error) {
+	*w.adjusted = req
+	return &model.Transaction{ID: 11, WalletID: req.WalletID, Amount: req.Amount}, nil
+}
+
+func (w approvalWallet) AfterTx(tx *sql.Tx, committed bool) {}
+
+// auditTrail records the actions logged to it.
+type auditTrail struct {
+	actions *[]string
+}
+
+func (a auditTrail) Log(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error {
+	*a.actions = append(*a.actions, entry.Action)
+	return nil
+}
+
+func Test_approvalService_Approve(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	proposer := int64(9)
+	reviewer := model.User{ID: 2, Role: model.RoleAdmin}
+
+	adjustment := func(status string, expiresAt time.Time) *model.PendingAction {
+		return &model.PendingAction{
+			ID:         4,
+			Type:       model.PendingActionAdjustment,
+			TargetID:   5,
+			Payload:    []byte(`{"amount":500,"reason":"missed top-up"}`),
+			Status:     status,
+			ProposedBy: proposer,
+			ExpiresAt:  expiresAt,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		action    *model.PendingAction
+		reviewer  model.User
+		updateErr error
+		wantErr   error
+	}{
+		{
+			name:     "positif",
+			action:   adjustment(model.PendingActionPending, time.Now().Add(time.Hour)),
+			reviewer: reviewer,
+		},
+		{
+			name:     "negatif: not found",
+			reviewer: reviewer,
+			wantErr:  cs.ErrNotFound,
+		},
+		{
+			name:     "negatif: approved by the proposer",
+			action:   adjustment(model.PendingActionPending, time.Now().Add(time.Hour)),
+			reviewer: model.User{ID: proposer, Role: model.RoleAdmin},
+			wantErr:  cs.ErrSelfApproval,
+		},
+		{
+			name:     "negatif: expired",
+			action:   adjustment(model.PendingActionPending, time.Now().Add(-time.Minute)),
+			reviewer: reviewer,
+			wantErr:  cs.ErrPendingActionExpired,
+		},
+		{
+			name:     "negatif: reviewed already",
+			action:   adjustment(model.PendingActionRejected, time.Now().Add(time.Hour)),
+			reviewer: reviewer,
+			wantErr:  cs.ErrPendingActionReviewed,
+		},
+		{
+			name:      "negatif: approved by someone else meanwhile",
+			action:    adjustment(model.PendingActionPending, time.Now().Add(time.Hour)),
+			reviewer:  reviewer,
+			updateErr: sql.ErrNoRows,
+			wantErr:   cs.ErrPendingActionReviewed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.ApprovalRepository{}
+
+			var (
+				adjusted model.AdjustmentRequest
+				logged   []string
+			)
+
+			tx := beginTx(db, mockDB)
+			if tt.wantErr == nil {
+				mockDB.ExpectCommit()
+			} else {
+				mockDB.ExpectRollback()
+				defer tx.Rollback()
+			}
+
+			mockRepo.On("ReadPendingActionByID", ctx, int64(4)).Return(tt.action, nil)
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("UpdatePendingAction", ctx, tx, mock.Anything).Return(tt.updateErr)
+
+			s := NewApprovalService(&mockRepo, approvalWallet{adjusted: &adjusted}, NewLimitService(&mocks.LimitRepository{}), auditTrail{actions: &logged}, time.Hour)
+			got, err := s.Approve(ctx, model.PendingActionReview{ActionID: 4, Note: "checked", Reviewer: tt.reviewer})
+			if err != tt.wantErr {
+				t.Fatalf("approvalService.Approve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(logged) != 0 {
+					t.Errorf("approvalService.Approve() logged %v", logged)
+				}
+				return
+			}
+
+			if adjusted.WalletID != 5 || adjusted.Amount != 500 || adjusted.Actor.ID != proposer {
+				t.Errorf("approvalService.Approve() adjusted %+v", adjusted)
+			}
+			if got.Status != model.PendingActionApproved || *got.ReviewedBy != reviewer.ID || string(got.Result) == "" {
+				t.Errorf("approvalService.Approve() = %+v", got)
+			}
+			if len(logged) != 1 || logged[0] != model.AuditActionApproved {
+				t.Errorf("approvalService.Approve() logged %v", logged)
+			}
+		})
+	}
+}
+
+func Test_approvalService_TopUp(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	proposer := model.User{ID: 9, Role: model.RoleAdmin}
+	reviewer := model.User{ID: 2, Role: model.RoleAdmin}
+
+	var (
+		proposed model.PendingAction
+		toppedUp model.TopUpRequest
+		logged   []string
+	)
+
+	mockRepo := mocks.ApprovalRepository{}
+	mockRepo.On("WritePendingAction", ctx, mock.MatchedBy(func(action model.PendingAction) bool {
+		proposed = action
+		return true
+	})).Return(int64(4), nil)
+
+	s := NewApprovalService(&mockRepo, approvalWallet{toppedUp: &toppedUp}, NewLimitService(&mocks.LimitRepository{}), auditTrail{actions: &logged}, time.Hour)
+
+	action, err := s.ProposeTopUp(ctx, model.TopUpRequest{Nominal: 1000, Recipient: "budi", Actor: proposer})
+	if err != nil {
+		t.Fatalf("approvalService.ProposeTopUp() error = %v", err)
+	}
+	if action.Type != model.PendingActionTopUp || action.TargetID != 5 || action.ProposedBy != proposer.ID {
+		t.Fatalf("approvalService.ProposeTopUp() = %+v", action)
+	}
+	if toppedUp.Nominal != 0 {
+		t.Fatalf("approvalService.ProposeTopUp() credited the wallet before approval")
+	}
+
+	tx := beginTx(db, mockDB)
+	mockDB.ExpectCommit()
+
+	proposed.ID = action.ID
+	mockRepo.On("ReadPendingActionByID", ctx, int64(4)).Return(&proposed, nil)
+	mockRepo.On("BeginTx", ctx).Return(tx)
+	mockRepo.On("UpdatePendingAction", ctx, tx, mock.Anything).Return(nil)
+
+	got, err := s.Approve(ctx, model.PendingActionReview{ActionID: 4, Reviewer: reviewer})
+	if err != nil {
+		t.Fatalf("approvalService.Approve() error = %v", err)
+	}
+
+	if toppedUp.WalletID != 5 || toppedUp.Nominal != 1000 || toppedUp.Actor.ID != proposer.ID {
+		t.Errorf("approvalService.Approve() topped up %+v", toppedUp)
+	}
+	if got.Status != model.PendingActionApproved || string(got.Result) == "" {
+		t.Errorf("approvalService.Approve() = %+v", got)
+	}
+}
+
+func Test_approvalService_Reject(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	mockRepo := mocks.ApprovalRepository{}
+
+	var (
+		adjusted model.AdjustmentRequest
+		logged   []string
+	)
+
+	tx := beginTx(db, mockDB)
+	mockDB.ExpectCommit()
+
+	// Rejecting makes nothing, so it is still allowed past the expiry.
+	action := &model.PendingAction{ID: 4, Type: model.PendingActionAdjustment, TargetID: 5, Status: model.PendingActionPending, ProposedBy: 9, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	mockRepo.On("ReadPendingActionByID", ctx, int64(4)).Return(action, nil)
+	mockRepo.On("BeginTx", ctx).Return(tx)
+	mockRepo.On("UpdatePendingAction", ctx, tx, mock.MatchedBy(func(a model.PendingAction) bool {
+		return a.Status == model.PendingActionRejected && a.ReviewNote == "wrong wallet" && a.Result == nil
+	})).Return(nil)
+
+	s := NewApprovalService(&mockRepo, approvalWallet{adjusted: &adjusted}, NewLimitService(&mocks.LimitRepository{}), auditTrail{actions: &logged}, time.Hour)
+	got, err := s.Reject(ctx, model.PendingActionReview{ActionID: 4, Note: "wrong wallet", Reviewer: model.User{ID: 2}})
+	if err != nil {
+		t.Fatalf("approvalService.Reject() error = %v", err)
+	}
+
+	if got.Status != model.PendingActionRejected || adjusted.WalletID != 0 {
+		t.Errorf("approvalService.Reject() = %+v, adjusted %+v", got, adjusted)
+	}
+	if len(logged) != 1 || logged[0] != model.AuditActionRejected {
+		t.Errorf("approvalService.Reject() logged %v", logged)
+	}
+}
+
+func Test_approvalService_ExpireActions(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	mockRepo := mocks.ApprovalRepository{}
+
+	var logged []string
+
+	first := beginTx(db, mockDB)
+	second := beginTx(db, mockDB)
+	mockDB.ExpectCommit()
+	mockDB.ExpectRollback()
+
+	actions := []model.PendingAction{
+		{ID: 4, Type: model.PendingActionUnfreeze, TargetID: 5, Status: model.PendingActionPending, ProposedBy: 9},
+		{ID: 6, Type: model.PendingActionUnfreeze, TargetID: 7, Status: model.PendingActionPending, ProposedBy: 9},
+	}
+
+	mockRepo.On("ReadExpiredPendingActions", ctx, mock.Anything).Return(actions, nil)
+	mockRepo.On("BeginTx", ctx).Return(first).Once()
+	mockRepo.On("BeginTx", ctx).Return(second).Once()
+	mockRepo.On("UpdatePendingAction", ctx, first, mock.MatchedBy(func(a model.PendingAction) bool {
+		return a.ID == 4 && a.Status == model.PendingActionExpired
+	})).Return(nil)
+	// The second one was reviewed after it was read.
+	mockRepo.On("UpdatePendingAction", ctx, second, mock.Anything).Return(sql.ErrNoRows)
+
+	s := NewApprovalService(&mockRepo, approvalWallet{}, NewLimitService(&mocks.LimitRepository{}), auditTrail{actions: &logged}, time.Hour)
+	got, err := s.ExpireActions(ctx)
+	if err != nil {
+		t.Fatalf("approvalService.ExpireActions() error = %v", err)
+	}
+
+	if got != 1 {
+		t.Errorf("approvalService.ExpireActions() = %v, want 1", got)
+	}
+	if len(logged) != 1 || logged[0] != model.AuditActionExpired {
+		t.Errorf("approvalService.ExpireActions() logged %v", logged)
+	}
+}
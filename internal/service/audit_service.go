@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+const (
+	// auditSealBatchSize is how many entries Seal chains per transaction.
+	auditSealBatchSize = 100
+	// auditVerifyBatchSize is how many entries Verify reads at a time.
+	auditVerifyBatchSize = 500
+)
+
+// AuditLogger records security and money events in the audit log. Entries
+// logged in the caller's transaction are only kept if it is committed; with
+// no transaction the entry is written and chained right away. The IP, user
+// agent and actor of the request are taken from ctx unless set.
+type AuditLogger interface {
+	Log(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error
+}
+
+type auditService struct {
+	repo         repository.AuditRepository
+	sealInterval time.Duration
+}
+
+// AuditService keeps the audit log. Entries are written unsealed, and Seal
+// chains them in the order they were written, so the transactions logging
+// them never wait on each other for the head of the chain. Seal is expected
+// to run every sealInterval; Verify fails when entries are left unsealed
+// for longer, as nothing would show if they were changed.
+type AuditService interface {
+	AuditLogger
+	Seal(ctx context.Context) (int64, error)
+	ListEntries(ctx context.Context, filter model.AuditFilter) (*model.Page, error)
+	Verify(ctx context.Context) (*model.AuditVerification, error)
+}
+
+func NewAuditService(auditRepo repository.AuditRepository, sealInterval time.Duration) AuditService {
+	return &auditService{
+		repo:         auditRepo,
+		sealInterval: sealInterval,
+	}
+}
+
+func (s *auditService) Log(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) (err error) {
+	if entry.IP == "" {
+		entry.IP = utils.GetContextValue(ctx, cs.CtxClientIP)
+	}
+	if entry.UserAgent == "" {
+		entry.UserAgent = utils.GetContextValue(ctx, cs.CtxUserAgent)
+	}
+	if entry.ActorID == 0 {
+		entry.ActorID, _ = ctx.Value(cs.CtxActorID).(int64)
+	}
+	if len(entry.UserAgent) > 255 {
+		entry.UserAgent = entry.UserAgent[:255]
+	}
+	entry.CreatedAt = time.Now().Truncate(time.Second)
+
+	if tx != nil {
+		if _, err = s.repo.WriteEntry(ctx, tx, entry); err != nil {
+			logger.Log.Error(err.Error())
+		}
+		return err
+	}
+
+	tx = s.repo.BeginTx(ctx)
+
+	if _, err = s.repo.WriteEntry(ctx, tx, entry); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	// The entry is kept either way; whatever is left unsealed is picked up
+	// by the next pass of Seal.
+	s.Seal(ctx)
+
+	return nil
+}
+
+// Seal chains the unsealed entries and returns how many it chained.
+func (s *auditService) Seal(ctx context.Context) (int64, error) {
+	var sealed int64
+	for {
+		n, err := s.sealBatch(ctx)
+		sealed += n
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return sealed, err
+		}
+
+		if n < auditSealBatchSize {
+			return sealed, nil
+		}
+	}
+}
+
+func (s *auditService) sealBatch(ctx context.Context) (n int64, err error) {
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			n = 0
+		}
+	}()
+
+	head, err := s.repo.ReadHeadForUpdate(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := s.repo.ReadUnsealedEntries(ctx, tx, auditSealBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(entries) == 0 {
+		return 0, tx.Commit()
+	}
+
+	for _, entry := range entries {
+		entry.Seq = head.Seq + 1
+		entry.PrevHash = head.Hash
+		entry.Hash = auditHash(entry)
+
+		if err = s.repo.SealEntry(ctx, tx, entry); err != nil {
+			return 0, err
+		}
+
+		head.Seq, head.Hash = entry.Seq, entry.Hash
+	}
+
+	if err = s.repo.UpdateHead(ctx, tx, *head); err != nil {
+		return 0, err
+	}
+
+	return int64(len(entries)), tx.Commit()
+}
+
+func (s *auditService) ListEntries(ctx context.Context, filter model.AuditFilter) (*model.Page, error) {
+	filter.PageRequest = filter.PageRequest.Normalize()
+
+	entries, total, err := s.repo.ReadEntries(ctx, filter)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return newPage(entries, filter.PageRequest, total), nil
+}
+
+// Verify walks the chain up to its head and reports the first entry that was
+// changed, or where entries went missing. Entries sealed after the head was
+// read are left for the next run. With a seal interval set, entries still
+// unsealed a full interval after they were written break the log too.
+func (s *auditService) Verify(ctx context.Context) (*model.AuditVerification, error) {
+	head, err := s.repo.ReadHead(ctx)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	result := &model.AuditVerification{Valid: true}
+
+	result.Unsealed, err = s.repo.CountUnsealed(ctx)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	broken := func(seq int64, problem string) (*model.AuditVerification, error) {
+		result.Valid = false
+		result.BrokenAt = seq
+		result.Problem = problem
+		return result, nil
+	}
+
+	var prevHash string
+	for result.Sealed < head.Seq {
+		entries, err := s.repo.ReadSealedEntries(ctx, result.Sealed, auditVerifyBatchSize)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		if len(entries) == 0 {
+			return broken(result.Sealed+1, fmt.Sprintf("entries %d to %d are missing", result.Sealed+1, head.Seq))
+		}
+
+		for _, entry := range entries {
+			if result.Sealed == head.Seq {
+				break
+			}
+
+			switch {
+			case entry.Seq != result.Sealed+1:
+				return broken(result.Sealed+1, fmt.Sprintf("entries %d to %d are missing", result.Sealed+1, entry.Seq-1))
+			case entry.PrevHash != prevHash:
+				return broken(entry.Seq, "does not follow the entry before it")
+			case entry.Hash != auditHash(entry):
+				return broken(entry.Seq, "was changed after it was sealed")
+			}
+
+			result.Sealed = entry.Seq
+			prevHash = entry.Hash
+		}
+	}
+
+	if prevHash != head.Hash {
+		return broken(head.Seq, "does not match the head of the chain")
+	}
+
+	if s.sealInterval <= 0 || result.Unsealed == 0 {
+		return result, nil
+	}
+
+	result.Stale, err = s.repo.CountUnsealedBefore(ctx, time.Now().Add(-s.sealInterval))
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if result.Stale > 0 {
+		result.Valid = false
+		result.Problem = fmt.Sprintf("%d entries were not sealed within %s", result.Stale, s.sealInterval)
+	}
+
+	return result, nil
+}
+
+// auditHash is the hash of an entry, covering every field recorded and the
+// hash of the entry before it.
+func auditHash(entry model.AuditEntry) string {
+	fields, _ := json.Marshal([]interface{}{
+		entry.ID,
+		entry.Seq,
+		entry.PrevHash,
+		entry.Action,
+		entry.ActorID,
+		entry.TargetType,
+		entry.TargetID,
+		entry.IP,
+		entry.UserAgent,
+		string(entry.Before),
+		string(entry.After),
+		entry.CreatedAt.Unix(),
+	})
+
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// auditValue encodes v as the before or after value of an audit entry.
+func auditValue(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil
+	}
+
+	return raw
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// auditChain seals entries into a valid chain.
+func auditChain(entries ...model.AuditEntry) []model.AuditEntry {
+	var prevHash string
+	for i := range entries {
+		entries[i].Seq = int64(i + 1)
+		entries[i].PrevHash = prevHash
+		entries[i].Hash = auditHash(entries[i])
+		prevHash = entries[i].Hash
+	}
+
+	return entries
+}
+
+func Test_auditService_Log(t *testing.T) {
+	db, mockDB := dbConn()
+	defer db.Close()
+
+	ctx := context.WithValue(context.Background(), cs.CtxClientIP, "10.0.0.1")
+	ctx = context.WithValue(ctx, cs.CtxUserAgent, "curl/8.0")
+	ctx = context.WithValue(ctx, cs.CtxActorID, int64(3))
+
+	mockRepo := mocks.AuditRepository{}
+
+	tx := beginTx(db, mockDB)
+	sealTx := beginTx(db, mockDB)
+	mockDB.ExpectCommit()
+	mockDB.ExpectCommit()
+	mockRepo.On("BeginTx", mock.Anything).Return(tx).Once()
+
+	var written model.AuditEntry
+	mockRepo.On("WriteEntry", mock.Anything, tx, mock.Anything).Run(func(args mock.Arguments) {
+		written = args.Get(2).(model.AuditEntry)
+	}).Return(int64(7), nil)
+
+	// The entry is sealed right after it is written.
+	mockRepo.On("BeginTx", mock.Anything).Return(sealTx).Once()
+	mockRepo.On("ReadHeadForUpdate", mock.Anything, sealTx).Return(&model.AuditHead{}, nil)
+	mockRepo.On("ReadUnsealedEntries", mock.Anything, sealTx, auditSealBatchSize).Return([]model.AuditEntry{}, nil)
+
+	s := NewAuditService(&mockRepo, 0)
+
+	err := s.Log(ctx, nil, model.AuditEntry{Action: model.AuditLogin, TargetType: model.AuditTargetUser, TargetID: 3})
+	if err != nil {
+		t.Fatalf("auditService.Log() error = %v", err)
+	}
+
+	if written.IP != "10.0.0.1" || written.UserAgent != "curl/8.0" || written.ActorID != 3 || written.CreatedAt.IsZero() {
+		t.Errorf("auditService.Log() wrote %+v", written)
+	}
+
+	if err = mockDB.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_auditService_Seal(t *testing.T) {
+	db, mockDB := dbConn()
+	defer db.Close()
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	mockRepo := mocks.AuditRepository{}
+
+	tx := beginTx(db, mockDB)
+	mockDB.ExpectCommit()
+	mockRepo.On("BeginTx", mock.Anything).Return(tx)
+	mockRepo.On("ReadHeadForUpdate", mock.Anything, tx).Return(&model.AuditHead{Seq: 4, Hash: "h4"}, nil)
+	mockRepo.On("ReadUnsealedEntries", mock.Anything, tx, auditSealBatchSize).Return([]model.AuditEntry{
+		{ID: 10, Action: model.AuditLogin, TargetType: model.AuditTargetUser, TargetID: 3, CreatedAt: now},
+		{ID: 12, Action: model.AuditLoginFailed, TargetType: model.AuditTargetUser, CreatedAt: now},
+	}, nil)
+
+	var sealed []model.AuditEntry
+	mockRepo.On("SealEntry", mock.Anything, tx, mock.Anything).Run(func(args mock.Arguments) {
+		sealed = append(sealed, args.Get(2).(model.AuditEntry))
+	}).Return(nil)
+
+	var head model.AuditHead
+	mockRepo.On("UpdateHead", mock.Anything, tx, mock.Anything).Run(func(args mock.Arguments) {
+		head = args.Get(2).(model.AuditHead)
+	}).Return(nil)
+
+	s := NewAuditService(&mockRepo, 0)
+
+	got, err := s.Seal(ctx)
+	if err != nil || got != 2 {
+		t.Fatalf("auditService.Seal() = %d, %v, want 2", got, err)
+	}
+
+	if sealed[0].Seq != 5 || sealed[0].PrevHash != "h4" || sealed[1].Seq != 6 || sealed[1].PrevHash != sealed[0].Hash {
+		t.Errorf("auditService.Seal() chained %+v", sealed)
+	}
+
+	for _, entry := range sealed {
+		if entry.Hash != auditHash(entry) {
+			t.Errorf("entry %d hashed as %s, want %s", entry.ID, entry.Hash, auditHash(entry))
+		}
+	}
+
+	if head.Seq != 6 || head.Hash != sealed[1].Hash {
+		t.Errorf("auditService.Seal() moved the head to %+v", head)
+	}
+
+	if err = mockDB.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_auditService_Verify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	chain := func() []model.AuditEntry {
+		return auditChain(
+			model.AuditEntry{ID: 1, Action: model.AuditUserRegistered, TargetType: model.AuditTargetUser, TargetID: 3, CreatedAt: now},
+			model.AuditEntry{ID: 2, Action: model.AuditBalanceChanged, TargetType: model.AuditTargetWallet, TargetID: 4, Before: []byte(`{"balance":0}`), After: []byte(`{"balance":50}`), CreatedAt: now},
+			model.AuditEntry{ID: 3, Action: model.AuditLogin, ActorID: 3, TargetType: model.AuditTargetUser, TargetID: 3, CreatedAt: now},
+		)
+	}
+
+	tests := []struct {
+		name    string
+		entries func() []model.AuditEntry
+		headSeq int64
+		// stale is how many entries were left unsealed past the interval.
+		stale    int64
+		want     bool
+		brokenAt int64
+	}{
+		{
+			name:    "positif",
+			entries: chain,
+			headSeq: 3,
+			want:    true,
+		},
+		{
+			name:    "negatif: entries left unsealed",
+			entries: chain,
+			headSeq: 3,
+			stale:   1,
+		},
+		{
+			name: "negatif: entry changed",
+			entries: func() []model.AuditEntry {
+				entries := chain()
+				entries[1].After = []byte(`{"balance":5000}`)
+				return entries
+			},
+			headSeq:  3,
+			brokenAt: 2,
+		},
+		{
+			name: "negatif: entry deleted",
+			entries: func() []model.AuditEntry {
+				entries := chain()
+				return append(entries[:1], entries[2])
+			},
+			headSeq:  3,
+			brokenAt: 2,
+		},
+		{
+			name: "negatif: last entry deleted",
+			entries: func() []model.AuditEntry {
+				return chain()[:2]
+			},
+			headSeq:  3,
+			brokenAt: 3,
+		},
+		{
+			name: "negatif: chain rewritten without updating the head",
+			entries: func() []model.AuditEntry {
+				entries := chain()
+				entries[2].ActorID = 9
+				return auditChain(entries...)
+			},
+			headSeq:  3,
+			brokenAt: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := tt.entries()
+			head := chain()[tt.headSeq-1]
+
+			mockRepo := mocks.AuditRepository{}
+			mockRepo.On("ReadHead", mock.Anything).Return(&model.AuditHead{Seq: head.Seq, Hash: head.Hash}, nil)
+			mockRepo.On("CountUnsealed", mock.Anything).Return(int64(1), nil)
+			mockRepo.On("CountUnsealedBefore", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+				return time.Since(before) >= time.Minute
+			})).Return(tt.stale, nil)
+			mockRepo.On("ReadSealedEntries", mock.Anything, int64(0), auditVerifyBatchSize).Return(entries, nil)
+			mockRepo.On("ReadSealedEntries", mock.Anything, mock.Anything, auditVerifyBatchSize).Return([]model.AuditEntry{}, nil)
+
+			s := NewAuditService(&mockRepo, time.Minute)
+
+			got, err := s.Verify(ctx)
+			if err != nil {
+				t.Fatalf("auditService.Verify() error = %v", err)
+			}
+
+			if got.Valid != tt.want || got.BrokenAt != tt.brokenAt || got.Unsealed != 1 || got.Stale != tt.stale {
+				t.Errorf("auditService.Verify() = %+v, want valid %v broken at %d", got, tt.want, tt.brokenAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// billReminderInterval is how long a participant is left alone after being
+// reminded of their share.
+const billReminderInterval = 24 * time.Hour
+
+type billService struct {
+	repo        repository.BillRepository
+	requestRepo repository.PaymentRequestRepository
+	userRepo    repository.UserRepository
+	walletRepo  repository.WalletRepository
+	webhooks    WebhookDispatcher
+	events      EventRecorder
+}
+
+type BillService interface {
+	Create(ctx context.Context, req model.CreateBillRequest) (*model.Bill, error)
+	Get(ctx context.Context, billID int64, viewer model.User) (*model.Bill, error)
+	List(ctx context.Context, userID int64) ([]model.Bill, error)
+	Cancel(ctx context.Context, billID, userID int64) (*model.Bill, error)
+	Remind(ctx context.Context, billID, userID int64) (*model.Bill, error)
+}
+
+// NewBillService returns a BillService that sends reminders to participants
+// through webhooks and the outbox.
+func NewBillService(billRepo repository.BillRepository, requestRepo repository.PaymentRequestRepository, userRepo repository.UserRepository, walletRepo repository.WalletRepository, webhooks WebhookDispatcher, events EventRecorder) BillService {
+	return &billService{
+		repo:        billRepo,
+		requestRepo: requestRepo,
+		userRepo:    userRepo,
+		walletRepo:  walletRepo,
+		webhooks:    webhooks,
+		events:      events,
+	}
+}
+
+// Create splits a bill between the caller and the participants. Each share
+// becomes a payment request into the caller's wallet that only its
+// participant can pay, so every share paid is a single debit and credit
+// through the payment request flow.
+func (s *billService) Create(ctx context.Context, req model.CreateBillRequest) (*model.Bill, error) {
+	wallet, err := s.walletRepo.ReadBalance(ctx, model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if wallet.ID == 0 {
+		return nil, cs.ErrNotFound
+	}
+
+	amounts, err := splitBill(req.Amount, req.Shares)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := defaultPaymentRequestExpiry
+	if req.ExpiresInSeconds > 0 {
+		expiry = time.Duration(req.ExpiresInSeconds) * time.Second
+	}
+
+	bill := model.Bill{
+		OrganizerID: req.UserID,
+		WalletID:    wallet.ID,
+		Title:       req.Title,
+		Amount:      req.Amount,
+		Status:      model.BillStatusOpen,
+		Shares:      []model.PaymentRequest{},
+	}
+
+	participants := map[int64]bool{}
+	for i, share := range req.Shares {
+		participant, err := s.userRepo.ReadByUsernameOrEmail(ctx, share.Participant, share.Participant)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		if participant == nil {
+			return nil, cs.ErrNotFound
+		}
+
+		if participant.ID == req.UserID || participants[participant.ID] {
+			return nil, cs.ErrBadParamInput
+		}
+		participants[participant.ID] = true
+
+		bill.Shares = append(bill.Shares, model.PaymentRequest{
+			Code:        utils.GenerateShortCode(paymentRequestCodeLength),
+			RequesterID: req.UserID,
+			WalletID:    wallet.ID,
+			PayerID:     &participant.ID,
+			Amount:      amounts[i],
+			Currency:    model.CurrencyIDR,
+			Description: req.Title,
+			Status:      model.PaymentRequestPending,
+			ExpiresAt:   time.Now().Add(expiry),
+		})
+	}
+
+	tx := s.repo.BeginTx(ctx)
+
+	bill.ID, err = s.repo.WriteBill(ctx, tx, bill)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	for i := range bill.Shares {
+		bill.Shares[i].BillID = &bill.ID
+
+		bill.Shares[i].ID, err = s.requestRepo.WritePaymentRequest(ctx, tx, bill.Shares[i])
+		if err != nil {
+			tx.Rollback()
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &bill, nil
+}
+
+// Get returns a bill with its shares to its organizer, its participants and
+// staff.
+func (s *billService) Get(ctx context.Context, billID int64, viewer model.User) (*model.Bill, error) {
+	bill, err := s.repo.ReadBillByID(ctx, billID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if bill == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if err = s.loadShares(ctx, bill); err != nil {
+		return nil, err
+	}
+
+	if bill.OrganizerID != viewer.ID && !viewer.IsStaff() && !participates(bill, viewer.ID) {
+		return nil, cs.ErrNotFound
+	}
+
+	return bill, nil
+}
+
+// List returns the bills a user organized or has a share in.
+func (s *billService) List(ctx context.Context, userID int64) ([]model.Bill, error) {
+	bills, err := s.repo.ReadBillsByUser(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	for i := range bills {
+		if err = s.loadShares(ctx, &bills[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return bills, nil
+}
+
+// Cancel closes an open bill and withdraws the shares still pending. Shares
+// already paid stay with the organizer. Only the organizer can cancel it.
+func (s *billService) Cancel(ctx context.Context, billID, userID int64) (*model.Bill, error) {
+	bill, err := s.organizerBill(ctx, billID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.repo.BeginTx(ctx)
+
+	if err = s.repo.CancelBill(ctx, tx, bill.ID); err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, cs.ErrBillNotActive
+		}
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = s.requestRepo.CancelBillPaymentRequests(ctx, tx, bill.ID); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, bill.ID, model.User{ID: userID})
+}
+
+// Remind reminds the participants who have yet to pay their share, with a
+// bill.reminder event to each one's webhooks and the outbox. A participant
+// is reminded at most once per billReminderInterval. Only the organizer can
+// send reminders.
+func (s *billService) Remind(ctx context.Context, billID, userID int64) (*model.Bill, error) {
+	bill, err := s.organizerBill(ctx, billID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	tx := s.repo.BeginTx(ctx)
+
+	var reminded int
+	for i := range bill.Shares {
+		share := &bill.Shares[i]
+		if share.Status != model.PaymentRequestPending || (share.RemindedAt != nil && now.Sub(*share.RemindedAt) < billReminderInterval) {
+			continue
+		}
+
+		// A share paid since it was read is simply not reminded.
+		if err = s.requestRepo.RemindPaymentRequest(ctx, tx, share.ID, now); err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			tx.Rollback()
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		share.Reminders++
+		share.RemindedAt = &now
+
+		if err = s.notifyReminder(ctx, tx, bill, *share); err != nil {
+			tx.Rollback()
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		reminded++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info(fmt.Sprintf("bill %d: reminded %d participants", bill.ID, reminded))
+
+	return bill, nil
+}
+
+// notifyReminder queues the bill.reminder event for a share's participant
+// in tx, so it is only sent if the reminder is recorded.
+func (s *billService) notifyReminder(ctx context.Context, tx *sql.Tx, bill *model.Bill, share model.PaymentRequest) error {
+	reminder := model.BillReminder{
+		BillID:      bill.ID,
+		Title:       bill.Title,
+		OrganizerID: bill.OrganizerID,
+		Code:        share.Code,
+		Amount:      share.Amount,
+		Currency:    share.Currency,
+		Reminders:   share.Reminders,
+		ExpiresAt:   share.ExpiresAt,
+	}
+
+	if err := s.webhooks.Dispatch(ctx, tx, *share.PayerID, model.WebhookEventBillReminder, reminder); err != nil {
+		return err
+	}
+
+	return s.events.Record(ctx, tx, *share.PayerID, model.WebhookEventBillReminder, reminder)
+}
+
+// organizerBill returns an open bill to its organizer.
+func (s *billService) organizerBill(ctx context.Context, billID, userID int64) (*model.Bill, error) {
+	bill, err := s.Get(ctx, billID, model.User{ID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	if bill.OrganizerID != userID {
+		return nil, cs.ErrNotFound
+	}
+
+	if bill.Status != model.BillStatusOpen {
+		return nil, cs.ErrBillNotActive
+	}
+
+	return bill, nil
+}
+
+// loadShares reads the shares of a bill and works out how much of it is paid
+// and whether it is still open.
+func (s *billService) loadShares(ctx context.Context, bill *model.Bill) error {
+	shares, err := s.requestRepo.ReadPaymentRequestsByBill(ctx, bill.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	var pending, expired bool
+	for i := range shares {
+		switch withEffectiveStatus(&shares[i]).Status {
+		case model.PaymentRequestPaid:
+			bill.PaidAmount += shares[i].Amount
+		case model.PaymentRequestPending:
+			pending = true
+		case model.PaymentRequestExpired:
+			expired = true
+		}
+	}
+
+	bill.Shares = shares
+
+	switch {
+	case bill.Status != model.BillStatusOpen || pending:
+	case expired:
+		bill.Status = model.BillStatusExpired
+	default:
+		bill.Status = model.BillStatusSettled
+	}
+
+	return nil
+}
+
+// participates reports whether a user has a share in a bill.
+func participates(bill *model.Bill, userID int64) bool {
+	for _, share := range bill.Shares {
+		if share.PayerID != nil && *share.PayerID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitBill works out the amount of each share. Shares without amounts split
+// the bill equally with the organizer, who covers what does not divide
+// evenly. Shares with amounts may not add up to more than the bill.
+func splitBill(amount float64, shares []model.BillShareRequest) ([]float64, error) {
+	var (
+		amounts = make([]float64, len(shares))
+		total   float64
+		unset   int
+	)
+
+	for i, share := range shares {
+		amounts[i] = share.Amount
+		total += share.Amount
+		if share.Amount == 0 {
+			unset++
+		}
+	}
+
+	switch {
+	case unset == len(shares):
+		equal := math.Floor(amount / float64(len(shares)+1))
+		if equal == 0 {
+			return nil, cs.ErrBadParamInput
+		}
+
+		for i := range amounts {
+			amounts[i] = equal
+		}
+	case unset > 0 || total > amount:
+		return nil, cs.ErrBadParamInput
+	}
+
+	return amounts, nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_billService_Create(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		shares      []model.BillShareRequest
+		wantAmounts []float64
+		wantErr     error
+	}{
+		{
+			name:        "positif: split equally",
+			shares:      []model.BillShareRequest{{Participant: "budi"}, {Participant: "cici"}},
+			wantAmounts: []float64{33333, 33333},
+		},
+		{
+			name:        "positif: custom shares",
+			shares:      []model.BillShareRequest{{Participant: "budi", Amount: 60000}, {Participant: "cici", Amount: 15000}},
+			wantAmounts: []float64{60000, 15000},
+		},
+		{
+			name:    "negatif: some shares without amount",
+			shares:  []model.BillShareRequest{{Participant: "budi", Amount: 60000}, {Participant: "cici"}},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: shares exceed bill",
+			shares:  []model.BillShareRequest{{Participant: "budi", Amount: 60000}, {Participant: "cici", Amount: 50000}},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: participant listed twice",
+			shares:  []model.BillShareRequest{{Participant: "budi"}, {Participant: "budi"}},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: organizer as participant",
+			shares:  []model.BillShareRequest{{Participant: "andi"}},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: unknown participant",
+			shares:  []model.BillShareRequest{{Participant: "nobody"}},
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.BillRepository{}
+			mockRequestRepo := mocks.PaymentRequestRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockWalletRepo := mocks.WalletRepository{}
+
+			tx := beginTx(db, mockDB)
+			if tt.wantErr == nil {
+				mockDB.ExpectCommit()
+			}
+
+			req := model.CreateBillRequest{Title: "dinner", Amount: 100000, Shares: tt.shares, UserID: 1, Address: "address"}
+
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("WriteBill", ctx, tx, mock.MatchedBy(func(b model.Bill) bool {
+				return b.OrganizerID == 1 && b.WalletID == 3 && b.Status == model.BillStatusOpen
+			})).Return(int64(7), nil)
+			mockWalletRepo.On("ReadBalance", ctx, model.CheckBalanceRequest{UserID: 1, Address: "address"}).Return(&model.Wallet{ID: 3, UserID: 1}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "andi", "andi").Return(&model.User{ID: 1}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "budi", "budi").Return(&model.User{ID: 2}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "cici", "cici").Return(&model.User{ID: 3}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "nobody", "nobody").Return(nil, nil)
+			mockRequestRepo.On("WritePaymentRequest", ctx, tx, mock.MatchedBy(func(r model.PaymentRequest) bool {
+				return *r.BillID == 7 && r.PayerID != nil && r.WalletID == 3 && r.Description == "dinner" && r.Status == model.PaymentRequestPending
+			})).Return(int64(1), nil)
+
+			s := NewBillService(&mockRepo, &mockRequestRepo, &mockUserRepo, &mockWalletRepo, noWebhooks{}, noEvents{})
+			got, err := s.Create(ctx, req)
+			if err != tt.wantErr {
+				t.Errorf("billService.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			amounts := []float64{}
+			for _, share := range got.Shares {
+				amounts = append(amounts, share.Amount)
+			}
+			if !reflect.DeepEqual(amounts, tt.wantAmounts) {
+				t.Errorf("billService.Create() shares = %v, want %v", amounts, tt.wantAmounts)
+			}
+			if err = mockDB.ExpectationsWereMet(); err != nil {
+				t.Errorf("billService.Create() %v", err)
+			}
+		})
+	}
+}
+
+func Test_billService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		participant = int64(2)
+		expiresAt   = time.Now().Add(time.Hour)
+		expiredAt   = time.Now().Add(-time.Hour)
+	)
+
+	tests := []struct {
+		name           string
+		shares         []model.PaymentRequest
+		viewer         model.User
+		wantStatus     string
+		wantPaidAmount float64
+		wantErr        error
+	}{
+		{
+			name: "positif: open",
+			shares: []model.PaymentRequest{
+				{PayerID: &participant, Amount: 30000, Status: model.PaymentRequestPaid, ExpiresAt: expiresAt},
+				{PayerID: &participant, Amount: 30000, Status: model.PaymentRequestPending, ExpiresAt: expiresAt},
+			},
+			viewer:         model.User{ID: 1},
+			wantStatus:     model.BillStatusOpen,
+			wantPaidAmount: 30000,
+		},
+		{
+			name: "positif: settled",
+			shares: []model.PaymentRequest{
+				{PayerID: &participant, Amount: 30000, Status: model.PaymentRequestPaid, ExpiresAt: expiresAt},
+				{PayerID: &participant, Amount: 30000, Status: model.PaymentRequestPaid, ExpiresAt: expiresAt},
+			},
+			viewer:         model.User{ID: 2},
+			wantStatus:     model.BillStatusSettled,
+			wantPaidAmount: 60000,
+		},
+		{
+			name: "positif: expired",
+			shares: []model.PaymentRequest{
+				{PayerID: &participant, Amount: 30000, Status: model.PaymentRequestPaid, ExpiresAt: expiresAt},
+				{PayerID: &participant, Amount: 30000, Status: model.PaymentRequestPending, ExpiresAt: expiredAt},
+			},
+			viewer:         model.User{ID: 1},
+			wantStatus:     model.BillStatusExpired,
+			wantPaidAmount: 30000,
+		},
+		{
+			name: "negatif: not a participant",
+			shares: []model.PaymentRequest{
+				{PayerID: &participant, Amount: 30000, Status: model.PaymentRequestPending, ExpiresAt: expiresAt},
+			},
+			viewer:  model.User{ID: 9},
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.BillRepository{}
+			mockRequestRepo := mocks.PaymentRequestRepository{}
+
+			mockRepo.On("ReadBillByID", ctx, int64(7)).Return(&model.Bill{ID: 7, OrganizerID: 1, Amount: 90000, Status: model.BillStatusOpen}, nil)
+			mockRequestRepo.On("ReadPaymentRequestsByBill", ctx, int64(7)).Return(tt.shares, nil)
+
+			s := NewBillService(&mockRepo, &mockRequestRepo, &mocks.UserRepository{}, &mocks.WalletRepository{}, noWebhooks{}, noEvents{})
+			got, err := s.Get(ctx, 7, tt.viewer)
+			if err != tt.wantErr {
+				t.Errorf("billService.Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.Status != tt.wantStatus || got.PaidAmount != tt.wantPaidAmount {
+				t.Errorf("billService.Get() = %s %v, want %s %v", got.Status, got.PaidAmount, tt.wantStatus, tt.wantPaidAmount)
+			}
+		})
+	}
+}
+
+// reminderEvents records the events recorded through it.
+type reminderEvents struct {
+	recorded *[]model.BillReminder
+}
+
+func (e reminderEvents) Record(ctx context.Context, tx *sql.Tx, userID int64, eventType string, data interface{}) error {
+	if reminder, ok := data.(model.BillReminder); ok && eventType == model.WebhookEventBillReminder {
+		*e.recorded = append(*e.recorded, reminder)
+	}
+	return nil
+}
+
+func Test_billService_Remind(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	var (
+		participant = int64(2)
+		expiresAt   = time.Now().Add(time.Hour)
+		recently    = time.Now().Add(-time.Hour)
+		longAgo     = time.Now().Add(-2 * billReminderInterval)
+	)
+
+	shares := []model.PaymentRequest{
+		{ID: 1, PayerID: &participant, Status: model.PaymentRequestPaid, ExpiresAt: expiresAt},
+		{ID: 2, PayerID: &participant, Status: model.PaymentRequestPending, ExpiresAt: expiresAt},
+		{ID: 3, PayerID: &participant, Status: model.PaymentRequestPending, ExpiresAt: expiresAt, Reminders: 1, RemindedAt: &recently},
+		{ID: 4, PayerID: &participant, Status: model.PaymentRequestPending, ExpiresAt: expiresAt, Reminders: 1, RemindedAt: &longAgo},
+		{ID: 5, PayerID: &participant, Status: model.PaymentRequestPending, ExpiresAt: expiresAt},
+	}
+
+	mockRepo := mocks.BillRepository{}
+	mockRequestRepo := mocks.PaymentRequestRepository{}
+
+	tx := beginTx(db, mockDB)
+	mockDB.ExpectCommit()
+
+	var recorded []model.BillReminder
+
+	mockRepo.On("ReadBillByID", ctx, int64(7)).Return(&model.Bill{ID: 7, OrganizerID: 1, Title: "Dinner", Status: model.BillStatusOpen}, nil)
+	mockRepo.On("BeginTx", ctx).Return(tx)
+	mockRequestRepo.On("ReadPaymentRequestsByBill", ctx, int64(7)).Return(shares, nil)
+	mockRequestRepo.On("RemindPaymentRequest", ctx, tx, int64(2), mock.Anything).Return(nil)
+	mockRequestRepo.On("RemindPaymentRequest", ctx, tx, int64(4), mock.Anything).Return(nil)
+	// Paid between reading the bill and reminding.
+	mockRequestRepo.On("RemindPaymentRequest", ctx, tx, int64(5), mock.Anything).Return(sql.ErrNoRows)
+
+	s := NewBillService(&mockRepo, &mockRequestRepo, &mocks.UserRepository{}, &mocks.WalletRepository{}, noWebhooks{}, reminderEvents{recorded: &recorded})
+
+	if _, err := s.Remind(ctx, 7, 2); err != cs.ErrNotFound {
+		t.Errorf("billService.Remind() by participant error = %v, want %v", err, cs.ErrNotFound)
+	}
+
+	got, err := s.Remind(ctx, 7, 1)
+	if err != nil {
+		t.Fatalf("billService.Remind() error = %v", err)
+	}
+
+	reminders := []int{}
+	for _, share := range got.Shares {
+		reminders = append(reminders, share.Reminders)
+	}
+	if want := []int{0, 1, 1, 2, 0}; !reflect.DeepEqual(reminders, want) {
+		t.Errorf("billService.Remind() reminders = %v, want %v", reminders, want)
+	}
+	mockRequestRepo.AssertNumberOfCalls(t, "RemindPaymentRequest", 3)
+
+	if len(recorded) != 2 || recorded[0].BillID != 7 || recorded[0].Title != "Dinner" || recorded[1].Reminders != 2 {
+		t.Errorf("billService.Remind() recorded %+v", recorded)
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+type FeeService interface {
+	Quote(ctx context.Context, operation string, amount float64, merchantID int64) (*model.FeeQuote, error)
+	ListRules(ctx context.Context) ([]model.FeeRule, error)
+	CreateRule(ctx context.Context, req model.FeeRuleRequest) (*model.FeeRule, error)
+	UpdateRule(ctx context.Context, ruleID int64, req model.FeeRuleRequest) (*model.FeeRule, error)
+}
+
+type feeService struct {
+	repo            repository.FeeRepository
+	revenueWalletID int64
+}
+
+// NewFeeService prices operations with the stored fee rules. Fees are
+// collected in the wallet with id revenueWalletID.
+func NewFeeService(feeRepo repository.FeeRepository, revenueWalletID int64) FeeService {
+	return &feeService{
+		repo:            feeRepo,
+		revenueWalletID: revenueWalletID,
+	}
+}
+
+// Quote computes the fee of an operation of amount, using the rule of
+// merchantID when it has one. Operations without a rule are free.
+func (s *feeService) Quote(ctx context.Context, operation string, amount float64, merchantID int64) (*model.FeeQuote, error) {
+	rule, err := s.repo.ReadApplicableRule(ctx, operation, merchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	quote := model.FeeQuote{
+		Operation:       operation,
+		Amount:          amount,
+		RevenueWalletID: s.revenueWalletID,
+	}
+
+	if rule != nil {
+		quote.Fee = calculateFee(*rule, amount)
+		quote.RuleID = &rule.ID
+	}
+
+	if operation == model.TransactionTypeTopUp {
+		quote.Net = amount - quote.Fee
+	} else {
+		quote.Net = -(amount + quote.Fee)
+	}
+
+	return &quote, nil
+}
+
+func (s *feeService) ListRules(ctx context.Context) ([]model.FeeRule, error) {
+	rules, err := s.repo.ReadRules(ctx)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (s *feeService) CreateRule(ctx context.Context, req model.FeeRuleRequest) (*model.FeeRule, error) {
+	if err := validateFeeRule(req); err != nil {
+		return nil, err
+	}
+
+	rule := model.FeeRule{}
+	applyFeeRule(&rule, req)
+
+	var err error
+	rule.ID, err = s.repo.WriteRule(ctx, rule)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (s *feeService) UpdateRule(ctx context.Context, ruleID int64, req model.FeeRuleRequest) (*model.FeeRule, error) {
+	if err := validateFeeRule(req); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.repo.ReadRuleByID(ctx, ruleID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if rule == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	applyFeeRule(rule, req)
+
+	if err = s.repo.UpdateRule(ctx, *rule); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// validateFeeRule rejects tiered rules without tiers and caps that leave no
+// valid fee.
+func validateFeeRule(req model.FeeRuleRequest) error {
+	if req.Type == model.FeeTypeTiered && len(req.Tiers) == 0 {
+		return cs.ErrBadParamInput
+	}
+
+	if req.MaxFee > 0 && req.MaxFee < req.MinFee {
+		return cs.ErrBadParamInput
+	}
+
+	return nil
+}
+
+// applyFeeRule copies req onto rule, ordering the tiers by their upper bound
+// with the unbounded tier last, which is the order calculateFee reads them in.
+func applyFeeRule(rule *model.FeeRule, req model.FeeRuleRequest) {
+	tiers := append([]model.FeeTier{}, req.Tiers...)
+	sort.SliceStable(tiers, func(i, j int) bool {
+		if tiers[j].UpTo == 0 {
+			return tiers[i].UpTo != 0
+		}
+		return tiers[i].UpTo != 0 && tiers[i].UpTo < tiers[j].UpTo
+	})
+
+	rule.Name = req.Name
+	rule.Operation = req.Operation
+	rule.Type = req.Type
+	rule.Flat = req.Flat
+	rule.Percentage = req.Percentage
+	rule.Tiers = tiers
+	rule.MinFee = req.MinFee
+	rule.MaxFee = req.MaxFee
+	rule.MerchantID = req.MerchantID
+	rule.Active = req.Active
+}
+
+// calculateFee applies a rule to amount and clamps the result to the rule's
+// caps. Fees are rounded to whole units, like balances.
+func calculateFee(rule model.FeeRule, amount float64) float64 {
+	var fee float64
+
+	switch rule.Type {
+	case model.FeeTypeFlat:
+		fee = rule.Flat
+	case model.FeeTypePercentage:
+		fee = amount * rule.Percentage / 100
+	case model.FeeTypeTiered:
+		for _, tier := range rule.Tiers {
+			if tier.UpTo == 0 || amount <= tier.UpTo {
+				fee = tier.Flat + amount*tier.Percentage/100
+				break
+			}
+		}
+	}
+
+	fee = math.Max(fee, rule.MinFee)
+	if rule.MaxFee > 0 {
+		fee = math.Min(fee, rule.MaxFee)
+	}
+
+	return math.Round(fee)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func Test_calculateFee(t *testing.T) {
+	tiers := []model.FeeTier{
+		{UpTo: 100000, Flat: 1000},
+		{UpTo: 1000000, Flat: 500, Percentage: 0.5},
+		{Percentage: 0.25},
+	}
+
+	tests := []struct {
+		name   string
+		rule   model.FeeRule
+		amount float64
+		want   float64
+	}{
+		{
+			name:   "flat",
+			rule:   model.FeeRule{Type: model.FeeTypeFlat, Flat: 2500},
+			amount: 50000,
+			want:   2500,
+		},
+		{
+			name:   "percentage rounded",
+			rule:   model.FeeRule{Type: model.FeeTypePercentage, Percentage: 1.5},
+			amount: 12345,
+			want:   185,
+		},
+		{
+			name:   "percentage below min",
+			rule:   model.FeeRule{Type: model.FeeTypePercentage, Percentage: 1, MinFee: 1000},
+			amount: 50000,
+			want:   1000,
+		},
+		{
+			name:   "percentage above max",
+			rule:   model.FeeRule{Type: model.FeeTypePercentage, Percentage: 1, MaxFee: 5000},
+			amount: 1000000,
+			want:   5000,
+		},
+		{
+			name:   "first tier",
+			rule:   model.FeeRule{Type: model.FeeTypeTiered, Tiers: tiers},
+			amount: 100000,
+			want:   1000,
+		},
+		{
+			name:   "middle tier",
+			rule:   model.FeeRule{Type: model.FeeTypeTiered, Tiers: tiers},
+			amount: 200000,
+			want:   1500,
+		},
+		{
+			name:   "unbounded tier",
+			rule:   model.FeeRule{Type: model.FeeTypeTiered, Tiers: tiers},
+			amount: 4000000,
+			want:   10000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calculateFee(tt.rule, tt.amount); got != tt.want {
+				t.Errorf("calculateFee() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_feeService_Quote(t *testing.T) {
+	ctx := context.Background()
+	ruleID := int64(1)
+
+	tests := []struct {
+		name      string
+		operation string
+		rule      *model.FeeRule
+		want      *model.FeeQuote
+	}{
+		{
+			name:      "positif: top up",
+			operation: model.TransactionTypeTopUp,
+			rule:      &model.FeeRule{ID: ruleID, Type: model.FeeTypeFlat, Flat: 1000},
+			want:      &model.FeeQuote{Operation: model.TransactionTypeTopUp, Amount: 50000, Fee: 1000, Net: 49000, RuleID: &ruleID, RevenueWalletID: 30},
+		},
+		{
+			name:      "positif: payment",
+			operation: model.TransactionTypePayment,
+			rule:      &model.FeeRule{ID: ruleID, Type: model.FeeTypePercentage, Percentage: 1},
+			want:      &model.FeeQuote{Operation: model.TransactionTypePayment, Amount: 50000, Fee: 500, Net: -50500, RuleID: &ruleID, RevenueWalletID: 30},
+		},
+		{
+			name:      "positif: no rule",
+			operation: model.TransactionTypePayment,
+			rule:      nil,
+			want:      &model.FeeQuote{Operation: model.TransactionTypePayment, Amount: 50000, Net: -50000, RevenueWalletID: 30},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFeeRepo := mocks.FeeRepository{}
+			mockFeeRepo.On("ReadApplicableRule", ctx, tt.operation, int64(8)).Return(tt.rule, nil)
+
+			s := NewFeeService(&mockFeeRepo, 30)
+			got, err := s.Quote(ctx, tt.operation, 50000, 8)
+			if err != nil {
+				t.Errorf("feeService.Quote() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("feeService.Quote() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_feeService_CreateRule(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		req       model.FeeRuleRequest
+		wantTiers []model.FeeTier
+		wantErr   error
+	}{
+		{
+			name: "positif: tiers are ordered",
+			req: model.FeeRuleRequest{
+				Name: "tiered payment", Operation: model.TransactionTypePayment, Type: model.FeeTypeTiered, Active: true,
+				Tiers: []model.FeeTier{{Percentage: 0.25}, {UpTo: 1000000, Flat: 500}, {UpTo: 100000, Flat: 1000}},
+			},
+			wantTiers: []model.FeeTier{{UpTo: 100000, Flat: 1000}, {UpTo: 1000000, Flat: 500}, {Percentage: 0.25}},
+		},
+		{
+			name:    "negatif: tiered without tiers",
+			req:     model.FeeRuleRequest{Name: "tiered payment", Operation: model.TransactionTypePayment, Type: model.FeeTypeTiered},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: max below min",
+			req:     model.FeeRuleRequest{Name: "capped", Operation: model.TransactionTypePayment, Type: model.FeeTypePercentage, Percentage: 1, MinFee: 2000, MaxFee: 1000},
+			wantErr: cs.ErrBadParamInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFeeRepo := mocks.FeeRepository{}
+			mockFeeRepo.On("WriteRule", ctx, model.FeeRule{
+				Name:      tt.req.Name,
+				Operation: tt.req.Operation,
+				Type:      tt.req.Type,
+				Tiers:     tt.wantTiers,
+				Active:    tt.req.Active,
+			}).Return(int64(5), nil)
+
+			s := NewFeeService(&mockFeeRepo, 30)
+			got, err := s.CreateRule(ctx, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("feeService.CreateRule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && (got.ID != 5 || !reflect.DeepEqual(got.Tiers, tt.wantTiers)) {
+				t.Errorf("feeService.CreateRule() = %+v, want tiers %+v", got, tt.wantTiers)
+			}
+		})
+	}
+}
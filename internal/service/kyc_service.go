@@ -0,0 +1,247 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/storage"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// kycDocumentExtensions lists the accepted document content types, detected
+// from the uploaded bytes rather than trusted from the client.
+var kycDocumentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+var kycLevelRank = map[string]int{
+	model.KYCLevelUnverified: 0,
+	model.KYCLevelBasic:      1,
+	model.KYCLevelFull:       2,
+}
+
+type KYCService interface {
+	Status(ctx context.Context, userID int64) (*model.KYCStatusResponse, error)
+	SubmitDocument(ctx context.Context, req model.KYCDocumentRequest, file io.Reader) (*model.KYCDocument, error)
+	ListDocuments(ctx context.Context, status string, page model.PageRequest) (*model.Page, error)
+	OpenDocument(ctx context.Context, documentID int64) (*model.KYCDocument, io.ReadCloser, error)
+	Review(ctx context.Context, req model.KYCReviewRequest) (*model.KYCDocument, error)
+}
+
+type kycService struct {
+	repo      repository.KYCRepository
+	userRepo  repository.UserRepository
+	blobStore storage.BlobStore
+	audit     AuditLogger
+}
+
+func NewKYCService(kycRepo repository.KYCRepository, userRepo repository.UserRepository, blobStore storage.BlobStore, audit AuditLogger) KYCService {
+	return &kycService{
+		repo:      kycRepo,
+		userRepo:  userRepo,
+		blobStore: blobStore,
+		audit:     audit,
+	}
+}
+
+func (s *kycService) Status(ctx context.Context, userID int64) (*model.KYCStatusResponse, error) {
+	user, err := s.userRepo.ReadByID(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	docs, err := s.repo.ReadDocumentsByUserID(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &model.KYCStatusResponse{
+		Level:     user.KYCLevel,
+		Limit:     model.KYCLimits[user.KYCLevel],
+		Documents: docs,
+	}, nil
+}
+
+func (s *kycService) SubmitDocument(ctx context.Context, req model.KYCDocumentRequest, file io.Reader) (*model.KYCDocument, error) {
+	user, err := s.userRepo.ReadByID(ctx, req.UserID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if kycLevelRank[user.KYCLevel] >= kycLevelRank[req.RequestedLevel] {
+		return nil, cs.ErrKYCLevelReached
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		logger.Log.Error(err.Error())
+		return nil, cs.ErrUnsupportedDocument
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	ext, ok := kycDocumentExtensions[contentType]
+	if !ok {
+		return nil, cs.ErrUnsupportedDocument
+	}
+
+	doc := model.KYCDocument{
+		UserID:         req.UserID,
+		DocumentType:   req.DocumentType,
+		RequestedLevel: req.RequestedLevel,
+		StorageKey:     fmt.Sprintf("kyc/%d/%s%s", req.UserID, utils.GenerateReference(), ext),
+		ContentType:    contentType,
+		Status:         model.KYCDocumentPending,
+	}
+
+	if err = s.blobStore.Put(ctx, doc.StorageKey, io.MultiReader(bytes.NewReader(head), file)); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	doc.ID, err = s.repo.WriteDocument(ctx, doc)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		s.blobStore.Delete(ctx, doc.StorageKey)
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+func (s *kycService) ListDocuments(ctx context.Context, status string, page model.PageRequest) (*model.Page, error) {
+	page = page.Normalize()
+
+	docs, total, err := s.repo.ReadDocumentPage(ctx, status, page)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return newPage(docs, page, total), nil
+}
+
+func (s *kycService) OpenDocument(ctx context.Context, documentID int64) (*model.KYCDocument, io.ReadCloser, error) {
+	doc, err := s.repo.ReadDocumentByID(ctx, documentID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, nil, err
+	}
+
+	if doc == nil {
+		return nil, nil, cs.ErrNotFound
+	}
+
+	file, err := s.blobStore.Get(ctx, doc.StorageKey)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, nil, err
+	}
+
+	return doc, file, nil
+}
+
+// Review approves or rejects a pending document. Approving raises the
+// owner's KYC level to the requested one; it never lowers it.
+func (s *kycService) Review(ctx context.Context, req model.KYCReviewRequest) (doc *model.KYCDocument, err error) {
+	doc, err = s.repo.ReadDocumentByID(ctx, req.DocumentID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if doc == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if doc.Status != model.KYCDocumentPending {
+		return nil, cs.ErrDocumentReviewed
+	}
+
+	if doc.UserID == req.ReviewerID {
+		return nil, cs.ErrForbidden
+	}
+
+	user, err := s.userRepo.ReadByID(ctx, doc.UserID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	now := time.Now()
+	doc.Status = model.KYCDocumentRejected
+	if req.Approve {
+		doc.Status = model.KYCDocumentApproved
+	}
+	doc.ReviewerID = &req.ReviewerID
+	doc.ReviewNote = req.Note
+	doc.ReviewedAt = &now
+
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = s.repo.UpdateReview(ctx, tx, *doc); err == sql.ErrNoRows {
+		return nil, cs.ErrDocumentReviewed
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if req.Approve && kycLevelRank[doc.RequestedLevel] > kycLevelRank[user.KYCLevel] {
+		if err = s.userRepo.UpdateKYCLevel(ctx, tx, doc.UserID, doc.RequestedLevel); err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		err = s.audit.Log(ctx, tx, model.AuditEntry{
+			Action:     model.AuditProfileChanged,
+			ActorID:    req.ReviewerID,
+			TargetType: model.AuditTargetUser,
+			TargetID:   doc.UserID,
+			Before:     auditValue(map[string]string{"kyc_level": user.KYCLevel}),
+			After:      auditValue(map[string]interface{}{"kyc_level": doc.RequestedLevel, "document_id": doc.ID}),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return doc, nil
+}
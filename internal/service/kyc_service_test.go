@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/storage"
+	"github.com/stretchr/testify/mock"
+)
+
+const pngHeader = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func Test_kycService_SubmitDocument(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		level   string
+		file    string
+		wantErr error
+	}{
+		{
+			name:    "positif",
+			level:   model.KYCLevelUnverified,
+			file:    pngHeader + "image",
+			wantErr: nil,
+		},
+		{
+			name:    "negatif: unsupported document",
+			level:   model.KYCLevelUnverified,
+			file:    "plain text",
+			wantErr: cs.ErrUnsupportedDocument,
+		},
+		{
+			name:    "negatif: level already reached",
+			level:   model.KYCLevelBasic,
+			file:    pngHeader + "image",
+			wantErr: cs.ErrKYCLevelReached,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockKYCRepo := mocks.KYCRepository{}
+			mockUserRepo := mocks.UserRepository{}
+
+			mockUserRepo.On("ReadByID", ctx, int64(1)).Return(&model.User{ID: 1, KYCLevel: tt.level}, nil)
+			mockKYCRepo.On("WriteDocument", ctx, mock.MatchedBy(func(doc model.KYCDocument) bool {
+				return doc.ContentType == "image/png" && strings.HasPrefix(doc.StorageKey, "kyc/1/") && doc.Status == model.KYCDocumentPending
+			})).Return(int64(4), nil)
+
+			s := NewKYCService(&mockKYCRepo, &mockUserRepo, storage.NewLocalBlobStore(t.TempDir()), noAudit{})
+
+			got, err := s.SubmitDocument(ctx, model.KYCDocumentRequest{
+				DocumentType:   "id_card",
+				RequestedLevel: model.KYCLevelBasic,
+				UserID:         1,
+			}, strings.NewReader(tt.file))
+			if err != tt.wantErr {
+				t.Errorf("kycService.SubmitDocument() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil && got.ID != 4 {
+				t.Errorf("kycService.SubmitDocument() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_kycService_Review(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		req        model.KYCReviewRequest
+		doc        *model.KYCDocument
+		wantLevel  bool
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name:       "positif: approve raises level",
+			req:        model.KYCReviewRequest{Approve: true, DocumentID: 4, ReviewerID: 9},
+			doc:        &model.KYCDocument{ID: 4, UserID: 1, RequestedLevel: model.KYCLevelBasic, Status: model.KYCDocumentPending},
+			wantLevel:  true,
+			wantStatus: model.KYCDocumentApproved,
+		},
+		{
+			name:       "positif: reject",
+			req:        model.KYCReviewRequest{Approve: false, Note: "blurry", DocumentID: 4, ReviewerID: 9},
+			doc:        &model.KYCDocument{ID: 4, UserID: 1, RequestedLevel: model.KYCLevelBasic, Status: model.KYCDocumentPending},
+			wantStatus: model.KYCDocumentRejected,
+		},
+		{
+			name:    "negatif: own document",
+			req:     model.KYCReviewRequest{Approve: true, DocumentID: 4, ReviewerID: 1},
+			doc:     &model.KYCDocument{ID: 4, UserID: 1, RequestedLevel: model.KYCLevelBasic, Status: model.KYCDocumentPending},
+			wantErr: cs.ErrForbidden,
+		},
+		{
+			name:    "negatif: already reviewed",
+			req:     model.KYCReviewRequest{Approve: true, DocumentID: 4, ReviewerID: 9},
+			doc:     &model.KYCDocument{ID: 4, UserID: 1, RequestedLevel: model.KYCLevelBasic, Status: model.KYCDocumentRejected},
+			wantErr: cs.ErrDocumentReviewed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockKYCRepo := mocks.KYCRepository{}
+			mockUserRepo := mocks.UserRepository{}
+
+			tx := beginTx(db, mockDB)
+			mockDB.ExpectCommit()
+
+			mockKYCRepo.On("ReadDocumentByID", ctx, tt.req.DocumentID).Return(tt.doc, nil)
+			mockUserRepo.On("ReadByID", ctx, tt.doc.UserID).Return(&model.User{ID: tt.doc.UserID, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockKYCRepo.On("BeginTx", ctx).Return(tx)
+			mockKYCRepo.On("UpdateReview", ctx, tx, mock.Anything).Return(nil)
+			mockUserRepo.On("UpdateKYCLevel", ctx, tx, tt.doc.UserID, tt.doc.RequestedLevel).Return(nil)
+
+			s := NewKYCService(&mockKYCRepo, &mockUserRepo, storage.NewLocalBlobStore(t.TempDir()), noAudit{})
+
+			got, err := s.Review(ctx, tt.req)
+			if err != tt.wantErr {
+				t.Errorf("kycService.Review() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err != nil {
+				return
+			}
+
+			if got.Status != tt.wantStatus || got.ReviewNote != tt.req.Note {
+				t.Errorf("kycService.Review() = %v", got)
+			}
+
+			if tt.wantLevel {
+				mockUserRepo.AssertCalled(t, "UpdateKYCLevel", ctx, tx, tt.doc.UserID, tt.doc.RequestedLevel)
+			} else {
+				mockUserRepo.AssertNotCalled(t, "UpdateKYCLevel", ctx, tx, tt.doc.UserID, tt.doc.RequestedLevel)
+			}
+		})
+	}
+}
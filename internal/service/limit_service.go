@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+type LimitService interface {
+	Check(ctx context.Context, tx *sql.Tx, wallet model.Wallet, operation string, amount float64) error
+	ListRules(ctx context.Context) ([]model.LimitRule, error)
+	ValidateRule(ctx context.Context, ruleID int64, req model.LimitRuleRequest) error
+	ApplyRule(ctx context.Context, tx *sql.Tx, ruleID int64, req model.LimitRuleRequest) (*model.LimitRule, error)
+}
+
+type limitService struct {
+	repo repository.LimitRepository
+}
+
+func NewLimitService(limitRepo repository.LimitRepository) LimitService {
+	return &limitService{
+		repo: limitRepo,
+	}
+}
+
+// Check evaluates every active rule for operation against a wallet that is
+// locked in tx, so the usage it reads cannot change before the balance
+// update commits. amount is the absolute amount of the operation.
+func (s *limitService) Check(ctx context.Context, tx *sql.Tx, wallet model.Wallet, operation string, amount float64) error {
+	rules, err := s.repo.ReadActiveRules(ctx, operation, wallet.UserID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	now := time.Now()
+
+	for _, rule := range effectiveRules(rules) {
+		if rule.Type == model.LimitTypeSingle {
+			if amount > rule.Threshold {
+				return limitExceeded(rule, amount, nil)
+			}
+			continue
+		}
+
+		window := time.Duration(rule.WindowSeconds) * time.Second
+
+		usage, err := s.repo.ReadUsage(ctx, tx, wallet.ID, operation, now.Add(-window))
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return err
+		}
+
+		var resetsAt *time.Time
+		if usage.Oldest != nil {
+			t := usage.Oldest.Add(window)
+			resetsAt = &t
+		}
+
+		switch rule.Type {
+		case model.LimitTypeCount:
+			if float64(usage.Count+1) > rule.Threshold {
+				return limitExceeded(rule, float64(usage.Count), resetsAt)
+			}
+		case model.LimitTypeAmount:
+			if usage.Amount+amount > rule.Threshold {
+				return limitExceeded(rule, usage.Amount, resetsAt)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *limitService) ListRules(ctx context.Context) ([]model.LimitRule, error) {
+	rules, err := s.repo.ReadRules(ctx)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// ValidateRule checks a new limit rule, when ruleID is zero, or a change to
+// an existing one before it is proposed. Rules loosen or tighten limits for
+// every user they apply to, so they are only made through ApplyRule once a
+// second admin approves them.
+func (s *limitService) ValidateRule(ctx context.Context, ruleID int64, req model.LimitRuleRequest) error {
+	_, err := s.ruleFor(ctx, ruleID, req)
+	return err
+}
+
+// ApplyRule creates a limit rule in tx when ruleID is zero, and replaces the
+// rule otherwise. It is run once a second admin approves the change.
+func (s *limitService) ApplyRule(ctx context.Context, tx *sql.Tx, ruleID int64, req model.LimitRuleRequest) (*model.LimitRule, error) {
+	rule, err := s.ruleFor(ctx, ruleID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ruleID == 0 {
+		rule.ID, err = s.repo.WriteRule(ctx, tx, *rule)
+	} else {
+		err = s.repo.UpdateRule(ctx, tx, *rule)
+	}
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// ruleFor returns the rule req makes of the rule ruleID, or of a new one
+// when ruleID is zero.
+func (s *limitService) ruleFor(ctx context.Context, ruleID int64, req model.LimitRuleRequest) (*model.LimitRule, error) {
+	if req.Type != model.LimitTypeSingle && req.WindowSeconds <= 0 {
+		return nil, cs.ErrBadParamInput
+	}
+
+	rule := &model.LimitRule{}
+	if ruleID != 0 {
+		var err error
+		rule, err = s.repo.ReadRuleByID(ctx, ruleID)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		if rule == nil {
+			return nil, cs.ErrNotFound
+		}
+	}
+
+	rule.Name = req.Name
+	rule.Operation = req.Operation
+	rule.Type = req.Type
+	rule.Threshold = req.Threshold
+	rule.WindowSeconds = req.WindowSeconds
+	rule.UserID = req.UserID
+	rule.Active = req.Active
+
+	return rule, nil
+}
+
+// effectiveRules drops every global rule that a user specific rule of the
+// same type and window overrides.
+func effectiveRules(rules []model.LimitRule) []model.LimitRule {
+	overridden := map[string]bool{}
+	for _, rule := range rules {
+		if rule.UserID != nil {
+			overridden[ruleKey(rule)] = true
+		}
+	}
+
+	effective := make([]model.LimitRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.UserID == nil && overridden[ruleKey(rule)] {
+			continue
+		}
+		effective = append(effective, rule)
+	}
+
+	return effective
+}
+
+func ruleKey(rule model.LimitRule) string {
+	return fmt.Sprintf("%s:%d", rule.Type, rule.WindowSeconds)
+}
+
+func limitExceeded(rule model.LimitRule, current float64, resetsAt *time.Time) error {
+	return &model.LimitExceededError{
+		RuleID:    rule.ID,
+		Rule:      rule.Name,
+		Operation: rule.Operation,
+		Type:      rule.Type,
+		Threshold: rule.Threshold,
+		Current:   current,
+		ResetsAt:  resetsAt,
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_limitService_Check(t *testing.T) {
+	db, mockDB := dbConn()
+	tx := beginTx(db, mockDB)
+
+	ctx := context.Background()
+	userID := int64(1)
+	oldest := time.Now().Add(-30 * time.Second)
+	wallet := model.Wallet{ID: 3, UserID: userID}
+
+	perMinute := model.LimitRule{ID: 1, Name: "5 payments per minute", Operation: model.TransactionTypePayment, Type: model.LimitTypeCount, Threshold: 5, WindowSeconds: 60, Active: true}
+	perDay := model.LimitRule{ID: 2, Name: "10M per day", Operation: model.TransactionTypePayment, Type: model.LimitTypeAmount, Threshold: 10000000, WindowSeconds: 86400, Active: true}
+	single := model.LimitRule{ID: 3, Name: "single payment", Operation: model.TransactionTypePayment, Type: model.LimitTypeSingle, Threshold: 1000000, Active: true}
+	override := model.LimitRule{ID: 4, Name: "vip per minute", Operation: model.TransactionTypePayment, Type: model.LimitTypeCount, Threshold: 50, WindowSeconds: 60, UserID: &userID, Active: true}
+
+	tests := []struct {
+		name     string
+		rules    []model.LimitRule
+		usage    model.LimitUsage
+		amount   float64
+		wantRule int64
+		wantErr  bool
+	}{
+		{
+			name:    "positif: within limits",
+			rules:   []model.LimitRule{perMinute, perDay, single},
+			usage:   model.LimitUsage{Count: 2, Amount: 500000, Oldest: &oldest},
+			amount:  100000,
+			wantErr: false,
+		},
+		{
+			name:     "negatif: velocity",
+			rules:    []model.LimitRule{perMinute},
+			usage:    model.LimitUsage{Count: 5, Amount: 500000, Oldest: &oldest},
+			amount:   100000,
+			wantRule: perMinute.ID,
+			wantErr:  true,
+		},
+		{
+			name:     "negatif: daily amount",
+			rules:    []model.LimitRule{perDay},
+			usage:    model.LimitUsage{Count: 5, Amount: 9950000, Oldest: &oldest},
+			amount:   100000,
+			wantRule: perDay.ID,
+			wantErr:  true,
+		},
+		{
+			name:     "negatif: single payment",
+			rules:    []model.LimitRule{single},
+			amount:   2000000,
+			wantRule: single.ID,
+			wantErr:  true,
+		},
+		{
+			name:    "positif: user override replaces global rule",
+			rules:   []model.LimitRule{perMinute, override},
+			usage:   model.LimitUsage{Count: 5, Amount: 500000, Oldest: &oldest},
+			amount:  100000,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLimitRepo := mocks.LimitRepository{}
+
+			mockLimitRepo.On("ReadActiveRules", ctx, model.TransactionTypePayment, userID).Return(tt.rules, nil)
+			mockLimitRepo.On("ReadUsage", ctx, tx, wallet.ID, model.TransactionTypePayment, mock.Anything).Return(tt.usage, nil)
+
+			s := NewLimitService(&mockLimitRepo)
+
+			err := s.Check(ctx, tx, wallet, model.TransactionTypePayment, tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("limitService.Check() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil {
+				return
+			}
+
+			var limitErr *model.LimitExceededError
+			if !errors.As(err, &limitErr) || limitErr.RuleID != tt.wantRule {
+				t.Errorf("limitService.Check() error = %v, want rule %v", err, tt.wantRule)
+				return
+			}
+
+			if limitErr.Type == model.LimitTypeSingle {
+				return
+			}
+
+			wantResetsAt := oldest.Add(time.Duration(tt.rules[0].WindowSeconds) * time.Second)
+			if limitErr.ResetsAt == nil || !limitErr.ResetsAt.Equal(wantResetsAt) {
+				t.Errorf("limitService.Check() resets at = %v, want %v", limitErr.ResetsAt, wantResetsAt)
+			}
+		})
+	}
+}
+
+func Test_limitService_ApplyRule(t *testing.T) {
+	db, mockDB := dbConn()
+	ctx := context.Background()
+	userID := int64(3)
+
+	existing := &model.LimitRule{ID: 8, Name: "5 per minute", Operation: model.TransactionTypePayment, Type: model.LimitTypeCount, Threshold: 5, WindowSeconds: 60, Active: true}
+
+	tests := []struct {
+		name    string
+		ruleID  int64
+		args    model.LimitRuleRequest
+		wantErr error
+	}{
+		{
+			name:    "positif: create",
+			args:    model.LimitRuleRequest{Name: "5 per minute", Operation: model.TransactionTypePayment, Type: model.LimitTypeCount, Threshold: 5, WindowSeconds: 60, Active: true},
+			wantErr: nil,
+		},
+		{
+			name:    "positif: user override",
+			args:    model.LimitRuleRequest{Name: "20 per minute", Operation: model.TransactionTypePayment, Type: model.LimitTypeCount, Threshold: 20, WindowSeconds: 60, UserID: &userID, Active: true},
+			wantErr: nil,
+		},
+		{
+			name:    "positif: update",
+			ruleID:  8,
+			args:    model.LimitRuleRequest{Name: "10 per minute", Operation: model.TransactionTypePayment, Type: model.LimitTypeCount, Threshold: 10, WindowSeconds: 60, Active: true},
+			wantErr: nil,
+		},
+		{
+			name:    "negatif: windowed rule without window",
+			args:    model.LimitRuleRequest{Name: "5 per minute", Operation: model.TransactionTypePayment, Type: model.LimitTypeCount, Threshold: 5},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: unknown rule",
+			ruleID:  9,
+			args:    model.LimitRuleRequest{Name: "10 per minute", Operation: model.TransactionTypePayment, Type: model.LimitTypeCount, Threshold: 10, WindowSeconds: 60, Active: true},
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			mockDB.ExpectRollback()
+			defer tx.Rollback()
+
+			mockLimitRepo := mocks.LimitRepository{}
+			mockLimitRepo.On("ReadRuleByID", ctx, int64(8)).Return(existing, nil)
+			mockLimitRepo.On("ReadRuleByID", ctx, int64(9)).Return(nil, nil)
+			mockLimitRepo.On("WriteRule", ctx, tx, mock.Anything).Return(int64(8), nil)
+			mockLimitRepo.On("UpdateRule", ctx, tx, mock.Anything).Return(nil)
+
+			s := NewLimitService(&mockLimitRepo)
+
+			got, err := s.ApplyRule(ctx, tx, tt.ruleID, tt.args)
+			if err != tt.wantErr {
+				t.Errorf("limitService.ApplyRule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil && (got.ID != 8 || got.Threshold != tt.args.Threshold) {
+				t.Errorf("limitService.ApplyRule() = %v", got)
+			}
+
+			if tt.wantErr != nil {
+				mockLimitRepo.AssertNotCalled(t, "WriteRule", ctx, mock.Anything, mock.Anything)
+				mockLimitRepo.AssertNotCalled(t, "UpdateRule", ctx, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/emvqr"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// merchantQRSize is the width and height in pixels of generated QR images.
+const merchantQRSize = 256
+
+type merchantService struct {
+	repo       repository.MerchantRepository
+	walletRepo repository.WalletRepository
+}
+
+type MerchantService interface {
+	Create(ctx context.Context, userID int64, req model.MerchantRequest) (*model.Merchant, error)
+	Get(ctx context.Context, merchantID int64, requester model.User) (*model.Merchant, error)
+	List(ctx context.Context, filter model.MerchantFilter) ([]model.Merchant, error)
+	UpdateStatus(ctx context.Context, merchantID int64, status string) (*model.Merchant, error)
+	ListPayments(ctx context.Context, merchantID int64, requester model.User) ([]model.MerchantPayment, error)
+	QRCode(ctx context.Context, merchantID int64, requester model.User, amount float64) (*model.MerchantQR, error)
+}
+
+func NewMerchantService(merchantRepo repository.MerchantRepository, walletRepo repository.WalletRepository) MerchantService {
+	return &merchantService{
+		repo:       merchantRepo,
+		walletRepo: walletRepo,
+	}
+}
+
+// Create onboards a merchant owned by userID with an empty settlement wallet.
+// The merchant stays pending until an admin activates it.
+func (s *merchantService) Create(ctx context.Context, userID int64, req model.MerchantRequest) (merchant *model.Merchant, err error) {
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	merchant = &model.Merchant{
+		UserID: userID,
+		Name:   req.Name,
+		Status: model.MerchantStatusPending,
+	}
+
+	merchant.WalletID, err = s.walletRepo.AddWallet(ctx, tx, model.Wallet{
+		Address: utils.GenerateReference(),
+		UserID:  userID,
+		Kind:    model.WalletKindSettlement,
+	})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	merchant.ID, err = s.repo.WriteMerchant(ctx, tx, *merchant)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
+// Get returns a merchant to its owner or to staff.
+func (s *merchantService) Get(ctx context.Context, merchantID int64, requester model.User) (*model.Merchant, error) {
+	merchant, err := s.repo.ReadMerchantByID(ctx, merchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if merchant == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if merchant.UserID != requester.ID && !requester.IsStaff() {
+		return nil, cs.ErrForbidden
+	}
+
+	return merchant, nil
+}
+
+func (s *merchantService) List(ctx context.Context, filter model.MerchantFilter) ([]model.Merchant, error) {
+	merchants, err := s.repo.ReadMerchants(ctx, filter)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return merchants, nil
+}
+
+func (s *merchantService) UpdateStatus(ctx context.Context, merchantID int64, status string) (*model.Merchant, error) {
+	err := s.repo.UpdateMerchantStatus(ctx, merchantID, status)
+	if err == sql.ErrNoRows {
+		return nil, cs.ErrNotFound
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	merchant, err := s.repo.ReadMerchantByID(ctx, merchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
+func (s *merchantService) ListPayments(ctx context.Context, merchantID int64, requester model.User) ([]model.MerchantPayment, error) {
+	if _, err := s.Get(ctx, merchantID, requester); err != nil {
+		return nil, err
+	}
+
+	payments, err := s.repo.ReadMerchantPayments(ctx, merchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+// QRCode issues a QR code for customers to pay the merchant with. A zero
+// amount gives a static code where the payer enters the amount.
+func (s *merchantService) QRCode(ctx context.Context, merchantID int64, requester model.User, amount float64) (*model.MerchantQR, error) {
+	merchant, err := s.Get(ctx, merchantID, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	if merchant.Status != model.MerchantStatusActive {
+		return nil, cs.ErrMerchantNotActive
+	}
+
+	payload, err := emvqr.Encode(emvqr.Payload{
+		MerchantID:   merchant.ID,
+		MerchantName: merchant.Name,
+		Currency:     model.CurrencyIDR,
+		Amount:       amount,
+	})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	image, err := emvqr.Image(payload, merchantQRSize)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &model.MerchantQR{
+		MerchantID: merchant.ID,
+		Amount:     amount,
+		Payload:    payload,
+		Image:      image,
+	}, nil
+}
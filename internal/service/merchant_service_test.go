@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/emvqr"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_merchantService_Create(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	tx := beginTx(db, mockDB)
+	mockDB.ExpectCommit()
+
+	mockRepo := mocks.MerchantRepository{}
+	mockWalletRepo := mocks.WalletRepository{}
+
+	mockRepo.On("BeginTx", ctx).Return(tx)
+	mockWalletRepo.On("AddWallet", ctx, tx, mock.MatchedBy(func(w model.Wallet) bool {
+		return w.UserID == 5 && w.Kind == model.WalletKindSettlement && w.Address != "" && w.Balance == 0
+	})).Return(int64(20), nil)
+	mockRepo.On("WriteMerchant", ctx, tx, model.Merchant{
+		UserID:   5,
+		Name:     "Ojek",
+		WalletID: 20,
+		Status:   model.MerchantStatusPending,
+	}).Return(int64(8), nil)
+
+	s := NewMerchantService(&mockRepo, &mockWalletRepo)
+	got, err := s.Create(ctx, 5, model.MerchantRequest{Name: "Ojek"})
+	if err != nil {
+		t.Fatalf("merchantService.Create() error = %v", err)
+	}
+	if got.ID != 8 || got.WalletID != 20 || got.Status != model.MerchantStatusPending {
+		t.Errorf("merchantService.Create() = %+v", got)
+	}
+}
+
+func Test_merchantService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	merchant := &model.Merchant{ID: 8, UserID: 5, WalletID: 20, Status: model.MerchantStatusActive}
+
+	tests := []struct {
+		name      string
+		requester model.User
+		wantErr   error
+	}{
+		{
+			name:      "positif: owner",
+			requester: model.User{ID: 5, Role: model.RoleUser},
+		},
+		{
+			name:      "positif: staff",
+			requester: model.User{ID: 2, Role: model.RoleSupport},
+		},
+		{
+			name:      "negatif: another user",
+			requester: model.User{ID: 6, Role: model.RoleUser},
+			wantErr:   cs.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.MerchantRepository{}
+			mockRepo.On("ReadMerchantByID", ctx, merchant.ID).Return(merchant, nil)
+
+			s := NewMerchantService(&mockRepo, &mocks.WalletRepository{})
+			if _, err := s.Get(ctx, merchant.ID, tt.requester); err != tt.wantErr {
+				t.Errorf("merchantService.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_merchantService_QRCode(t *testing.T) {
+	ctx := context.Background()
+	owner := model.User{ID: 5, Role: model.RoleUser}
+
+	tests := []struct {
+		name     string
+		merchant *model.Merchant
+		amount   float64
+		wantErr  error
+	}{
+		{
+			name:     "positif: static",
+			merchant: &model.Merchant{ID: 8, UserID: 5, Name: "Ojek", Status: model.MerchantStatusActive},
+		},
+		{
+			name:     "positif: dynamic",
+			merchant: &model.Merchant{ID: 8, UserID: 5, Name: "Ojek", Status: model.MerchantStatusActive},
+			amount:   25000,
+		},
+		{
+			name:     "negatif: merchant not active",
+			merchant: &model.Merchant{ID: 8, UserID: 5, Name: "Ojek", Status: model.MerchantStatusPending},
+			wantErr:  cs.ErrMerchantNotActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.MerchantRepository{}
+			mockRepo.On("ReadMerchantByID", ctx, tt.merchant.ID).Return(tt.merchant, nil)
+
+			s := NewMerchantService(&mockRepo, &mocks.WalletRepository{})
+			got, err := s.QRCode(ctx, tt.merchant.ID, owner, tt.amount)
+			if err != tt.wantErr {
+				t.Errorf("merchantService.QRCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			payload, err := emvqr.Decode(got.Payload)
+			if err != nil || payload.MerchantID != tt.merchant.ID || payload.Amount != tt.amount || len(got.Image) == 0 {
+				t.Errorf("merchantService.QRCode() = %+v, decode error %v", got, err)
+			}
+		})
+	}
+}
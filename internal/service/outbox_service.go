@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/broker"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+const (
+	// outboxSubjectPrefix is prepended to an event's type to make the
+	// subject it is published on.
+	outboxSubjectPrefix = "starworks."
+	// outboxBatchSize is how many pending events one pass of Relay picks up.
+	outboxBatchSize = 100
+	// outboxRetryBackoff is the wait after the first failed attempt to
+	// publish an event, doubled after each further one up to
+	// outboxMaxBackoff. Events are never given up on.
+	outboxRetryBackoff = 5 * time.Second
+	outboxMaxBackoff   = 10 * time.Minute
+	// outboxLease is how long a relay has to publish an event it claimed
+	// before another relay may pick it up.
+	outboxLease = time.Minute
+)
+
+// EventRecorder records domain events in the caller's transaction, so they
+// are published if and only if the change they report is committed.
+type EventRecorder interface {
+	Record(ctx context.Context, tx *sql.Tx, userID int64, eventType string, data interface{}) error
+}
+
+type outboxService struct {
+	repo   repository.OutboxRepository
+	broker broker.Broker
+}
+
+type OutboxService interface {
+	EventRecorder
+	Relay(ctx context.Context) (int64, error)
+}
+
+func NewOutboxService(outboxRepo repository.OutboxRepository, b broker.Broker) OutboxService {
+	return &outboxService{
+		repo:   outboxRepo,
+		broker: b,
+	}
+}
+
+// Record adds an event about userID to the outbox.
+func (s *outboxService) Record(ctx context.Context, tx *sql.Tx, userID int64, eventType string, data interface{}) error {
+	evt := model.DomainEvent{
+		ID:         utils.GenerateReference(),
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	_, err = s.repo.WriteEvent(ctx, tx, model.OutboxEvent{
+		EventID:       evt.ID,
+		Type:          eventType,
+		Key:           strconv.FormatInt(userID, 10),
+		Payload:       payload,
+		NextAttemptAt: evt.OccurredAt,
+	})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Relay publishes the pending events in the order they were recorded and
+// returns how many were published. It stops at the first event the broker
+// does not accept, so later events do not overtake it; that event is
+// retried with exponential backoff. Events another relay claimed first are
+// skipped.
+func (s *outboxService) Relay(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	pending, err := s.repo.ReadPendingEvents(ctx, now, outboxBatchSize)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	var published int64
+	for _, event := range pending {
+		if err = s.repo.ClaimEvent(ctx, event, now, now.Add(outboxLease)); err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			logger.Log.Error(err.Error())
+			return published, err
+		}
+
+		pubErr := s.broker.Publish(ctx, broker.Message{
+			ID:      event.EventID,
+			Subject: outboxSubjectPrefix + event.Type,
+			Key:     event.Key,
+			Data:    event.Payload,
+		})
+
+		at := time.Now()
+		event.Attempts++
+
+		if pubErr == nil {
+			event.LastError = ""
+			event.PublishedAt = &at
+		} else {
+			backoff := outboxMaxBackoff
+			if event.Attempts < 16 && outboxRetryBackoff<<(event.Attempts-1) < outboxMaxBackoff {
+				backoff = outboxRetryBackoff << (event.Attempts - 1)
+			}
+			event.NextAttemptAt = at.Add(backoff)
+			event.LastError = pubErr.Error()
+			if len(event.LastError) > 255 {
+				event.LastError = event.LastError[:255]
+			}
+			logger.Log.Error(fmt.Sprintf("publishing outbox event %s: %s", event.EventID, event.LastError))
+		}
+
+		if err = s.repo.UpdateEvent(ctx, event); err != nil {
+			logger.Log.Error(err.Error())
+			return published, err
+		}
+
+		if pubErr != nil {
+			break
+		}
+		published++
+	}
+
+	return published, nil
+}
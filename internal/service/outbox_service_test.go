@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/broker"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// flakyBroker rejects the messages whose IDs are in fail and records the
+// ones it accepts.
+type flakyBroker struct {
+	fail      map[string]bool
+	published *[]broker.Message
+}
+
+func (b flakyBroker) Publish(ctx context.Context, msg broker.Message) error {
+	if b.fail[msg.ID] {
+		return errors.New("broker unavailable")
+	}
+	*b.published = append(*b.published, msg)
+	return nil
+}
+
+func (b flakyBroker) Close() error { return nil }
+
+func Test_outboxService_Relay(t *testing.T) {
+	ctx := context.Background()
+
+	pending := []model.OutboxEvent{
+		{ID: 1, EventID: "E1", Type: model.WebhookEventUserRegistered, Key: "1", Payload: []byte(`{"id":"E1"}`)},
+		{ID: 2, EventID: "E2", Type: model.WebhookEventTopUpCompleted, Key: "1", Payload: []byte(`{"id":"E2"}`), Attempts: 3},
+		{ID: 3, EventID: "E3", Type: model.WebhookEventPaymentSent, Key: "2", Payload: []byte(`{"id":"E3"}`)},
+	}
+
+	tests := []struct {
+		name          string
+		fail          map[string]bool
+		claimed       map[int64]bool
+		wantPublished []string
+		wantUpdated   int
+	}{
+		{
+			name:          "positif",
+			wantPublished: []string{"E1", "E2", "E3"},
+			wantUpdated:   3,
+		},
+		{
+			name:          "positif: claimed elsewhere",
+			claimed:       map[int64]bool{2: true},
+			wantPublished: []string{"E1", "E3"},
+			wantUpdated:   2,
+		},
+		{
+			name:          "negatif: stops at the first failure",
+			fail:          map[string]bool{"E2": true},
+			wantPublished: []string{"E1"},
+			wantUpdated:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.OutboxRepository{}
+			mockRepo.On("ReadPendingEvents", mock.Anything, mock.Anything, outboxBatchSize).Return(pending, nil)
+			for _, event := range pending {
+				var err error
+				if tt.claimed[event.ID] {
+					err = sql.ErrNoRows
+				}
+				mockRepo.On("ClaimEvent", mock.Anything, event, mock.Anything, mock.Anything).Return(err)
+			}
+
+			updated := map[string]model.OutboxEvent{}
+			mockRepo.On("UpdateEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				event := args.Get(1).(model.OutboxEvent)
+				updated[event.EventID] = event
+			}).Return(nil)
+
+			var published []broker.Message
+			s := NewOutboxService(&mockRepo, flakyBroker{fail: tt.fail, published: &published})
+
+			before := time.Now()
+			got, err := s.Relay(ctx)
+			if err != nil {
+				t.Fatalf("outboxService.Relay() error = %v", err)
+			}
+			if got != int64(len(tt.wantPublished)) || len(published) != len(tt.wantPublished) {
+				t.Fatalf("outboxService.Relay() = %d, published %v, want %v", got, published, tt.wantPublished)
+			}
+
+			for i, id := range tt.wantPublished {
+				if published[i].ID != id || published[i].Subject != outboxSubjectPrefix+updated[id].Type {
+					t.Errorf("published[%d] = %s on %s, want %s", i, published[i].ID, published[i].Subject, id)
+				}
+				if updated[id].PublishedAt == nil {
+					t.Errorf("event %s not marked published", id)
+				}
+			}
+
+			if len(updated) != tt.wantUpdated {
+				t.Errorf("outboxService.Relay() updated %d event(s), want %d", len(updated), tt.wantUpdated)
+			}
+
+			for id := range tt.fail {
+				failed := updated[id]
+				if failed.PublishedAt != nil || failed.Attempts != 4 || failed.LastError == "" {
+					t.Errorf("failed event recorded as %+v", failed)
+				}
+				if failed.NextAttemptAt.Before(before.Add(8 * outboxRetryBackoff)) {
+					t.Errorf("failed event retried at %v, want %v later", failed.NextAttemptAt, 8*outboxRetryBackoff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+type paymentRequestService struct {
+	repo       repository.PaymentRequestRepository
+	userRepo   repository.UserRepository
+	walletRepo repository.WalletRepository
+}
+
+type PaymentRequestService interface {
+	Create(ctx context.Context, req model.CreatePaymentRequest) (*model.PaymentRequest, error)
+	Get(ctx context.Context, code string, viewer model.User) (*model.PaymentRequest, error)
+	List(ctx context.Context, userID int64) ([]model.PaymentRequest, error)
+	Cancel(ctx context.Context, code string, userID int64) (*model.PaymentRequest, error)
+}
+
+const (
+	// defaultPaymentRequestExpiry applies to requests created without an
+	// explicit expiry.
+	defaultPaymentRequestExpiry = 7 * 24 * time.Hour
+	paymentRequestCodeLength    = 8
+)
+
+func NewPaymentRequestService(requestRepo repository.PaymentRequestRepository, userRepo repository.UserRepository, walletRepo repository.WalletRepository) PaymentRequestService {
+	return &paymentRequestService{
+		repo:       requestRepo,
+		userRepo:   userRepo,
+		walletRepo: walletRepo,
+	}
+}
+
+// Create requests a payment into the caller's wallet. req.Payer, a username
+// or email, restricts who may pay it; without it anyone with the code can.
+func (s *paymentRequestService) Create(ctx context.Context, req model.CreatePaymentRequest) (*model.PaymentRequest, error) {
+	wallet, err := s.walletRepo.ReadBalance(ctx, model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if wallet.ID == 0 {
+		return nil, cs.ErrNotFound
+	}
+
+	expiry := defaultPaymentRequestExpiry
+	if req.ExpiresInSeconds > 0 {
+		expiry = time.Duration(req.ExpiresInSeconds) * time.Second
+	}
+
+	request := model.PaymentRequest{
+		Code:        utils.GenerateShortCode(paymentRequestCodeLength),
+		RequesterID: req.UserID,
+		WalletID:    wallet.ID,
+		Amount:      req.Amount,
+		Currency:    model.CurrencyIDR,
+		Description: req.Description,
+		Status:      model.PaymentRequestPending,
+		ExpiresAt:   time.Now().Add(expiry),
+	}
+
+	if req.Payer != "" {
+		payer, err := s.userRepo.ReadByUsernameOrEmail(ctx, req.Payer, req.Payer)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		if payer == nil {
+			return nil, cs.ErrNotFound
+		}
+
+		if payer.ID == req.UserID {
+			return nil, cs.ErrBadParamInput
+		}
+
+		request.PayerID = &payer.ID
+	}
+
+	tx := s.walletRepo.BeginTx(ctx)
+
+	request.ID, err = s.repo.WritePaymentRequest(ctx, tx, request)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// Get returns a request to its requester, its payer and staff. Requests
+// without a payer are visible to anyone holding the code.
+func (s *paymentRequestService) Get(ctx context.Context, code string, viewer model.User) (*model.PaymentRequest, error) {
+	request, err := s.repo.ReadPaymentRequestByCode(ctx, code)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if request == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if request.PayerID != nil && *request.PayerID != viewer.ID && request.RequesterID != viewer.ID && !viewer.IsStaff() {
+		return nil, cs.ErrNotFound
+	}
+
+	return withEffectiveStatus(request), nil
+}
+
+// List returns the requests a user made or was asked to pay.
+func (s *paymentRequestService) List(ctx context.Context, userID int64) ([]model.PaymentRequest, error) {
+	requests, err := s.repo.ReadPaymentRequestsByUser(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	for i := range requests {
+		withEffectiveStatus(&requests[i])
+	}
+
+	return requests, nil
+}
+
+// Cancel withdraws a pending request. Only its requester can cancel it.
+func (s *paymentRequestService) Cancel(ctx context.Context, code string, userID int64) (*model.PaymentRequest, error) {
+	request, err := s.repo.ReadPaymentRequestByCode(ctx, code)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if request == nil || request.RequesterID != userID {
+		return nil, cs.ErrNotFound
+	}
+
+	if request.Status != model.PaymentRequestPending || request.Expired(time.Now()) {
+		return nil, cs.ErrPaymentRequestNotActive
+	}
+
+	if err = s.repo.CancelPaymentRequest(ctx, request.ID); err == sql.ErrNoRows {
+		return nil, cs.ErrPaymentRequestNotActive
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	request.Status = model.PaymentRequestCancelled
+
+	return request, nil
+}
+
+// withEffectiveStatus reports pending requests past their expiry as expired.
+// Expiry is never stored; a request stops being payable the moment it passes.
+func withEffectiveStatus(request *model.PaymentRequest) *model.PaymentRequest {
+	if request.Expired(time.Now()) {
+		request.Status = model.PaymentRequestExpired
+	}
+
+	return request
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_paymentRequestService_Create(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	req := model.CreatePaymentRequest{
+		Amount:      25000,
+		Description: "dinner",
+		UserID:      1,
+		Address:     "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+	}
+
+	tests := []struct {
+		name        string
+		payer       string
+		wantPayerID int64
+		wantErr     error
+	}{
+		{
+			name: "positif: anyone can pay",
+		},
+		{
+			name:        "positif: named payer",
+			payer:       "budi",
+			wantPayerID: 2,
+		},
+		{
+			name:    "negatif: unknown payer",
+			payer:   "nobody",
+			wantErr: cs.ErrNotFound,
+		},
+		{
+			name:    "negatif: requester as payer",
+			payer:   "andi",
+			wantErr: cs.ErrBadParamInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.PaymentRequestRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockWalletRepo := mocks.WalletRepository{}
+
+			tx := beginTx(db, mockDB)
+			if tt.wantErr == nil {
+				mockDB.ExpectCommit()
+			}
+
+			mockWalletRepo.On("BeginTx", ctx).Return(tx)
+			mockWalletRepo.On("ReadBalance", ctx, model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}).Return(&model.Wallet{ID: 3, UserID: req.UserID}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "budi", "budi").Return(&model.User{ID: 2}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "andi", "andi").Return(&model.User{ID: 1}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "nobody", "nobody").Return(nil, nil)
+			mockRepo.On("WritePaymentRequest", ctx, tx, mock.MatchedBy(func(r model.PaymentRequest) bool {
+				return len(r.Code) == paymentRequestCodeLength && r.WalletID == 3 && r.Currency == model.CurrencyIDR && r.Status == model.PaymentRequestPending
+			})).Return(int64(1), nil)
+
+			req.Payer = tt.payer
+
+			s := NewPaymentRequestService(&mockRepo, &mockUserRepo, &mockWalletRepo)
+			got, err := s.Create(ctx, req)
+			if err != tt.wantErr {
+				t.Errorf("paymentRequestService.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if (got.PayerID == nil) != (tt.wantPayerID == 0) || (got.PayerID != nil && *got.PayerID != tt.wantPayerID) {
+				t.Errorf("paymentRequestService.Create() payer = %v, want %v", got.PayerID, tt.wantPayerID)
+			}
+		})
+	}
+}
+
+func Test_paymentRequestService_Get(t *testing.T) {
+	ctx := context.Background()
+	payerID := int64(2)
+
+	tests := []struct {
+		name       string
+		request    model.PaymentRequest
+		viewer     model.User
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name:       "positif: open request",
+			request:    model.PaymentRequest{Code: "K7PQ2MZX", RequesterID: 1, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(time.Hour)},
+			viewer:     model.User{ID: 6, Role: model.RoleUser},
+			wantStatus: model.PaymentRequestPending,
+		},
+		{
+			name:       "positif: expired",
+			request:    model.PaymentRequest{Code: "K7PQ2MZX", RequesterID: 1, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(-time.Hour)},
+			viewer:     model.User{ID: 1, Role: model.RoleUser},
+			wantStatus: model.PaymentRequestExpired,
+		},
+		{
+			name:    "negatif: someone else's request",
+			request: model.PaymentRequest{Code: "K7PQ2MZX", RequesterID: 1, PayerID: &payerID, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(time.Hour)},
+			viewer:  model.User{ID: 6, Role: model.RoleUser},
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.request
+
+			mockRepo := mocks.PaymentRequestRepository{}
+			mockRepo.On("ReadPaymentRequestByCode", ctx, request.Code).Return(&request, nil)
+
+			s := NewPaymentRequestService(&mockRepo, &mocks.UserRepository{}, &mocks.WalletRepository{})
+			got, err := s.Get(ctx, request.Code, tt.viewer)
+			if err != tt.wantErr {
+				t.Errorf("paymentRequestService.Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && got.Status != tt.wantStatus {
+				t.Errorf("paymentRequestService.Get() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func Test_paymentRequestService_Cancel(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		request   model.PaymentRequest
+		userID    int64
+		cancelErr error
+		wantErr   error
+	}{
+		{
+			name:    "positif",
+			request: model.PaymentRequest{ID: 1, Code: "K7PQ2MZX", RequesterID: 1, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(time.Hour)},
+			userID:  1,
+		},
+		{
+			name:    "negatif: not the requester",
+			request: model.PaymentRequest{ID: 1, Code: "K7PQ2MZX", RequesterID: 1, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(time.Hour)},
+			userID:  2,
+			wantErr: cs.ErrNotFound,
+		},
+		{
+			name:    "negatif: already paid",
+			request: model.PaymentRequest{ID: 1, Code: "K7PQ2MZX", RequesterID: 1, Status: model.PaymentRequestPaid, ExpiresAt: time.Now().Add(time.Hour)},
+			userID:  1,
+			wantErr: cs.ErrPaymentRequestNotActive,
+		},
+		{
+			name:      "negatif: paid concurrently",
+			request:   model.PaymentRequest{ID: 1, Code: "K7PQ2MZX", RequesterID: 1, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(time.Hour)},
+			userID:    1,
+			cancelErr: sql.ErrNoRows,
+			wantErr:   cs.ErrPaymentRequestNotActive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := tt.request
+
+			mockRepo := mocks.PaymentRequestRepository{}
+			mockRepo.On("ReadPaymentRequestByCode", ctx, request.Code).Return(&request, nil)
+			mockRepo.On("CancelPaymentRequest", ctx, request.ID).Return(tt.cancelErr)
+
+			s := NewPaymentRequestService(&mockRepo, &mocks.UserRepository{}, &mocks.WalletRepository{})
+			got, err := s.Cancel(ctx, request.Code, tt.userID)
+			if err != tt.wantErr {
+				t.Errorf("paymentRequestService.Cancel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && got.Status != model.PaymentRequestCancelled {
+				t.Errorf("paymentRequestService.Cancel() status = %v", got.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/payout"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+const (
+	// payoutBatchSize is how many payouts of each status one pass of Poll
+	// picks up.
+	payoutBatchSize = 100
+	// payoutResubmitAfter is how long a payout the bank has not accepted
+	// waits before it is submitted again.
+	payoutResubmitAfter = time.Minute
+	// payoutPollAfter is how long the bank has to call back on a payout
+	// before it is asked for its status.
+	payoutPollAfter = 5 * time.Minute
+)
+
+type payoutService struct {
+	repo           repository.PayoutRepository
+	walletService  WalletService
+	provider       payout.PayoutProvider
+	callbackURL    string
+	callbackSecret string
+}
+
+type PayoutService interface {
+	AddBankAccount(ctx context.Context, req model.BankAccountRequest) (*model.BankAccount, error)
+	ListBankAccounts(ctx context.Context, userID int64) ([]model.BankAccount, error)
+	RemoveBankAccount(ctx context.Context, accountID, userID int64) error
+	Withdraw(ctx context.Context, req model.WithdrawRequest) (*model.Payout, error)
+	WithdrawHeld(ctx context.Context, held model.HeldTransaction) (*model.Payout, error)
+	Get(ctx context.Context, payoutID, userID int64) (*model.Payout, error)
+	List(ctx context.Context, userID int64) ([]model.Payout, error)
+	HandleCallback(ctx context.Context, body []byte, signature string) (*model.Payout, error)
+	Poll(ctx context.Context) (int64, error)
+}
+
+func NewPayoutService(payoutRepo repository.PayoutRepository, walletService WalletService, provider payout.PayoutProvider, callbackURL, callbackSecret string) PayoutService {
+	return &payoutService{
+		repo:           payoutRepo,
+		walletService:  walletService,
+		provider:       provider,
+		callbackURL:    callbackURL,
+		callbackSecret: callbackSecret,
+	}
+}
+
+func (s *payoutService) AddBankAccount(ctx context.Context, req model.BankAccountRequest) (*model.BankAccount, error) {
+	account := model.BankAccount{
+		UserID:        req.UserID,
+		BankCode:      req.BankCode,
+		AccountNumber: req.AccountNumber,
+		AccountName:   req.AccountName,
+		CreatedAt:     time.Now(),
+	}
+
+	var err error
+	account.ID, err = s.repo.WriteBankAccount(ctx, account)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (s *payoutService) ListBankAccounts(ctx context.Context, userID int64) ([]model.BankAccount, error) {
+	accounts, err := s.repo.ReadBankAccountsByUser(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// RemoveBankAccount removes an account from the user's saved accounts.
+// Payouts already made to it are still settled.
+func (s *payoutService) RemoveBankAccount(ctx context.Context, accountID, userID int64) error {
+	if err := s.repo.DeleteBankAccount(ctx, accountID, userID); err == sql.ErrNoRows {
+		return cs.ErrNotFound
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Withdraw debits the caller's wallet and records a pending payout in one
+// transaction, then submits it to the bank. A payout the bank rejects is
+// reverted straight away; one that could not be submitted stays pending and
+// is submitted again by Poll. A withdrawal the risk engine sends to review
+// is held, and made by WithdrawHeld once approved.
+func (s *payoutService) Withdraw(ctx context.Context, req model.WithdrawRequest) (*model.Payout, error) {
+	if err := s.walletService.AssessWithdrawal(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.withdraw(ctx, req)
+}
+
+// WithdrawHeld makes a withdrawal held for review once an admin has
+// approved it, without scoring it again. Balance and limit checks still
+// apply.
+func (s *payoutService) WithdrawHeld(ctx context.Context, held model.HeldTransaction) (*model.Payout, error) {
+	if held.Operation != model.TransactionTypeWithdrawal || held.Status != model.HeldTransactionApproved {
+		return nil, cs.ErrBadParamInput
+	}
+
+	var req model.WithdrawRequest
+	if err := json.Unmarshal([]byte(held.Payload), &req); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return s.withdraw(ctx, req)
+}
+
+func (s *payoutService) withdraw(ctx context.Context, req model.WithdrawRequest) (*model.Payout, error) {
+	account, err := s.repo.ReadBankAccountByID(ctx, req.BankAccountID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if account == nil || account.UserID != req.UserID || account.DeletedAt != nil {
+		return nil, cs.ErrNotFound
+	}
+
+	p, err := s.create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.submit(ctx, *p, *account)
+}
+
+func (s *payoutService) Get(ctx context.Context, payoutID, userID int64) (*model.Payout, error) {
+	p, err := s.repo.ReadPayoutByID(ctx, payoutID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if p == nil || p.UserID != userID {
+		return nil, cs.ErrNotFound
+	}
+
+	return p, nil
+}
+
+func (s *payoutService) List(ctx context.Context, userID int64) ([]model.Payout, error) {
+	payouts, err := s.repo.ReadPayoutsByUser(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return payouts, nil
+}
+
+// HandleCallback settles a payout from a bank callback signed with the
+// callback secret. Callbacks may be repeated; one that contradicts how the
+// payout was already settled is refused.
+func (s *payoutService) HandleCallback(ctx context.Context, body []byte, signature string) (*model.Payout, error) {
+	if !payout.Verify(s.callbackSecret, body, signature) {
+		return nil, cs.ErrInvalidSignature
+	}
+
+	var result payout.Result
+	if err := json.Unmarshal(body, &result); err != nil || result.Reference == "" {
+		return nil, cs.ErrBadParamInput
+	}
+
+	return s.settle(ctx, result)
+}
+
+// Poll catches up on payouts the bank has not called back on: pending
+// payouts are submitted again and the bank is asked about processing ones.
+// It returns how many payouts were settled.
+func (s *payoutService) Poll(ctx context.Context) (int64, error) {
+	pending, err := s.repo.ReadPayoutsByStatus(ctx, model.PayoutStatusPending, time.Now().Add(-payoutResubmitAfter), payoutBatchSize)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	processing, err := s.repo.ReadPayoutsByStatus(ctx, model.PayoutStatusProcessing, time.Now().Add(-payoutPollAfter), payoutBatchSize)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	var settled int64
+	for _, p := range append(pending, processing...) {
+		got, err := s.refresh(ctx, p)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("polling payout %s: %s", p.Reference, err.Error()))
+			continue
+		}
+
+		if got.Finalized() {
+			settled++
+		}
+	}
+
+	return settled, nil
+}
+
+// create debits the wallet and records the payout in one transaction.
+func (s *payoutService) create(ctx context.Context, req model.WithdrawRequest) (p *model.Payout, err error) {
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+		s.walletService.AfterTx(tx, err == nil)
+	}()
+
+	debit, err := s.walletService.Withdraw(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	p = &model.Payout{
+		UserID:        req.UserID,
+		WalletID:      debit.WalletID,
+		BankAccountID: req.BankAccountID,
+		Amount:        req.Amount,
+		Currency:      model.CurrencyIDR,
+		Status:        model.PayoutStatusPending,
+		Reference:     debit.Reference,
+		TransactionID: debit.ID,
+	}
+
+	if p.ID, err = s.repo.WritePayout(ctx, tx, *p); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+
+	return p, nil
+}
+
+// submit sends a pending payout to the bank and records its answer. Failing
+// to reach the bank is not an error: the payout stays pending.
+func (s *payoutService) submit(ctx context.Context, p model.Payout, account model.BankAccount) (*model.Payout, error) {
+	result, err := s.provider.Submit(ctx, payout.Instruction{
+		Reference:     p.Reference,
+		Amount:        p.Amount,
+		Currency:      p.Currency,
+		BankCode:      account.BankCode,
+		AccountNumber: account.AccountNumber,
+		AccountName:   account.AccountName,
+		CallbackURL:   s.callbackURL,
+	})
+	if errors.Is(err, payout.ErrRejected) {
+		return s.settle(ctx, payout.Result{Reference: p.Reference, Status: payout.StatusFailed, Reason: err.Error()})
+	} else if err != nil {
+		logger.Log.Error(fmt.Sprintf("submitting payout %s: %s", p.Reference, err.Error()))
+		return &p, nil
+	}
+
+	if result.Status != payout.StatusPending {
+		return s.settle(ctx, *result)
+	}
+
+	// The bank may already have called back, so a payout that is no longer
+	// pending is left as it is.
+	if err = s.repo.MarkPayoutProcessing(ctx, p.ID, result.ProviderReference); err == sql.ErrNoRows {
+		return s.repo.ReadPayoutByID(ctx, p.ID)
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	p.Status = model.PayoutStatusProcessing
+	p.ProviderReference = result.ProviderReference
+
+	return &p, nil
+}
+
+// refresh submits a pending payout again, or asks the bank about a
+// processing one and settles it if the bank has. A processing payout the
+// bank has lost is submitted again.
+func (s *payoutService) refresh(ctx context.Context, p model.Payout) (*model.Payout, error) {
+	if p.Status == model.PayoutStatusProcessing {
+		result, err := s.provider.Status(ctx, p.Reference)
+		if err == nil && result.Status != payout.StatusPending {
+			return s.settle(ctx, *result)
+		} else if err == nil {
+			return &p, nil
+		} else if err != payout.ErrUnknownPayout {
+			return nil, err
+		}
+	}
+
+	account, err := s.repo.ReadBankAccountByID(ctx, p.BankAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if account == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	return s.submit(ctx, p, *account)
+}
+
+// settle records the outcome the bank reported for a payout, crediting a
+// failed payout back to the wallet in the same transaction. Reporting the
+// outcome already recorded is a no-op.
+func (s *payoutService) settle(ctx context.Context, result payout.Result) (p *model.Payout, err error) {
+	var status string
+	switch result.Status {
+	case payout.StatusSucceeded:
+		status = model.PayoutStatusSucceeded
+	case payout.StatusFailed:
+		status = model.PayoutStatusFailed
+	case payout.StatusPending:
+		status = model.PayoutStatusProcessing
+	default:
+		return nil, cs.ErrBadParamInput
+	}
+
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+		s.walletService.AfterTx(tx, err == nil)
+	}()
+
+	p, err = s.repo.ReadPayoutForUpdate(ctx, tx, result.Reference)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if p == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if p.Finalized() || status == model.PayoutStatusProcessing {
+		if p.Finalized() && p.Status != status {
+			return nil, cs.ErrPayoutFinalized
+		}
+
+		return p, tx.Rollback()
+	}
+
+	p.Status = status
+	if result.ProviderReference != "" {
+		p.ProviderReference = result.ProviderReference
+	}
+
+	if status == model.PayoutStatusFailed {
+		p.FailureReason = result.Reason
+		if len(p.FailureReason) > 255 {
+			p.FailureReason = p.FailureReason[:255]
+		}
+
+		var credit *model.Transaction
+		if credit, err = s.walletService.RevertWithdrawal(ctx, tx, *p); err != nil {
+			return nil, err
+		}
+
+		p.ReversalTransactionID = &credit.ID
+	}
+
+	if err = s.repo.UpdatePayout(ctx, tx, *p); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	p.UpdatedAt = time.Now()
+
+	return p, nil
+}
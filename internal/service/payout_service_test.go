@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/payout"
+	"github.com/stretchr/testify/mock"
+)
+
+// payoutWallet stands in for the wallet service when withdrawing, assessing
+// every withdrawal with assessErr and recording whether one was reverted.
+type payoutWallet struct {
+	WalletService
+	assessErr error
+	reverted  *bool
+}
+
+func (w payoutWallet) AssessWithdrawal(ctx context.Context, req model.WithdrawRequest) error {
+	return w.assessErr
+}
+
+func (w payoutWallet) Withdraw(ctx context.Context, tx *sql.Tx, req model.WithdrawRequest) (*model.Transaction, error) {
+	return &model.Transaction{ID: 50, WalletID: 3, Amount: -req.Amount, Reference: "WD-1"}, nil
+}
+
+func (w payoutWallet) RevertWithdrawal(ctx context.Context, tx *sql.Tx, p model.Payout) (*model.Transaction, error) {
+	*w.reverted = true
+	return &model.Transaction{ID: 51, WalletID: p.WalletID, Amount: p.Amount, Reference: p.Reference}, nil
+}
+
+func (w payoutWallet) AfterTx(tx *sql.Tx, committed bool) {}
+
+// stubProvider answers every submission with result or err.
+type stubProvider struct {
+	result *payout.Result
+	err    error
+}
+
+func (p stubProvider) Submit(ctx context.Context, instruction payout.Instruction) (*payout.Result, error) {
+	return p.result, p.err
+}
+
+func (p stubProvider) Status(ctx context.Context, reference string) (*payout.Result, error) {
+	return p.result, p.err
+}
+
+func Test_payoutService_Withdraw(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	account := &model.BankAccount{ID: 7, UserID: 1, BankCode: "014", AccountNumber: "1234567890", AccountName: "Andi"}
+
+	tests := []struct {
+		name         string
+		req          model.WithdrawRequest
+		provider     stubProvider
+		assessErr    error
+		wantStatus   string
+		wantReverted bool
+		wantErr      error
+	}{
+		{
+			name:       "positif: accepted by the bank",
+			req:        model.WithdrawRequest{BankAccountID: 7, Amount: 50000, UserID: 1},
+			provider:   stubProvider{result: &payout.Result{Reference: "WD-1", ProviderReference: "MB-1", Status: payout.StatusPending}},
+			wantStatus: model.PayoutStatusProcessing,
+		},
+		{
+			name:         "negatif: rejected by the bank",
+			req:          model.WithdrawRequest{BankAccountID: 7, Amount: 50000, UserID: 1},
+			provider:     stubProvider{err: payout.ErrRejected},
+			wantStatus:   model.PayoutStatusFailed,
+			wantReverted: true,
+		},
+		{
+			name:       "negatif: bank unreachable",
+			req:        model.WithdrawRequest{BankAccountID: 7, Amount: 50000, UserID: 1},
+			provider:   stubProvider{err: errors.New("connection refused")},
+			wantStatus: model.PayoutStatusPending,
+		},
+		{
+			name:    "negatif: someone else's account",
+			req:     model.WithdrawRequest{BankAccountID: 7, Amount: 50000, UserID: 2},
+			wantErr: cs.ErrNotFound,
+		},
+		{
+			name:      "negatif: denied by the risk engine",
+			req:       model.WithdrawRequest{BankAccountID: 7, Amount: 50000, UserID: 1},
+			assessErr: cs.ErrTransactionDenied,
+			wantErr:   cs.ErrTransactionDenied,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.PayoutRepository{}
+
+			if tt.wantErr == nil {
+				tx := beginTx(db, mockDB)
+				mockRepo.On("BeginTx", ctx).Return(tx).Once()
+
+				if tt.wantReverted {
+					settleTx := beginTx(db, mockDB)
+					mockRepo.On("BeginTx", ctx).Return(settleTx).Once()
+					mockDB.ExpectCommit()
+				}
+				mockDB.ExpectCommit()
+			}
+
+			mockRepo.On("ReadBankAccountByID", ctx, int64(7)).Return(account, nil)
+			mockRepo.On("WritePayout", ctx, mock.Anything, mock.MatchedBy(func(p model.Payout) bool {
+				return p.Status == model.PayoutStatusPending && p.Reference == "WD-1" && p.WalletID == 3 && p.TransactionID == 50
+			})).Return(int64(9), nil)
+			mockRepo.On("MarkPayoutProcessing", ctx, int64(9), "MB-1").Return(nil)
+			mockRepo.On("ReadPayoutForUpdate", ctx, mock.Anything, "WD-1").Return(&model.Payout{ID: 9, WalletID: 3, Amount: 50000, Status: model.PayoutStatusPending, Reference: "WD-1"}, nil)
+			mockRepo.On("UpdatePayout", ctx, mock.Anything, mock.MatchedBy(func(p model.Payout) bool {
+				return p.Status == model.PayoutStatusFailed && p.ReversalTransactionID != nil
+			})).Return(nil)
+
+			reverted := false
+			s := NewPayoutService(&mockRepo, payoutWallet{assessErr: tt.assessErr, reverted: &reverted}, tt.provider, "", "")
+			got, err := s.Withdraw(ctx, tt.req)
+			if err != tt.wantErr {
+				t.Errorf("payoutService.Withdraw() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Status != tt.wantStatus {
+				t.Errorf("payoutService.Withdraw() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if reverted != tt.wantReverted {
+				t.Errorf("payoutService.Withdraw() reverted = %v, want %v", reverted, tt.wantReverted)
+			}
+			if tt.assessErr != nil {
+				mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+			}
+			if err := mockDB.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_payoutService_WithdrawHeld(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	account := &model.BankAccount{ID: 7, UserID: 1, BankCode: "014", AccountNumber: "1234567890", AccountName: "Andi"}
+
+	tests := []struct {
+		name    string
+		held    model.HeldTransaction
+		wantErr error
+	}{
+		{
+			name: "positif",
+			held: model.HeldTransaction{ID: 9, UserID: 1, WalletID: 3, Operation: model.TransactionTypeWithdrawal, Amount: 50000, Status: model.HeldTransactionApproved, Payload: `{"bank_account_id":7,"amount":50000,"user_id":1}`},
+		},
+		{
+			name:    "negatif: not approved",
+			held:    model.HeldTransaction{ID: 9, UserID: 1, WalletID: 3, Operation: model.TransactionTypeWithdrawal, Amount: 50000, Status: model.HeldTransactionRejected, Payload: `{"bank_account_id":7,"amount":50000,"user_id":1}`},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: not a withdrawal",
+			held:    model.HeldTransaction{ID: 9, UserID: 1, WalletID: 3, Operation: model.TransactionTypePayment, Amount: 50000, Status: model.HeldTransactionApproved},
+			wantErr: cs.ErrBadParamInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.PayoutRepository{}
+
+			if tt.wantErr == nil {
+				tx := beginTx(db, mockDB)
+				mockRepo.On("BeginTx", ctx).Return(tx).Once()
+				mockDB.ExpectCommit()
+			}
+
+			mockRepo.On("ReadBankAccountByID", ctx, int64(7)).Return(account, nil)
+			mockRepo.On("WritePayout", ctx, mock.Anything, mock.Anything).Return(int64(9), nil)
+			mockRepo.On("MarkPayoutProcessing", ctx, int64(9), "MB-1").Return(nil)
+
+			reverted := false
+			provider := stubProvider{result: &payout.Result{Reference: "WD-1", ProviderReference: "MB-1", Status: payout.StatusPending}}
+
+			// The approval stands in for the risk engine, which would
+			// deny the withdrawal if it were asked again.
+			s := NewPayoutService(&mockRepo, payoutWallet{assessErr: cs.ErrTransactionDenied, reverted: &reverted}, provider, "", "")
+			got, err := s.WithdrawHeld(ctx, tt.held)
+			if err != tt.wantErr {
+				t.Fatalf("payoutService.WithdrawHeld() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Status != model.PayoutStatusProcessing {
+				t.Errorf("payoutService.WithdrawHeld() status = %v, want %v", got.Status, model.PayoutStatusProcessing)
+			}
+			if err := mockDB.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_payoutService_HandleCallback(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	secret := "callback-secret"
+
+	tests := []struct {
+		name         string
+		status       string
+		body         string
+		signature    string
+		wantStatus   string
+		wantReverted bool
+		wantUpdate   bool
+		wantErr      error
+	}{
+		{
+			name:       "positif: succeeded",
+			status:     model.PayoutStatusProcessing,
+			body:       `{"reference":"WD-1","provider_reference":"MB-1","status":"succeeded"}`,
+			wantStatus: model.PayoutStatusSucceeded,
+			wantUpdate: true,
+		},
+		{
+			name:         "positif: failed and reverted",
+			status:       model.PayoutStatusProcessing,
+			body:         `{"reference":"WD-1","provider_reference":"MB-1","status":"failed","reason":"account closed"}`,
+			wantStatus:   model.PayoutStatusFailed,
+			wantReverted: true,
+			wantUpdate:   true,
+		},
+		{
+			name:       "positif: repeated callback",
+			status:     model.PayoutStatusSucceeded,
+			body:       `{"reference":"WD-1","provider_reference":"MB-1","status":"succeeded"}`,
+			wantStatus: model.PayoutStatusSucceeded,
+		},
+		{
+			name:    "negatif: contradicts the settled outcome",
+			status:  model.PayoutStatusSucceeded,
+			body:    `{"reference":"WD-1","provider_reference":"MB-1","status":"failed"}`,
+			wantErr: cs.ErrPayoutFinalized,
+		},
+		{
+			name:      "negatif: bad signature",
+			body:      `{"reference":"WD-1","provider_reference":"MB-1","status":"succeeded"}`,
+			signature: "forged",
+			wantErr:   cs.ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.PayoutRepository{}
+
+			if tt.status != "" {
+				tx := beginTx(db, mockDB)
+				mockRepo.On("BeginTx", ctx).Return(tx).Once()
+				if tt.wantUpdate {
+					mockDB.ExpectCommit()
+				} else {
+					mockDB.ExpectRollback()
+				}
+			}
+
+			mockRepo.On("ReadPayoutForUpdate", ctx, mock.Anything, "WD-1").Return(&model.Payout{ID: 9, WalletID: 3, Amount: 50000, Status: tt.status, Reference: "WD-1"}, nil)
+			mockRepo.On("UpdatePayout", ctx, mock.Anything, mock.MatchedBy(func(p model.Payout) bool {
+				return p.Status == tt.wantStatus && (p.ReversalTransactionID != nil) == tt.wantReverted
+			})).Return(nil)
+
+			signature := tt.signature
+			if signature == "" {
+				signature = payout.Sign(secret, []byte(tt.body))
+			}
+
+			reverted := false
+			s := NewPayoutService(&mockRepo, payoutWallet{reverted: &reverted}, stubProvider{}, "", secret)
+			got, err := s.HandleCallback(ctx, []byte(tt.body), signature)
+			if err != tt.wantErr {
+				t.Errorf("payoutService.HandleCallback() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Status != tt.wantStatus {
+				t.Errorf("payoutService.HandleCallback() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if reverted != tt.wantReverted {
+				t.Errorf("payoutService.HandleCallback() reverted = %v, want %v", reverted, tt.wantReverted)
+			}
+			if err := mockDB.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/gateway"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+type reconciliationService struct {
+	walletRepo repository.WalletRepository
+	topUpRepo  repository.TopUpRepository
+}
+
+type ReconciliationService interface {
+	Reconcile(ctx context.Context, settlement []gateway.SettlementRecord) (*model.ReconciliationReport, error)
+}
+
+func NewReconciliationService(walletRepo repository.WalletRepository, topUpRepo repository.TopUpRepository) ReconciliationService {
+	return &reconciliationService{
+		walletRepo: walletRepo,
+		topUpRepo:  topUpRepo,
+	}
+}
+
+// Reconcile recomputes every wallet's balance from its ledger and reports
+// the wallets whose stored balance differs. When a gateway settlement file
+// is given, its charges are also matched against top-up intents, both ways
+// over the period the file covers.
+func (s *reconciliationService) Reconcile(ctx context.Context, settlement []gateway.SettlementRecord) (*model.ReconciliationReport, error) {
+	balances, err := s.walletRepo.ReadLedgerBalances(ctx)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	report := &model.ReconciliationReport{
+		GeneratedAt:       time.Now(),
+		WalletsChecked:    len(balances),
+		SettlementRecords: len(settlement),
+		Discrepancies:     []model.Discrepancy{},
+	}
+
+	for _, balance := range balances {
+		if balance.Balance != balance.LedgerBalance {
+			report.Discrepancies = append(report.Discrepancies, model.Discrepancy{
+				Kind:     model.DiscrepancyBalanceMismatch,
+				WalletID: balance.WalletID,
+				Expected: balance.LedgerBalance,
+				Actual:   balance.Balance,
+				Detail:   fmt.Sprintf("stored balance is off by %.0f over %d ledger entries", balance.Balance-balance.LedgerBalance, balance.Entries),
+			})
+		}
+	}
+
+	if len(settlement) > 0 {
+		discrepancies, err := s.reconcileSettlement(ctx, settlement)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Discrepancies = append(report.Discrepancies, discrepancies...)
+	}
+
+	return report, nil
+}
+
+// reconcileSettlement matches settled charges against top-up intents, then
+// looks for intents credited over the period of the file that it does not
+// settle as paid.
+func (s *reconciliationService) reconcileSettlement(ctx context.Context, settlement []gateway.SettlementRecord) ([]model.Discrepancy, error) {
+	references := make([]string, len(settlement))
+	from, to := settlement[0].SettledAt, settlement[0].SettledAt
+	for i, record := range settlement {
+		references[i] = record.Reference
+		if record.SettledAt.Before(from) {
+			from = record.SettledAt
+		}
+		if record.SettledAt.After(to) {
+			to = record.SettledAt
+		}
+	}
+
+	intents, err := s.topUpRepo.ReadTopUpIntentsByReferences(ctx, references)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	byReference := make(map[string]model.TopUpIntent, len(intents))
+	for _, intent := range intents {
+		byReference[intent.Reference] = intent
+	}
+
+	discrepancies := []model.Discrepancy{}
+	paid := map[string]bool{}
+
+	for _, record := range settlement {
+		intent, ok := byReference[record.Reference]
+		if record.Status == gateway.StatusPaid {
+			paid[record.Reference] = true
+		}
+
+		switch {
+		case !ok:
+			discrepancies = append(discrepancies, model.Discrepancy{
+				Kind:      model.DiscrepancyUnknownCharge,
+				Reference: record.Reference,
+				Expected:  record.Amount,
+				Detail:    fmt.Sprintf("gateway charge %s settled %s with no top-up intent", record.GatewayReference, record.Status),
+			})
+		case record.Amount != intent.Amount:
+			discrepancies = append(discrepancies, model.Discrepancy{
+				Kind:      model.DiscrepancyAmountMismatch,
+				WalletID:  intent.WalletID,
+				Reference: record.Reference,
+				Expected:  record.Amount,
+				Actual:    intent.Amount,
+				Detail:    fmt.Sprintf("gateway charge %s settled for another amount than the top-up intent", record.GatewayReference),
+			})
+		case record.Status == gateway.StatusPaid && intent.Status != model.TopUpIntentSucceeded:
+			discrepancies = append(discrepancies, model.Discrepancy{
+				Kind:      model.DiscrepancyNotCredited,
+				WalletID:  intent.WalletID,
+				Reference: record.Reference,
+				Expected:  record.Amount,
+				Detail:    fmt.Sprintf("gateway charge %s was paid but the top-up is %s", record.GatewayReference, intent.Status),
+			})
+		}
+	}
+
+	credited, err := s.topUpRepo.ReadTopUpIntentsSucceededBetween(ctx, from, to)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	for _, intent := range credited {
+		if !paid[intent.Reference] {
+			discrepancies = append(discrepancies, model.Discrepancy{
+				Kind:      model.DiscrepancyNotSettled,
+				WalletID:  intent.WalletID,
+				Reference: intent.Reference,
+				Actual:    intent.Amount,
+				Detail:    "top-up was credited but the gateway did not settle it as paid",
+			})
+		}
+	}
+
+	return discrepancies, nil
+}
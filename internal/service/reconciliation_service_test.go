@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/gateway"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_reconciliationService_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	settledAt := time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC)
+
+	balances := []model.LedgerBalance{
+		{WalletID: 1, Balance: 50000, LedgerBalance: 50000, Entries: 3},
+		{WalletID: 2, Balance: 75000, LedgerBalance: 50000, Entries: 2},
+	}
+
+	intents := []model.TopUpIntent{
+		{Reference: "TU-1", WalletID: 1, Amount: 50000, Status: model.TopUpIntentSucceeded},
+		{Reference: "TU-2", WalletID: 1, Amount: 20000, Status: model.TopUpIntentPending},
+		{Reference: "TU-3", WalletID: 2, Amount: 10000, Status: model.TopUpIntentSucceeded},
+	}
+
+	tests := []struct {
+		name       string
+		settlement []gateway.SettlementRecord
+		credited   []model.TopUpIntent
+		want       []string
+	}{
+		{
+			name: "positif: ledger only",
+			want: []string{model.DiscrepancyBalanceMismatch},
+		},
+		{
+			name: "positif: with settlement",
+			settlement: []gateway.SettlementRecord{
+				{Reference: "TU-1", GatewayReference: "MG-1", Amount: 50000, Status: gateway.StatusPaid, SettledAt: settledAt},
+				{Reference: "TU-2", GatewayReference: "MG-2", Amount: 20000, Status: gateway.StatusPaid, SettledAt: settledAt},
+				{Reference: "TU-3", GatewayReference: "MG-3", Amount: 15000, Status: gateway.StatusPaid, SettledAt: settledAt},
+				{Reference: "TU-9", GatewayReference: "MG-9", Amount: 5000, Status: gateway.StatusPaid, SettledAt: settledAt},
+			},
+			credited: []model.TopUpIntent{intents[0], intents[2], {Reference: "TU-4", WalletID: 2, Amount: 30000, Status: model.TopUpIntentSucceeded}},
+			want: []string{
+				model.DiscrepancyBalanceMismatch,
+				model.DiscrepancyNotCredited,
+				model.DiscrepancyAmountMismatch,
+				model.DiscrepancyUnknownCharge,
+				model.DiscrepancyNotSettled,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockWalletRepo := mocks.WalletRepository{}
+			mockTopUpRepo := mocks.TopUpRepository{}
+
+			mockWalletRepo.On("ReadLedgerBalances", ctx).Return(balances, nil)
+			mockTopUpRepo.On("ReadTopUpIntentsByReferences", ctx, []string{"TU-1", "TU-2", "TU-3", "TU-9"}).Return(intents, nil)
+			mockTopUpRepo.On("ReadTopUpIntentsSucceededBetween", ctx, settledAt, settledAt).Return(tt.credited, nil)
+
+			s := NewReconciliationService(&mockWalletRepo, &mockTopUpRepo)
+			got, err := s.Reconcile(ctx, tt.settlement)
+			if err != nil {
+				t.Errorf("reconciliationService.Reconcile() error = %v", err)
+				return
+			}
+			if got.WalletsChecked != len(balances) || got.SettlementRecords != len(tt.settlement) {
+				t.Errorf("reconciliationService.Reconcile() checked %d wallet(s) and %d record(s)", got.WalletsChecked, got.SettlementRecords)
+			}
+			if len(got.Discrepancies) != len(tt.want) {
+				t.Fatalf("reconciliationService.Reconcile() = %+v, want %v", got.Discrepancies, tt.want)
+			}
+			for i, d := range got.Discrepancies {
+				if d.Kind != tt.want[i] {
+					t.Errorf("reconciliationService.Reconcile() discrepancy %d = %+v, want %s", i, d, tt.want[i])
+				}
+			}
+			if tt.settlement == nil {
+				mockTopUpRepo.AssertNotCalled(t, "ReadTopUpIntentsByReferences", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+// RiskEngine scores a wallet operation before any money moves.
+type RiskEngine interface {
+	Assess(ctx context.Context, input model.RiskInput) (*model.RiskAssessment, error)
+}
+
+// Risk reasons reported by the rule based engine.
+const (
+	RiskReasonNewBrowser    = "new_browser"
+	RiskReasonUnusualAmount = "unusual_amount"
+	RiskReasonHighVelocity  = "high_velocity"
+	RiskReasonNewAccount    = "new_account"
+	RiskReasonRecentAccount = "recent_account"
+)
+
+const (
+	// riskVelocityWindow and riskVelocityThreshold flag a wallet doing that
+	// many operations of one type within the window.
+	riskVelocityWindow    = 10 * time.Minute
+	riskVelocityThreshold = 5
+
+	// An amount is unusual when it exceeds the user's average for the
+	// operation by this factor, once there are enough samples.
+	riskUnusualAmountFactor  = 5
+	riskUnusualAmountSamples = 3
+)
+
+// RiskWeights are the points each signal adds to the score, and the scores
+// from which an operation is sent to review or denied.
+type RiskWeights struct {
+	NewBrowser      int
+	UnusualAmount   int
+	HighVelocity    int
+	NewAccount      int
+	RecentAccount   int
+	ReviewThreshold int
+	DenyThreshold   int
+}
+
+var DefaultRiskWeights = RiskWeights{
+	NewBrowser:      30,
+	UnusualAmount:   30,
+	HighVelocity:    25,
+	NewAccount:      20,
+	RecentAccount:   10,
+	ReviewThreshold: 40,
+	DenyThreshold:   70,
+}
+
+type ruleRiskEngine struct {
+	userRepo repository.UserRepository
+	riskRepo repository.RiskRepository
+	weights  RiskWeights
+}
+
+// NewRuleRiskEngine returns a RiskEngine that adds up weighted signals from
+// the login history, the wallet's ledger and the account age.
+func NewRuleRiskEngine(userRepo repository.UserRepository, riskRepo repository.RiskRepository, weights RiskWeights) RiskEngine {
+	return &ruleRiskEngine{
+		userRepo: userRepo,
+		riskRepo: riskRepo,
+		weights:  weights,
+	}
+}
+
+func (r *ruleRiskEngine) Assess(ctx context.Context, input model.RiskInput) (*model.RiskAssessment, error) {
+	assessment := &model.RiskAssessment{Reasons: []string{}}
+
+	add := func(points int, reason string) {
+		assessment.Score += points
+		assessment.Reasons = append(assessment.Reasons, reason)
+	}
+
+	isNewBrowser, err := r.isNewBrowser(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if isNewBrowser {
+		add(r.weights.NewBrowser, RiskReasonNewBrowser)
+	}
+
+	history, err := r.riskRepo.ReadTransactionStats(ctx, input.WalletID, input.Operation, time.Time{})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+	if history.Count >= riskUnusualAmountSamples && input.Amount > riskUnusualAmountFactor*history.Total/float64(history.Count) {
+		add(r.weights.UnusualAmount, RiskReasonUnusualAmount)
+	}
+
+	recent, err := r.riskRepo.ReadTransactionStats(ctx, input.WalletID, input.Operation, time.Now().Add(-riskVelocityWindow))
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+	if recent.Count >= riskVelocityThreshold {
+		add(r.weights.HighVelocity, RiskReasonHighVelocity)
+	}
+
+	user, err := r.userRepo.ReadByID(ctx, input.UserID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+	if user != nil {
+		switch age := time.Since(user.CreatedAt); {
+		case age < 24*time.Hour:
+			add(r.weights.NewAccount, RiskReasonNewAccount)
+		case age < 7*24*time.Hour:
+			add(r.weights.RecentAccount, RiskReasonRecentAccount)
+		}
+	}
+
+	switch {
+	case assessment.Score >= r.weights.DenyThreshold:
+		assessment.Decision = model.RiskDecisionDeny
+	case assessment.Score >= r.weights.ReviewThreshold:
+		assessment.Decision = model.RiskDecisionReview
+	default:
+		assessment.Decision = model.RiskDecisionAllow
+	}
+
+	return assessment, nil
+}
+
+// isNewBrowser reports whether the operation comes from a browser the user
+// never logged in with successfully.
+func (r *ruleRiskEngine) isNewBrowser(ctx context.Context, input model.RiskInput) (bool, error) {
+	if input.BrowserName == "" {
+		return false, nil
+	}
+
+	histories, err := r.userRepo.ReadLoginHistories(ctx, input.UserID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return false, err
+	}
+
+	for _, history := range histories {
+		if history.BrowserName == input.BrowserName && history.LoginSucceed > 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_ruleRiskEngine_Assess(t *testing.T) {
+	ctx := context.Background()
+
+	input := model.RiskInput{
+		UserID:      1,
+		WalletID:    3,
+		Operation:   model.TransactionTypePayment,
+		Amount:      1000,
+		BrowserName: "Chrome",
+	}
+
+	knownBrowser := []model.LoginHistory{{BrowserName: "Chrome", LoginSucceed: 4, UserID: 1}}
+
+	tests := []struct {
+		name      string
+		histories []model.LoginHistory
+		history   model.TransactionStats
+		recent    model.TransactionStats
+		createdAt time.Time
+		want      *model.RiskAssessment
+	}{
+		{
+			name:      "positif: allow",
+			histories: knownBrowser,
+			history:   model.TransactionStats{Count: 10, Total: 10000},
+			createdAt: time.Now().AddDate(0, -1, 0),
+			want:      &model.RiskAssessment{Score: 0, Decision: model.RiskDecisionAllow, Reasons: []string{}},
+		},
+		{
+			name:      "positif: review new browser on new account",
+			histories: []model.LoginHistory{{BrowserName: "Firefox", LoginSucceed: 1, UserID: 1}},
+			createdAt: time.Now().Add(-time.Hour),
+			want: &model.RiskAssessment{
+				Score:    50,
+				Decision: model.RiskDecisionReview,
+				Reasons:  []string{RiskReasonNewBrowser, RiskReasonNewAccount},
+			},
+		},
+		{
+			name:      "positif: deny unusual amount at high velocity",
+			histories: []model.LoginHistory{{BrowserName: "Chrome", LoginFailed: 3, UserID: 1}},
+			history:   model.TransactionStats{Count: 5, Total: 500},
+			recent:    model.TransactionStats{Count: 5, Total: 500},
+			createdAt: time.Now().AddDate(0, -1, 0),
+			want: &model.RiskAssessment{
+				Score:    85,
+				Decision: model.RiskDecisionDeny,
+				Reasons:  []string{RiskReasonNewBrowser, RiskReasonUnusualAmount, RiskReasonHighVelocity},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := mocks.UserRepository{}
+			mockRiskRepo := mocks.RiskRepository{}
+
+			mockUserRepo.On("ReadLoginHistories", ctx, input.UserID).Return(tt.histories, nil)
+			mockUserRepo.On("ReadByID", ctx, input.UserID).Return(&model.User{ID: input.UserID, CreatedAt: tt.createdAt}, nil)
+			mockRiskRepo.On("ReadTransactionStats", ctx, input.WalletID, input.Operation, time.Time{}).Return(tt.history, nil)
+			mockRiskRepo.On("ReadTransactionStats", ctx, input.WalletID, input.Operation, mock.AnythingOfType("time.Time")).Return(tt.recent, nil)
+
+			r := NewRuleRiskEngine(&mockUserRepo, &mockRiskRepo, DefaultRiskWeights)
+			got, err := r.Assess(ctx, input)
+			if err != nil {
+				t.Fatalf("ruleRiskEngine.Assess() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ruleRiskEngine.Assess() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+const (
+	// scheduleBatchSize is how many due schedules one pass of RunDue picks up.
+	scheduleBatchSize = 100
+	// maxScheduleAttempts is how many times an occurrence is tried before it
+	// is given up on.
+	maxScheduleAttempts = 5
+	// scheduleRetryBackoff is the wait after the first failed attempt. It
+	// doubles with every further attempt.
+	scheduleRetryBackoff = 5 * time.Minute
+)
+
+type scheduleService struct {
+	repo          repository.ScheduleRepository
+	userRepo      repository.UserRepository
+	merchantRepo  repository.MerchantRepository
+	walletService WalletService
+}
+
+type ScheduleService interface {
+	Create(ctx context.Context, req model.ScheduleRequest) (*model.Schedule, error)
+	List(ctx context.Context, userID int64) ([]model.Schedule, error)
+	Get(ctx context.Context, scheduleID, userID int64) (*model.Schedule, error)
+	Update(ctx context.Context, scheduleID, userID int64, req model.UpdateScheduleRequest) (*model.Schedule, error)
+	Cancel(ctx context.Context, scheduleID, userID int64) (*model.Schedule, error)
+	ListRuns(ctx context.Context, scheduleID, userID int64) ([]model.ScheduleRun, error)
+	RunDue(ctx context.Context) (int64, error)
+}
+
+func NewScheduleService(scheduleRepo repository.ScheduleRepository, userRepo repository.UserRepository, merchantRepo repository.MerchantRepository, walletService WalletService) ScheduleService {
+	return &scheduleService{
+		repo:          scheduleRepo,
+		userRepo:      userRepo,
+		merchantRepo:  merchantRepo,
+		walletService: walletService,
+	}
+}
+
+// Create sets up a schedule paying a merchant or another user. The payee is
+// checked now, but funds only when each occurrence is paid.
+func (s *scheduleService) Create(ctx context.Context, req model.ScheduleRequest) (*model.Schedule, error) {
+	if (req.MerchantID == 0) == (req.Recipient == "") {
+		return nil, cs.ErrBadParamInput
+	}
+
+	if !req.StartAt.After(time.Now()) || (req.EndAt != nil && req.EndAt.Before(req.StartAt)) {
+		return nil, cs.ErrBadParamInput
+	}
+
+	schedule := model.Schedule{
+		UserID:      req.UserID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Frequency:   req.Frequency,
+		StartAt:     req.StartAt,
+		EndAt:       req.EndAt,
+		NextRunAt:   req.StartAt,
+		DueAt:       req.StartAt,
+		Status:      model.ScheduleStatusActive,
+	}
+
+	if req.MerchantID != 0 {
+		merchant, err := s.merchantRepo.ReadMerchantByID(ctx, req.MerchantID)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		if merchant == nil {
+			return nil, cs.ErrNotFound
+		}
+
+		if merchant.Status != model.MerchantStatusActive {
+			return nil, cs.ErrMerchantNotActive
+		}
+
+		schedule.MerchantID = &merchant.ID
+	} else {
+		recipient, err := s.userRepo.ReadByUsernameOrEmail(ctx, req.Recipient, req.Recipient)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		if recipient == nil {
+			return nil, cs.ErrNotFound
+		}
+
+		if recipient.ID == req.UserID {
+			return nil, cs.ErrBadParamInput
+		}
+
+		schedule.RecipientID = &recipient.ID
+	}
+
+	var err error
+	schedule.ID, err = s.repo.WriteSchedule(ctx, schedule)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (s *scheduleService) List(ctx context.Context, userID int64) ([]model.Schedule, error) {
+	schedules, err := s.repo.ReadSchedulesByUser(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// Get returns a schedule to the user who set it up.
+func (s *scheduleService) Get(ctx context.Context, scheduleID, userID int64) (*model.Schedule, error) {
+	schedule, err := s.repo.ReadScheduleByID(ctx, scheduleID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if schedule == nil || schedule.UserID != userID {
+		return nil, cs.ErrNotFound
+	}
+
+	return schedule, nil
+}
+
+// Update changes the amount, description and end of an active schedule.
+// Occurrences already paid are not affected.
+func (s *scheduleService) Update(ctx context.Context, scheduleID, userID int64, req model.UpdateScheduleRequest) (*model.Schedule, error) {
+	schedule, err := s.Get(ctx, scheduleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.EndAt != nil && req.EndAt.Before(schedule.StartAt) {
+		return nil, cs.ErrBadParamInput
+	}
+
+	schedule.Amount = req.Amount
+	schedule.Description = req.Description
+	schedule.EndAt = req.EndAt
+
+	if err = s.repo.UpdateSchedule(ctx, *schedule); err == sql.ErrNoRows {
+		return nil, cs.ErrScheduleNotActive
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *scheduleService) Cancel(ctx context.Context, scheduleID, userID int64) (*model.Schedule, error) {
+	schedule, err := s.Get(ctx, scheduleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.repo.CancelSchedule(ctx, schedule.ID); err == sql.ErrNoRows {
+		return nil, cs.ErrScheduleNotActive
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	schedule.Status = model.ScheduleStatusCancelled
+
+	return schedule, nil
+}
+
+func (s *scheduleService) ListRuns(ctx context.Context, scheduleID, userID int64) ([]model.ScheduleRun, error) {
+	if _, err := s.Get(ctx, scheduleID, userID); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.repo.ReadScheduleRuns(ctx, scheduleID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// RunDue attempts every schedule that is due and returns how many attempts
+// were made. Several schedulers can run at once: each schedule is locked
+// while it is attempted and skipped by the others.
+func (s *scheduleService) RunDue(ctx context.Context) (int64, error) {
+	ids, err := s.repo.ReadDueScheduleIDs(ctx, time.Now(), scheduleBatchSize)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	var attempted int64
+	for _, id := range ids {
+		ok, err := s.run(ctx, id)
+		if err != nil {
+			logger.Log.Error(fmt.Sprintf("running schedule %d: %s", id, err.Error()))
+			continue
+		}
+
+		if ok {
+			attempted++
+		}
+	}
+
+	return attempted, nil
+}
+
+// run attempts the due occurrence of a schedule. The payment, the run record
+// and the schedule's progress are committed together, so an occurrence is
+// paid exactly once. A failed payment is rolled back and recorded on its own.
+func (s *scheduleService) run(ctx context.Context, scheduleID int64) (ok bool, err error) {
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+		s.walletService.AfterTx(tx, err == nil)
+	}()
+
+	schedule, err := s.repo.ReadScheduleForUpdate(ctx, tx, scheduleID)
+	if err != nil {
+		return false, err
+	}
+
+	if !due(schedule) {
+		return false, tx.Rollback()
+	}
+
+	debit, payErr := s.walletService.RunSchedule(ctx, tx, *schedule)
+	if payErr != nil {
+		if err = tx.Rollback(); err != nil {
+			return false, err
+		}
+		s.walletService.AfterTx(tx, false)
+
+		return true, s.recordFailure(ctx, *schedule, payErr)
+	}
+
+	run := model.ScheduleRun{
+		ScheduleID:    schedule.ID,
+		ScheduledFor:  schedule.NextRunAt,
+		Attempt:       schedule.Attempts + 1,
+		Status:        model.ScheduleRunSucceeded,
+		TransactionID: &debit.ID,
+	}
+
+	if _, err = s.repo.WriteScheduleRun(ctx, tx, run); err != nil {
+		return false, err
+	}
+
+	schedule.LastError = ""
+	advanceSchedule(schedule)
+
+	if err = s.repo.UpdateScheduleProgress(ctx, tx, *schedule); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// recordFailure records a failed attempt and sets when the occurrence is
+// retried. After maxScheduleAttempts the occurrence is given up on: a
+// recurring schedule moves on to its next occurrence and a one-off schedule
+// fails.
+func (s *scheduleService) recordFailure(ctx context.Context, attempted model.Schedule, cause error) (err error) {
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	schedule, err := s.repo.ReadScheduleForUpdate(ctx, tx, attempted.ID)
+	if err != nil {
+		return err
+	}
+
+	// Another scheduler got to the schedule between the rollback and now.
+	if !due(schedule) || schedule.Attempts != attempted.Attempts || !schedule.NextRunAt.Equal(attempted.NextRunAt) {
+		return tx.Rollback()
+	}
+
+	message := cause.Error()
+	if len(message) > 255 {
+		message = message[:255]
+	}
+
+	schedule.Attempts++
+	schedule.LastError = message
+
+	run := model.ScheduleRun{
+		ScheduleID:   schedule.ID,
+		ScheduledFor: schedule.NextRunAt,
+		Attempt:      schedule.Attempts,
+		Status:       model.ScheduleRunFailed,
+		Error:        message,
+	}
+
+	if _, err = s.repo.WriteScheduleRun(ctx, tx, run); err != nil {
+		return err
+	}
+
+	switch {
+	case schedule.Attempts < maxScheduleAttempts:
+		schedule.DueAt = time.Now().Add(scheduleRetryBackoff << (schedule.Attempts - 1))
+	case schedule.Frequency == model.ScheduleOnce:
+		schedule.Status = model.ScheduleStatusFailed
+	default:
+		advanceSchedule(schedule)
+	}
+
+	if err = s.repo.UpdateScheduleProgress(ctx, tx, *schedule); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// due reports whether a schedule locked for a run still needs one.
+func due(schedule *model.Schedule) bool {
+	return schedule != nil && schedule.Status == model.ScheduleStatusActive && !schedule.DueAt.After(time.Now())
+}
+
+// advanceSchedule settles the current occurrence of a schedule and moves it
+// to the next one, completing it when there is none.
+func advanceSchedule(schedule *model.Schedule) {
+	schedule.Occurrences++
+	schedule.Attempts = 0
+
+	next := occurrence(schedule.StartAt, schedule.Frequency, schedule.Occurrences)
+	if schedule.Frequency == model.ScheduleOnce || (schedule.EndAt != nil && next.After(*schedule.EndAt)) {
+		schedule.Status = model.ScheduleStatusCompleted
+		return
+	}
+
+	schedule.NextRunAt = next
+	schedule.DueAt = next
+}
+
+// occurrence returns the nth occurrence of a schedule starting at start,
+// counting from zero. Monthly schedules keep the day of month of start,
+// falling back to the last day of shorter months.
+func occurrence(start time.Time, frequency string, n int) time.Time {
+	switch frequency {
+	case model.ScheduleDaily:
+		return start.AddDate(0, 0, n)
+	case model.ScheduleWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case model.ScheduleMonthly:
+		month := time.Date(start.Year(), start.Month()+time.Month(n), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		lastDay := month.AddDate(0, 1, -1).Day()
+
+		day := start.Day()
+		if day > lastDay {
+			day = lastDay
+		}
+
+		return month.AddDate(0, 0, day-1)
+	default:
+		return start
+	}
+}
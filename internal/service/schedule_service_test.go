@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// scheduleWallet stands in for the wallet service when running schedules,
+// failing every payment with err when it is set.
+type scheduleWallet struct {
+	WalletService
+	err error
+}
+
+func (w scheduleWallet) RunSchedule(ctx context.Context, tx *sql.Tx, schedule model.Schedule) (*model.Transaction, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	return &model.Transaction{ID: 40, Amount: -schedule.Amount}, nil
+}
+
+func (w scheduleWallet) AfterTx(tx *sql.Tx, committed bool) {}
+
+func Test_scheduleService_Create(t *testing.T) {
+	ctx := context.Background()
+	tomorrow := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		req     model.ScheduleRequest
+		wantErr error
+	}{
+		{
+			name: "positif: merchant",
+			req:  model.ScheduleRequest{MerchantID: 8, Amount: 1000, Frequency: model.ScheduleMonthly, StartAt: tomorrow, UserID: 1},
+		},
+		{
+			name: "positif: recipient",
+			req:  model.ScheduleRequest{Recipient: "budi", Amount: 1000, Frequency: model.ScheduleOnce, StartAt: tomorrow, UserID: 1},
+		},
+		{
+			name:    "negatif: merchant and recipient",
+			req:     model.ScheduleRequest{MerchantID: 8, Recipient: "budi", Amount: 1000, Frequency: model.ScheduleOnce, StartAt: tomorrow, UserID: 1},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: starts in the past",
+			req:     model.ScheduleRequest{MerchantID: 8, Amount: 1000, Frequency: model.ScheduleOnce, StartAt: time.Now().Add(-time.Hour), UserID: 1},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: merchant not active",
+			req:     model.ScheduleRequest{MerchantID: 9, Amount: 1000, Frequency: model.ScheduleOnce, StartAt: tomorrow, UserID: 1},
+			wantErr: cs.ErrMerchantNotActive,
+		},
+		{
+			name:    "negatif: transfer to self",
+			req:     model.ScheduleRequest{Recipient: "andi", Amount: 1000, Frequency: model.ScheduleOnce, StartAt: tomorrow, UserID: 1},
+			wantErr: cs.ErrBadParamInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.ScheduleRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+
+			mockMerchantRepo.On("ReadMerchantByID", ctx, int64(8)).Return(&model.Merchant{ID: 8, Status: model.MerchantStatusActive}, nil)
+			mockMerchantRepo.On("ReadMerchantByID", ctx, int64(9)).Return(&model.Merchant{ID: 9, Status: model.MerchantStatusSuspended}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "budi", "budi").Return(&model.User{ID: 2}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "andi", "andi").Return(&model.User{ID: 1}, nil)
+			mockRepo.On("WriteSchedule", ctx, mock.MatchedBy(func(s model.Schedule) bool {
+				return s.Status == model.ScheduleStatusActive && s.NextRunAt.Equal(tt.req.StartAt) && s.DueAt.Equal(tt.req.StartAt)
+			})).Return(int64(1), nil)
+
+			s := NewScheduleService(&mockRepo, &mockUserRepo, &mockMerchantRepo, scheduleWallet{})
+			if _, err := s.Create(ctx, tt.req); err != tt.wantErr {
+				t.Errorf("scheduleService.Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_scheduleService_RunDue(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	start := time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		schedule      model.Schedule
+		payErr        error
+		wantStatus    string
+		wantNextRunAt time.Time
+		wantAttempts  int
+		wantRun       string
+		wantRetry     bool
+	}{
+		{
+			name:          "positif: paid and moved to next month",
+			schedule:      model.Schedule{ID: 1, Frequency: model.ScheduleMonthly, StartAt: start, NextRunAt: start, DueAt: start, Status: model.ScheduleStatusActive},
+			wantStatus:    model.ScheduleStatusActive,
+			wantNextRunAt: time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC),
+			wantRun:       model.ScheduleRunSucceeded,
+		},
+		{
+			name:          "positif: last occurrence paid",
+			schedule:      model.Schedule{ID: 1, Frequency: model.ScheduleMonthly, StartAt: start, EndAt: &end, NextRunAt: time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC), DueAt: start, Occurrences: 1, Status: model.ScheduleStatusActive},
+			wantStatus:    model.ScheduleStatusCompleted,
+			wantNextRunAt: time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC),
+			wantRun:       model.ScheduleRunSucceeded,
+		},
+		{
+			name:          "negatif: failed attempt is retried later",
+			schedule:      model.Schedule{ID: 1, Frequency: model.ScheduleMonthly, StartAt: start, NextRunAt: start, DueAt: start, Attempts: 1, Status: model.ScheduleStatusActive},
+			payErr:        cs.ErrInsufficientBalance,
+			wantStatus:    model.ScheduleStatusActive,
+			wantNextRunAt: start,
+			wantAttempts:  2,
+			wantRun:       model.ScheduleRunFailed,
+			wantRetry:     true,
+		},
+		{
+			name:          "negatif: recurring occurrence given up on",
+			schedule:      model.Schedule{ID: 1, Frequency: model.ScheduleMonthly, StartAt: start, NextRunAt: start, DueAt: start, Attempts: maxScheduleAttempts - 1, Status: model.ScheduleStatusActive},
+			payErr:        cs.ErrInsufficientBalance,
+			wantStatus:    model.ScheduleStatusActive,
+			wantNextRunAt: time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC),
+			wantRun:       model.ScheduleRunFailed,
+		},
+		{
+			name:          "negatif: one-off payment given up on",
+			schedule:      model.Schedule{ID: 1, Frequency: model.ScheduleOnce, StartAt: start, NextRunAt: start, DueAt: start, Attempts: maxScheduleAttempts - 1, Status: model.ScheduleStatusActive},
+			payErr:        cs.ErrInsufficientBalance,
+			wantStatus:    model.ScheduleStatusFailed,
+			wantNextRunAt: start,
+			wantAttempts:  maxScheduleAttempts,
+			wantRun:       model.ScheduleRunFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.ScheduleRepository{}
+
+			tx := beginTx(db, mockDB)
+			if tt.payErr != nil {
+				retryTx := beginTx(db, mockDB)
+				mockDB.ExpectRollback()
+				mockDB.ExpectCommit()
+
+				mockRepo.On("BeginTx", ctx).Return(tx).Once()
+				mockRepo.On("BeginTx", ctx).Return(retryTx).Once()
+				mockRepo.On("ReadScheduleForUpdate", ctx, retryTx, tt.schedule.ID).Return(func(context.Context, *sql.Tx, int64) *model.Schedule {
+					schedule := tt.schedule
+					return &schedule
+				}, nil).Once()
+			} else {
+				mockDB.ExpectCommit()
+				mockRepo.On("BeginTx", ctx).Return(tx).Once()
+			}
+
+			var got model.Schedule
+
+			mockRepo.On("ReadDueScheduleIDs", ctx, mock.Anything, scheduleBatchSize).Return([]int64{tt.schedule.ID}, nil)
+			mockRepo.On("ReadScheduleForUpdate", ctx, tx, tt.schedule.ID).Return(func(context.Context, *sql.Tx, int64) *model.Schedule {
+				schedule := tt.schedule
+				return &schedule
+			}, nil).Once()
+			mockRepo.On("WriteScheduleRun", ctx, mock.Anything, mock.MatchedBy(func(run model.ScheduleRun) bool {
+				return run.Status == tt.wantRun && run.ScheduledFor.Equal(tt.schedule.NextRunAt) && run.Attempt == tt.schedule.Attempts+1
+			})).Return(int64(1), nil)
+			mockRepo.On("UpdateScheduleProgress", ctx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				got = args.Get(2).(model.Schedule)
+			}).Return(nil)
+
+			s := NewScheduleService(&mockRepo, &mocks.UserRepository{}, &mocks.MerchantRepository{}, scheduleWallet{err: tt.payErr})
+			attempted, err := s.RunDue(ctx)
+			if err != nil || attempted != 1 {
+				t.Errorf("scheduleService.RunDue() = %v, error = %v", attempted, err)
+				return
+			}
+			if got.Status != tt.wantStatus || !got.NextRunAt.Equal(tt.wantNextRunAt) || got.Attempts != tt.wantAttempts {
+				t.Errorf("scheduleService.RunDue() schedule = %v %v attempts %v, want %v %v attempts %v", got.Status, got.NextRunAt, got.Attempts, tt.wantStatus, tt.wantNextRunAt, tt.wantAttempts)
+			}
+			if got.DueAt.After(time.Now()) != tt.wantRetry {
+				t.Errorf("scheduleService.RunDue() due at %v, retry %v", got.DueAt, tt.wantRetry)
+			}
+			if err := mockDB.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_occurrence(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		frequency string
+		n         int
+		want      time.Time
+	}{
+		{
+			name:      "daily",
+			frequency: model.ScheduleDaily,
+			n:         2,
+			want:      time.Date(2024, time.February, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "weekly",
+			frequency: model.ScheduleWeekly,
+			n:         1,
+			want:      time.Date(2024, time.February, 7, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "monthly in a leap february",
+			frequency: model.ScheduleMonthly,
+			n:         1,
+			want:      time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "monthly keeps the day after a short month",
+			frequency: model.ScheduleMonthly,
+			n:         2,
+			want:      time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "monthly across the year",
+			frequency: model.ScheduleMonthly,
+			n:         13,
+			want:      time.Date(2025, time.February, 28, 9, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := occurrence(start, tt.frequency, tt.n); !got.Equal(tt.want) {
+				t.Errorf("occurrence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/statement"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+type statementService struct {
+	walletRepo    repository.WalletRepository
+	statementRepo repository.StatementRepository
+	signingSecret string
+}
+
+type StatementService interface {
+	Generate(ctx context.Context, req model.StatementRequest) (*model.Statement, error)
+	Verify(ctx context.Context, hash string) (*model.StatementRecord, error)
+}
+
+func NewStatementService(walletRepo repository.WalletRepository, statementRepo repository.StatementRepository, signingSecret string) StatementService {
+	return &statementService{
+		walletRepo:    walletRepo,
+		statementRepo: statementRepo,
+		signingSecret: signingSecret,
+	}
+}
+
+// Generate builds the statement of the caller's wallet for a completed
+// month from its ledger, signs it and records it so it can be verified
+// later. The opening balance is the balance after the last entry before the
+// month.
+func (s *statementService) Generate(ctx context.Context, req model.StatementRequest) (*model.Statement, error) {
+	now := time.Now()
+
+	from, err := time.ParseInLocation(model.StatementMonthLayout, req.Month, now.Location())
+	if err != nil {
+		return nil, cs.ErrBadParamInput
+	}
+
+	to := from.AddDate(0, 1, 0)
+	if to.After(now) {
+		return nil, cs.ErrBadParamInput
+	}
+
+	wallet, err := s.walletRepo.ReadBalance(ctx, model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if wallet.ID == 0 {
+		return nil, cs.ErrNotFound
+	}
+
+	opening, err := s.walletRepo.ReadBalanceAt(ctx, wallet.ID, from)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	transactions, err := s.walletRepo.ReadTransactionsBetween(ctx, wallet.ID, from, to)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	st := model.Statement{
+		UserID:         req.UserID,
+		WalletID:       wallet.ID,
+		Address:        wallet.Address,
+		Month:          req.Month,
+		From:           from,
+		To:             to,
+		Currency:       model.CurrencyIDR,
+		OpeningBalance: opening,
+		ClosingBalance: opening,
+		Transactions:   transactions,
+		GeneratedAt:    now,
+	}
+
+	for _, trx := range transactions {
+		switch {
+		case trx.Type == model.TransactionTypeFee:
+			st.TotalFees -= trx.Amount
+		case trx.Amount > 0:
+			st.TotalCredits += trx.Amount
+		default:
+			st.TotalDebits -= trx.Amount
+		}
+		st.ClosingBalance = trx.BalanceAfter
+	}
+
+	if st.Hash, err = statement.Hash(s.signingSecret, st); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	err = s.statementRepo.WriteStatement(ctx, model.StatementRecord{
+		UserID:           st.UserID,
+		WalletID:         st.WalletID,
+		Month:            st.Month,
+		OpeningBalance:   st.OpeningBalance,
+		ClosingBalance:   st.ClosingBalance,
+		TransactionCount: len(st.Transactions),
+		Hash:             st.Hash,
+	})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &st, nil
+}
+
+// Verify returns the issued statement with the given hash, or ErrNotFound
+// if no statement was issued with it.
+func (s *statementService) Verify(ctx context.Context, hash string) (*model.StatementRecord, error) {
+	record, err := s.statementRepo.ReadStatementByHash(ctx, hash)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if record == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	return record, nil
+}
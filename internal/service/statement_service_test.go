@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_statementService_Generate(t *testing.T) {
+	ctx := context.Background()
+
+	transactions := []model.Transaction{
+		{ID: 1, WalletID: 3, Type: model.TransactionTypeTopUp, Amount: 50000, BalanceAfter: 150000, Reference: "TU-1"},
+		{ID: 2, WalletID: 3, Type: model.TransactionTypeTransfer, Amount: -20000, BalanceAfter: 130000, Reference: "TR-1"},
+		{ID: 3, WalletID: 3, Type: model.TransactionTypeFee, Amount: -1000, BalanceAfter: 129000, Reference: "TR-1"},
+	}
+
+	tests := []struct {
+		name        string
+		month       string
+		wallet      *model.Wallet
+		want        model.Statement
+		wantErr     error
+		wantWritten bool
+	}{
+		{
+			name:        "positif",
+			month:       "2026-03",
+			wallet:      &model.Wallet{ID: 3, Address: "address"},
+			want:        model.Statement{OpeningBalance: 100000, TotalCredits: 50000, TotalDebits: 20000, TotalFees: 1000, ClosingBalance: 129000},
+			wantWritten: true,
+		},
+		{
+			name:    "negatif: month not over",
+			month:   time.Now().Format(model.StatementMonthLayout),
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: not a month",
+			month:   "March",
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: wallet not found",
+			month:   "2026-03",
+			wallet:  &model.Wallet{},
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			walletRepo := mocks.WalletRepository{}
+			statementRepo := mocks.StatementRepository{}
+
+			walletRepo.On("ReadBalance", ctx, model.CheckBalanceRequest{UserID: 1, Address: "address"}).Return(tt.wallet, nil)
+			walletRepo.On("ReadBalanceAt", ctx, int64(3), mock.Anything).Return(float64(100000), nil)
+			walletRepo.On("ReadTransactionsBetween", ctx, int64(3), mock.Anything, mock.Anything).Return(transactions, nil)
+			statementRepo.On("WriteStatement", ctx, mock.MatchedBy(func(r model.StatementRecord) bool {
+				return r.WalletID == 3 && r.Month == tt.month && r.ClosingBalance == 129000 && r.TransactionCount == 3 && len(r.Hash) == 64
+			})).Return(nil)
+
+			s := NewStatementService(&walletRepo, &statementRepo, "secret")
+			got, err := s.Generate(ctx, model.StatementRequest{Month: tt.month, UserID: 1, Address: "address"})
+			if err != tt.wantErr {
+				t.Errorf("statementService.Generate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (got.OpeningBalance != tt.want.OpeningBalance || got.TotalCredits != tt.want.TotalCredits ||
+				got.TotalDebits != tt.want.TotalDebits || got.TotalFees != tt.want.TotalFees || got.ClosingBalance != tt.want.ClosingBalance) {
+				t.Errorf("statementService.Generate() = %+v, want %+v", got, tt.want)
+			}
+			if tt.wantWritten {
+				statementRepo.AssertCalled(t, "WriteStatement", ctx, mock.Anything)
+			} else {
+				statementRepo.AssertNotCalled(t, "WriteStatement", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_statementService_Verify(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		hash    string
+		record  *model.StatementRecord
+		wantErr error
+	}{
+		{
+			name:   "positif",
+			hash:   "issued",
+			record: &model.StatementRecord{ID: 1, WalletID: 3, Month: "2026-03", Hash: "issued"},
+		},
+		{
+			name:    "negatif: never issued",
+			hash:    "forged",
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statementRepo := mocks.StatementRepository{}
+			statementRepo.On("ReadStatementByHash", ctx, tt.hash).Return(tt.record, nil)
+
+			s := NewStatementService(&mocks.WalletRepository{}, &statementRepo, "secret")
+			got, err := s.Verify(ctx, tt.hash)
+			if err != tt.wantErr {
+				t.Errorf("statementService.Verify() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Hash != tt.hash {
+				t.Errorf("statementService.Verify() = %+v, want hash %s", got, tt.hash)
+			}
+		})
+	}
+}
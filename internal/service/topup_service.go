@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/gateway"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+type topUpService struct {
+	repo           repository.TopUpRepository
+	walletService  WalletService
+	gateway        gateway.PaymentGateway
+	callbackURL    string
+	callbackSecret string
+}
+
+type TopUpService interface {
+	Create(ctx context.Context, req model.TopUpIntentRequest) (*model.TopUpIntent, error)
+	OpenHeld(ctx context.Context, held model.HeldTransaction) (*model.TopUpIntent, error)
+	Get(ctx context.Context, intentID, userID int64) (*model.TopUpIntent, error)
+	List(ctx context.Context, userID int64) ([]model.TopUpIntent, error)
+	HandleCallback(ctx context.Context, body []byte, signature string) (*model.TopUpIntent, error)
+}
+
+func NewTopUpService(topUpRepo repository.TopUpRepository, walletService WalletService, paymentGateway gateway.PaymentGateway, callbackURL, callbackSecret string) TopUpService {
+	return &topUpService{
+		repo:           topUpRepo,
+		walletService:  walletService,
+		gateway:        paymentGateway,
+		callbackURL:    callbackURL,
+		callbackSecret: callbackSecret,
+	}
+}
+
+// Create records a top-up intent and charges it through the payment
+// gateway. The caller completes the payment at the returned redirect URL;
+// the wallet is credited when the gateway calls back.
+func (s *topUpService) Create(ctx context.Context, req model.TopUpIntentRequest) (*model.TopUpIntent, error) {
+	walletID, err := s.walletService.CheckTopUp(ctx, model.TopUpRequest{
+		Nominal: req.Amount,
+		Address: req.Address,
+		UserID:  req.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.open(ctx, req.UserID, walletID, req.Amount)
+}
+
+// OpenHeld opens the intent of a top-up held for review once an admin has
+// approved it. The approval checked the top-up again, so it is not scored
+// a second time; the user finds the intent in their list to pay it.
+func (s *topUpService) OpenHeld(ctx context.Context, held model.HeldTransaction) (*model.TopUpIntent, error) {
+	if held.Operation != model.TransactionTypeTopUp || held.Status != model.HeldTransactionApproved {
+		return nil, cs.ErrBadParamInput
+	}
+
+	return s.open(ctx, held.UserID, held.WalletID, held.Amount)
+}
+
+// open records a checked top-up intent and charges it through the payment
+// gateway.
+func (s *topUpService) open(ctx context.Context, userID, walletID int64, amount float64) (*model.TopUpIntent, error) {
+	intent := model.TopUpIntent{
+		UserID:    userID,
+		WalletID:  walletID,
+		Amount:    amount,
+		Currency:  model.CurrencyIDR,
+		Status:    model.TopUpIntentPending,
+		Reference: utils.GenerateReference(),
+		CreatedAt: time.Now(),
+	}
+	intent.UpdatedAt = intent.CreatedAt
+
+	var err error
+	intent.ID, err = s.repo.WriteTopUpIntent(ctx, intent)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	result, chargeErr := s.gateway.CreateCharge(ctx, gateway.Charge{
+		Reference:   intent.Reference,
+		Amount:      intent.Amount,
+		Currency:    intent.Currency,
+		Description: fmt.Sprintf("wallet top-up %s", intent.Reference),
+		CallbackURL: s.callbackURL,
+	})
+	if chargeErr != nil {
+		logger.Log.Error(fmt.Sprintf("charging top-up %s: %s", intent.Reference, chargeErr.Error()))
+
+		intent.Status = model.TopUpIntentFailed
+		intent.FailureReason = "payment gateway unavailable"
+	} else {
+		intent.GatewayReference = result.GatewayReference
+		intent.RedirectURL = result.RedirectURL
+	}
+
+	if err = s.repo.UpdateTopUpIntentCharge(ctx, intent); err == sql.ErrNoRows {
+		return s.repo.ReadTopUpIntentByID(ctx, intent.ID)
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &intent, nil
+}
+
+// Get returns a top-up intent to the user who made it, to poll for its
+// status.
+func (s *topUpService) Get(ctx context.Context, intentID, userID int64) (*model.TopUpIntent, error) {
+	intent, err := s.repo.ReadTopUpIntentByID(ctx, intentID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if intent == nil || intent.UserID != userID {
+		return nil, cs.ErrNotFound
+	}
+
+	return intent, nil
+}
+
+func (s *topUpService) List(ctx context.Context, userID int64) ([]model.TopUpIntent, error) {
+	intents, err := s.repo.ReadTopUpIntentsByUser(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return intents, nil
+}
+
+// HandleCallback settles a top-up intent from a gateway callback signed
+// with the callback secret, crediting the wallet in the same transaction
+// when the charge was paid. Callbacks may be repeated; one that contradicts
+// how the intent was already settled, or is for another amount, is refused.
+func (s *topUpService) HandleCallback(ctx context.Context, body []byte, signature string) (intent *model.TopUpIntent, err error) {
+	if !gateway.Verify(s.callbackSecret, body, signature) {
+		return nil, cs.ErrInvalidSignature
+	}
+
+	var result gateway.Result
+	if err = json.Unmarshal(body, &result); err != nil || result.Reference == "" {
+		return nil, cs.ErrBadParamInput
+	}
+
+	var status string
+	switch result.Status {
+	case gateway.StatusPaid:
+		status = model.TopUpIntentSucceeded
+	case gateway.StatusFailed:
+		status = model.TopUpIntentFailed
+	default:
+		return nil, cs.ErrBadParamInput
+	}
+
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+		s.walletService.AfterTx(tx, err == nil)
+	}()
+
+	intent, err = s.repo.ReadTopUpIntentForUpdate(ctx, tx, result.Reference)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if intent == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if result.Amount != intent.Amount {
+		logger.Log.Warn(fmt.Sprintf("top-up %s confirmed for %.0f, expected %.0f", intent.Reference, result.Amount, intent.Amount))
+		return nil, cs.ErrBadParamInput
+	}
+
+	if intent.Finalized() {
+		if intent.Status != status {
+			return nil, cs.ErrTopUpFinalized
+		}
+
+		return intent, tx.Rollback()
+	}
+
+	intent.Status = status
+	if result.GatewayReference != "" {
+		intent.GatewayReference = result.GatewayReference
+	}
+
+	if status == model.TopUpIntentSucceeded {
+		var credit *model.Transaction
+		if credit, err = s.walletService.CreditTopUp(ctx, tx, *intent); err != nil {
+			return nil, err
+		}
+
+		intent.TransactionID = &credit.ID
+	} else {
+		intent.FailureReason = result.Reason
+		if len(intent.FailureReason) > 255 {
+			intent.FailureReason = intent.FailureReason[:255]
+		}
+	}
+
+	if err = s.repo.UpdateTopUpIntent(ctx, tx, *intent); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	intent.UpdatedAt = time.Now()
+
+	return intent, nil
+}
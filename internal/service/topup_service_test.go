@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/gateway"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// topUpWallet stands in for the wallet service when topping up, refusing
+// every top-up with err when it is set and recording whether a top-up was
+// credited.
+type topUpWallet struct {
+	WalletService
+	err      error
+	credited *bool
+}
+
+func (w topUpWallet) CheckTopUp(ctx context.Context, req model.TopUpRequest) (int64, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	return 3, nil
+}
+
+func (w topUpWallet) CreditTopUp(ctx context.Context, tx *sql.Tx, intent model.TopUpIntent) (*model.Transaction, error) {
+	*w.credited = true
+	return &model.Transaction{ID: 60, WalletID: intent.WalletID, Amount: intent.Amount, Reference: intent.Reference}, nil
+}
+
+func (w topUpWallet) AfterTx(tx *sql.Tx, committed bool) {}
+
+// stubGateway answers every charge with result or err.
+type stubGateway struct {
+	result *gateway.Result
+	err    error
+}
+
+func (g stubGateway) CreateCharge(ctx context.Context, charge gateway.Charge) (*gateway.Result, error) {
+	return g.result, g.err
+}
+
+func Test_topUpService_Create(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		walletErr  error
+		gateway    stubGateway
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name:       "positif: charged",
+			gateway:    stubGateway{result: &gateway.Result{GatewayReference: "MG-1", Status: gateway.StatusPending, RedirectURL: "http://gateway/checkout/MG-1"}},
+			wantStatus: model.TopUpIntentPending,
+		},
+		{
+			name:       "negatif: gateway unavailable",
+			gateway:    stubGateway{err: errors.New("connection refused")},
+			wantStatus: model.TopUpIntentFailed,
+		},
+		{
+			name:      "negatif: balance limit exceeded",
+			walletErr: cs.ErrBalanceLimitExceeded,
+			wantErr:   cs.ErrBalanceLimitExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.TopUpRepository{}
+
+			mockRepo.On("WriteTopUpIntent", ctx, mock.MatchedBy(func(i model.TopUpIntent) bool {
+				return i.Status == model.TopUpIntentPending && i.WalletID == 3 && i.Reference != ""
+			})).Return(int64(4), nil)
+			mockRepo.On("UpdateTopUpIntentCharge", ctx, mock.MatchedBy(func(i model.TopUpIntent) bool {
+				return i.ID == 4 && i.Status == tt.wantStatus
+			})).Return(nil)
+
+			credited := false
+			s := NewTopUpService(&mockRepo, topUpWallet{err: tt.walletErr, credited: &credited}, tt.gateway, "", "")
+			got, err := s.Create(ctx, model.TopUpIntentRequest{Amount: 50000, UserID: 1})
+			if err != tt.wantErr {
+				t.Errorf("topUpService.Create() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Status != tt.wantStatus {
+				t.Errorf("topUpService.Create() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if err != nil {
+				mockRepo.AssertNotCalled(t, "WriteTopUpIntent", mock.Anything, mock.Anything)
+			}
+			if credited {
+				t.Error("topUpService.Create() credited the wallet before the gateway confirmed")
+			}
+		})
+	}
+}
+
+func Test_topUpService_OpenHeld(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		held    model.HeldTransaction
+		wantErr error
+	}{
+		{
+			name: "positif",
+			held: model.HeldTransaction{ID: 9, UserID: 1, WalletID: 3, Operation: model.TransactionTypeTopUp, Amount: 50000, Status: model.HeldTransactionApproved},
+		},
+		{
+			name:    "negatif: not approved",
+			held:    model.HeldTransaction{ID: 9, UserID: 1, WalletID: 3, Operation: model.TransactionTypeTopUp, Amount: 50000, Status: model.HeldTransactionPending},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: not a top-up",
+			held:    model.HeldTransaction{ID: 9, UserID: 1, WalletID: 3, Operation: model.TransactionTypePayment, Amount: 50000, Status: model.HeldTransactionApproved},
+			wantErr: cs.ErrBadParamInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.TopUpRepository{}
+
+			mockRepo.On("WriteTopUpIntent", ctx, mock.MatchedBy(func(i model.TopUpIntent) bool {
+				return i.UserID == 1 && i.WalletID == 3 && i.Amount == 50000 && i.Status == model.TopUpIntentPending
+			})).Return(int64(4), nil)
+			mockRepo.On("UpdateTopUpIntentCharge", ctx, mock.Anything).Return(nil)
+
+			credited := false
+			s := NewTopUpService(&mockRepo, topUpWallet{credited: &credited}, stubGateway{result: &gateway.Result{GatewayReference: "MG-1", Status: gateway.StatusPending}}, "", "")
+			got, err := s.OpenHeld(ctx, tt.held)
+			if err != tt.wantErr {
+				t.Fatalf("topUpService.OpenHeld() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Status != model.TopUpIntentPending {
+				t.Errorf("topUpService.OpenHeld() status = %v, want %v", got.Status, model.TopUpIntentPending)
+			}
+			if err != nil {
+				mockRepo.AssertNotCalled(t, "WriteTopUpIntent", mock.Anything, mock.Anything)
+			}
+			if credited {
+				t.Error("topUpService.OpenHeld() credited the wallet before the user paid")
+			}
+		})
+	}
+}
+
+func Test_topUpService_HandleCallback(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	secret := "callback-secret"
+
+	tests := []struct {
+		name         string
+		status       string
+		body         string
+		signature    string
+		wantStatus   string
+		wantCredited bool
+		wantUpdate   bool
+		wantErr      error
+	}{
+		{
+			name:         "positif: paid and credited",
+			status:       model.TopUpIntentPending,
+			body:         `{"reference":"TU-1","gateway_reference":"MG-1","amount":50000,"status":"paid"}`,
+			wantStatus:   model.TopUpIntentSucceeded,
+			wantCredited: true,
+			wantUpdate:   true,
+		},
+		{
+			name:       "positif: failed",
+			status:     model.TopUpIntentPending,
+			body:       `{"reference":"TU-1","gateway_reference":"MG-1","amount":50000,"status":"failed","reason":"card declined"}`,
+			wantStatus: model.TopUpIntentFailed,
+			wantUpdate: true,
+		},
+		{
+			name:       "positif: repeated callback",
+			status:     model.TopUpIntentSucceeded,
+			body:       `{"reference":"TU-1","gateway_reference":"MG-1","amount":50000,"status":"paid"}`,
+			wantStatus: model.TopUpIntentSucceeded,
+		},
+		{
+			name:    "negatif: contradicts the settled outcome",
+			status:  model.TopUpIntentSucceeded,
+			body:    `{"reference":"TU-1","gateway_reference":"MG-1","amount":50000,"status":"failed"}`,
+			wantErr: cs.ErrTopUpFinalized,
+		},
+		{
+			name:    "negatif: another amount",
+			status:  model.TopUpIntentPending,
+			body:    `{"reference":"TU-1","gateway_reference":"MG-1","amount":5000000,"status":"paid"}`,
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:      "negatif: bad signature",
+			body:      `{"reference":"TU-1","gateway_reference":"MG-1","amount":50000,"status":"paid"}`,
+			signature: "forged",
+			wantErr:   cs.ErrInvalidSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.TopUpRepository{}
+
+			if tt.status != "" {
+				tx := beginTx(db, mockDB)
+				mockRepo.On("BeginTx", ctx).Return(tx).Once()
+				if tt.wantUpdate {
+					mockDB.ExpectCommit()
+				} else {
+					mockDB.ExpectRollback()
+				}
+			}
+
+			mockRepo.On("ReadTopUpIntentForUpdate", ctx, mock.Anything, "TU-1").Return(&model.TopUpIntent{ID: 4, WalletID: 3, Amount: 50000, Status: tt.status, Reference: "TU-1"}, nil)
+			mockRepo.On("UpdateTopUpIntent", ctx, mock.Anything, mock.MatchedBy(func(i model.TopUpIntent) bool {
+				return i.Status == tt.wantStatus && (i.TransactionID != nil) == tt.wantCredited
+			})).Return(nil)
+
+			signature := tt.signature
+			if signature == "" {
+				signature = gateway.Sign(secret, []byte(tt.body))
+			}
+
+			credited := false
+			s := NewTopUpService(&mockRepo, topUpWallet{credited: &credited}, stubGateway{}, "", secret)
+			got, err := s.HandleCallback(ctx, []byte(tt.body), signature)
+			if err != tt.wantErr {
+				t.Errorf("topUpService.HandleCallback() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Status != tt.wantStatus {
+				t.Errorf("topUpService.HandleCallback() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if credited != tt.wantCredited {
+				t.Errorf("topUpService.HandleCallback() credited = %v, want %v", credited, tt.wantCredited)
+			}
+			if err := mockDB.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
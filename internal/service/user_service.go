@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	cs "github.com/cecepsprd/starworks-test/constans"
 	"github.com/cecepsprd/starworks-test/internal/model"
 	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/storage"
 	"github.com/cecepsprd/starworks-test/utils"
 	"github.com/cecepsprd/starworks-test/utils/logger"
 	"github.com/golang-jwt/jwt"
@@ -18,21 +20,38 @@ type UserService interface {
 	Login(context.Context, model.LoginRequest) (user *model.User, token string, err error)
 	Create(ctx context.Context, request model.User) error
 	WriteLoginHistory(ctx context.Context, userID int64, isLoginFailed bool) error
+	Export(ctx context.Context, userID int64) (*model.UserExport, error)
+	RequestDeletion(ctx context.Context, userID int64) (*model.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID int64) error
+	PurgeDeletedAccounts(ctx context.Context) (purged int, err error)
+	CheckActive(ctx context.Context, userID int64) error
 }
 
 type userService struct {
-	repo           repository.UserRepository
-	walletRepo     repository.WalletRepository
-	JWTSecret      string
-	contextTimeout time.Duration
+	repo                repository.UserRepository
+	walletRepo          repository.WalletRepository
+	kycRepo             repository.KYCRepository
+	blobStore           storage.BlobStore
+	webhooks            WebhookDispatcher
+	events              EventRecorder
+	audit               AuditLogger
+	JWTSecret           string
+	contextTimeout      time.Duration
+	deletionGracePeriod time.Duration
 }
 
-func NewUserService(urepo repository.UserRepository, walletRepo repository.WalletRepository, JWTSecret string, timeout time.Duration) UserService {
+func NewUserService(urepo repository.UserRepository, walletRepo repository.WalletRepository, kycRepo repository.KYCRepository, blobStore storage.BlobStore, webhooks WebhookDispatcher, events EventRecorder, audit AuditLogger, JWTSecret string, timeout, deletionGracePeriod time.Duration) UserService {
 	return &userService{
-		repo:           urepo,
-		walletRepo:     walletRepo,
-		JWTSecret:      JWTSecret,
-		contextTimeout: timeout,
+		repo:                urepo,
+		walletRepo:          walletRepo,
+		kycRepo:             kycRepo,
+		blobStore:           blobStore,
+		webhooks:            webhooks,
+		events:              events,
+		audit:               audit,
+		JWTSecret:           JWTSecret,
+		contextTimeout:      timeout,
+		deletionGracePeriod: deletionGracePeriod,
 	}
 }
 
@@ -44,12 +63,22 @@ func (s *userService) Login(ctx context.Context, request model.LoginRequest) (us
 	}
 
 	if user == nil {
+		s.audit.Log(ctx, nil, model.AuditEntry{
+			Action:     model.AuditLoginFailed,
+			TargetType: model.AuditTargetUser,
+			After:      auditValue(map[string]string{"username": request.Username, "email": request.Email}),
+		})
 		return nil, "", errors.New("user with this email/username does not exist")
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
 	if err != nil {
 		logger.Log.Error(err.Error())
+		s.audit.Log(ctx, nil, model.AuditEntry{
+			Action:     model.AuditLoginFailed,
+			TargetType: model.AuditTargetUser,
+			TargetID:   user.ID,
+		})
 		return user, "", errors.New("login failed, incorrect password")
 	}
 
@@ -58,6 +87,7 @@ func (s *userService) Login(ctx context.Context, request model.LoginRequest) (us
 	claims["user_id"] = user.ID
 	claims["username"] = user.Username
 	claims["email"] = user.Email
+	claims["role"] = user.Role
 	claims["exp"] = time.Now().Add(time.Hour * 72).Unix()
 
 	signedToken, err := jwtToken.SignedString([]byte(s.JWTSecret))
@@ -65,6 +95,13 @@ func (s *userService) Login(ctx context.Context, request model.LoginRequest) (us
 		return user, "", err
 	}
 
+	s.audit.Log(ctx, nil, model.AuditEntry{
+		Action:     model.AuditLogin,
+		ActorID:    user.ID,
+		TargetType: model.AuditTargetUser,
+		TargetID:   user.ID,
+	})
+
 	return user, signedToken, nil
 }
 
@@ -105,14 +142,44 @@ func (s *userService) Create(ctx context.Context, request model.User) error {
 	wallet := model.Wallet{
 		Address: walletAddress,
 		UserID:  userID,
+		Kind:    model.WalletKindPersonal,
 	}
 
-	if err = s.walletRepo.AddWallet(ctx, tx, wallet); err != nil {
+	if _, err = s.walletRepo.AddWallet(ctx, tx, wallet); err != nil {
 		logger.Log.Error(err.Error())
 		return err
 	}
 
-	tx.Commit()
+	registered := model.RegisteredUser{
+		ID:        userID,
+		Username:  request.Username,
+		Email:     request.Email,
+		CreatedAt: time.Now(),
+	}
+
+	if err = s.webhooks.Dispatch(ctx, tx, userID, model.WebhookEventUserRegistered, registered); err != nil {
+		return err
+	}
+
+	if err = s.events.Record(ctx, tx, userID, model.WebhookEventUserRegistered, registered); err != nil {
+		return err
+	}
+
+	err = s.audit.Log(ctx, tx, model.AuditEntry{
+		Action:     model.AuditUserRegistered,
+		ActorID:    userID,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+		After:      auditValue(registered),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
 
 	return nil
 }
@@ -154,3 +221,213 @@ func (s *userService) WriteLoginHistory(ctx context.Context, userID int64, isLog
 
 	return err
 }
+
+func (s *userService) Export(ctx context.Context, userID int64) (*model.UserExport, error) {
+	user, err := s.repo.ReadByID(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	export := model.UserExport{
+		Transactions: []model.Transaction{},
+		ExportedAt:   time.Now(),
+	}
+
+	if err = utils.MappingInterface(user, &export.Profile); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	export.Wallets, err = s.walletRepo.ReadByUserID(ctx, userID, model.WalletKindPersonal)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	for _, wallet := range export.Wallets {
+		transactions, err := s.walletRepo.ReadTransactions(ctx, wallet.ID)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+		export.Transactions = append(export.Transactions, transactions...)
+	}
+
+	export.LoginHistory, err = s.repo.ReadLoginHistories(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// RequestDeletion schedules the account for anonymization once the grace
+// period has passed. Every wallet of the user must be empty.
+func (s *userService) RequestDeletion(ctx context.Context, userID int64) (*model.AccountDeletion, error) {
+	pending, err := s.repo.ReadPendingAccountDeletion(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if pending != nil {
+		return nil, cs.ErrDeletionRequested
+	}
+
+	if err = s.ensureZeroBalance(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	deletion := model.AccountDeletion{
+		UserID:      userID,
+		Status:      model.AccountDeletionPending,
+		ScheduledAt: time.Now().Add(s.deletionGracePeriod),
+	}
+
+	deletion.ID, err = s.repo.WriteAccountDeletion(ctx, deletion)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &deletion, nil
+}
+
+func (s *userService) CancelDeletion(ctx context.Context, userID int64) error {
+	pending, err := s.repo.ReadPendingAccountDeletion(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	if pending == nil {
+		return cs.ErrNotFound
+	}
+
+	if err = s.repo.CancelAccountDeletion(ctx, pending.ID); err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// PurgeDeletedAccounts anonymizes every account whose grace period has ended.
+// Accounts that received funds in the meantime are skipped and stay pending.
+func (s *userService) PurgeDeletedAccounts(ctx context.Context) (purged int, err error) {
+	deletions, err := s.repo.ReadDueAccountDeletions(ctx, time.Now())
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	for _, deletion := range deletions {
+		if err := s.ensureZeroBalance(ctx, deletion.UserID); err != nil {
+			logger.Log.Warn(fmt.Sprintf("skipping deletion of user %d: %s", deletion.UserID, err.Error()))
+			continue
+		}
+
+		if err := s.anonymize(ctx, deletion); err != nil {
+			logger.Log.Error(err.Error())
+			return purged, err
+		}
+
+		purged++
+	}
+
+	return purged, nil
+}
+
+// anonymize scrubs the user's personal data and drops their KYC documents.
+// The document files are removed only once the rows are gone, so a failed
+// purge never leaves rows pointing at missing files.
+func (s *userService) anonymize(ctx context.Context, deletion model.AccountDeletion) (err error) {
+	docs, err := s.kycRepo.ReadDocumentsByUserID(ctx, deletion.UserID)
+	if err != nil {
+		return err
+	}
+
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = s.repo.Anonymize(ctx, tx, deletion.UserID); err != nil {
+		return err
+	}
+
+	if err = s.kycRepo.DeleteDocumentsByUserID(ctx, tx, deletion.UserID); err != nil {
+		return err
+	}
+
+	if err = s.repo.CompleteAccountDeletion(ctx, tx, deletion.ID); err != nil {
+		return err
+	}
+
+	err = s.audit.Log(ctx, tx, model.AuditEntry{
+		Action:     model.AuditProfileChanged,
+		TargetType: model.AuditTargetUser,
+		TargetID:   deletion.UserID,
+		After:      auditValue(map[string]interface{}{"anonymized": true, "deletion_id": deletion.ID, "kyc_documents": len(docs)}),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, doc := range docs {
+		if err := s.blobStore.Delete(ctx, doc.StorageKey); err != nil {
+			logger.Log.Warn(fmt.Sprintf("error deleting kyc document %d of user %d: %s", doc.ID, deletion.UserID, err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// CheckActive fails with ErrAccountDeleted once the user's account has been
+// purged, so the tokens issued to it before stop working.
+func (s *userService) CheckActive(ctx context.Context, userID int64) error {
+	active, err := s.repo.IsUserActive(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	if !active {
+		return cs.ErrAccountDeleted
+	}
+
+	return nil
+}
+
+// ensureZeroBalance checks the user holds no money, in their own wallets or
+// in the settlement wallets of the merchants they own.
+func (s *userService) ensureZeroBalance(ctx context.Context, userID int64) error {
+	for _, kind := range []string{model.WalletKindPersonal, model.WalletKindSettlement} {
+		wallets, err := s.walletRepo.ReadByUserID(ctx, userID, kind)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return err
+		}
+
+		for _, wallet := range wallets {
+			if wallet.Balance != 0 {
+				return cs.ErrBalanceNotZero
+			}
+		}
+	}
+
+	return nil
+}
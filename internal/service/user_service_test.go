@@ -7,12 +7,16 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/broker"
 	"github.com/cecepsprd/starworks-test/internal/mocks"
 	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/storage"
 	"github.com/cecepsprd/starworks-test/utils"
 	"github.com/cecepsprd/starworks-test/utils/logger"
 	"github.com/stretchr/testify/mock"
@@ -88,7 +92,7 @@ func Test_userService_Login(t *testing.T) {
 				mockUserRepo.On("ReadByUsernameOrEmail", ctx, tt.args.Username, tt.args.Email).Return(&user, nil)
 			}
 
-			s := NewUserService(&mockUserRepo, &mockWalletRepo, "jwtSecret", 5*time.Second)
+			s := NewUserService(&mockUserRepo, &mockWalletRepo, &mocks.KYCRepository{}, storage.NewLocalBlobStore(t.TempDir()), noWebhooks{}, noEvents{}, noAudit{}, "jwtSecret", 5*time.Second, 24*time.Hour)
 
 			gotUser, _, err := s.Login(ctx, tt.args)
 			if (err != nil) != tt.wantErr {
@@ -142,6 +146,7 @@ func Test_userService_Create(t *testing.T) {
 
 			mockUserRepo := mocks.UserRepository{}
 			mockWalletRepo := mocks.WalletRepository{}
+			mockOutboxRepo := mocks.OutboxRepository{}
 
 			if tt.wantErr {
 				mockUserRepo.On("IsUserRegistered", ctx, tt.args.Username, tt.args.Email).Return(true, nil)
@@ -149,13 +154,257 @@ func Test_userService_Create(t *testing.T) {
 				mockUserRepo.On("IsUserRegistered", ctx, tt.args.Username, tt.args.Email).Return(false, nil)
 				mockUserRepo.On("BeginTx", ctx).Return(tx)
 				mockUserRepo.On("Create", ctx, tx, mock.Anything).Return(int64(1), nil)
-				mockWalletRepo.On("AddWallet", ctx, tx, mock.Anything).Return(nil)
+				mockWalletRepo.On("AddWallet", ctx, tx, mock.Anything).Return(int64(1), nil)
+				mockOutboxRepo.On("WriteEvent", ctx, tx, mock.MatchedBy(func(e model.OutboxEvent) bool {
+					return e.Type == model.WebhookEventUserRegistered && e.Key == "1"
+				})).Return(int64(1), nil)
+				mockDB.ExpectCommit()
 			}
-			s := NewUserService(&mockUserRepo, &mockWalletRepo, "secret", 5*time.Second)
+			s := NewUserService(&mockUserRepo, &mockWalletRepo, &mocks.KYCRepository{}, storage.NewLocalBlobStore(t.TempDir()), noWebhooks{}, NewOutboxService(&mockOutboxRepo, broker.NewMemoryBroker()), noAudit{}, "secret", 5*time.Second, 24*time.Hour)
 
 			if err := s.Create(ctx, tt.args); (err != nil) != tt.wantErr {
 				t.Errorf("userService.Create() error = %v, wantErr %v", err, tt.wantErr)
 			}
+
+			mockOutboxRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_userService_Export(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		user    *model.User
+		wantErr bool
+	}{
+		{
+			name:    "positif",
+			user:    &model.User{ID: 1, Username: "elon", Email: "elon@spacex.com"},
+			wantErr: false,
+		},
+		{
+			name:    "negatif: user not found",
+			user:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := mocks.UserRepository{}
+			mockWalletRepo := mocks.WalletRepository{}
+
+			wallets := []model.Wallet{{ID: 3, UserID: 1, Balance: 500}}
+			transactions := []model.Transaction{{ID: 9, WalletID: 3, Type: model.TransactionTypeTopUp, Amount: 500}}
+			histories := []model.LoginHistory{{BrowserName: "Firefox", LoginSucceed: 2, UserID: 1}}
+
+			mockUserRepo.On("ReadByID", ctx, int64(1)).Return(tt.user, nil)
+			mockWalletRepo.On("ReadByUserID", ctx, int64(1), model.WalletKindPersonal).Return(wallets, nil)
+			mockWalletRepo.On("ReadTransactions", ctx, int64(3)).Return(transactions, nil)
+			mockUserRepo.On("ReadLoginHistories", ctx, int64(1)).Return(histories, nil)
+
+			s := NewUserService(&mockUserRepo, &mockWalletRepo, &mocks.KYCRepository{}, storage.NewLocalBlobStore(t.TempDir()), noWebhooks{}, noEvents{}, noAudit{}, "secret", 5*time.Second, 24*time.Hour)
+
+			got, err := s.Export(ctx, 1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("userService.Export() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got == nil {
+				return
+			}
+
+			if got.Profile.Username != tt.user.Username ||
+				!reflect.DeepEqual(got.Wallets, wallets) ||
+				!reflect.DeepEqual(got.Transactions, transactions) ||
+				!reflect.DeepEqual(got.LoginHistory, histories) {
+				t.Errorf("userService.Export() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_userService_RequestDeletion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		pending *model.AccountDeletion
+		balance float64
+		// settled is the balance of the user's merchant settlement wallet.
+		settled float64
+		wantErr error
+	}{
+		{
+			name:    "positif",
+			balance: 0,
+			wantErr: nil,
+		},
+		{
+			name:    "negatif: balance not zero",
+			balance: 100,
+			wantErr: cs.ErrBalanceNotZero,
+		},
+		{
+			name:    "negatif: merchant funds not paid out",
+			settled: 100,
+			wantErr: cs.ErrBalanceNotZero,
+		},
+		{
+			name:    "negatif: already requested",
+			pending: &model.AccountDeletion{ID: 1, UserID: 1, Status: model.AccountDeletionPending},
+			wantErr: cs.ErrDeletionRequested,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := mocks.UserRepository{}
+			mockWalletRepo := mocks.WalletRepository{}
+
+			mockUserRepo.On("ReadPendingAccountDeletion", ctx, int64(1)).Return(tt.pending, nil)
+			mockWalletRepo.On("ReadByUserID", ctx, int64(1), model.WalletKindPersonal).Return([]model.Wallet{{ID: 3, UserID: 1, Balance: tt.balance}}, nil)
+			mockWalletRepo.On("ReadByUserID", ctx, int64(1), model.WalletKindSettlement).Return([]model.Wallet{{ID: 20, UserID: 1, Balance: tt.settled}}, nil)
+			mockUserRepo.On("WriteAccountDeletion", ctx, mock.MatchedBy(func(d model.AccountDeletion) bool {
+				return d.UserID == 1 && d.Status == model.AccountDeletionPending && d.ScheduledAt.After(time.Now().Add(23*time.Hour))
+			})).Return(int64(2), nil)
+
+			s := NewUserService(&mockUserRepo, &mockWalletRepo, &mocks.KYCRepository{}, storage.NewLocalBlobStore(t.TempDir()), noWebhooks{}, noEvents{}, noAudit{}, "secret", 5*time.Second, 24*time.Hour)
+
+			got, err := s.RequestDeletion(ctx, 1)
+			if err != tt.wantErr {
+				t.Errorf("userService.RequestDeletion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err == nil && got.ID != 2 {
+				t.Errorf("userService.RequestDeletion() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_userService_CancelDeletion(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		pending *model.AccountDeletion
+		wantErr error
+	}{
+		{
+			name:    "positif",
+			pending: &model.AccountDeletion{ID: 2, UserID: 1, Status: model.AccountDeletionPending},
+			wantErr: nil,
+		},
+		{
+			name:    "negatif: nothing to cancel",
+			pending: nil,
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := mocks.UserRepository{}
+			mockWalletRepo := mocks.WalletRepository{}
+
+			mockUserRepo.On("ReadPendingAccountDeletion", ctx, int64(1)).Return(tt.pending, nil)
+			mockUserRepo.On("CancelAccountDeletion", ctx, int64(2)).Return(nil)
+
+			s := NewUserService(&mockUserRepo, &mockWalletRepo, &mocks.KYCRepository{}, storage.NewLocalBlobStore(t.TempDir()), noWebhooks{}, noEvents{}, noAudit{}, "secret", 5*time.Second, 24*time.Hour)
+
+			if err := s.CancelDeletion(ctx, 1); err != tt.wantErr {
+				t.Errorf("userService.CancelDeletion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_userService_CheckActive(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		active  bool
+		wantErr error
+	}{
+		{
+			name:    "positif",
+			active:  true,
+			wantErr: nil,
+		},
+		{
+			name:    "negatif: account purged",
+			active:  false,
+			wantErr: cs.ErrAccountDeleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := mocks.UserRepository{}
+			mockWalletRepo := mocks.WalletRepository{}
+
+			mockUserRepo.On("IsUserActive", ctx, int64(1)).Return(tt.active, nil)
+
+			s := NewUserService(&mockUserRepo, &mockWalletRepo, &mocks.KYCRepository{}, storage.NewLocalBlobStore(t.TempDir()), noWebhooks{}, noEvents{}, noAudit{}, "secret", 5*time.Second, 24*time.Hour)
+
+			if err := s.CheckActive(ctx, 1); err != tt.wantErr {
+				t.Errorf("userService.CheckActive() error = %v, wantErr %v", err, tt.wantErr)
+			}
 		})
 	}
 }
+
+func Test_userService_PurgeDeletedAccounts(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	mockUserRepo := mocks.UserRepository{}
+	mockWalletRepo := mocks.WalletRepository{}
+	mockKYCRepo := mocks.KYCRepository{}
+	blobStore := storage.NewLocalBlobStore(t.TempDir())
+
+	deletions := []model.AccountDeletion{
+		{ID: 1, UserID: 10, Status: model.AccountDeletionPending},
+		{ID: 2, UserID: 20, Status: model.AccountDeletionPending},
+	}
+
+	docs := []model.KYCDocument{{ID: 5, UserID: 10, StorageKey: "kyc/10/id_card"}}
+	if err := blobStore.Put(ctx, docs[0].StorageKey, strings.NewReader("scan")); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := beginTx(db, mockDB)
+	mockDB.ExpectCommit()
+
+	mockUserRepo.On("ReadDueAccountDeletions", ctx, mock.Anything).Return(deletions, nil)
+	mockWalletRepo.On("ReadByUserID", ctx, int64(10), mock.Anything).Return([]model.Wallet{{UserID: 10, Balance: 0}}, nil)
+	mockWalletRepo.On("ReadByUserID", ctx, int64(20), model.WalletKindPersonal).Return([]model.Wallet{{UserID: 20, Balance: 50}}, nil)
+	mockKYCRepo.On("ReadDocumentsByUserID", ctx, int64(10)).Return(docs, nil)
+	mockKYCRepo.On("DeleteDocumentsByUserID", ctx, tx, int64(10)).Return(nil)
+	mockUserRepo.On("BeginTx", ctx).Return(tx)
+	mockUserRepo.On("Anonymize", ctx, tx, int64(10)).Return(nil)
+	mockUserRepo.On("CompleteAccountDeletion", ctx, tx, int64(1)).Return(nil)
+
+	s := NewUserService(&mockUserRepo, &mockWalletRepo, &mockKYCRepo, blobStore, noWebhooks{}, noEvents{}, noAudit{}, "secret", 5*time.Second, 24*time.Hour)
+
+	purged, err := s.PurgeDeletedAccounts(ctx)
+	if err != nil {
+		t.Errorf("userService.PurgeDeletedAccounts() error = %v", err)
+		return
+	}
+
+	if purged != 1 {
+		t.Errorf("userService.PurgeDeletedAccounts() = %v, want %v", purged, 1)
+	}
+
+	mockUserRepo.AssertNotCalled(t, "Anonymize", ctx, tx, int64(20))
+	mockKYCRepo.AssertCalled(t, "DeleteDocumentsByUserID", ctx, tx, int64(10))
+	mockKYCRepo.AssertNotCalled(t, "DeleteDocumentsByUserID", ctx, mock.Anything, int64(20))
+
+	if _, err := blobStore.Get(ctx, docs[0].StorageKey); !os.IsNotExist(err) {
+		t.Errorf("kyc document file still present, err = %v", err)
+	}
+}
@@ -2,26 +2,133 @@ package service
 
 import (
 	"context"
-	"errors"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
 
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/emvqr"
+	"github.com/cecepsprd/starworks-test/internal/event"
 	"github.com/cecepsprd/starworks-test/internal/model"
 	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils"
 	"github.com/cecepsprd/starworks-test/utils/logger"
 )
 
 type walletService struct {
-	repo repository.WalletRepository
+	repo         repository.WalletRepository
+	userRepo     repository.UserRepository
+	riskRepo     repository.RiskRepository
+	holdRepo     repository.HoldRepository
+	refundRepo   repository.RefundRepository
+	merchantRepo repository.MerchantRepository
+	requestRepo  repository.PaymentRequestRepository
+	escrowRepo   repository.EscrowRepository
+	limitService LimitService
+	feeService   FeeService
+	riskEngine   RiskEngine
+	// escrowWalletID is the wallet escrowed funds are kept in.
+	escrowWalletID int64
+	bus            *event.Bus
+	webhooks       WebhookDispatcher
+	events         EventRecorder
+	audit          AuditLogger
+
+	// posted holds the ledger entries of each open transaction until it
+	// ends, so only committed changes are published.
+	postedMu sync.Mutex
+	posted   map[*sql.Tx][]postedEntry
+}
+
+// postedEntry is a ledger entry posted to a wallet and the wallet's owner.
+type postedEntry struct {
+	userID  int64
+	address string
+	entry   model.Transaction
 }
 
 type WalletService interface {
 	CheckBalance(context.Context, model.CheckBalanceRequest) (*model.Wallet, error)
-	TopUp(ctx context.Context, req model.TopUpRequest) error
 	Pay(ctx context.Context, req model.PayRequest) error
+	PayPaymentRequest(ctx context.Context, code string, payer model.CheckBalanceRequest) error
+	PayByQR(ctx context.Context, req model.QRPayRequest) error
+	Transfer(ctx context.Context, req model.TransferRequest) error
+	RunSchedule(ctx context.Context, tx *sql.Tx, schedule model.Schedule) (*model.Transaction, error)
+	CheckTopUp(ctx context.Context, req model.TopUpRequest) (walletID int64, err error)
+	ResolveTopUp(ctx context.Context, req model.TopUpRequest) (*model.TopUpRequest, error)
+	TopUp(ctx context.Context, tx *sql.Tx, req model.TopUpRequest) (*model.Transaction, error)
+	CreditTopUp(ctx context.Context, tx *sql.Tx, intent model.TopUpIntent) (*model.Transaction, error)
+	AssessWithdrawal(ctx context.Context, req model.WithdrawRequest) error
+	Withdraw(ctx context.Context, tx *sql.Tx, req model.WithdrawRequest) (*model.Transaction, error)
+	RevertWithdrawal(ctx context.Context, tx *sql.Tx, payout model.Payout) (*model.Transaction, error)
+	PreviewFee(ctx context.Context, req model.FeePreviewRequest) (*model.FeeQuote, error)
+	ListTransactions(ctx context.Context, req model.CheckBalanceRequest) ([]model.Transaction, error)
+	ListHeldTransactions(ctx context.Context, status string, page model.PageRequest) (*model.Page, error)
+	ApproveHeldTransaction(ctx context.Context, heldID, reviewerID int64) (*model.HeldTransaction, error)
+	RejectHeldTransaction(ctx context.Context, heldID, reviewerID int64) (*model.HeldTransaction, error)
+	Authorize(ctx context.Context, req model.AuthorizeRequest) (*model.Hold, error)
+	Capture(ctx context.Context, holdID int64, req model.CaptureRequest) (*model.Hold, error)
+	Void(ctx context.Context, holdID int64, req model.VoidRequest) (*model.Hold, error)
+	ListHolds(ctx context.Context, req model.CheckBalanceRequest) ([]model.Hold, error)
+	ExpireHolds(ctx context.Context) (int64, error)
+	Refund(ctx context.Context, req model.RefundRequest) (*model.Refund, error)
+	ListRefunds(ctx context.Context, trxID int64, requester model.User) ([]model.Refund, error)
+	FundEscrow(ctx context.Context, req model.EscrowRequest) (*model.Escrow, error)
+	GetEscrow(ctx context.Context, escrowID int64, viewer model.User) (*model.Escrow, error)
+	ListEscrows(ctx context.Context, userID int64) ([]model.Escrow, error)
+	ReleaseEscrow(ctx context.Context, escrowID int64, req model.EscrowActionRequest) (*model.Escrow, error)
+	RefundEscrow(ctx context.Context, escrowID int64, req model.EscrowActionRequest) (*model.Escrow, error)
+	DisputeEscrow(ctx context.Context, escrowID int64, req model.EscrowActionRequest) (*model.Escrow, error)
+	ResolveTimedOutEscrows(ctx context.Context) (int64, error)
+	ChangeStatus(ctx context.Context, req model.WalletStatusRequest) (*model.Wallet, error)
+	Unfreeze(ctx context.Context, tx *sql.Tx, req model.WalletStatusRequest) (*model.Wallet, error)
+	ListStatusChanges(ctx context.Context, walletID int64) ([]model.WalletStatusChange, error)
+	Adjust(ctx context.Context, tx *sql.Tx, req model.AdjustmentRequest) (*model.Transaction, error)
+	AfterTx(tx *sql.Tx, committed bool)
+	Subscribe(ctx context.Context, userID, lastEventID int64) (*event.Subscription, error)
+}
+
+const (
+	// defaultHoldExpiry applies to holds authorized without an explicit
+	// expiry.
+	defaultHoldExpiry = 7 * 24 * time.Hour
+	// defaultEscrowExpiry applies to escrows funded without an explicit
+	// expiry.
+	defaultEscrowExpiry = 14 * 24 * time.Hour
+	// escrowTimeoutBatchSize is how many timed out escrows one pass of
+	// ResolveTimedOutEscrows picks up.
+	escrowTimeoutBatchSize = 100
+)
+
+// walletStatusTransitions lists the statuses a wallet may move to from each
+// status. A frozen wallet may be frozen again to block or, once approved,
+// allow credits; closed wallets stay closed.
+var walletStatusTransitions = map[string][]string{
+	model.WalletStatusActive: {model.WalletStatusFrozen, model.WalletStatusClosed},
+	model.WalletStatusFrozen: {model.WalletStatusFrozen, model.WalletStatusActive, model.WalletStatusClosed},
 }
 
-func NewWalletService(walletRepo repository.WalletRepository) WalletService {
+func NewWalletService(walletRepo repository.WalletRepository, userRepo repository.UserRepository, riskRepo repository.RiskRepository, holdRepo repository.HoldRepository, refundRepo repository.RefundRepository, merchantRepo repository.MerchantRepository, requestRepo repository.PaymentRequestRepository, escrowRepo repository.EscrowRepository, limitService LimitService, feeService FeeService, riskEngine RiskEngine, escrowWalletID int64, bus *event.Bus, webhooks WebhookDispatcher, events EventRecorder, audit AuditLogger) WalletService {
 	return &walletService{
-		repo: walletRepo,
+		repo:           walletRepo,
+		userRepo:       userRepo,
+		riskRepo:       riskRepo,
+		holdRepo:       holdRepo,
+		refundRepo:     refundRepo,
+		merchantRepo:   merchantRepo,
+		requestRepo:    requestRepo,
+		escrowRepo:     escrowRepo,
+		limitService:   limitService,
+		feeService:     feeService,
+		riskEngine:     riskEngine,
+		escrowWalletID: escrowWalletID,
+		bus:            bus,
+		webhooks:       webhooks,
+		events:         events,
+		audit:          audit,
+		posted:         map[*sql.Tx][]postedEntry{},
 	}
 }
 
@@ -32,60 +139,2271 @@ func (s *walletService) CheckBalance(ctx context.Context, req model.CheckBalance
 		return nil, err
 	}
 
+	res.Available, err = s.available(ctx, res)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
 	return res, nil
 }
 
-func (s *walletService) TopUp(ctx context.Context, req model.TopUpRequest) error {
-	wallet, err := s.repo.ReadBalance(ctx, model.CheckBalanceRequest{
-		UserID:  req.UserID,
-		Address: req.Address,
+func (s *walletService) Pay(ctx context.Context, req model.PayRequest) error {
+	target := model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}
+
+	if err := s.assess(ctx, target, model.TransactionTypePayment, req.NominalPayment, req); err != nil {
+		return err
+	}
+
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := s.pay(ctx, tx, req)
+		return err
 	})
+}
 
+// PayPaymentRequest pays a payment request in full through Pay, so it is
+// risk scored, limited and charged a fee like any other payment.
+func (s *walletService) PayPaymentRequest(ctx context.Context, code string, payer model.CheckBalanceRequest) error {
+	request, err := s.requestRepo.ReadPaymentRequestByCode(ctx, code)
 	if err != nil {
 		logger.Log.Error(err.Error())
 		return err
 	}
 
-	err = s.repo.UpdateBalance(ctx, model.Wallet{
-		Balance: wallet.Balance + req.Nominal,
-		Address: req.Address,
-		UserID:  req.UserID,
+	if request == nil {
+		return cs.ErrNotFound
+	}
+
+	return s.Pay(ctx, model.PayRequest{
+		NominalPayment:     request.Amount,
+		PaymentRequestCode: request.Code,
+		Address:            payer.Address,
+		UserID:             payer.UserID,
+	})
+}
+
+// PayByQR pays the merchant named by a scanned QR payload through Pay. A
+// dynamic code fixes the amount; a static one takes it from the payer.
+func (s *walletService) PayByQR(ctx context.Context, req model.QRPayRequest) error {
+	payload, err := emvqr.Decode(req.Payload)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return cs.ErrInvalidQRPayload
+	}
+
+	if payload.Currency != model.CurrencyIDR {
+		return cs.ErrInvalidQRPayload
+	}
+
+	amount := payload.Amount
+	if payload.Static() {
+		amount = req.Amount
+	} else if req.Amount != 0 && req.Amount != payload.Amount {
+		return cs.ErrBadParamInput
+	}
+
+	if amount <= 0 {
+		return cs.ErrBadParamInput
+	}
+
+	return s.Pay(ctx, model.PayRequest{
+		NominalPayment: amount,
+		MerchantID:     payload.MerchantID,
+		Address:        req.Address,
+		UserID:         req.UserID,
+	})
+}
+
+// PreviewFee quotes the fee of an operation so the caller can confirm it
+// before submitting. The operation itself quotes again when it runs.
+func (s *walletService) PreviewFee(ctx context.Context, req model.FeePreviewRequest) (*model.FeeQuote, error) {
+	quote, err := s.feeService.Quote(ctx, req.Operation, req.Amount, req.MerchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// ListTransactions returns the ledger of the caller's wallet, fee entries
+// included, oldest first.
+func (s *walletService) ListTransactions(ctx context.Context, req model.CheckBalanceRequest) ([]model.Transaction, error) {
+	wallet, err := s.repo.ReadBalance(ctx, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if wallet.ID == 0 {
+		return nil, cs.ErrNotFound
+	}
+
+	transactions, err := s.repo.ReadTransactions(ctx, wallet.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (s *walletService) ListHeldTransactions(ctx context.Context, status string, page model.PageRequest) (*model.Page, error) {
+	page = page.Normalize()
+
+	helds, total, err := s.riskRepo.ReadHeldTransactionPage(ctx, status, page)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return newPage(helds, page, total), nil
+}
+
+// ApproveHeldTransaction executes a held operation exactly as it was
+// requested, skipping risk scoring but not balance and limit checks. The
+// review is recorded in the same transaction as the balance change.
+func (s *walletService) ApproveHeldTransaction(ctx context.Context, heldID, reviewerID int64) (*model.HeldTransaction, error) {
+	return s.reviewHeldTransaction(ctx, heldID, reviewerID, model.HeldTransactionApproved, func(tx *sql.Tx, held *model.HeldTransaction) error {
+		switch held.Operation {
+		case model.TransactionTypeTopUp:
+			// Nothing is credited until the user pays through the gateway,
+			// so approving a top-up only checks it again; the top-up
+			// service opens its intent once the review is committed.
+			var req model.TopUpRequest
+			if err := json.Unmarshal([]byte(held.Payload), &req); err != nil {
+				return err
+			}
+			_, err := s.checkTopUp(ctx, tx, req)
+			return err
+		case model.HeldOperationAuthorization:
+			var req model.AuthorizeRequest
+			if err := json.Unmarshal([]byte(held.Payload), &req); err != nil {
+				return err
+			}
+			_, err := s.authorize(ctx, tx, req)
+			return err
+		case model.TransactionTypeEscrowFund:
+			var req model.EscrowRequest
+			if err := json.Unmarshal([]byte(held.Payload), &req); err != nil {
+				return err
+			}
+			_, err := s.fundEscrow(ctx, tx, req)
+			return err
+		case model.TransactionTypeWithdrawal:
+			// The debit and the payout it pays out are recorded together
+			// by the payout service once the review is committed.
+			return nil
+		case model.TransactionTypePayment:
+			var req model.PayRequest
+			if err := json.Unmarshal([]byte(held.Payload), &req); err != nil {
+				return err
+			}
+			_, err := s.pay(ctx, tx, req)
+			return err
+		case model.TransactionTypeTransfer:
+			var req model.TransferRequest
+			if err := json.Unmarshal([]byte(held.Payload), &req); err != nil {
+				return err
+			}
+			_, err := s.transfer(ctx, tx, req)
+			return err
+		default:
+			return fmt.Errorf("unknown held operation %q", held.Operation)
+		}
+	})
+}
+
+func (s *walletService) RejectHeldTransaction(ctx context.Context, heldID, reviewerID int64) (*model.HeldTransaction, error) {
+	return s.reviewHeldTransaction(ctx, heldID, reviewerID, model.HeldTransactionRejected, nil)
+}
+
+func (s *walletService) reviewHeldTransaction(ctx context.Context, heldID, reviewerID int64, status string, execute func(tx *sql.Tx, held *model.HeldTransaction) error) (*model.HeldTransaction, error) {
+	held, err := s.riskRepo.ReadHeldTransactionByID(ctx, heldID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if held == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if held.Status != model.HeldTransactionPending {
+		return nil, cs.ErrHeldTransactionReviewed
+	}
+
+	now := time.Now()
+	held.Status = status
+	held.ReviewerID = &reviewerID
+	held.ReviewedAt = &now
+
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		if err := s.riskRepo.UpdateHeldTransaction(ctx, tx, *held); err == sql.ErrNoRows {
+			return cs.ErrHeldTransactionReviewed
+		} else if err != nil {
+			return err
+		}
+
+		if execute == nil {
+			return nil
+		}
+
+		return execute(tx, held)
 	})
 
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return held, nil
+}
+
+// assess scores an operation before any money moves. Denied operations fail
+// with ErrTransactionDenied; operations sent to review are stored together
+// with req and fail with a TransactionHeldError.
+func (s *walletService) assess(ctx context.Context, target model.CheckBalanceRequest, operation string, amount float64, req interface{}) error {
+	wallet, err := s.repo.ReadBalance(ctx, target)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	if wallet.ID == 0 {
+		return cs.ErrNotFound
+	}
+
+	return s.assessWallet(ctx, target.UserID, wallet.ID, operation, amount, req)
+}
+
+// assessWallet scores an operation of userID on a wallet other than their
+// personal one, like assess.
+func (s *walletService) assessWallet(ctx context.Context, userID, walletID int64, operation string, amount float64, req interface{}) error {
+	scored := operation
+	if operation == model.HeldOperationAuthorization {
+		scored = model.TransactionTypePayment
+	}
+
+	assessment, err := s.riskEngine.Assess(ctx, model.RiskInput{
+		UserID:      userID,
+		WalletID:    walletID,
+		Operation:   scored,
+		Amount:      amount,
+		BrowserName: utils.GetBrowserName(ctx),
+	})
 	if err != nil {
 		logger.Log.Error(err.Error())
 		return err
 	}
 
+	switch assessment.Decision {
+	case model.RiskDecisionDeny:
+		logger.Log.Warn(fmt.Sprintf("%s of %.0f on wallet %d denied, score %d %v", operation, amount, walletID, assessment.Score, assessment.Reasons))
+		return cs.ErrTransactionDenied
+	case model.RiskDecisionReview:
+		payload, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+
+		held := model.HeldTransaction{
+			UserID:    userID,
+			WalletID:  walletID,
+			Operation: operation,
+			Amount:    amount,
+			Payload:   string(payload),
+			Score:     assessment.Score,
+			Reasons:   assessment.Reasons,
+			Status:    model.HeldTransactionPending,
+		}
+
+		held.ID, err = s.riskRepo.WriteHeldTransaction(ctx, held)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return err
+		}
+
+		return &model.TransactionHeldError{HeldTransaction: &held}
+	}
+
 	return nil
 }
 
-func (s *walletService) Pay(ctx context.Context, req model.PayRequest) error {
+// CheckTopUp verifies that a top-up through the payment gateway would be
+// allowed on the caller's wallet and returns the wallet. It is checked
+// before the user is charged, since once the gateway confirms the payment
+// the wallet is credited regardless.
+func (s *walletService) CheckTopUp(ctx context.Context, req model.TopUpRequest) (walletID int64, err error) {
+	target := model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}
 
-	wallet, err := s.repo.ReadBalance(ctx, model.CheckBalanceRequest{
+	if err = s.assess(ctx, target, model.TransactionTypeTopUp, req.Nominal, req); err != nil {
+		return 0, err
+	}
+
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		walletID, err = s.checkTopUp(ctx, tx, req)
+		return err
+	})
+
+	return walletID, err
+}
+
+func (s *walletService) checkTopUp(ctx context.Context, tx *sql.Tx, req model.TopUpRequest) (int64, error) {
+	wallet, err := s.repo.ReadBalanceForUpdate(ctx, tx, model.CheckBalanceRequest{
 		UserID:  req.UserID,
 		Address: req.Address,
 	})
-
 	if err != nil {
 		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	if wallet.ID == 0 {
+		return 0, cs.ErrNotFound
+	}
+
+	if _, err = s.checkTopUpWallet(ctx, tx, wallet, req.Nominal); err != nil {
+		return 0, err
+	}
+
+	return wallet.ID, nil
+}
+
+// checkTopUpWallet checks a top-up of nominal into the locked wallet against
+// its status, fee, KYC level and limits, and returns the fee quote.
+func (s *walletService) checkTopUpWallet(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, nominal float64) (*model.FeeQuote, error) {
+	if err := checkWalletStatus(wallet, model.Transaction{Type: model.TransactionTypeTopUp, Amount: nominal}); err != nil {
+		return nil, err
+	}
+
+	quote, err := s.feeService.Quote(ctx, model.TransactionTypeTopUp, nominal, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.Fee > 0 && quote.Fee >= nominal {
+		return nil, cs.ErrFeeExceedsAmount
+	}
+
+	if err = s.checkKYCLimit(ctx, tx, wallet, quote.Net); err != nil {
+		return nil, err
+	}
+
+	if err = s.limitService.Check(ctx, tx, *wallet, model.TransactionTypeTopUp, nominal); err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// ResolveTopUp fills in the wallet a direct top-up credits, that of the
+// recipient or the admin's own, and checks it would be allowed now. It is
+// not risk scored, since a second admin approves it before it is made.
+func (s *walletService) ResolveTopUp(ctx context.Context, req model.TopUpRequest) (*model.TopUpRequest, error) {
+	if req.Recipient != "" {
+		recipient, err := s.userRepo.ReadByUsernameOrEmail(ctx, req.Recipient, req.Recipient)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return nil, err
+		}
+
+		if recipient == nil {
+			return nil, cs.ErrNotFound
+		}
+
+		req.UserID = recipient.ID
+		req.Address = utils.GenerateEncryptedAddress(recipient.Username, recipient.Email)
+	}
+
+	err := s.withTx(ctx, func(tx *sql.Tx) (err error) {
+		req.WalletID, err = s.checkTopUp(ctx, tx, req)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if wallet.Balance < req.NominalPayment {
-		return errors.New("your current balance is insufficient")
+	return &req, nil
+}
+
+// TopUp credits a wallet directly in tx, without a payment, and returns the
+// credit. The checks of ResolveTopUp are made again, as the wallet may have
+// changed while the top-up waited for approval. It is run once a second
+// admin approves the top-up.
+func (s *walletService) TopUp(ctx context.Context, tx *sql.Tx, req model.TopUpRequest) (*model.Transaction, error) {
+	wallet, err := s.repo.ReadByIDForUpdate(ctx, tx, req.WalletID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
 	}
 
-	err = s.repo.UpdateBalance(ctx, model.Wallet{
-		Balance: wallet.Balance - req.NominalPayment,
-		Address: req.Address,
+	quote, err := s.checkTopUpWallet(ctx, tx, wallet, req.Nominal)
+	if err != nil {
+		return nil, err
+	}
+
+	credit := &model.Transaction{
+		Type:      model.TransactionTypeTopUp,
+		Amount:    req.Nominal,
+		Reference: utils.GenerateReference(),
+	}
+
+	if err = s.post(ctx, tx, wallet, credit); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = s.chargeFee(ctx, tx, wallet, quote, credit.Reference); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return credit, nil
+}
+
+// CreditTopUp credits a top-up the payment gateway confirmed in tx, under
+// the reference of its intent, and returns the credit. The user has already
+// paid, so only the fee is applied.
+func (s *walletService) CreditTopUp(ctx context.Context, tx *sql.Tx, intent model.TopUpIntent) (*model.Transaction, error) {
+	wallet, err := s.repo.ReadByIDForUpdate(ctx, tx, intent.WalletID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	quote, err := s.feeService.Quote(ctx, model.TransactionTypeTopUp, intent.Amount, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	credit := &model.Transaction{
+		Type:      model.TransactionTypeTopUp,
+		Amount:    intent.Amount,
+		Reference: intent.Reference,
+	}
+
+	if err = s.post(ctx, tx, wallet, credit); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = s.chargeFee(ctx, tx, wallet, quote, credit.Reference); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return credit, nil
+}
+
+// pay runs a payment in tx and returns the payer's debit.
+func (s *walletService) pay(ctx context.Context, tx *sql.Tx, req model.PayRequest) (*model.Transaction, error) {
+	var (
+		merchant *model.Merchant
+		request  *model.PaymentRequest
+		err      error
+	)
+
+	if req.PaymentRequestCode != "" {
+		request, err = s.payableRequest(ctx, tx, req)
+	} else {
+		merchant, err = s.activeMerchant(ctx, req.MerchantID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.repo.ReadBalanceForUpdate(ctx, tx, model.CheckBalanceRequest{
 		UserID:  req.UserID,
+		Address: req.Address,
 	})
 
 	if err != nil {
 		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	quote, err := s.feeService.Quote(ctx, model.TransactionTypePayment, req.NominalPayment, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := s.available(ctx, wallet)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if available < -quote.Net {
+		return nil, cs.ErrInsufficientBalance
+	}
+
+	if err = s.checkKYCLimit(ctx, tx, wallet, quote.Net); err != nil {
+		return nil, err
+	}
+
+	if err = s.limitService.Check(ctx, tx, *wallet, model.TransactionTypePayment, req.NominalPayment); err != nil {
+		return nil, err
+	}
+
+	debit := &model.Transaction{
+		Type:      model.TransactionTypePayment,
+		Amount:    -req.NominalPayment,
+		Reference: utils.GenerateReference(),
+	}
+
+	if request != nil {
+		debit.Description = fmt.Sprintf("payment request %s", request.Code)
+	}
+
+	if err = s.post(ctx, tx, wallet, debit); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if request != nil {
+		err = s.creditRequester(ctx, tx, request, wallet.UserID, debit)
+	} else {
+		err = s.creditMerchant(ctx, tx, merchant, debit)
+	}
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = s.chargeFee(ctx, tx, wallet, quote, debit.Reference); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return debit, nil
+}
+
+func (s *walletService) Transfer(ctx context.Context, req model.TransferRequest) error {
+	target := model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}
+
+	if err := s.assess(ctx, target, model.TransactionTypeTransfer, req.Amount, req); err != nil {
 		return err
 	}
 
-	return nil
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		_, err := s.transfer(ctx, tx, req)
+		return err
+	})
+}
+
+// RunSchedule makes the payment or transfer due on a schedule in tx and
+// returns the payer's debit. It was authorized when the schedule was set up,
+// so it skips risk scoring but not balance, limit and fee checks.
+func (s *walletService) RunSchedule(ctx context.Context, tx *sql.Tx, schedule model.Schedule) (*model.Transaction, error) {
+	user, err := s.userRepo.ReadByID(ctx, schedule.UserID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	address := utils.GenerateEncryptedAddress(user.Username, user.Email)
+
+	if schedule.MerchantID != nil {
+		return s.pay(ctx, tx, model.PayRequest{
+			NominalPayment: schedule.Amount,
+			MerchantID:     *schedule.MerchantID,
+			Address:        address,
+			UserID:         user.ID,
+		})
+	}
+
+	recipient, err := s.userRepo.ReadByID(ctx, *schedule.RecipientID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if recipient == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	return s.transfer(ctx, tx, model.TransferRequest{
+		Amount:      schedule.Amount,
+		Recipient:   recipient.Username,
+		Description: schedule.Description,
+		Address:     address,
+		UserID:      user.ID,
+	})
+}
+
+// Withdraw debits a withdrawal to a bank account in tx and returns the
+// debit. The money leaves the wallet now; if the bank does not pay it out,
+// the debit is reverted with RevertWithdrawal.
+func (s *walletService) Withdraw(ctx context.Context, tx *sql.Tx, req model.WithdrawRequest) (*model.Transaction, error) {
+	wallet, err := s.withdrawalWallet(ctx, tx, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if wallet.ID == 0 {
+		return nil, cs.ErrNotFound
+	}
+
+	available, err := s.available(ctx, wallet)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if available < req.Amount {
+		return nil, cs.ErrInsufficientBalance
+	}
+
+	if err = s.checkKYCLimit(ctx, tx, wallet, -req.Amount); err != nil {
+		return nil, err
+	}
+
+	if err = s.limitService.Check(ctx, tx, *wallet, model.TransactionTypeWithdrawal, req.Amount); err != nil {
+		return nil, err
+	}
+
+	debit := &model.Transaction{
+		Type:      model.TransactionTypeWithdrawal,
+		Amount:    -req.Amount,
+		Reference: utils.GenerateReference(),
+	}
+
+	if err = s.post(ctx, tx, wallet, debit); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return debit, nil
+}
+
+// AssessWithdrawal scores a withdrawal before it is made, on the wallet it
+// is paid out of. A withdrawal sent to review fails with a
+// TransactionHeldError and is made by the payout service once approved.
+func (s *walletService) AssessWithdrawal(ctx context.Context, req model.WithdrawRequest) error {
+	if req.MerchantID == 0 {
+		target := model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}
+		return s.assess(ctx, target, model.TransactionTypeWithdrawal, req.Amount, req)
+	}
+
+	merchant, err := s.merchantRepo.ReadMerchantByID(ctx, req.MerchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	if merchant == nil || merchant.UserID != req.UserID {
+		return cs.ErrNotFound
+	}
+
+	return s.assessWallet(ctx, req.UserID, merchant.WalletID, model.TransactionTypeWithdrawal, req.Amount, req)
+}
+
+// withdrawalWallet locks the wallet a withdrawal is paid out of: the
+// caller's own, or the settlement wallet of a merchant they own.
+func (s *walletService) withdrawalWallet(ctx context.Context, tx *sql.Tx, req model.WithdrawRequest) (*model.Wallet, error) {
+	if req.MerchantID == 0 {
+		return s.repo.ReadBalanceForUpdate(ctx, tx, model.CheckBalanceRequest{
+			UserID:  req.UserID,
+			Address: req.Address,
+		})
+	}
+
+	merchant, err := s.merchantRepo.ReadMerchantByID(ctx, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if merchant == nil || merchant.UserID != req.UserID {
+		return nil, cs.ErrNotFound
+	}
+
+	return s.repo.ReadByIDForUpdate(ctx, tx, merchant.WalletID)
+}
+
+// Adjust posts a manual adjustment an admin made to a wallet in tx, with its
+// reason as the description of the ledger entry, and records who made it. A
+// debit may not take more than is available. It is run once a second admin
+// approves the adjustment.
+func (s *walletService) Adjust(ctx context.Context, tx *sql.Tx, req model.AdjustmentRequest) (*model.Transaction, error) {
+	entry := &model.Transaction{
+		Type:        model.TransactionTypeAdjustment,
+		Amount:      req.Amount,
+		Reference:   utils.GenerateReference(),
+		Description: req.Reason,
+	}
+
+	wallet, err := s.repo.ReadByIDForUpdate(ctx, tx, req.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount < 0 {
+		available, err := s.available(ctx, wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		if available < -req.Amount {
+			return nil, cs.ErrInsufficientBalance
+		}
+	}
+
+	if err = s.post(ctx, tx, wallet, entry); err != nil {
+		return nil, err
+	}
+
+	_, err = s.repo.WriteAdjustment(ctx, tx, model.Adjustment{
+		WalletID:      wallet.ID,
+		TransactionID: entry.ID,
+		Amount:        entry.Amount,
+		Reason:        req.Reason,
+		AdjustedBy:    req.Actor.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// RevertWithdrawal credits a failed payout back to its wallet in tx, under
+// the reference of the original debit, and returns the credit.
+func (s *walletService) RevertWithdrawal(ctx context.Context, tx *sql.Tx, payout model.Payout) (*model.Transaction, error) {
+	wallet, err := s.repo.ReadByIDForUpdate(ctx, tx, payout.WalletID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	credit := &model.Transaction{
+		Type:        model.TransactionTypeWithdrawalReversal,
+		Amount:      payout.Amount,
+		Reference:   payout.Reference,
+		Description: payout.FailureReason,
+	}
+
+	if err = s.post(ctx, tx, wallet, credit); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return credit, nil
+}
+
+// transfer runs a transfer in tx and returns the sender's debit. Both
+// wallets are locked in id order, so two users sending each other money at
+// the same time cannot deadlock.
+func (s *walletService) transfer(ctx context.Context, tx *sql.Tx, req model.TransferRequest) (*model.Transaction, error) {
+	recipient, err := s.userRepo.ReadByUsernameOrEmail(ctx, req.Recipient, req.Recipient)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if recipient == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if recipient.ID == req.UserID {
+		return nil, cs.ErrBadParamInput
+	}
+
+	payee, err := s.repo.ReadBalance(ctx, model.CheckBalanceRequest{
+		UserID:  recipient.ID,
+		Address: utils.GenerateEncryptedAddress(recipient.Username, recipient.Email),
+	})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if payee.ID == 0 {
+		return nil, cs.ErrNotFound
+	}
+
+	sender, err := s.repo.ReadBalance(ctx, model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if payee.ID < sender.ID {
+		if payee, err = s.repo.ReadByIDForUpdate(ctx, tx, payee.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	wallet, err := s.repo.ReadBalanceForUpdate(ctx, tx, model.CheckBalanceRequest{
+		UserID:  req.UserID,
+		Address: req.Address,
+	})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if payee.ID > wallet.ID {
+		if payee, err = s.repo.ReadByIDForUpdate(ctx, tx, payee.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	quote, err := s.feeService.Quote(ctx, model.TransactionTypeTransfer, req.Amount, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := s.available(ctx, wallet)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if available < -quote.Net {
+		return nil, cs.ErrInsufficientBalance
+	}
+
+	if err = s.checkKYCLimit(ctx, tx, wallet, quote.Net); err != nil {
+		return nil, err
+	}
+
+	if err = s.checkKYCLimit(ctx, tx, payee, req.Amount); err != nil {
+		return nil, err
+	}
+
+	if err = s.limitService.Check(ctx, tx, *wallet, model.TransactionTypeTransfer, req.Amount); err != nil {
+		return nil, err
+	}
+
+	debit := &model.Transaction{
+		Type:        model.TransactionTypeTransfer,
+		Amount:      -req.Amount,
+		Reference:   utils.GenerateReference(),
+		Description: req.Description,
+	}
+
+	if err = s.post(ctx, tx, wallet, debit); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	credit := &model.Transaction{
+		Type:        model.TransactionTypeTransferReceived,
+		Amount:      req.Amount,
+		Reference:   debit.Reference,
+		Description: req.Description,
+	}
+
+	if err = s.post(ctx, tx, payee, credit); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = s.chargeFee(ctx, tx, wallet, quote, debit.Reference); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return debit, nil
+}
+
+// Authorize reserves funds on the caller's wallet. The hold counts against
+// the available balance as a payment would, but no money moves until it is
+// captured. It is risk scored as a payment and checked against the wallet's
+// limits here, and checked against the limits again on capture once it is
+// in the ledger.
+func (s *walletService) Authorize(ctx context.Context, req model.AuthorizeRequest) (*model.Hold, error) {
+	target := model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}
+
+	if err := s.assess(ctx, target, model.HeldOperationAuthorization, req.Amount, req); err != nil {
+		return nil, err
+	}
+
+	var hold *model.Hold
+	err := s.withTx(ctx, func(tx *sql.Tx) (err error) {
+		hold, err = s.authorize(ctx, tx, req)
+		return err
+	})
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// authorize writes a hold in tx.
+func (s *walletService) authorize(ctx context.Context, tx *sql.Tx, req model.AuthorizeRequest) (*model.Hold, error) {
+	expiry := defaultHoldExpiry
+	if req.ExpiresInSeconds > 0 {
+		expiry = time.Duration(req.ExpiresInSeconds) * time.Second
+	}
+
+	if _, err := s.activeMerchant(ctx, req.MerchantID); err != nil {
+		return nil, err
+	}
+
+	hold := model.Hold{
+		MerchantID:  req.MerchantID,
+		Amount:      req.Amount,
+		Status:      model.HoldStatusAuthorized,
+		Reference:   utils.GenerateReference(),
+		Description: req.Description,
+		ExpiresAt:   time.Now().Add(expiry),
+	}
+
+	wallet, err := s.repo.ReadBalanceForUpdate(ctx, tx, model.CheckBalanceRequest{
+		UserID:  req.UserID,
+		Address: req.Address,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkWalletStatus(wallet, model.Transaction{Type: model.TransactionTypePayment, Amount: -req.Amount}); err != nil {
+		return nil, err
+	}
+
+	available, err := s.available(ctx, wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	if available < req.Amount {
+		return nil, cs.ErrInsufficientBalance
+	}
+
+	if err = s.checkKYCLimit(ctx, tx, wallet, -req.Amount); err != nil {
+		return nil, err
+	}
+
+	if err = s.limitService.Check(ctx, tx, *wallet, model.TransactionTypePayment, req.Amount); err != nil {
+		return nil, err
+	}
+
+	hold.WalletID = wallet.ID
+	if hold.ID, err = s.holdRepo.WriteHold(ctx, tx, hold); err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// Capture settles a hold with a payment of req.Amount to the hold's merchant,
+// or of the full hold when no amount is given. Whatever is not captured is
+// released. The payment fee is charged on capture, out of the hold and
+// whatever else is available, and the payment is checked against the
+// wallet's limits again as it is posted.
+func (s *walletService) Capture(ctx context.Context, holdID int64, req model.CaptureRequest) (*model.Hold, error) {
+	return s.settleHold(ctx, holdID, req.UserID, func(tx *sql.Tx, wallet *model.Wallet, merchant *model.Merchant, hold *model.Hold) error {
+		if merchant.Status != model.MerchantStatusActive {
+			return cs.ErrMerchantNotActive
+		}
+
+		amount := req.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+
+		if amount > hold.Amount {
+			return cs.ErrCaptureExceedsHold
+		}
+
+		quote, err := s.feeService.Quote(ctx, model.TransactionTypePayment, amount, merchant.ID)
+		if err != nil {
+			return err
+		}
+
+		available, err := s.available(ctx, wallet)
+		if err != nil {
+			return err
+		}
+
+		if available+hold.Amount < -quote.Net {
+			return cs.ErrInsufficientBalance
+		}
+
+		// Open holds are not in the ledger, so other captures since this
+		// hold was authorized may have used up the wallet's limits.
+		if err = s.checkKYCLimit(ctx, tx, wallet, -amount); err != nil {
+			return err
+		}
+
+		if err = s.limitService.Check(ctx, tx, *wallet, model.TransactionTypePayment, amount); err != nil {
+			return err
+		}
+
+		hold.CapturedAmount = amount
+		hold.Status = model.HoldStatusCaptured
+
+		debit := &model.Transaction{
+			Type:        model.TransactionTypePayment,
+			Amount:      -amount,
+			Reference:   hold.Reference,
+			Description: fmt.Sprintf("capture of hold #%d", hold.ID),
+		}
+
+		if err = s.post(ctx, tx, wallet, debit); err != nil {
+			return err
+		}
+
+		if err = s.creditMerchant(ctx, tx, merchant, debit); err != nil {
+			return err
+		}
+
+		return s.chargeFee(ctx, tx, wallet, quote, hold.Reference)
+	})
+}
+
+func (s *walletService) Void(ctx context.Context, holdID int64, req model.VoidRequest) (*model.Hold, error) {
+	return s.settleHold(ctx, holdID, req.UserID, func(tx *sql.Tx, wallet *model.Wallet, merchant *model.Merchant, hold *model.Hold) error {
+		hold.Status = model.HoldStatusVoided
+		return nil
+	})
+}
+
+func (s *walletService) ListHolds(ctx context.Context, req model.CheckBalanceRequest) ([]model.Hold, error) {
+	wallet, err := s.repo.ReadBalance(ctx, req)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	holds, err := s.holdRepo.ReadHoldsByWallet(ctx, wallet.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+// ExpireHolds marks the holds past their expiry as expired. Expired holds
+// already stop reserving funds on their own; this keeps their status honest.
+func (s *walletService) ExpireHolds(ctx context.Context) (int64, error) {
+	expired, err := s.holdRepo.ExpireHolds(ctx, time.Now())
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	return expired, nil
+}
+
+// Refund credits the payer of a payment with a reversing ledger entry and
+// debits the wallet that received the payment, a merchant's settlement
+// wallet or the requester of a payment request, by the same amount. The
+// payer's wallet is locked while the refunded total is checked, so
+// concurrent refunds can never exceed the original payment. Fees charged on
+// the payment are kept.
+func (s *walletService) Refund(ctx context.Context, req model.RefundRequest) (*model.Refund, error) {
+	original, payeeWalletID, err := s.refundable(ctx, req.TransactionID, req.Requester)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := model.Refund{
+		TransactionID: original.ID,
+		Reason:        req.Reason,
+		CreatedBy:     req.Requester.ID,
+	}
+
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		// Both wallets are locked in id order, as payments lock them, so a
+		// refund cannot deadlock with a payment between the same wallets.
+		if payeeWalletID < original.WalletID {
+			if _, err := s.repo.ReadByIDForUpdate(ctx, tx, payeeWalletID); err != nil {
+				return err
+			}
+		}
+
+		wallet, err := s.repo.ReadByIDForUpdate(ctx, tx, original.WalletID)
+		if err != nil {
+			return err
+		}
+
+		refunded, err := s.refundRepo.SumRefunds(ctx, tx, original.ID)
+		if err != nil {
+			return err
+		}
+
+		remaining := -original.Amount - refunded
+
+		refund.Amount = req.Amount
+		if refund.Amount == 0 {
+			refund.Amount = remaining
+		}
+
+		if remaining <= 0 || refund.Amount > remaining {
+			return cs.ErrRefundExceedsPayment
+		}
+
+		description := fmt.Sprintf("refund of transaction #%d: %s", original.ID, req.Reason)
+
+		if err = s.debitPayee(ctx, tx, payeeWalletID, refund.Amount, original.Reference, description); err != nil {
+			return err
+		}
+
+		entry := &model.Transaction{
+			Type:        model.TransactionTypeRefund,
+			Amount:      refund.Amount,
+			Reference:   original.Reference,
+			Description: description,
+		}
+
+		if err = s.post(ctx, tx, wallet, entry); err != nil {
+			return err
+		}
+
+		refund.RefundTransactionID = entry.ID
+		refund.ID, err = s.refundRepo.WriteRefund(ctx, tx, refund)
+
+		return err
+	})
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+func (s *walletService) ListRefunds(ctx context.Context, trxID int64, requester model.User) ([]model.Refund, error) {
+	if _, _, err := s.refundable(ctx, trxID, requester); err != nil {
+		return nil, err
+	}
+
+	refunds, err := s.refundRepo.ReadRefundsByTransaction(ctx, trxID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// refundable returns the payment a requester may refund, together with the
+// wallet that received it. Staff can refund any payment whose payee is
+// recorded; a merchant's owner only the payments made to that merchant.
+func (s *walletService) refundable(ctx context.Context, trxID int64, requester model.User) (*model.Transaction, int64, error) {
+	original, err := s.repo.ReadTransactionByID(ctx, trxID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, 0, err
+	}
+
+	if original == nil {
+		return nil, 0, cs.ErrNotFound
+	}
+
+	if original.Type != model.TransactionTypePayment {
+		return nil, 0, cs.ErrNotRefundable
+	}
+
+	payment, err := s.merchantRepo.ReadMerchantPaymentByTransaction(ctx, original.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, 0, err
+	}
+
+	if payment == nil {
+		if !requester.IsStaff() {
+			return nil, 0, cs.ErrForbidden
+		}
+
+		return s.refundableRequest(ctx, original)
+	}
+
+	merchant, err := s.merchantRepo.ReadMerchantByID(ctx, payment.MerchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, 0, err
+	}
+
+	if merchant == nil {
+		return nil, 0, cs.ErrNotFound
+	}
+
+	if !requester.IsStaff() && merchant.UserID != requester.ID {
+		return nil, 0, cs.ErrForbidden
+	}
+
+	return original, merchant.WalletID, nil
+}
+
+// refundableRequest returns a payment that paid a payment request, including
+// a share of a split bill, with the requester's wallet. Payments whose payee
+// is not recorded cannot be refunded, as nothing could be taken back for
+// them.
+func (s *walletService) refundableRequest(ctx context.Context, original *model.Transaction) (*model.Transaction, int64, error) {
+	request, err := s.requestRepo.ReadPaymentRequestByTransaction(ctx, original.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, 0, err
+	}
+
+	if request == nil {
+		return nil, 0, cs.ErrNotRefundable
+	}
+
+	return original, request.WalletID, nil
+}
+
+// debitPayee takes a refund back from the wallet that received the payment.
+// It must run after the payer's wallet is locked, and after the payee's too
+// when it sorts first.
+func (s *walletService) debitPayee(ctx context.Context, tx *sql.Tx, walletID int64, amount float64, reference, description string) error {
+	payee, err := s.repo.ReadByIDForUpdate(ctx, tx, walletID)
+	if err != nil {
+		return err
+	}
+
+	if payee.Balance < amount {
+		return cs.ErrInsufficientBalance
+	}
+
+	return s.post(ctx, tx, payee, &model.Transaction{
+		Type:        model.TransactionTypeRefund,
+		Amount:      -amount,
+		Reference:   reference,
+		Description: description,
+	})
+}
+
+// FundEscrow moves a payment from the caller's wallet into the escrow
+// wallet, where it stays until it is released to the merchant or refunded.
+// It is risk scored, checked and charged a fee like a payment to the
+// merchant would be.
+func (s *walletService) FundEscrow(ctx context.Context, req model.EscrowRequest) (*model.Escrow, error) {
+	if s.escrowWalletID == 0 {
+		return nil, cs.ErrEscrowWalletNotSet
+	}
+
+	target := model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}
+
+	if err := s.assess(ctx, target, model.TransactionTypeEscrowFund, req.Amount, req); err != nil {
+		return nil, err
+	}
+
+	var escrow *model.Escrow
+	err := s.withTx(ctx, func(tx *sql.Tx) (err error) {
+		escrow, err = s.fundEscrow(ctx, tx, req)
+		return err
+	})
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return escrow, nil
+}
+
+// fundEscrow funds an escrow in tx.
+func (s *walletService) fundEscrow(ctx context.Context, tx *sql.Tx, req model.EscrowRequest) (*model.Escrow, error) {
+	if s.escrowWalletID == 0 {
+		return nil, cs.ErrEscrowWalletNotSet
+	}
+
+	merchant, err := s.activeMerchant(ctx, req.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := defaultEscrowExpiry
+	if req.ExpiresInSeconds > 0 {
+		expiry = time.Duration(req.ExpiresInSeconds) * time.Second
+	}
+
+	escrow := model.Escrow{
+		BuyerID:        req.UserID,
+		MerchantID:     merchant.ID,
+		EscrowWalletID: s.escrowWalletID,
+		Amount:         req.Amount,
+		Status:         model.EscrowStatusFunded,
+		TimeoutAction:  req.TimeoutAction,
+		Reference:      utils.GenerateReference(),
+		Description:    req.Description,
+		ExpiresAt:      time.Now().Add(expiry),
+	}
+
+	if escrow.TimeoutAction == "" {
+		escrow.TimeoutAction = model.EscrowActionRelease
+	}
+
+	wallet, err := s.repo.ReadBalanceForUpdate(ctx, tx, model.CheckBalanceRequest{
+		UserID:  req.UserID,
+		Address: req.Address,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.feeService.Quote(ctx, model.TransactionTypePayment, req.Amount, merchant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	available, err := s.available(ctx, wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	if available < -quote.Net {
+		return nil, cs.ErrInsufficientBalance
+	}
+
+	if err = s.checkKYCLimit(ctx, tx, wallet, quote.Net); err != nil {
+		return nil, err
+	}
+
+	if err = s.limitService.Check(ctx, tx, *wallet, model.TransactionTypePayment, req.Amount); err != nil {
+		return nil, err
+	}
+
+	debit := &model.Transaction{
+		Type:        model.TransactionTypeEscrowFund,
+		Amount:      -req.Amount,
+		Reference:   escrow.Reference,
+		Description: fmt.Sprintf("escrow for merchant #%d", merchant.ID),
+	}
+
+	if err = s.post(ctx, tx, wallet, debit); err != nil {
+		return nil, err
+	}
+
+	escrowWallet, err := s.repo.ReadByIDForUpdate(ctx, tx, s.escrowWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A wallet with an owner is a customer's, never the escrow wallet.
+	if escrowWallet.UserID != 0 {
+		return nil, cs.ErrEscrowWalletNotSet
+	}
+
+	err = s.post(ctx, tx, escrowWallet, &model.Transaction{
+		Type:        model.TransactionTypeEscrowFund,
+		Amount:      req.Amount,
+		Reference:   escrow.Reference,
+		Description: fmt.Sprintf("escrow from wallet #%d", wallet.ID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.chargeFee(ctx, tx, wallet, quote, escrow.Reference); err != nil {
+		return nil, err
+	}
+
+	escrow.BuyerWalletID = wallet.ID
+	if escrow.ID, err = s.escrowRepo.WriteEscrow(ctx, tx, escrow); err != nil {
+		return nil, err
+	}
+
+	event := model.EscrowEvent{
+		EscrowID:      escrow.ID,
+		ToStatus:      model.EscrowStatusFunded,
+		ActorID:       &req.UserID,
+		TransactionID: &debit.ID,
+	}
+
+	event.ID, err = s.escrowRepo.WriteEscrowEvent(ctx, tx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	escrow.Events = []model.EscrowEvent{event}
+
+	return &escrow, nil
+}
+
+// GetEscrow returns an escrow with its history to its buyer, the owner of
+// its merchant and staff.
+func (s *walletService) GetEscrow(ctx context.Context, escrowID int64, viewer model.User) (*model.Escrow, error) {
+	escrow, merchant, err := s.readEscrow(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if escrow.BuyerID != viewer.ID && merchant.UserID != viewer.ID && !viewer.IsStaff() {
+		return nil, cs.ErrNotFound
+	}
+
+	escrow.Events, err = s.escrowRepo.ReadEscrowEvents(ctx, escrow.ID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return escrow, nil
+}
+
+// ListEscrows returns the escrows a user funded or is the seller in.
+func (s *walletService) ListEscrows(ctx context.Context, userID int64) ([]model.Escrow, error) {
+	escrows, err := s.escrowRepo.ReadEscrowsByUser(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return escrows, nil
+}
+
+// ReleaseEscrow pays a funded escrow out to its merchant. The buyer releases
+// it once satisfied; a disputed escrow can only be released by staff.
+func (s *walletService) ReleaseEscrow(ctx context.Context, escrowID int64, req model.EscrowActionRequest) (*model.Escrow, error) {
+	return s.resolveEscrow(ctx, escrowID, &req.Requester.ID, req.Note, func(escrow *model.Escrow, merchant *model.Merchant) (string, error) {
+		if err := escrowActor(escrow, merchant, req.Requester, escrow.BuyerID); err != nil {
+			return "", err
+		}
+
+		if merchant.Status != model.MerchantStatusActive {
+			return "", cs.ErrMerchantNotActive
+		}
+
+		return model.EscrowActionRelease, nil
+	})
+}
+
+// RefundEscrow returns a funded escrow to its buyer. The merchant's owner
+// refunds it when the sale falls through; a disputed escrow can only be
+// refunded by staff.
+func (s *walletService) RefundEscrow(ctx context.Context, escrowID int64, req model.EscrowActionRequest) (*model.Escrow, error) {
+	return s.resolveEscrow(ctx, escrowID, &req.Requester.ID, req.Note, func(escrow *model.Escrow, merchant *model.Merchant) (string, error) {
+		if err := escrowActor(escrow, merchant, req.Requester, merchant.UserID); err != nil {
+			return "", err
+		}
+
+		return model.EscrowActionRefund, nil
+	})
+}
+
+// DisputeEscrow stops a funded escrow from timing out and leaves it to staff
+// to resolve. Either the buyer or the merchant's owner can dispute it.
+func (s *walletService) DisputeEscrow(ctx context.Context, escrowID int64, req model.EscrowActionRequest) (*model.Escrow, error) {
+	escrow, merchant, err := s.readEscrow(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+
+	if escrow.BuyerID != req.Requester.ID && merchant.UserID != req.Requester.ID {
+		return nil, cs.ErrNotFound
+	}
+
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		escrow, err = s.escrowRepo.ReadEscrowForUpdate(ctx, tx, escrowID)
+		if err != nil {
+			return err
+		}
+
+		if escrow.Status != model.EscrowStatusFunded {
+			return cs.ErrEscrowNotFunded
+		}
+
+		return s.transitionEscrow(ctx, tx, escrow, model.EscrowStatusDisputed, &req.Requester.ID, nil, req.Note)
+	})
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return escrow, nil
+}
+
+// ResolveTimedOutEscrows applies the timeout action of the funded escrows
+// past their expiry and returns how many were resolved. An escrow that
+// cannot be resolved, e.g. because its merchant was suspended, is left
+// funded and tried again on the next pass.
+func (s *walletService) ResolveTimedOutEscrows(ctx context.Context) (int64, error) {
+	ids, err := s.escrowRepo.ReadTimedOutEscrowIDs(ctx, time.Now(), escrowTimeoutBatchSize)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	var resolved int64
+	for _, id := range ids {
+		_, err := s.resolveEscrow(ctx, id, nil, "timed out", func(escrow *model.Escrow, merchant *model.Merchant) (string, error) {
+			// Resolved or disputed since it was picked up.
+			if escrow.Status != model.EscrowStatusFunded || escrow.ExpiresAt.After(time.Now()) {
+				return "", cs.ErrEscrowNotFunded
+			}
+
+			if escrow.TimeoutAction == model.EscrowActionRelease && merchant.Status != model.MerchantStatusActive {
+				return "", cs.ErrMerchantNotActive
+			}
+
+			return escrow.TimeoutAction, nil
+		})
+
+		if err == cs.ErrEscrowNotFunded {
+			continue
+		} else if err != nil {
+			logger.Log.Error(fmt.Sprintf("resolving escrow %d: %s", id, err.Error()))
+			continue
+		}
+
+		resolved++
+	}
+
+	return resolved, nil
+}
+
+// ChangeStatus freezes or closes a wallet, recording who did it and why. A
+// wallet is only closed once its balance is zero and nothing is held on it.
+// Changes lifting restrictions on a frozen wallet, unfreezing it or letting
+// credits into it again, need a second admin's approval and go through
+// Unfreeze; they are refused here with ErrApprovalRequired.
+func (s *walletService) ChangeStatus(ctx context.Context, req model.WalletStatusRequest) (*model.Wallet, error) {
+	var wallet *model.Wallet
+
+	err := s.withTx(ctx, func(tx *sql.Tx) (err error) {
+		wallet, err = s.changeStatus(ctx, tx, req, false)
+		return err
+	})
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// Unfreeze lifts restrictions on a frozen wallet in tx: it makes the wallet
+// active again or, when Status is frozen, lets credits into it while it
+// stays frozen. It is run once a second admin approves the change.
+func (s *walletService) Unfreeze(ctx context.Context, tx *sql.Tx, req model.WalletStatusRequest) (*model.Wallet, error) {
+	if req.Status != model.WalletStatusFrozen {
+		req.Status = model.WalletStatusActive
+	}
+
+	return s.changeStatus(ctx, tx, req, true)
+}
+
+// changeStatus moves a wallet to the status req asks for in tx. Unless the
+// change was approved, it refuses any that lifts restrictions on the wallet.
+func (s *walletService) changeStatus(ctx context.Context, tx *sql.Tx, req model.WalletStatusRequest, approved bool) (*model.Wallet, error) {
+	wallet, err := s.repo.ReadByIDForUpdate(ctx, tx, req.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wallets without an owner, such as the revenue wallet, keep the
+	// books of every other one.
+	if wallet.UserID == 0 {
+		return nil, cs.ErrForbidden
+	}
+
+	change := model.WalletStatusChange{
+		WalletID:     wallet.ID,
+		FromStatus:   wallet.Status,
+		ToStatus:     req.Status,
+		BlockCredits: req.Status == model.WalletStatusFrozen && req.BlockCredits,
+		Reason:       req.Reason,
+		Note:         req.Note,
+		ChangedBy:    req.Actor.ID,
+	}
+
+	if !canChangeWalletStatus(change) || (change.FromStatus == change.ToStatus && wallet.BlockCredits == change.BlockCredits) {
+		return nil, cs.ErrWalletStatusTransition
+	}
+
+	if !approved && liftsRestrictions(wallet, change) {
+		return nil, cs.ErrApprovalRequired
+	}
+
+	if change.ToStatus == model.WalletStatusClosed {
+		available, err := s.available(ctx, wallet)
+		if err != nil {
+			return nil, err
+		}
+
+		if wallet.Balance != 0 || available != 0 {
+			return nil, cs.ErrWalletNotEmpty
+		}
+	}
+
+	if err = s.repo.UpdateStatus(ctx, tx, *wallet, change); err != nil {
+		return nil, err
+	}
+
+	err = s.audit.Log(ctx, tx, model.AuditEntry{
+		Action:     model.AuditWalletStatusChanged,
+		ActorID:    req.Actor.ID,
+		TargetType: model.AuditTargetWallet,
+		TargetID:   wallet.ID,
+		Before:     auditValue(map[string]interface{}{"status": change.FromStatus, "block_credits": wallet.BlockCredits}),
+		After: auditValue(map[string]interface{}{
+			"status":        change.ToStatus,
+			"block_credits": change.BlockCredits,
+			"reason":        change.Reason,
+			"note":          change.Note,
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.Status = change.ToStatus
+	wallet.BlockCredits = change.BlockCredits
+
+	return wallet, nil
+}
+
+// ListStatusChanges returns the status history of a wallet, oldest first.
+func (s *walletService) ListStatusChanges(ctx context.Context, walletID int64) ([]model.WalletStatusChange, error) {
+	changes, err := s.repo.ReadStatusChanges(ctx, walletID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func canChangeWalletStatus(change model.WalletStatusChange) bool {
+	for _, status := range walletStatusTransitions[change.FromStatus] {
+		if status == change.ToStatus {
+			return true
+		}
+	}
+
+	return false
+}
+
+// liftsRestrictions reports whether change lets more money move in or out
+// of a frozen wallet: unfreezing it, or letting credits into it while it
+// stays frozen. Such changes need a second admin's approval.
+func liftsRestrictions(wallet *model.Wallet, change model.WalletStatusChange) bool {
+	if change.FromStatus != model.WalletStatusFrozen {
+		return false
+	}
+
+	return change.ToStatus == model.WalletStatusActive || (wallet.BlockCredits && !change.BlockCredits)
+}
+
+// checkWalletStatus fails if the status of a wallet does not let entry be
+// posted to it. Withdrawal reversals still reach a frozen wallet, as they
+// only return money that left it before.
+func checkWalletStatus(wallet *model.Wallet, entry model.Transaction) error {
+	switch wallet.Status {
+	case model.WalletStatusClosed:
+		return cs.ErrWalletClosed
+	case model.WalletStatusFrozen:
+		if entry.Amount < 0 || (wallet.BlockCredits && entry.Type != model.TransactionTypeWithdrawalReversal) {
+			return cs.ErrWalletFrozen
+		}
+	}
+
+	return nil
+}
+
+// resolveEscrow locks the buyer's wallet, the escrow and the escrow wallet,
+// the buyer's wallet first as FundEscrow does, and moves the escrowed funds
+// out: to the merchant's settlement wallet on release, back to the buyer on
+// refund. decide picks the action for the escrow as locked, or fails when
+// the caller may not resolve it.
+func (s *walletService) resolveEscrow(ctx context.Context, escrowID int64, actorID *int64, note string, decide func(escrow *model.Escrow, merchant *model.Merchant) (string, error)) (*model.Escrow, error) {
+	escrow, merchant, err := s.readEscrow(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		buyer, err := s.repo.ReadByIDForUpdate(ctx, tx, escrow.BuyerWalletID)
+		if err != nil {
+			return err
+		}
+
+		escrow, err = s.escrowRepo.ReadEscrowForUpdate(ctx, tx, escrowID)
+		if err != nil {
+			return err
+		}
+
+		action, err := decide(escrow, merchant)
+		if err != nil {
+			return err
+		}
+
+		escrowWallet, err := s.repo.ReadByIDForUpdate(ctx, tx, escrow.EscrowWalletID)
+		if err != nil {
+			return err
+		}
+
+		var (
+			entryType = model.TransactionTypeEscrowRefund
+			payee     = buyer
+			status    = model.EscrowStatusRefunded
+		)
+
+		if action == model.EscrowActionRelease {
+			entryType = model.TransactionTypeEscrowRelease
+			status = model.EscrowStatusReleased
+
+			if payee, err = s.repo.ReadByIDForUpdate(ctx, tx, merchant.WalletID); err != nil {
+				return err
+			}
+		}
+
+		description := fmt.Sprintf("%s of escrow #%d", action, escrow.ID)
+
+		err = s.post(ctx, tx, escrowWallet, &model.Transaction{
+			Type:        entryType,
+			Amount:      -escrow.Amount,
+			Reference:   escrow.Reference,
+			Description: description,
+		})
+		if err != nil {
+			return err
+		}
+
+		credit := &model.Transaction{
+			Type:        entryType,
+			Amount:      escrow.Amount,
+			Reference:   escrow.Reference,
+			Description: description,
+		}
+
+		if err = s.post(ctx, tx, payee, credit); err != nil {
+			return err
+		}
+
+		return s.transitionEscrow(ctx, tx, escrow, status, actorID, &credit.ID, note)
+	})
+
+	if err != nil {
+		if err != cs.ErrEscrowNotFunded {
+			logger.Log.Error(err.Error())
+		}
+		return nil, err
+	}
+
+	return escrow, nil
+}
+
+// readEscrow returns an escrow together with its merchant.
+func (s *walletService) readEscrow(ctx context.Context, escrowID int64) (*model.Escrow, *model.Merchant, error) {
+	escrow, err := s.escrowRepo.ReadEscrowByID(ctx, escrowID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, nil, err
+	}
+
+	if escrow == nil {
+		return nil, nil, cs.ErrNotFound
+	}
+
+	merchant, err := s.merchantRepo.ReadMerchantByID(ctx, escrow.MerchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, nil, err
+	}
+
+	if merchant == nil {
+		return nil, nil, cs.ErrNotFound
+	}
+
+	return escrow, merchant, nil
+}
+
+// transitionEscrow moves an escrow locked in tx to status and records the
+// transition, with the ledger entry that moved its funds if any did.
+func (s *walletService) transitionEscrow(ctx context.Context, tx *sql.Tx, escrow *model.Escrow, status string, actorID, transactionID *int64, note string) (err error) {
+	event := model.EscrowEvent{
+		EscrowID:      escrow.ID,
+		FromStatus:    escrow.Status,
+		ToStatus:      status,
+		ActorID:       actorID,
+		TransactionID: transactionID,
+		Note:          note,
+	}
+
+	escrow.Status = status
+	if escrow.Resolved() {
+		now := time.Now()
+		escrow.ResolvedAt = &now
+	}
+
+	if err = s.escrowRepo.UpdateEscrowStatus(ctx, tx, *escrow); err != nil {
+		return err
+	}
+
+	event.ID, err = s.escrowRepo.WriteEscrowEvent(ctx, tx, event)
+	escrow.Events = append(escrow.Events, event)
+
+	return err
+}
+
+// escrowActor checks that requester may resolve an escrow as the party
+// entitled to, or as staff. Only staff resolve disputed escrows.
+func escrowActor(escrow *model.Escrow, merchant *model.Merchant, requester model.User, entitledID int64) error {
+	switch {
+	case requester.ID != escrow.BuyerID && requester.ID != merchant.UserID && !requester.IsStaff():
+		return cs.ErrNotFound
+	case escrow.Status != model.EscrowStatusFunded && escrow.Status != model.EscrowStatusDisputed:
+		return cs.ErrEscrowNotFunded
+	case requester.IsStaff():
+		return nil
+	case escrow.Status == model.EscrowStatusDisputed || requester.ID != entitledID:
+		return cs.ErrForbidden
+	}
+
+	return nil
+}
+
+// settleHold locks the payer's wallet and then the hold, the same order
+// Authorize takes them in, and lets settle move the hold out of the
+// authorized state. Both the payer and the merchant's owner may settle it.
+func (s *walletService) settleHold(ctx context.Context, holdID, requesterID int64, settle func(tx *sql.Tx, wallet *model.Wallet, merchant *model.Merchant, hold *model.Hold) error) (*model.Hold, error) {
+	hold, err := s.holdRepo.ReadHoldByID(ctx, holdID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if hold == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	merchant, err := s.merchantRepo.ReadMerchantByID(ctx, hold.MerchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if merchant == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	err = s.withTx(ctx, func(tx *sql.Tx) error {
+		wallet, err := s.repo.ReadByIDForUpdate(ctx, tx, hold.WalletID)
+		if err != nil {
+			return err
+		}
+
+		if wallet.UserID != requesterID && merchant.UserID != requesterID {
+			return cs.ErrNotFound
+		}
+
+		hold, err = s.holdRepo.ReadHoldForUpdate(ctx, tx, holdID)
+		if err != nil {
+			return err
+		}
+
+		if !hold.Active(time.Now()) {
+			return cs.ErrHoldNotActive
+		}
+
+		if err = settle(tx, wallet, merchant, hold); err != nil {
+			return err
+		}
+
+		return s.holdRepo.UpdateHold(ctx, tx, *hold)
+	})
+
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// activeMerchant returns the merchant a payment is made to, failing unless
+// it can receive payments.
+func (s *walletService) activeMerchant(ctx context.Context, merchantID int64) (*model.Merchant, error) {
+	merchant, err := s.merchantRepo.ReadMerchantByID(ctx, merchantID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if merchant == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	if merchant.Status != model.MerchantStatusActive {
+		return nil, cs.ErrMerchantNotActive
+	}
+
+	return merchant, nil
+}
+
+// creditMerchant posts the other leg of a payment debit to the merchant's
+// settlement wallet and records the payment for the merchant. The payer's
+// wallet must already be locked in tx, so wallets are always locked payer
+// first.
+func (s *walletService) creditMerchant(ctx context.Context, tx *sql.Tx, merchant *model.Merchant, debit *model.Transaction) error {
+	settlement, err := s.repo.ReadByIDForUpdate(ctx, tx, merchant.WalletID)
+	if err != nil {
+		return err
+	}
+
+	credit := &model.Transaction{
+		Type:        model.TransactionTypePaymentReceived,
+		Amount:      -debit.Amount,
+		Reference:   debit.Reference,
+		Description: fmt.Sprintf("payment from wallet #%d", debit.WalletID),
+	}
+
+	if err = s.post(ctx, tx, settlement, credit); err != nil {
+		return err
+	}
+
+	_, err = s.merchantRepo.WriteMerchantPayment(ctx, tx, model.MerchantPayment{
+		MerchantID:          merchant.ID,
+		TransactionID:       debit.ID,
+		CreditTransactionID: credit.ID,
+		Amount:              credit.Amount,
+		Reference:           debit.Reference,
+	})
+
+	return err
+}
+
+// chargeFee posts a quoted fee as its own pair of ledger entries under the
+// operation's reference: a debit on the caller's wallet, locked in tx, and a
+// credit on the revenue wallet. The revenue wallet is always locked last.
+func (s *walletService) chargeFee(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, quote *model.FeeQuote, reference string) error {
+	if quote.Fee == 0 {
+		return nil
+	}
+
+	if quote.RevenueWalletID == 0 {
+		return cs.ErrRevenueWalletNotSet
+	}
+
+	err := s.post(ctx, tx, wallet, &model.Transaction{
+		Type:        model.TransactionTypeFee,
+		Amount:      -quote.Fee,
+		Reference:   reference,
+		Description: fmt.Sprintf("%s fee", quote.Operation),
+	})
+	if err != nil {
+		return err
+	}
+
+	revenue, err := s.repo.ReadByIDForUpdate(ctx, tx, quote.RevenueWalletID)
+	if err != nil {
+		return err
+	}
+
+	// A wallet with an owner is a customer's, never the revenue wallet.
+	if revenue.UserID != 0 {
+		return cs.ErrRevenueWalletNotSet
+	}
+
+	return s.post(ctx, tx, revenue, &model.Transaction{
+		Type:        model.TransactionTypeFeeRevenue,
+		Amount:      quote.Fee,
+		Reference:   reference,
+		Description: fmt.Sprintf("%s fee from wallet #%d", quote.Operation, wallet.ID),
+	})
+}
+
+// payableRequest locks the payment request a payment settles and checks the
+// payer may pay it. Two users can pay each other's requests at the same
+// time, so when the requester's wallet sorts before the payer's it is locked
+// here, ahead of the payer's, to keep wallets locked in id order.
+func (s *walletService) payableRequest(ctx context.Context, tx *sql.Tx, req model.PayRequest) (*model.PaymentRequest, error) {
+	request, err := s.requestRepo.ReadPaymentRequestForUpdate(ctx, tx, req.PaymentRequestCode)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if request == nil || (request.PayerID != nil && *request.PayerID != req.UserID) {
+		return nil, cs.ErrNotFound
+	}
+
+	if request.RequesterID == req.UserID {
+		return nil, cs.ErrForbidden
+	}
+
+	if request.Status != model.PaymentRequestPending || request.Expired(time.Now()) {
+		return nil, cs.ErrPaymentRequestNotActive
+	}
+
+	if req.NominalPayment != request.Amount {
+		return nil, cs.ErrBadParamInput
+	}
+
+	payer, err := s.repo.ReadBalance(ctx, model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address})
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if request.WalletID < payer.ID {
+		if _, err = s.repo.ReadByIDForUpdate(ctx, tx, request.WalletID); err != nil {
+			return nil, err
+		}
+	}
+
+	return request, nil
+}
+
+// creditRequester posts the other leg of a payment debit to the requester's
+// wallet, within the requester's KYC limits, and marks the request paid by
+// payerID.
+func (s *walletService) creditRequester(ctx context.Context, tx *sql.Tx, request *model.PaymentRequest, payerID int64, debit *model.Transaction) error {
+	payee, err := s.repo.ReadByIDForUpdate(ctx, tx, request.WalletID)
+	if err != nil {
+		return err
+	}
+
+	credit := &model.Transaction{
+		Type:        model.TransactionTypePaymentReceived,
+		Amount:      -debit.Amount,
+		Reference:   debit.Reference,
+		Description: fmt.Sprintf("payment request %s from wallet #%d", request.Code, debit.WalletID),
+	}
+
+	if err = s.checkKYCLimit(ctx, tx, payee, credit.Amount); err != nil {
+		return err
+	}
+
+	if err = s.post(ctx, tx, payee, credit); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	request.Status = model.PaymentRequestPaid
+	request.PayerID = &payerID
+	request.TransactionID = &debit.ID
+	request.PaidAt = &now
+
+	if err = s.requestRepo.MarkPaymentRequestPaid(ctx, tx, *request); err == sql.ErrNoRows {
+		return cs.ErrPaymentRequestNotActive
+	}
+
+	return err
+}
+
+// available returns the part of a wallet's balance that is not reserved by
+// active holds.
+func (s *walletService) available(ctx context.Context, wallet *model.Wallet) (float64, error) {
+	held, err := s.holdRepo.SumActiveHolds(ctx, wallet.ID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return wallet.Balance - held, nil
+}
+
+// withTx runs fn in a database transaction, committing when it succeeds and
+// rolling back otherwise.
+func (s *walletService) withTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx := s.repo.BeginTx(ctx)
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+		s.AfterTx(tx, err == nil)
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AfterTx publishes the balance changes posted in tx once it is committed,
+// or drops them if it was rolled back. Services that run the wallet's
+// operations in their own transaction call it when the transaction ends.
+func (s *walletService) AfterTx(tx *sql.Tx, committed bool) {
+	s.postedMu.Lock()
+	entries := s.posted[tx]
+	delete(s.posted, tx)
+	s.postedMu.Unlock()
+
+	if !committed {
+		return
+	}
+
+	for _, p := range entries {
+		s.bus.Publish(p.userID, event.TypeTransaction, p.entry)
+		s.bus.Publish(p.userID, event.TypeBalance, model.BalanceChange{
+			WalletID:      p.entry.WalletID,
+			Address:       p.address,
+			Balance:       p.entry.BalanceAfter,
+			TransactionID: p.entry.ID,
+		})
+	}
+}
+
+// Subscribe streams the balance changes and new ledger entries of the
+// user's wallets, replaying those published after lastEventID. When they
+// are no longer all known, or lastEventID is zero, the current balance of
+// every wallet is replayed instead.
+func (s *walletService) Subscribe(ctx context.Context, userID, lastEventID int64) (*event.Subscription, error) {
+	sub := s.bus.Subscribe(userID, lastEventID)
+	if lastEventID > 0 && !sub.Missed {
+		return sub, nil
+	}
+
+	wallets, err := s.repo.ReadByUserID(ctx, userID, model.WalletKindPersonal)
+	if err != nil {
+		sub.Close()
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	sub.Replay = nil
+	for _, wallet := range wallets {
+		sub.Replay = append(sub.Replay, event.Event{
+			ID:     sub.LastID,
+			UserID: userID,
+			Type:   event.TypeBalance,
+			Data:   model.BalanceChange{WalletID: wallet.ID, Address: wallet.Address, Balance: wallet.Balance},
+		})
+	}
+
+	return sub, nil
+}
+
+// checkKYCLimit verifies that applying amount to a wallet locked in tx keeps
+// it within the limits of its owner's KYC level: credits within the balance
+// limit and debits within the daily limit.
+func (s *walletService) checkKYCLimit(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, amount float64) error {
+	user, err := s.userRepo.ReadByID(ctx, wallet.UserID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	if user == nil {
+		return cs.ErrNotFound
+	}
+
+	limit, ok := model.KYCLimits[user.KYCLevel]
+	if !ok {
+		limit = model.KYCLimits[model.KYCLevelUnverified]
+	}
+
+	if amount > 0 {
+		if wallet.Balance+amount > limit.MaxBalance {
+			return cs.ErrBalanceLimitExceeded
+		}
+		return nil
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	volume, err := s.repo.SumTransactionVolume(ctx, tx, wallet.ID, startOfDay)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	if volume-amount > limit.DailyTransaction {
+		return cs.ErrDailyLimitExceeded
+	}
+
+	return nil
+}
+
+// post applies a signed ledger entry to a wallet locked in tx, updating the
+// stored balance and recording the entry with the resulting balance. The
+// entry is completed with its id, wallet and resulting balance. Entries the
+// wallet's status does not allow are refused.
+func (s *walletService) post(ctx context.Context, tx *sql.Tx, wallet *model.Wallet, entry *model.Transaction) (err error) {
+	if err = checkWalletStatus(wallet, *entry); err != nil {
+		return err
+	}
+
+	wallet.Balance += entry.Amount
+
+	if err = s.repo.UpdateBalance(ctx, tx, *wallet); err != nil {
+		return err
+	}
+
+	// An event sourced wallet's stream moved on a version, which the next
+	// post to it in this transaction has to start from.
+	wallet.Version++
+
+	entry.WalletID = wallet.ID
+	entry.BalanceAfter = wallet.Balance
+
+	if entry.ID, err = s.repo.WriteTransaction(ctx, tx, *entry); err != nil {
+		return err
+	}
+
+	err = s.audit.Log(ctx, tx, model.AuditEntry{
+		Action:     model.AuditBalanceChanged,
+		TargetType: model.AuditTargetWallet,
+		TargetID:   wallet.ID,
+		Before:     auditValue(map[string]float64{"balance": wallet.Balance - entry.Amount}),
+		After: auditValue(map[string]interface{}{
+			"balance":        wallet.Balance,
+			"transaction_id": entry.ID,
+			"type":           entry.Type,
+			"reference":      entry.Reference,
+		}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Wallets without an owner, such as the revenue wallet, are not
+	// reported on.
+	if wallet.UserID == 0 {
+		return nil
+	}
+
+	posted := *entry
+	if posted.CreatedAt.IsZero() {
+		posted.CreatedAt = time.Now()
+	}
+
+	if eventType := webhookEventType(posted); eventType != "" {
+		if err = s.webhooks.Dispatch(ctx, tx, wallet.UserID, eventType, posted); err != nil {
+			return err
+		}
+		if err = s.events.Record(ctx, tx, wallet.UserID, eventType, posted); err != nil {
+			return err
+		}
+	}
+
+	s.postedMu.Lock()
+	s.posted[tx] = append(s.posted[tx], postedEntry{userID: wallet.UserID, address: wallet.Address, entry: posted})
+	s.postedMu.Unlock()
+
+	return nil
+}
+
+// webhookEventType is the webhook event a ledger entry is reported to its
+// wallet's owner as, if any.
+func webhookEventType(entry model.Transaction) string {
+	switch entry.Type {
+	case model.TransactionTypeTopUp:
+		return model.WebhookEventTopUpCompleted
+	case model.TransactionTypePayment:
+		return model.WebhookEventPaymentSent
+	case model.TransactionTypePaymentReceived:
+		return model.WebhookEventPaymentReceived
+	case model.TransactionTypeRefund:
+		if entry.Amount > 0 {
+			return model.WebhookEventRefundReceived
+		}
+		return model.WebhookEventRefundIssued
+	}
+
+	return ""
 }
@@ -2,13 +2,76 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/emvqr"
+	"github.com/cecepsprd/starworks-test/internal/event"
 	"github.com/cecepsprd/starworks-test/internal/mocks"
 	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/stretchr/testify/mock"
 )
 
+// staticRiskEngine returns the same assessment for every operation.
+type staticRiskEngine struct {
+	assessment model.RiskAssessment
+}
+
+func (r staticRiskEngine) Assess(ctx context.Context, input model.RiskInput) (*model.RiskAssessment, error) {
+	assessment := r.assessment
+	return &assessment, nil
+}
+
+// riskEngineFunc assesses every operation with a function.
+type riskEngineFunc func(input model.RiskInput) model.RiskAssessment
+
+func (f riskEngineFunc) Assess(ctx context.Context, input model.RiskInput) (*model.RiskAssessment, error) {
+	assessment := f(input)
+	return &assessment, nil
+}
+
+func allowRiskEngine() RiskEngine {
+	return staticRiskEngine{assessment: model.RiskAssessment{Decision: model.RiskDecisionAllow}}
+}
+
+// noFeeService prices every operation without a fee.
+func noFeeService() FeeService {
+	mockFeeRepo := mocks.FeeRepository{}
+	mockFeeRepo.On("ReadApplicableRule", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+	return NewFeeService(&mockFeeRepo, 0)
+}
+
+// noWebhooks has no subscriptions to queue deliveries for.
+type noWebhooks struct{}
+
+func (noWebhooks) Dispatch(ctx context.Context, tx *sql.Tx, userID int64, eventType string, data interface{}) error {
+	return nil
+}
+
+// noEvents drops the events recorded through it.
+type noEvents struct{}
+
+func (noEvents) Record(ctx context.Context, tx *sql.Tx, userID int64, eventType string, data interface{}) error {
+	return nil
+}
+
+// noAudit drops the audit entries logged through it.
+type noAudit struct{}
+
+func (noAudit) Log(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error {
+	return nil
+}
+
 func Test_walletService_CheckBalance(t *testing.T) {
 
 	ctx := context.Background()
@@ -26,9 +89,11 @@ func Test_walletService_CheckBalance(t *testing.T) {
 				Address: "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
 			},
 			want: &model.Wallet{
-				UserID:  1,
-				Address: "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
-				Balance: 1000,
+				ID:        3,
+				UserID:    1,
+				Address:   "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+				Balance:   1000,
+				Available: 600,
 			},
 			wantErr: false,
 		},
@@ -36,10 +101,18 @@ func Test_walletService_CheckBalance(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
 
-			mockRepo.On("ReadBalance", ctx, tt.args).Return(tt.want, nil)
+			mockRepo.On("ReadBalance", ctx, tt.args).Return(&model.Wallet{
+				ID:      3,
+				UserID:  tt.args.UserID,
+				Address: tt.args.Address,
+				Balance: tt.want.Balance,
+			}, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, int64(3), mock.Anything).Return(float64(400), nil)
 
-			s := NewWalletService(&mockRepo)
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mocks.RiskRepository{}, &mockHoldRepo, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mocks.LimitRepository{}), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
 			got, err := s.CheckBalance(ctx, tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("walletService.CheckBalance() error = %v, wantErr %v", err, tt.wantErr)
@@ -52,27 +125,112 @@ func Test_walletService_CheckBalance(t *testing.T) {
 	}
 }
 
-func Test_walletService_TopUp(t *testing.T) {
+func Test_walletService_Pay(t *testing.T) {
+	db, mockDB := dbConn()
+
 	ctx := context.Background()
 
 	tests := []struct {
-		name    string
-		args    model.TopUpRequest
-		wantErr bool
+		name string
+		args model.PayRequest
+		held float64
+		fee  float64
+		// revenueOwner is the user the revenue wallet belongs to, if any.
+		revenueOwner int64
+		wantErr      bool
 	}{
 		{
 			name: "positif",
-			args: model.TopUpRequest{
-				Nominal: 1000,
-				UserID:  1,
-				Address: "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+			args: model.PayRequest{
+				NominalPayment: 1000,
+				MerchantID:     8,
+				UserID:         1,
+				Address:        "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+			},
+			wantErr: false,
+		},
+		{
+			name: "positif: with fee",
+			args: model.PayRequest{
+				NominalPayment: 1000,
+				MerchantID:     8,
+				UserID:         1,
+				Address:        "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
 			},
+			fee:     100,
 			wantErr: false,
 		},
+		{
+			name: "negatif: revenue wallet belongs to a customer",
+			args: model.PayRequest{
+				NominalPayment: 1000,
+				MerchantID:     8,
+				UserID:         1,
+				Address:        "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+			},
+			fee:          100,
+			revenueOwner: 2,
+			wantErr:      true,
+		},
+		{
+			name: "negatif: fee exceeds available balance",
+			args: model.PayRequest{
+				NominalPayment: 1000,
+				MerchantID:     8,
+				UserID:         1,
+				Address:        "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+			},
+			held:    3950,
+			fee:     100,
+			wantErr: true,
+		},
+		{
+			name: "negatif: balance reserved by holds",
+			args: model.PayRequest{
+				NominalPayment: 1000,
+				MerchantID:     8,
+				UserID:         1,
+				Address:        "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+			},
+			held:    4500,
+			wantErr: true,
+		},
+		{
+			name: "negatif: merchant not active",
+			args: model.PayRequest{
+				NominalPayment: 1000,
+				MerchantID:     9,
+				UserID:         1,
+				Address:        "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negatif: insufficient balance",
+			args: model.PayRequest{
+				NominalPayment: 10000,
+				MerchantID:     8,
+				UserID:         1,
+				Address:        "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			if tt.wantErr {
+				mockDB.ExpectRollback()
+			} else {
+				mockDB.ExpectCommit()
+			}
+
 			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+			mockFeeRepo := mocks.FeeRepository{}
 
 			checkBalReq := model.CheckBalanceRequest{
 				UserID:  tt.args.UserID,
@@ -80,67 +238,1780 @@ func Test_walletService_TopUp(t *testing.T) {
 			}
 
 			wallet := model.Wallet{
-				Balance: 1000,
+				ID:      3,
+				Balance: 5000,
+				Address: tt.args.Address,
+				UserID:  tt.args.UserID,
 			}
 
 			mockRepo.On("ReadBalance", ctx, checkBalReq).Return(&wallet, nil)
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("ReadBalanceForUpdate", ctx, tx, checkBalReq).Return(&wallet, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(tt.held, nil)
+			mockRepo.On("SumTransactionVolume", ctx, tx, wallet.ID, mock.Anything).Return(float64(0), nil)
+			mockUserRepo.On("ReadByID", ctx, tt.args.UserID).Return(&model.User{ID: tt.args.UserID, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockLimitRepo.On("ReadActiveRules", ctx, mock.Anything, tt.args.UserID).Return([]model.LimitRule{}, nil)
+
+			mockRepo.On("UpdateBalance", ctx, tx, model.Wallet{
+				ID:      wallet.ID,
+				Balance: wallet.Balance - tt.args.NominalPayment,
+				Address: tt.args.Address,
+				UserID:  tt.args.UserID,
+			}).Return(nil)
+
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == wallet.ID && trx.Type == model.TransactionTypePayment && trx.Amount == -tt.args.NominalPayment
+			})).Return(int64(1), nil)
+
+			settlement := model.Wallet{ID: 20, Balance: 100, Address: "settlement", UserID: 5}
+
+			mockMerchantRepo.On("ReadMerchantByID", ctx, int64(8)).Return(&model.Merchant{ID: 8, UserID: 5, WalletID: settlement.ID, Status: model.MerchantStatusActive}, nil)
+			mockMerchantRepo.On("ReadMerchantByID", ctx, int64(9)).Return(&model.Merchant{ID: 9, UserID: 5, WalletID: 21, Status: model.MerchantStatusPending}, nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, settlement.ID).Return(&settlement, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.MatchedBy(func(w model.Wallet) bool {
+				return w.ID == settlement.ID && w.Balance == 100+tt.args.NominalPayment
+			})).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == settlement.ID && trx.Type == model.TransactionTypePaymentReceived && trx.Amount == tt.args.NominalPayment
+			})).Return(int64(2), nil)
+			mockMerchantRepo.On("WriteMerchantPayment", ctx, tx, mock.MatchedBy(func(p model.MerchantPayment) bool {
+				return p.MerchantID == 8 && p.TransactionID == 1 && p.CreditTransactionID == 2 && p.Amount == tt.args.NominalPayment
+			})).Return(int64(1), nil)
 
-			mockRepo.On("UpdateBalance", ctx, model.Wallet{
-				Balance: tt.args.Nominal + wallet.Balance,
+			var rule *model.FeeRule
+			if tt.fee > 0 {
+				rule = &model.FeeRule{ID: 1, Operation: model.TransactionTypePayment, Type: model.FeeTypeFlat, Flat: tt.fee}
+			}
+			mockFeeRepo.On("ReadApplicableRule", ctx, model.TransactionTypePayment, tt.args.MerchantID).Return(rule, nil)
+
+			revenue := model.Wallet{ID: 30, Balance: 0, Address: "revenue", UserID: tt.revenueOwner}
+
+			mockRepo.On("UpdateBalance", ctx, tx, model.Wallet{
+				ID:      wallet.ID,
+				Balance: wallet.Balance - tt.args.NominalPayment - tt.fee,
 				Address: tt.args.Address,
 				UserID:  tt.args.UserID,
+				Version: 1,
 			}).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == wallet.ID && trx.Type == model.TransactionTypeFee && trx.Amount == -tt.fee
+			})).Return(int64(3), nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, revenue.ID).Return(&revenue, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.MatchedBy(func(w model.Wallet) bool {
+				return w.ID == revenue.ID && w.Balance == tt.fee
+			})).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == revenue.ID && trx.Type == model.TransactionTypeFeeRevenue && trx.Amount == tt.fee
+			})).Return(int64(4), nil)
 
-			s := NewWalletService(&mockRepo)
-			if err := s.TopUp(ctx, tt.args); (err != nil) != tt.wantErr {
-				t.Errorf("walletService.TopUp() error = %v, wantErr %v", err, tt.wantErr)
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mocks.RiskRepository{}, &mockHoldRepo, &mocks.RefundRepository{}, &mockMerchantRepo, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), NewFeeService(&mockFeeRepo, revenue.ID), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			if err := s.Pay(ctx, tt.args); (err != nil) != tt.wantErr {
+				t.Errorf("walletService.Pay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				mockMerchantRepo.AssertCalled(t, "WriteMerchantPayment", ctx, tx, mock.Anything)
+			}
+			if !tt.wantErr && tt.fee > 0 {
+				mockRepo.AssertCalled(t, "ReadByIDForUpdate", ctx, tx, revenue.ID)
 			}
 		})
 	}
 }
 
-func Test_walletService_Pay(t *testing.T) {
+func Test_walletService_post_eventSourced(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	tx := beginTx(db, mockDB)
+
+	wallet := model.Wallet{ID: 3, Balance: 5000, Address: "addressx", UserID: 1, Status: model.WalletStatusActive, Version: 4}
+
+	// A payment and its fee are posted to the payer's wallet one after the
+	// other, each at the next version of its stream.
+	for i, amount := range []float64{-1000, -100} {
+		version := wallet.Version + int64(i)
+		balance := wallet.Balance - 1000
+		if i == 1 {
+			balance -= 100
+		}
+
+		mockDB.ExpectExec("UPDATE wallet SET balance=\\?, version=version\\+1, updated_at=\\? WHERE id=\\? AND version=\\?").
+			WithArgs(balance, sqlmock.AnyArg(), wallet.ID, version).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mockDB.ExpectExec("INSERT INTO wallet_transaction").
+			WithArgs(wallet.ID, sqlmock.AnyArg(), amount, balance, "ref", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(int64(11+i), 1))
+		mockDB.ExpectQuery("SELECT version FROM wallet WHERE id = \\?").
+			WithArgs(wallet.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version + 1))
+		mockDB.ExpectExec("INSERT INTO wallet_event").
+			WithArgs(wallet.ID, version+1, model.WalletEventDebited, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mockDB.ExpectCommit()
+
+	repo := repository.NewEventSourcedWalletRepository(db, 0)
+	s := NewWalletService(repo, &mocks.UserRepository{}, &mocks.RiskRepository{}, &mocks.HoldRepository{}, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mocks.LimitRepository{}), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+
+	payment := &model.Transaction{Type: model.TransactionTypePayment, Amount: -1000, Reference: "ref"}
+	if err := s.(*walletService).post(ctx, tx, &wallet, payment); err != nil {
+		t.Fatalf("walletService.post() payment error = %v", err)
+	}
+
+	fee := &model.Transaction{Type: model.TransactionTypeFee, Amount: -100, Reference: "ref"}
+	if err := s.(*walletService).post(ctx, tx, &wallet, fee); err != nil {
+		t.Fatalf("walletService.post() fee error = %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mockDB.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if wallet.Balance != 3900 || wallet.Version != 6 {
+		t.Errorf("walletService.post() wallet = %+v", wallet)
+	}
+}
+
+func Test_walletService_CheckTopUp(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		decision string
+		wantErr  error
+		wantHeld bool
+	}{
+		{
+			name:     "positif",
+			decision: model.RiskDecisionAllow,
+		},
+		{
+			name:     "negatif: denied",
+			decision: model.RiskDecisionDeny,
+			wantErr:  cs.ErrTransactionDenied,
+		},
+		{
+			name:     "negatif: held for review",
+			decision: model.RiskDecisionReview,
+			wantHeld: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockRiskRepo := mocks.RiskRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+
+			req := model.TopUpRequest{Nominal: 50000, Address: "addressx", UserID: 1}
+			target := model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}
+			wallet := model.Wallet{ID: 3, Balance: 5000, Address: req.Address, UserID: req.UserID, Status: model.WalletStatusActive}
+
+			mockRepo.On("ReadBalance", ctx, target).Return(&wallet, nil)
+			mockRiskRepo.On("WriteHeldTransaction", ctx, mock.MatchedBy(func(h model.HeldTransaction) bool {
+				return h.Operation == model.TransactionTypeTopUp && h.WalletID == wallet.ID && h.Amount == req.Nominal
+			})).Return(int64(9), nil)
+
+			if tt.decision == model.RiskDecisionAllow {
+				tx := beginTx(db, mockDB)
+				mockDB.ExpectCommit()
+
+				mockRepo.On("BeginTx", ctx).Return(tx)
+				mockRepo.On("ReadBalanceForUpdate", ctx, tx, target).Return(&wallet, nil)
+				mockUserRepo.On("ReadByID", ctx, req.UserID).Return(&model.User{ID: req.UserID}, nil)
+				mockLimitRepo.On("ReadActiveRules", ctx, model.TransactionTypeTopUp, req.UserID).Return([]model.LimitRule{}, nil)
+			}
+
+			riskEngine := staticRiskEngine{assessment: model.RiskAssessment{Decision: tt.decision}}
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mockRiskRepo, &mocks.HoldRepository{}, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), riskEngine, 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.CheckTopUp(ctx, req)
+
+			var heldErr *model.TransactionHeldError
+			if tt.wantHeld {
+				if !errors.As(err, &heldErr) {
+					t.Fatalf("walletService.CheckTopUp() error = %v, want held", err)
+				}
+				return
+			}
+			if err != tt.wantErr {
+				t.Fatalf("walletService.CheckTopUp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != wallet.ID {
+				t.Errorf("walletService.CheckTopUp() = %v, want %v", got, wallet.ID)
+			}
+			if tt.wantErr != nil {
+				mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_walletService_PayPaymentRequest(t *testing.T) {
+	db, mockDB := dbConn()
+
 	ctx := context.Background()
 
+	payer := model.CheckBalanceRequest{
+		UserID:  2,
+		Address: "0a6f6a1e1f3c0c4b3f1b8f0e2d8c6a5b4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b",
+	}
+	otherID := int64(6)
+
 	tests := []struct {
 		name    string
-		args    model.PayRequest
-		wantErr bool
+		request model.PaymentRequest
+		// requesterBalance is what the requester's wallet holds already.
+		requesterBalance float64
+		wantErr          error
 	}{
 		{
-			name: "positif",
-			args: model.PayRequest{
-				NominalPayment: 1000,
-				UserID:         1,
-				Address:        "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
-			},
-			wantErr: false,
+			name:    "positif",
+			request: model.PaymentRequest{ID: 1, Code: "K7PQ2MZX", RequesterID: 1, WalletID: 3, Amount: 1000, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(time.Hour)},
+		},
+		{
+			name:    "negatif: expired",
+			request: model.PaymentRequest{ID: 1, Code: "K7PQ2MZX", RequesterID: 1, WalletID: 3, Amount: 1000, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(-time.Hour)},
+			wantErr: cs.ErrPaymentRequestNotActive,
+		},
+		{
+			name:    "negatif: addressed to another payer",
+			request: model.PaymentRequest{ID: 1, Code: "K7PQ2MZX", RequesterID: 1, WalletID: 3, PayerID: &otherID, Amount: 1000, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(time.Hour)},
+			wantErr: cs.ErrNotFound,
+		},
+		{
+			name:             "negatif: requester's balance limit",
+			request:          model.PaymentRequest{ID: 1, Code: "K7PQ2MZX", RequesterID: 1, WalletID: 3, Amount: 1000, Status: model.PaymentRequestPending, ExpiresAt: time.Now().Add(time.Hour)},
+			requesterBalance: model.KYCLimits[model.KYCLevelUnverified].MaxBalance,
+			wantErr:          cs.ErrBalanceLimitExceeded,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			if tt.wantErr != nil {
+				mockDB.ExpectRollback()
+			} else {
+				mockDB.ExpectCommit()
+			}
+
+			request := tt.request
+
 			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+			mockRequestRepo := mocks.PaymentRequestRepository{}
 
-			checkBalReq := model.CheckBalanceRequest{
-				UserID:  tt.args.UserID,
-				Address: tt.args.Address,
+			wallet := model.Wallet{ID: 5, Balance: 5000, Address: payer.Address, UserID: payer.UserID}
+			requester := model.Wallet{ID: 3, Balance: tt.requesterBalance, Address: "requester", UserID: 1}
+
+			mockRequestRepo.On("ReadPaymentRequestByCode", ctx, request.Code).Return(&request, nil)
+			mockRequestRepo.On("ReadPaymentRequestForUpdate", ctx, tx, request.Code).Return(&request, nil)
+			mockRequestRepo.On("MarkPaymentRequestPaid", ctx, tx, mock.MatchedBy(func(r model.PaymentRequest) bool {
+				return r.ID == request.ID && *r.PayerID == payer.UserID && *r.TransactionID == 1
+			})).Return(nil)
+
+			mockRepo.On("ReadBalance", ctx, payer).Return(&wallet, nil)
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("ReadBalanceForUpdate", ctx, tx, payer).Return(&wallet, nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, requester.ID).Return(&requester, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(float64(0), nil)
+			mockRepo.On("SumTransactionVolume", ctx, tx, wallet.ID, mock.Anything).Return(float64(0), nil)
+			mockUserRepo.On("ReadByID", ctx, payer.UserID).Return(&model.User{ID: payer.UserID, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockUserRepo.On("ReadByID", ctx, requester.UserID).Return(&model.User{ID: requester.UserID, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockLimitRepo.On("ReadActiveRules", ctx, mock.Anything, payer.UserID).Return([]model.LimitRule{}, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == wallet.ID && trx.Type == model.TransactionTypePayment && trx.Amount == -request.Amount
+			})).Return(int64(1), nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == requester.ID && trx.Type == model.TransactionTypePaymentReceived && trx.Amount == request.Amount
+			})).Return(int64(2), nil)
+
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mocks.RiskRepository{}, &mockHoldRepo, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mockRequestRepo, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			if err := s.PayPaymentRequest(ctx, request.Code, payer); err != tt.wantErr {
+				t.Errorf("walletService.PayPaymentRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
 			}
+			if tt.wantErr == nil {
+				if requester.Balance != request.Amount || request.Status != model.PaymentRequestPaid {
+					t.Errorf("walletService.PayPaymentRequest() requester balance = %v, status = %v", requester.Balance, request.Status)
+				}
+				mockRepo.AssertNumberOfCalls(t, "ReadByIDForUpdate", 2)
+			}
+		})
+	}
+}
 
-			wallet := model.Wallet{
-				Balance: 5000,
+func Test_walletService_ApproveHeldTransaction(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		status    string
+		updateErr error
+		wantErr   error
+	}{
+		{
+			name:   "positif",
+			status: model.HeldTransactionPending,
+		},
+		{
+			name:    "negatif: already reviewed",
+			status:  model.HeldTransactionRejected,
+			wantErr: cs.ErrHeldTransactionReviewed,
+		},
+		{
+			name:      "negatif: reviewed concurrently",
+			status:    model.HeldTransactionPending,
+			updateErr: sql.ErrNoRows,
+			wantErr:   cs.ErrHeldTransactionReviewed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockRiskRepo := mocks.RiskRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+
+			req := model.PayRequest{NominalPayment: 1000, MerchantID: 8, UserID: 1, Address: "addressx"}
+			wallet := model.Wallet{ID: 3, Balance: 5000, Address: req.Address, UserID: req.UserID}
+			settlement := model.Wallet{ID: 20, Address: "settlement", UserID: 5}
+
+			held := model.HeldTransaction{
+				ID:        9,
+				UserID:    req.UserID,
+				WalletID:  wallet.ID,
+				Operation: model.TransactionTypePayment,
+				Amount:    req.NominalPayment,
+				Payload:   `{"nominal_payment":1000,"merchant_id":8,"user_id":1,"address":"addressx"}`,
+				Status:    tt.status,
 			}
 
-			mockRepo.On("ReadBalance", ctx, checkBalReq).Return(&wallet, nil)
+			mockRiskRepo.On("ReadHeldTransactionByID", ctx, held.ID).Return(&held, nil)
 
-			mockRepo.On("UpdateBalance", ctx, model.Wallet{
-				Balance: wallet.Balance - tt.args.NominalPayment,
-				Address: tt.args.Address,
-				UserID:  tt.args.UserID,
-			}).Return(nil)
+			if tt.status == model.HeldTransactionPending {
+				tx := beginTx(db, mockDB)
+				if tt.wantErr != nil {
+					mockDB.ExpectRollback()
+				} else {
+					mockDB.ExpectCommit()
+				}
 
-			s := NewWalletService(&mockRepo)
-			if err := s.Pay(ctx, tt.args); (err != nil) != tt.wantErr {
-				t.Errorf("walletService.Pay() error = %v, wantErr %v", err, tt.wantErr)
+				mockRepo.On("BeginTx", ctx).Return(tx)
+				mockRiskRepo.On("UpdateHeldTransaction", ctx, tx, mock.MatchedBy(func(h model.HeldTransaction) bool {
+					return h.ID == held.ID && h.Status == model.HeldTransactionApproved && *h.ReviewerID == 2
+				})).Return(tt.updateErr)
+				mockMerchantRepo.On("ReadMerchantByID", ctx, req.MerchantID).Return(&model.Merchant{ID: 8, UserID: 5, WalletID: settlement.ID, Status: model.MerchantStatusActive}, nil)
+				mockRepo.On("ReadBalanceForUpdate", ctx, tx, model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}).Return(&wallet, nil)
+				mockRepo.On("ReadByIDForUpdate", ctx, tx, settlement.ID).Return(&settlement, nil)
+				mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(float64(0), nil)
+				mockRepo.On("SumTransactionVolume", ctx, tx, wallet.ID, mock.Anything).Return(float64(0), nil)
+				mockUserRepo.On("ReadByID", ctx, req.UserID).Return(&model.User{ID: req.UserID}, nil)
+				mockLimitRepo.On("ReadActiveRules", ctx, mock.Anything, req.UserID).Return([]model.LimitRule{}, nil)
+				mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+				mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+					return trx.WalletID == wallet.ID && trx.Amount == -req.NominalPayment
+				})).Return(int64(1), nil)
+				mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+					return trx.WalletID == settlement.ID && trx.Amount == req.NominalPayment
+				})).Return(int64(2), nil)
+				mockMerchantRepo.On("WriteMerchantPayment", ctx, tx, mock.Anything).Return(int64(1), nil)
+			}
+
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mockRiskRepo, &mockHoldRepo, &mocks.RefundRepository{}, &mockMerchantRepo, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.ApproveHeldTransaction(ctx, held.ID, 2)
+			if err != tt.wantErr {
+				t.Fatalf("walletService.ApproveHeldTransaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Status != model.HeldTransactionApproved {
+				t.Errorf("walletService.ApproveHeldTransaction() status = %v, want %v", got.Status, model.HeldTransactionApproved)
+			}
+			if tt.wantErr == nil {
+				mockRepo.AssertCalled(t, "WriteTransaction", ctx, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_walletService_Authorize(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		decision string
+		wantErr  error
+		wantHeld bool
+	}{
+		{
+			name:     "positif",
+			decision: model.RiskDecisionAllow,
+		},
+		{
+			name:     "negatif: denied",
+			decision: model.RiskDecisionDeny,
+			wantErr:  cs.ErrTransactionDenied,
+		},
+		{
+			name:     "negatif: held for review",
+			decision: model.RiskDecisionReview,
+			wantHeld: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockRiskRepo := mocks.RiskRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+
+			req := model.AuthorizeRequest{MerchantID: 8, Amount: 1000, Address: "addressx", UserID: 1}
+			target := model.CheckBalanceRequest{UserID: req.UserID, Address: req.Address}
+			wallet := model.Wallet{ID: 3, Balance: 5000, Address: req.Address, UserID: req.UserID, Status: model.WalletStatusActive}
+
+			mockRepo.On("ReadBalance", ctx, target).Return(&wallet, nil)
+			mockRiskRepo.On("WriteHeldTransaction", ctx, mock.MatchedBy(func(h model.HeldTransaction) bool {
+				return h.Operation == model.HeldOperationAuthorization && h.WalletID == wallet.ID && h.Amount == req.Amount
+			})).Return(int64(9), nil)
+
+			if tt.decision == model.RiskDecisionAllow {
+				tx := beginTx(db, mockDB)
+				mockDB.ExpectCommit()
+
+				mockRepo.On("BeginTx", ctx).Return(tx)
+				mockMerchantRepo.On("ReadMerchantByID", ctx, req.MerchantID).Return(&model.Merchant{ID: 8, UserID: 5, WalletID: 20, Status: model.MerchantStatusActive}, nil)
+				mockRepo.On("ReadBalanceForUpdate", ctx, tx, target).Return(&wallet, nil)
+				mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(float64(0), nil)
+				mockRepo.On("SumTransactionVolume", ctx, tx, wallet.ID, mock.Anything).Return(float64(0), nil)
+				mockUserRepo.On("ReadByID", ctx, req.UserID).Return(&model.User{ID: req.UserID}, nil)
+				mockLimitRepo.On("ReadActiveRules", ctx, model.TransactionTypePayment, req.UserID).Return([]model.LimitRule{}, nil)
+				mockHoldRepo.On("WriteHold", ctx, tx, mock.MatchedBy(func(h model.Hold) bool {
+					return h.WalletID == wallet.ID && h.Amount == req.Amount && h.Status == model.HoldStatusAuthorized
+				})).Return(int64(4), nil)
+			}
+
+			var scored string
+			riskEngine := riskEngineFunc(func(input model.RiskInput) model.RiskAssessment {
+				scored = input.Operation
+				return model.RiskAssessment{Decision: tt.decision}
+			})
+
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mockRiskRepo, &mockHoldRepo, &mocks.RefundRepository{}, &mockMerchantRepo, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), riskEngine, 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.Authorize(ctx, req)
+
+			if scored != model.TransactionTypePayment {
+				t.Errorf("walletService.Authorize() scored as %q, want %q", scored, model.TransactionTypePayment)
+			}
+
+			var heldErr *model.TransactionHeldError
+			if tt.wantHeld {
+				if !errors.As(err, &heldErr) {
+					t.Fatalf("walletService.Authorize() error = %v, want held", err)
+				}
+				mockHoldRepo.AssertNotCalled(t, "WriteHold", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if err != tt.wantErr {
+				t.Fatalf("walletService.Authorize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.ID != 4 {
+				t.Errorf("walletService.Authorize() = %+v", got)
+			}
+			if tt.wantErr != nil {
+				mockRepo.AssertNotCalled(t, "BeginTx", mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_walletService_Capture(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	active := func(walletID int64, expiresAt time.Time) *model.Hold {
+		return &model.Hold{ID: 5, WalletID: walletID, MerchantID: 8, Amount: 3000, Status: model.HoldStatusAuthorized, ExpiresAt: expiresAt}
+	}
+
+	tests := []struct {
+		name         string
+		hold         *model.Hold
+		requesterID  int64
+		amount       float64
+		volume       float64
+		wantCaptured float64
+		wantErr      error
+	}{
+		{
+			name:         "positif: partial capture by payer",
+			hold:         active(3, time.Now().Add(time.Hour)),
+			requesterID:  1,
+			amount:       1200,
+			wantCaptured: 1200,
+		},
+		{
+			name:         "positif: full capture by merchant",
+			hold:         active(3, time.Now().Add(time.Hour)),
+			requesterID:  5,
+			wantCaptured: 3000,
+		},
+		{
+			name:        "negatif: exceeds hold",
+			hold:        active(3, time.Now().Add(time.Hour)),
+			requesterID: 1,
+			amount:      3001,
+			wantErr:     cs.ErrCaptureExceedsHold,
+		},
+		{
+			name:        "negatif: daily limit used since authorized",
+			hold:        active(3, time.Now().Add(time.Hour)),
+			requesterID: 1,
+			amount:      1200,
+			volume:      1999000,
+			wantErr:     cs.ErrDailyLimitExceeded,
+		},
+		{
+			name:        "negatif: expired",
+			hold:        active(3, time.Now().Add(-time.Minute)),
+			requesterID: 1,
+			wantErr:     cs.ErrHoldNotActive,
+		},
+		{
+			name:        "negatif: neither payer nor merchant",
+			hold:        active(3, time.Now().Add(time.Hour)),
+			requesterID: 99,
+			wantErr:     cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			if tt.wantErr != nil {
+				mockDB.ExpectRollback()
+			} else {
+				mockDB.ExpectCommit()
+			}
+
+			mockRepo := mocks.WalletRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+
+			wallet := model.Wallet{ID: 3, Balance: 5000, Address: "addressx", UserID: 1}
+			settlement := model.Wallet{ID: 20, Balance: 0, Address: "settlement", UserID: 5}
+
+			mockHoldRepo.On("ReadHoldByID", ctx, tt.hold.ID).Return(tt.hold, nil)
+			mockMerchantRepo.On("ReadMerchantByID", ctx, tt.hold.MerchantID).Return(&model.Merchant{ID: 8, UserID: 5, WalletID: settlement.ID, Status: model.MerchantStatusActive}, nil)
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, wallet.ID).Return(&wallet, nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, settlement.ID).Return(&settlement, nil)
+			mockHoldRepo.On("ReadHoldForUpdate", ctx, tx, tt.hold.ID).Return(tt.hold, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(tt.hold.Amount, nil)
+			mockUserRepo.On("ReadByID", ctx, wallet.UserID).Return(&model.User{ID: wallet.UserID, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockRepo.On("SumTransactionVolume", ctx, tx, wallet.ID, mock.Anything).Return(tt.volume, nil)
+			mockLimitRepo.On("ReadActiveRules", ctx, model.TransactionTypePayment, wallet.UserID).Return([]model.LimitRule{}, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.Type == model.TransactionTypePayment && trx.Amount == -tt.wantCaptured
+			})).Return(int64(1), nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.Type == model.TransactionTypePaymentReceived && trx.Amount == tt.wantCaptured
+			})).Return(int64(2), nil)
+			mockMerchantRepo.On("WriteMerchantPayment", ctx, tx, mock.Anything).Return(int64(1), nil)
+			mockHoldRepo.On("UpdateHold", ctx, tx, mock.MatchedBy(func(h model.Hold) bool {
+				return h.Status == model.HoldStatusCaptured && h.CapturedAmount == tt.wantCaptured
+			})).Return(nil)
+
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mocks.RiskRepository{}, &mockHoldRepo, &mocks.RefundRepository{}, &mockMerchantRepo, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.Capture(ctx, tt.hold.ID, model.CaptureRequest{Amount: tt.amount, UserID: tt.requesterID})
+			if err != tt.wantErr {
+				t.Fatalf("walletService.Capture() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.CapturedAmount != tt.wantCaptured {
+				t.Errorf("walletService.Capture() captured = %v, want %v", got.CapturedAmount, tt.wantCaptured)
+			}
+			if tt.wantErr == nil && settlement.Balance != tt.wantCaptured {
+				t.Errorf("walletService.Capture() settlement balance = %v, want %v", settlement.Balance, tt.wantCaptured)
+			}
+		})
+	}
+}
+
+func Test_walletService_Withdraw(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		merchantID int64
+		wantWallet int64
+		wantErr    error
+	}{
+		{
+			name:       "positif: from own wallet",
+			wantWallet: 3,
+		},
+		{
+			name:       "positif: from settlement wallet",
+			merchantID: 8,
+			wantWallet: 20,
+		},
+		{
+			name:       "negatif: someone else's merchant",
+			merchantID: 9,
+			wantErr:    cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			mockDB.ExpectRollback()
+			defer tx.Rollback()
+
+			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+
+			wallet := model.Wallet{ID: 3, Balance: 5000, Address: "addressx", UserID: 1}
+			settlement := model.Wallet{ID: 20, Balance: 5000, Address: "settlement", UserID: 1, Kind: model.WalletKindSettlement}
+
+			mockRepo.On("ReadBalanceForUpdate", ctx, tx, model.CheckBalanceRequest{UserID: 1, Address: wallet.Address}).Return(&wallet, nil)
+			mockMerchantRepo.On("ReadMerchantByID", ctx, int64(8)).Return(&model.Merchant{ID: 8, UserID: 1, WalletID: settlement.ID, Status: model.MerchantStatusActive}, nil)
+			mockMerchantRepo.On("ReadMerchantByID", ctx, int64(9)).Return(&model.Merchant{ID: 9, UserID: 5, WalletID: 21, Status: model.MerchantStatusActive}, nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, settlement.ID).Return(&settlement, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, mock.Anything, mock.Anything).Return(float64(0), nil)
+			mockUserRepo.On("ReadByID", ctx, int64(1)).Return(&model.User{ID: 1, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockRepo.On("SumTransactionVolume", ctx, tx, mock.Anything, mock.Anything).Return(float64(0), nil)
+			mockLimitRepo.On("ReadActiveRules", ctx, model.TransactionTypeWithdrawal, int64(1)).Return([]model.LimitRule{}, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.Type == model.TransactionTypeWithdrawal && trx.Amount == -1000
+			})).Return(int64(1), nil)
+
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mocks.RiskRepository{}, &mockHoldRepo, &mocks.RefundRepository{}, &mockMerchantRepo, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.Withdraw(ctx, tx, model.WithdrawRequest{BankAccountID: 2, MerchantID: tt.merchantID, Amount: 1000, Address: wallet.Address, UserID: 1})
+			if err != tt.wantErr {
+				t.Fatalf("walletService.Withdraw() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.WalletID != tt.wantWallet {
+				t.Errorf("walletService.Withdraw() wallet = %v, want %v", got.WalletID, tt.wantWallet)
+			}
+		})
+	}
+}
+
+func Test_walletService_AssessWithdrawal(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		req        model.WithdrawRequest
+		decision   string
+		wantWallet int64
+		wantErr    error
+	}{
+		{
+			name:       "positif: personal wallet",
+			req:        model.WithdrawRequest{BankAccountID: 7, Amount: 50000, Address: "addressx", UserID: 1},
+			decision:   model.RiskDecisionAllow,
+			wantWallet: 3,
+		},
+		{
+			name:       "positif: settlement wallet",
+			req:        model.WithdrawRequest{BankAccountID: 7, MerchantID: 8, Amount: 50000, Address: "addressx", UserID: 1},
+			decision:   model.RiskDecisionAllow,
+			wantWallet: 20,
+		},
+		{
+			name:       "negatif: denied",
+			req:        model.WithdrawRequest{BankAccountID: 7, Amount: 50000, Address: "addressx", UserID: 1},
+			decision:   model.RiskDecisionDeny,
+			wantWallet: 3,
+			wantErr:    cs.ErrTransactionDenied,
+		},
+		{
+			name:     "negatif: someone else's merchant",
+			req:      model.WithdrawRequest{BankAccountID: 7, MerchantID: 8, Amount: 50000, Address: "addressx", UserID: 2},
+			decision: model.RiskDecisionAllow,
+			wantErr:  cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.WalletRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+
+			mockRepo.On("ReadBalance", ctx, model.CheckBalanceRequest{UserID: tt.req.UserID, Address: tt.req.Address}).Return(&model.Wallet{ID: 3, UserID: tt.req.UserID}, nil)
+			mockMerchantRepo.On("ReadMerchantByID", ctx, int64(8)).Return(&model.Merchant{ID: 8, UserID: 1, WalletID: 20, Status: model.MerchantStatusActive}, nil)
+
+			var assessed int64
+			riskEngine := riskEngineFunc(func(input model.RiskInput) model.RiskAssessment {
+				assessed = input.WalletID
+				return model.RiskAssessment{Decision: tt.decision}
+			})
+
+			s := NewWalletService(&mockRepo, &mocks.UserRepository{}, &mocks.RiskRepository{}, &mocks.HoldRepository{}, &mocks.RefundRepository{}, &mockMerchantRepo, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mocks.LimitRepository{}), noFeeService(), riskEngine, 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			err := s.AssessWithdrawal(ctx, tt.req)
+			if err != tt.wantErr {
+				t.Fatalf("walletService.AssessWithdrawal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if assessed != tt.wantWallet {
+				t.Errorf("walletService.AssessWithdrawal() assessed wallet %v, want %v", assessed, tt.wantWallet)
+			}
+		})
+	}
+}
+
+func Test_walletService_Refund(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	var (
+		payment  = &model.Transaction{ID: 11, WalletID: 3, Type: model.TransactionTypePayment, Amount: -1000, Reference: "ref"}
+		received = &model.MerchantPayment{ID: 1, MerchantID: 8, TransactionID: 11, CreditTransactionID: 12, Amount: 1000}
+		request  = &model.PaymentRequest{ID: 4, RequesterID: 5, WalletID: 20, Amount: 1000, Status: model.PaymentRequestPaid}
+		// early is a payment whose payer's wallet sorts after the payee's.
+		early    = &model.Transaction{ID: 11, WalletID: 30, Type: model.TransactionTypePayment, Amount: -1000, Reference: "ref"}
+		support  = model.User{ID: 2, Role: model.RoleSupport}
+		owner    = model.User{ID: 5, Role: model.RoleUser}
+		stranger = model.User{ID: 6, Role: model.RoleUser}
+	)
+
+	tests := []struct {
+		name       string
+		original   *model.Transaction
+		received   *model.MerchantPayment
+		request    *model.PaymentRequest
+		requester  model.User
+		refunded   float64
+		amount     float64
+		wantAmount float64
+		wantErr    error
+	}{
+		{
+			name:       "positif: partial refund of a payment request by support",
+			original:   payment,
+			request:    request,
+			requester:  support,
+			refunded:   200,
+			amount:     300,
+			wantAmount: 300,
+		},
+		{
+			name:       "positif: merchant refunds the remainder",
+			original:   payment,
+			received:   received,
+			requester:  owner,
+			refunded:   200,
+			wantAmount: 800,
+		},
+		{
+			name:       "positif: payee's wallet locked first",
+			original:   early,
+			received:   received,
+			requester:  owner,
+			wantAmount: 1000,
+		},
+		{
+			name:      "negatif: exceeds payment",
+			original:  payment,
+			received:  received,
+			requester: support,
+			refunded:  800,
+			amount:    300,
+			wantErr:   cs.ErrRefundExceedsPayment,
+		},
+		{
+			name:      "negatif: fully refunded",
+			original:  payment,
+			received:  received,
+			requester: support,
+			refunded:  1000,
+			wantErr:   cs.ErrRefundExceedsPayment,
+		},
+		{
+			name:      "negatif: not the merchant's payment",
+			original:  payment,
+			received:  received,
+			requester: stranger,
+			wantErr:   cs.ErrForbidden,
+		},
+		{
+			name:      "negatif: payee not recorded",
+			original:  payment,
+			requester: support,
+			wantErr:   cs.ErrNotRefundable,
+		},
+		{
+			name:      "negatif: not a payment",
+			original:  &model.Transaction{ID: 11, WalletID: 3, Type: model.TransactionTypeTopUp, Amount: 1000},
+			requester: support,
+			wantErr:   cs.ErrNotRefundable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.WalletRepository{}
+			mockRefundRepo := mocks.RefundRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+			mockRequestRepo := mocks.PaymentRequestRepository{}
+
+			wallet := model.Wallet{ID: tt.original.WalletID, Balance: 5000, Address: "addressx", UserID: 1}
+			settlement := model.Wallet{ID: 20, Balance: 1000, Address: "settlement", UserID: owner.ID}
+
+			mockRepo.On("ReadTransactionByID", ctx, tt.original.ID).Return(tt.original, nil)
+			mockMerchantRepo.On("ReadMerchantPaymentByTransaction", ctx, tt.original.ID).Return(tt.received, nil)
+			mockMerchantRepo.On("ReadMerchantByID", ctx, received.MerchantID).Return(&model.Merchant{ID: 8, UserID: owner.ID, WalletID: settlement.ID, Status: model.MerchantStatusActive}, nil)
+			mockRequestRepo.On("ReadPaymentRequestByTransaction", ctx, tt.original.ID).Return(tt.request, nil)
+
+			if tt.original.Type == model.TransactionTypePayment && tt.wantErr != cs.ErrForbidden && tt.wantErr != cs.ErrNotRefundable {
+				tx := beginTx(db, mockDB)
+				if tt.wantErr != nil {
+					mockDB.ExpectRollback()
+				} else {
+					mockDB.ExpectCommit()
+				}
+
+				mockRepo.On("BeginTx", ctx).Return(tx)
+				mockRepo.On("ReadByIDForUpdate", ctx, tx, wallet.ID).Return(&wallet, nil)
+				mockRepo.On("ReadByIDForUpdate", ctx, tx, settlement.ID).Return(&settlement, nil)
+				mockRefundRepo.On("SumRefunds", ctx, tx, tt.original.ID).Return(tt.refunded, nil)
+				mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+				mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+					return trx.WalletID == wallet.ID && trx.Type == model.TransactionTypeRefund && trx.Amount == tt.wantAmount && trx.Reference == tt.original.Reference
+				})).Return(int64(13), nil)
+				mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+					return trx.WalletID == settlement.ID && trx.Type == model.TransactionTypeRefund && trx.Amount == -tt.wantAmount
+				})).Return(int64(14), nil)
+				mockRefundRepo.On("WriteRefund", ctx, tx, mock.MatchedBy(func(r model.Refund) bool {
+					return r.TransactionID == tt.original.ID && r.RefundTransactionID == 13 && r.Amount == tt.wantAmount && r.CreatedBy == tt.requester.ID
+				})).Return(int64(1), nil)
+			}
+
+			s := NewWalletService(&mockRepo, &mocks.UserRepository{}, &mocks.RiskRepository{}, &mocks.HoldRepository{}, &mockRefundRepo, &mockMerchantRepo, &mockRequestRepo, &mocks.EscrowRepository{}, NewLimitService(&mocks.LimitRepository{}), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.Refund(ctx, model.RefundRequest{TransactionID: tt.original.ID, Amount: tt.amount, Reason: "duplicate", Requester: tt.requester})
+			if err != tt.wantErr {
+				t.Fatalf("walletService.Refund() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Amount != tt.wantAmount {
+				t.Errorf("walletService.Refund() amount = %v, want %v", got.Amount, tt.wantAmount)
+			}
+			if tt.wantErr == nil && settlement.Balance != 1000-tt.wantAmount {
+				t.Errorf("walletService.Refund() settlement balance = %v, want %v", settlement.Balance, 1000-tt.wantAmount)
+			}
+			if tt.wantErr == nil {
+				var locked []int64
+				for _, call := range mockRepo.Calls {
+					if call.Method == "ReadByIDForUpdate" {
+						locked = append(locked, call.Arguments.Get(2).(int64))
+					}
+				}
+				lowest := wallet.ID
+				if settlement.ID < lowest {
+					lowest = settlement.ID
+				}
+				if locked[0] != lowest {
+					t.Errorf("walletService.Refund() locked wallets %v, want %d first", locked, lowest)
+				}
+			}
+		})
+	}
+}
+
+func Test_walletService_PayByQR(t *testing.T) {
+	ctx := context.Background()
+
+	static, _ := emvqr.Encode(emvqr.Payload{MerchantID: 8, MerchantName: "Ojek", Currency: model.CurrencyIDR})
+	dynamic, _ := emvqr.Encode(emvqr.Payload{MerchantID: 8, MerchantName: "Ojek", Currency: model.CurrencyIDR, Amount: 1000})
+
+	tests := []struct {
+		name    string
+		args    model.QRPayRequest
+		wantErr error
+	}{
+		{
+			name:    "negatif: tampered payload",
+			args:    model.QRPayRequest{Payload: strings.Replace(dynamic, "54041000", "54049000", 1), Amount: 9000},
+			wantErr: cs.ErrInvalidQRPayload,
+		},
+		{
+			name:    "negatif: static code without amount",
+			args:    model.QRPayRequest{Payload: static},
+			wantErr: cs.ErrBadParamInput,
+		},
+		{
+			name:    "negatif: amount differs from dynamic code",
+			args:    model.QRPayRequest{Payload: dynamic, Amount: 500},
+			wantErr: cs.ErrBadParamInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewWalletService(&mocks.WalletRepository{}, &mocks.UserRepository{}, &mocks.RiskRepository{}, &mocks.HoldRepository{}, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mocks.LimitRepository{}), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			if err := s.PayByQR(ctx, tt.args); err != tt.wantErr {
+				t.Errorf("walletService.PayByQR() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_walletService_Transfer(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	sender := model.CheckBalanceRequest{
+		UserID:  1,
+		Address: "d49b7e51ca34f55e1e40d922cc42a134d1c731d5f03f3ecd661b7c5501f8c954",
+	}
+	budi := model.User{ID: 2, Username: "budi", Email: "budi@mail.com", KYCLevel: model.KYCLevelUnverified}
+	budiWallet := model.CheckBalanceRequest{UserID: budi.ID, Address: utils.GenerateEncryptedAddress(budi.Username, budi.Email)}
+
+	tests := []struct {
+		name        string
+		args        model.TransferRequest
+		payeeID     int64
+		payeeFrozen bool
+		wantErr     error
+	}{
+		{
+			name:    "positif",
+			args:    model.TransferRequest{Amount: 1000, Recipient: "budi", Address: sender.Address, UserID: sender.UserID},
+			payeeID: 7,
+		},
+		{
+			name:    "positif: recipient wallet locked first",
+			args:    model.TransferRequest{Amount: 1000, Recipient: "budi", Address: sender.Address, UserID: sender.UserID},
+			payeeID: 2,
+		},
+		{
+			name:    "negatif: unknown recipient",
+			args:    model.TransferRequest{Amount: 1000, Recipient: "nobody", Address: sender.Address, UserID: sender.UserID},
+			payeeID: 7,
+			wantErr: cs.ErrNotFound,
+		},
+		{
+			name:    "negatif: insufficient balance",
+			args:    model.TransferRequest{Amount: 10000, Recipient: "budi", Address: sender.Address, UserID: sender.UserID},
+			payeeID: 7,
+			wantErr: cs.ErrInsufficientBalance,
+		},
+		{
+			name:        "negatif: recipient wallet frozen",
+			args:        model.TransferRequest{Amount: 1000, Recipient: "budi", Address: sender.Address, UserID: sender.UserID},
+			payeeID:     7,
+			payeeFrozen: true,
+			wantErr:     cs.ErrWalletFrozen,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			if tt.wantErr != nil {
+				mockDB.ExpectRollback()
+			} else {
+				mockDB.ExpectCommit()
+			}
+
+			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+
+			wallet := model.Wallet{ID: 5, Balance: 5000, Address: sender.Address, UserID: sender.UserID}
+			payee := model.Wallet{ID: tt.payeeID, Balance: 0, Address: budiWallet.Address, UserID: budi.ID}
+			if tt.payeeFrozen {
+				payee.Status = model.WalletStatusFrozen
+				payee.BlockCredits = true
+			}
+
+			var locked []int64
+
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "budi", "budi").Return(&budi, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "nobody", "nobody").Return(nil, nil)
+			mockUserRepo.On("ReadByID", ctx, sender.UserID).Return(&model.User{ID: sender.UserID, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockUserRepo.On("ReadByID", ctx, budi.ID).Return(&budi, nil)
+
+			mockRepo.On("ReadBalance", ctx, sender).Return(&wallet, nil)
+			mockRepo.On("ReadBalance", ctx, budiWallet).Return(&model.Wallet{ID: payee.ID, UserID: budi.ID}, nil)
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("ReadBalanceForUpdate", ctx, tx, sender).Run(func(mock.Arguments) {
+				locked = append(locked, wallet.ID)
+			}).Return(&wallet, nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, payee.ID).Run(func(mock.Arguments) {
+				locked = append(locked, payee.ID)
+			}).Return(&payee, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(float64(0), nil)
+			mockRepo.On("SumTransactionVolume", ctx, tx, mock.Anything, mock.Anything).Return(float64(0), nil)
+			mockLimitRepo.On("ReadActiveRules", ctx, mock.Anything, sender.UserID).Return([]model.LimitRule{}, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == wallet.ID && trx.Type == model.TransactionTypeTransfer && trx.Amount == -tt.args.Amount
+			})).Return(int64(1), nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == payee.ID && trx.Type == model.TransactionTypeTransferReceived && trx.Amount == tt.args.Amount
+			})).Return(int64(2), nil)
+
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mocks.RiskRepository{}, &mockHoldRepo, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			if err := s.Transfer(ctx, tt.args); err != tt.wantErr {
+				t.Errorf("walletService.Transfer() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if wallet.Balance != 5000-tt.args.Amount || payee.Balance != tt.args.Amount {
+				t.Errorf("walletService.Transfer() balances = %v, %v", wallet.Balance, payee.Balance)
+			}
+			if len(locked) != 2 || locked[0] > locked[1] {
+				t.Errorf("walletService.Transfer() locked wallets %v, want id order", locked)
+			}
+		})
+	}
+}
+
+func Test_walletService_FundEscrow(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	buyer := model.CheckBalanceRequest{UserID: 1, Address: "addressx"}
+
+	tests := []struct {
+		name        string
+		amount      float64
+		timeout     string
+		wantTimeout string
+		// escrowOwner is the user the escrow wallet belongs to, if any.
+		escrowOwner int64
+		decision    string
+		wantErr     error
+		wantHeld    bool
+	}{
+		{
+			name:        "positif: releases on timeout by default",
+			amount:      3000,
+			wantTimeout: model.EscrowActionRelease,
+		},
+		{
+			name:        "positif: refunds on timeout",
+			amount:      3000,
+			timeout:     model.EscrowActionRefund,
+			wantTimeout: model.EscrowActionRefund,
+		},
+		{
+			name:    "negatif: insufficient balance",
+			amount:  6000,
+			wantErr: cs.ErrInsufficientBalance,
+		},
+		{
+			name:        "negatif: escrow wallet belongs to a customer",
+			amount:      3000,
+			escrowOwner: 2,
+			wantErr:     cs.ErrEscrowWalletNotSet,
+		},
+		{
+			name:     "negatif: denied",
+			amount:   3000,
+			decision: model.RiskDecisionDeny,
+			wantErr:  cs.ErrTransactionDenied,
+		},
+		{
+			name:     "negatif: held for review",
+			amount:   3000,
+			decision: model.RiskDecisionReview,
+			wantHeld: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := tt.decision
+			if decision == "" {
+				decision = model.RiskDecisionAllow
+			}
+
+			var tx *sql.Tx
+			if decision == model.RiskDecisionAllow {
+				tx = beginTx(db, mockDB)
+				if tt.wantErr != nil {
+					mockDB.ExpectRollback()
+				} else {
+					mockDB.ExpectCommit()
+				}
+			}
+
+			mockRepo := mocks.WalletRepository{}
+			mockRiskRepo := mocks.RiskRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+			mockEscrowRepo := mocks.EscrowRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+
+			wallet := model.Wallet{ID: 3, Balance: 5000, Address: buyer.Address, UserID: buyer.UserID}
+			escrowWallet := model.Wallet{ID: 2, Balance: 0, UserID: tt.escrowOwner}
+
+			mockMerchantRepo.On("ReadMerchantByID", ctx, int64(8)).Return(&model.Merchant{ID: 8, UserID: 5, WalletID: 20, Status: model.MerchantStatusActive}, nil)
+			mockRepo.On("ReadBalance", ctx, buyer).Return(&wallet, nil)
+			mockRiskRepo.On("WriteHeldTransaction", ctx, mock.MatchedBy(func(h model.HeldTransaction) bool {
+				return h.Operation == model.TransactionTypeEscrowFund && h.WalletID == wallet.ID && h.Amount == tt.amount
+			})).Return(int64(9), nil)
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("ReadBalanceForUpdate", ctx, tx, buyer).Return(&wallet, nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, escrowWallet.ID).Return(&escrowWallet, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(float64(0), nil)
+			mockUserRepo.On("ReadByID", ctx, buyer.UserID).Return(&model.User{ID: buyer.UserID, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockRepo.On("SumTransactionVolume", ctx, tx, wallet.ID, mock.Anything).Return(float64(0), nil)
+			mockLimitRepo.On("ReadActiveRules", ctx, mock.Anything, buyer.UserID).Return([]model.LimitRule{}, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == wallet.ID && trx.Type == model.TransactionTypeEscrowFund && trx.Amount == -tt.amount
+			})).Return(int64(1), nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == escrowWallet.ID && trx.Type == model.TransactionTypeEscrowFund && trx.Amount == tt.amount
+			})).Return(int64(2), nil)
+			mockEscrowRepo.On("WriteEscrow", ctx, tx, mock.MatchedBy(func(e model.Escrow) bool {
+				return e.BuyerWalletID == wallet.ID && e.EscrowWalletID == escrowWallet.ID && e.Status == model.EscrowStatusFunded && e.TimeoutAction == tt.wantTimeout
+			})).Return(int64(4), nil)
+			mockEscrowRepo.On("WriteEscrowEvent", ctx, tx, mock.MatchedBy(func(e model.EscrowEvent) bool {
+				return e.EscrowID == 4 && e.FromStatus == "" && e.ToStatus == model.EscrowStatusFunded && *e.TransactionID == 1
+			})).Return(int64(1), nil)
+
+			riskEngine := staticRiskEngine{assessment: model.RiskAssessment{Decision: decision}}
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mockRiskRepo, &mockHoldRepo, &mocks.RefundRepository{}, &mockMerchantRepo, &mocks.PaymentRequestRepository{}, &mockEscrowRepo, NewLimitService(&mockLimitRepo), noFeeService(), riskEngine, escrowWallet.ID, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.FundEscrow(ctx, model.EscrowRequest{MerchantID: 8, Amount: tt.amount, TimeoutAction: tt.timeout, Address: buyer.Address, UserID: buyer.UserID})
+
+			var heldErr *model.TransactionHeldError
+			if tt.wantHeld {
+				if !errors.As(err, &heldErr) {
+					t.Fatalf("walletService.FundEscrow() error = %v, want held", err)
+				}
+				mockEscrowRepo.AssertNotCalled(t, "WriteEscrow", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if err != tt.wantErr {
+				t.Fatalf("walletService.FundEscrow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.ID != 4 || wallet.Balance != 5000-tt.amount || escrowWallet.Balance != tt.amount {
+				t.Errorf("walletService.FundEscrow() escrow %d, balances = %v, %v", got.ID, wallet.Balance, escrowWallet.Balance)
+			}
+		})
+	}
+}
+
+func Test_walletService_resolveEscrow(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+
+	var (
+		buyer    = model.User{ID: 1, Role: model.RoleUser}
+		seller   = model.User{ID: 5, Role: model.RoleUser}
+		support  = model.User{ID: 9, Role: model.RoleSupport}
+		stranger = model.User{ID: 99, Role: model.RoleUser}
+	)
+
+	tests := []struct {
+		name       string
+		release    bool
+		status     string
+		requester  model.User
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name:       "positif: released by buyer",
+			release:    true,
+			status:     model.EscrowStatusFunded,
+			requester:  buyer,
+			wantStatus: model.EscrowStatusReleased,
+		},
+		{
+			name:       "positif: refunded by seller",
+			status:     model.EscrowStatusFunded,
+			requester:  seller,
+			wantStatus: model.EscrowStatusRefunded,
+		},
+		{
+			name:       "positif: dispute released by staff",
+			release:    true,
+			status:     model.EscrowStatusDisputed,
+			requester:  support,
+			wantStatus: model.EscrowStatusReleased,
+		},
+		{
+			name:      "negatif: released by seller",
+			release:   true,
+			status:    model.EscrowStatusFunded,
+			requester: seller,
+			wantErr:   cs.ErrForbidden,
+		},
+		{
+			name:      "negatif: dispute refunded by seller",
+			status:    model.EscrowStatusDisputed,
+			requester: seller,
+			wantErr:   cs.ErrForbidden,
+		},
+		{
+			name:      "negatif: already released",
+			status:    model.EscrowStatusReleased,
+			requester: seller,
+			wantErr:   cs.ErrEscrowNotFunded,
+		},
+		{
+			name:      "negatif: not a party",
+			release:   true,
+			status:    model.EscrowStatusFunded,
+			requester: stranger,
+			wantErr:   cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			if tt.wantErr != nil {
+				mockDB.ExpectRollback()
+			} else {
+				mockDB.ExpectCommit()
+			}
+
+			mockRepo := mocks.WalletRepository{}
+			mockMerchantRepo := mocks.MerchantRepository{}
+			mockEscrowRepo := mocks.EscrowRepository{}
+
+			wallet := model.Wallet{ID: 3, Balance: 2000, UserID: buyer.ID}
+			escrowWallet := model.Wallet{ID: 2, Balance: 3000}
+			settlement := model.Wallet{ID: 20, Balance: 0, UserID: seller.ID}
+			escrow := model.Escrow{ID: 4, BuyerID: buyer.ID, BuyerWalletID: wallet.ID, MerchantID: 8, EscrowWalletID: escrowWallet.ID, Amount: 3000, Status: tt.status}
+
+			payee, credited := &settlement, model.TransactionTypeEscrowRelease
+			if !tt.release {
+				payee, credited = &wallet, model.TransactionTypeEscrowRefund
+			}
+
+			mockEscrowRepo.On("ReadEscrowByID", ctx, escrow.ID).Return(&escrow, nil)
+			mockMerchantRepo.On("ReadMerchantByID", ctx, escrow.MerchantID).Return(&model.Merchant{ID: 8, UserID: seller.ID, WalletID: settlement.ID, Status: model.MerchantStatusActive}, nil)
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, wallet.ID).Return(&wallet, nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, escrowWallet.ID).Return(&escrowWallet, nil)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, settlement.ID).Return(&settlement, nil)
+			mockEscrowRepo.On("ReadEscrowForUpdate", ctx, tx, escrow.ID).Return(&escrow, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == escrowWallet.ID && trx.Type == credited && trx.Amount == -escrow.Amount
+			})).Return(int64(1), nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == payee.ID && trx.Type == credited && trx.Amount == escrow.Amount
+			})).Return(int64(2), nil)
+			mockEscrowRepo.On("UpdateEscrowStatus", ctx, tx, mock.MatchedBy(func(e model.Escrow) bool {
+				return e.Status == tt.wantStatus && e.ResolvedAt != nil
+			})).Return(nil)
+			mockEscrowRepo.On("WriteEscrowEvent", ctx, tx, mock.MatchedBy(func(e model.EscrowEvent) bool {
+				return e.FromStatus == tt.status && e.ToStatus == tt.wantStatus && *e.ActorID == tt.requester.ID && *e.TransactionID == 2
+			})).Return(int64(2), nil)
+
+			s := NewWalletService(&mockRepo, &mocks.UserRepository{}, &mocks.RiskRepository{}, &mocks.HoldRepository{}, &mocks.RefundRepository{}, &mockMerchantRepo, &mocks.PaymentRequestRepository{}, &mockEscrowRepo, NewLimitService(&mocks.LimitRepository{}), noFeeService(), allowRiskEngine(), escrowWallet.ID, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+
+			resolve := s.RefundEscrow
+			if tt.release {
+				resolve = s.ReleaseEscrow
+			}
+
+			payeeBalance := payee.Balance
+
+			got, err := resolve(ctx, escrow.ID, model.EscrowActionRequest{Requester: tt.requester})
+			if err != tt.wantErr {
+				t.Fatalf("walletService.resolveEscrow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.Status != tt.wantStatus || escrowWallet.Balance != 0 || payee.Balance != payeeBalance+escrow.Amount {
+				t.Errorf("walletService.resolveEscrow() status = %s, balances = %v, %v", got.Status, escrowWallet.Balance, payee.Balance)
+			}
+		})
+	}
+}
+
+func Test_walletService_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	wallets := []model.Wallet{{ID: 3, UserID: 1, Address: "address", Balance: 1000}}
+
+	tests := []struct {
+		name        string
+		lastEventID func(bus *event.Bus) int64
+		wantReplay  []string
+	}{
+		{
+			name:        "positif: snapshot on first connection",
+			lastEventID: func(bus *event.Bus) int64 { return 0 },
+			wantReplay:  []string{event.TypeBalance},
+		},
+		{
+			name: "positif: replay after reconnecting",
+			lastEventID: func(bus *event.Bus) int64 {
+				last := bus.Subscribe(1, 0)
+				last.Close()
+				bus.Publish(1, event.TypeTransaction, model.Transaction{ID: 7})
+				bus.Publish(1, event.TypeBalance, model.BalanceChange{WalletID: 3, TransactionID: 7})
+				return last.LastID
+			},
+			wantReplay: []string{event.TypeTransaction, event.TypeBalance},
+		},
+		{
+			name:        "positif: snapshot when events were missed",
+			lastEventID: func(bus *event.Bus) int64 { return 1 },
+			wantReplay:  []string{event.TypeBalance},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.WalletRepository{}
+			mockRepo.On("ReadByUserID", ctx, int64(1), model.WalletKindPersonal).Return(wallets, nil)
+
+			bus := event.NewBus(16)
+			lastEventID := tt.lastEventID(bus)
+
+			s := NewWalletService(&mockRepo, &mocks.UserRepository{}, &mocks.RiskRepository{}, &mocks.HoldRepository{}, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mocks.LimitRepository{}), noFeeService(), allowRiskEngine(), 0, bus, noWebhooks{}, noEvents{}, noAudit{})
+			sub, err := s.Subscribe(ctx, 1, lastEventID)
+			if err != nil {
+				t.Fatalf("walletService.Subscribe() error = %v", err)
+			}
+			defer sub.Close()
+
+			var got []string
+			for _, e := range sub.Replay {
+				got = append(got, e.Type)
+			}
+			if !reflect.DeepEqual(got, tt.wantReplay) {
+				t.Errorf("walletService.Subscribe() replayed %v, want %v", got, tt.wantReplay)
+			}
+		})
+	}
+}
+
+func Test_checkWalletStatus(t *testing.T) {
+	var (
+		payment  = model.Transaction{Type: model.TransactionTypePayment, Amount: -1000}
+		topUp    = model.Transaction{Type: model.TransactionTypeTopUp, Amount: 1000}
+		reversal = model.Transaction{Type: model.TransactionTypeWithdrawalReversal, Amount: 1000}
+	)
+
+	tests := []struct {
+		name    string
+		wallet  model.Wallet
+		entry   model.Transaction
+		wantErr error
+	}{
+		{
+			name:   "positif: active",
+			wallet: model.Wallet{Status: model.WalletStatusActive},
+			entry:  payment,
+		},
+		{
+			name:   "positif: credit to frozen wallet",
+			wallet: model.Wallet{Status: model.WalletStatusFrozen},
+			entry:  topUp,
+		},
+		{
+			name:   "positif: reversal to frozen wallet blocking credits",
+			wallet: model.Wallet{Status: model.WalletStatusFrozen, BlockCredits: true},
+			entry:  reversal,
+		},
+		{
+			name:    "negatif: debit from frozen wallet",
+			wallet:  model.Wallet{Status: model.WalletStatusFrozen},
+			entry:   payment,
+			wantErr: cs.ErrWalletFrozen,
+		},
+		{
+			name:    "negatif: credit to frozen wallet blocking credits",
+			wallet:  model.Wallet{Status: model.WalletStatusFrozen, BlockCredits: true},
+			entry:   topUp,
+			wantErr: cs.ErrWalletFrozen,
+		},
+		{
+			name:    "negatif: closed",
+			wallet:  model.Wallet{Status: model.WalletStatusClosed},
+			entry:   reversal,
+			wantErr: cs.ErrWalletClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkWalletStatus(&tt.wallet, tt.entry); err != tt.wantErr {
+				t.Errorf("checkWalletStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_walletService_ChangeStatus(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	admin := model.User{ID: 9, Role: model.RoleAdmin}
+
+	tests := []struct {
+		name     string
+		wallet   model.Wallet
+		held     float64
+		req      model.WalletStatusRequest
+		unfreeze bool
+		wantErr  error
+	}{
+		{
+			name:   "positif: freeze",
+			wallet: model.Wallet{ID: 5, UserID: 1, Balance: 5000, Status: model.WalletStatusActive},
+			req:    model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusFrozen, Reason: model.WalletReasonFraudSuspected, BlockCredits: true, Actor: admin},
+		},
+		{
+			name:     "positif: unfreeze",
+			wallet:   model.Wallet{ID: 5, UserID: 1, Balance: 5000, Status: model.WalletStatusFrozen},
+			req:      model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusActive, Reason: model.WalletReasonResolved, BlockCredits: true, Actor: admin},
+			unfreeze: true,
+		},
+		{
+			name:   "positif: close empty wallet",
+			wallet: model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusFrozen},
+			req:    model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusClosed, Reason: model.WalletReasonCustomerRequest, Actor: admin},
+		},
+		{
+			name:    "negatif: unfreeze without approval",
+			wallet:  model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusFrozen},
+			req:     model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusActive, Reason: model.WalletReasonResolved, Actor: admin},
+			wantErr: cs.ErrApprovalRequired,
+		},
+		{
+			name:     "negatif: unfreeze active wallet",
+			wallet:   model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusActive},
+			req:      model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusActive, Reason: model.WalletReasonResolved, Actor: admin},
+			unfreeze: true,
+			wantErr:  cs.ErrWalletStatusTransition,
+		},
+		{
+			name:     "positif: allow credits into frozen wallet",
+			wallet:   model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusFrozen, BlockCredits: true},
+			req:      model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusFrozen, Reason: model.WalletReasonResolved, Actor: admin},
+			unfreeze: true,
+		},
+		{
+			name:   "positif: block credits into frozen wallet",
+			wallet: model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusFrozen},
+			req:    model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusFrozen, Reason: model.WalletReasonLegalOrder, BlockCredits: true, Actor: admin},
+		},
+		{
+			name:    "negatif: allow credits into frozen wallet without approval",
+			wallet:  model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusFrozen, BlockCredits: true},
+			req:     model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusFrozen, Reason: model.WalletReasonResolved, Actor: admin},
+			wantErr: cs.ErrApprovalRequired,
+		},
+		{
+			name:    "negatif: freeze frozen wallet the same way",
+			wallet:  model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusFrozen},
+			req:     model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusFrozen, Reason: model.WalletReasonLegalOrder, Actor: admin},
+			wantErr: cs.ErrWalletStatusTransition,
+		},
+		{
+			name:     "negatif: reopen closed wallet",
+			wallet:   model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusClosed},
+			req:      model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusActive, Reason: model.WalletReasonOther, Actor: admin},
+			unfreeze: true,
+			wantErr:  cs.ErrWalletStatusTransition,
+		},
+		{
+			name:    "negatif: close wallet with balance",
+			wallet:  model.Wallet{ID: 5, UserID: 1, Balance: 5000, Status: model.WalletStatusActive},
+			req:     model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusClosed, Reason: model.WalletReasonCustomerRequest, Actor: admin},
+			wantErr: cs.ErrWalletNotEmpty,
+		},
+		{
+			name:    "negatif: close wallet with active hold",
+			wallet:  model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusActive},
+			held:    1000,
+			req:     model.WalletStatusRequest{WalletID: 5, Status: model.WalletStatusClosed, Reason: model.WalletReasonCustomerRequest, Actor: admin},
+			wantErr: cs.ErrWalletNotEmpty,
+		},
+		{
+			name:    "negatif: system wallet",
+			wallet:  model.Wallet{ID: 1, Status: model.WalletStatusActive},
+			req:     model.WalletStatusRequest{WalletID: 1, Status: model.WalletStatusFrozen, Reason: model.WalletReasonOther, Actor: admin},
+			wantErr: cs.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			if tt.wantErr != nil {
+				mockDB.ExpectRollback()
+			} else {
+				mockDB.ExpectCommit()
+			}
+
+			mockRepo := mocks.WalletRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+
+			wallet := tt.wallet
+			var recorded model.WalletStatusChange
+
+			mockRepo.On("BeginTx", ctx).Return(tx)
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, tt.req.WalletID).Return(&wallet, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(tt.held, nil)
+			mockRepo.On("UpdateStatus", ctx, tx, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				recorded = args.Get(3).(model.WalletStatusChange)
+			}).Return(nil)
+
+			s := NewWalletService(&mockRepo, &mocks.UserRepository{}, &mocks.RiskRepository{}, &mockHoldRepo, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mocks.LimitRepository{}), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+
+			var (
+				got *model.Wallet
+				err error
+			)
+			// Changes lifting restrictions run in the transaction of their
+			// approval, and are refused without one.
+			if tt.unfreeze {
+				got, err = s.Unfreeze(ctx, tx, tt.req)
+				if err != nil {
+					tx.Rollback()
+				} else {
+					tx.Commit()
+				}
+			} else {
+				got, err = s.ChangeStatus(ctx, tt.req)
+			}
+			if err != tt.wantErr {
+				t.Fatalf("walletService.ChangeStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				mockRepo.AssertNotCalled(t, "UpdateStatus", ctx, tx, mock.Anything, mock.Anything)
+				return
+			}
+
+			wantBlockCredits := tt.req.Status == model.WalletStatusFrozen && tt.req.BlockCredits
+			if got.Status != tt.req.Status || got.BlockCredits != wantBlockCredits {
+				t.Errorf("walletService.ChangeStatus() = %+v", got)
+			}
+			if recorded.FromStatus != tt.wallet.Status || recorded.ToStatus != tt.req.Status || recorded.BlockCredits != wantBlockCredits || recorded.Reason != tt.req.Reason || recorded.ChangedBy != admin.ID {
+				t.Errorf("walletService.ChangeStatus() recorded %+v", recorded)
+			}
+		})
+	}
+}
+
+func Test_walletService_ResolveTopUp(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	admin := model.User{ID: 9, Role: model.RoleAdmin}
+	budi := model.CheckBalanceRequest{UserID: 1, Address: utils.GenerateEncryptedAddress("budi", "budi@mail.com")}
+
+	tests := []struct {
+		name       string
+		req        model.TopUpRequest
+		wantWallet int64
+		wantErr    error
+	}{
+		{
+			name:       "positif: own wallet",
+			req:        model.TopUpRequest{Nominal: 1000, UserID: 9, Address: "admin-address", Actor: admin},
+			wantWallet: 7,
+		},
+		{
+			name:       "positif: another user",
+			req:        model.TopUpRequest{Nominal: 1000, Recipient: "budi", UserID: 9, Address: "admin-address", Actor: admin},
+			wantWallet: 3,
+		},
+		{
+			name:    "negatif: unknown recipient",
+			req:     model.TopUpRequest{Nominal: 1000, Recipient: "nobody", UserID: 9, Address: "admin-address", Actor: admin},
+			wantErr: cs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+
+			if tt.wantErr == nil {
+				tx := beginTx(db, mockDB)
+				mockDB.ExpectCommit()
+				mockRepo.On("BeginTx", ctx).Return(tx)
+				mockRepo.On("ReadBalanceForUpdate", ctx, tx, budi).Return(&model.Wallet{ID: 3, UserID: 1, Address: budi.Address}, nil)
+				mockRepo.On("ReadBalanceForUpdate", ctx, tx, model.CheckBalanceRequest{UserID: 9, Address: "admin-address"}).Return(&model.Wallet{ID: 7, UserID: 9}, nil)
+				mockRepo.On("SumTransactionVolume", ctx, tx, mock.Anything, mock.Anything).Return(float64(0), nil)
+			}
+
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "budi", "budi").Return(&model.User{ID: 1, Username: "budi", Email: "budi@mail.com"}, nil)
+			mockUserRepo.On("ReadByUsernameOrEmail", ctx, "nobody", "nobody").Return(nil, nil)
+			mockUserRepo.On("ReadByID", ctx, mock.Anything).Return(&model.User{KYCLevel: model.KYCLevelUnverified}, nil)
+			mockLimitRepo.On("ReadActiveRules", ctx, mock.Anything, mock.Anything).Return([]model.LimitRule{}, nil)
+
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mocks.RiskRepository{}, &mocks.HoldRepository{}, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.ResolveTopUp(ctx, tt.req)
+			if err != tt.wantErr {
+				t.Fatalf("walletService.ResolveTopUp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.WalletID != tt.wantWallet || got.Nominal != tt.req.Nominal {
+				t.Errorf("walletService.ResolveTopUp() = %+v, want wallet %v", got, tt.wantWallet)
+			}
+
+			mockRepo.AssertNotCalled(t, "UpdateBalance", ctx, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func Test_walletService_TopUp(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	admin := model.User{ID: 9, Role: model.RoleAdmin}
+
+	tests := []struct {
+		name    string
+		wallet  model.Wallet
+		req     model.TopUpRequest
+		wantErr error
+	}{
+		{
+			name:   "positif",
+			wallet: model.Wallet{ID: 3, UserID: 1, Balance: 1000},
+			req:    model.TopUpRequest{WalletID: 3, Nominal: 1000, Actor: admin},
+		},
+		{
+			name:    "negatif: closed meanwhile",
+			wallet:  model.Wallet{ID: 3, UserID: 1, Status: model.WalletStatusClosed},
+			req:     model.TopUpRequest{WalletID: 3, Nominal: 1000, Actor: admin},
+			wantErr: cs.ErrWalletClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			mockDB.ExpectRollback()
+			defer tx.Rollback()
+
+			mockRepo := mocks.WalletRepository{}
+			mockUserRepo := mocks.UserRepository{}
+			mockLimitRepo := mocks.LimitRepository{}
+
+			wallet := tt.wallet
+
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, tt.req.WalletID).Return(&wallet, nil)
+			mockRepo.On("SumTransactionVolume", ctx, tx, wallet.ID, mock.Anything).Return(float64(0), nil)
+			mockUserRepo.On("ReadByID", ctx, wallet.UserID).Return(&model.User{ID: wallet.UserID, KYCLevel: model.KYCLevelUnverified}, nil)
+			mockLimitRepo.On("ReadActiveRules", ctx, mock.Anything, wallet.UserID).Return([]model.LimitRule{}, nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.WalletID == wallet.ID && trx.Type == model.TransactionTypeTopUp && trx.Amount == tt.req.Nominal
+			})).Return(int64(11), nil)
+
+			s := NewWalletService(&mockRepo, &mockUserRepo, &mocks.RiskRepository{}, &mocks.HoldRepository{}, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mockLimitRepo), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.TopUp(ctx, tx, tt.req)
+			if err != tt.wantErr {
+				t.Fatalf("walletService.TopUp() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				mockRepo.AssertNotCalled(t, "WriteTransaction", ctx, tx, mock.Anything)
+				return
+			}
+			if got.ID != 11 || wallet.Balance != tt.wallet.Balance+tt.req.Nominal {
+				t.Errorf("walletService.TopUp() = %+v, balance %v", got, wallet.Balance)
+			}
+		})
+	}
+}
+
+func Test_walletService_Adjust(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	admin := model.User{ID: 9, Role: model.RoleAdmin}
+
+	tests := []struct {
+		name    string
+		wallet  model.Wallet
+		req     model.AdjustmentRequest
+		wantErr error
+	}{
+		{
+			name:   "positif: credit",
+			wallet: model.Wallet{ID: 5, UserID: 1, Balance: 1000},
+			req:    model.AdjustmentRequest{WalletID: 5, Amount: 500, Reason: "missed top-up", Actor: admin},
+		},
+		{
+			name:   "positif: debit",
+			wallet: model.Wallet{ID: 5, UserID: 1, Balance: 1000},
+			req:    model.AdjustmentRequest{WalletID: 5, Amount: -1000, Reason: "duplicate top-up", Actor: admin},
+		},
+		{
+			name:    "negatif: debit more than available",
+			wallet:  model.Wallet{ID: 5, UserID: 1, Balance: 1000},
+			req:     model.AdjustmentRequest{WalletID: 5, Amount: -1500, Reason: "duplicate top-up", Actor: admin},
+			wantErr: cs.ErrInsufficientBalance,
+		},
+		{
+			name:    "negatif: closed wallet",
+			wallet:  model.Wallet{ID: 5, UserID: 1, Status: model.WalletStatusClosed},
+			req:     model.AdjustmentRequest{WalletID: 5, Amount: 500, Reason: "missed top-up", Actor: admin},
+			wantErr: cs.ErrWalletClosed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := beginTx(db, mockDB)
+			mockDB.ExpectRollback()
+			defer tx.Rollback()
+
+			mockRepo := mocks.WalletRepository{}
+			mockHoldRepo := mocks.HoldRepository{}
+
+			wallet := tt.wallet
+
+			mockRepo.On("ReadByIDForUpdate", ctx, tx, tt.req.WalletID).Return(&wallet, nil)
+			mockHoldRepo.On("SumActiveHolds", ctx, wallet.ID, mock.Anything).Return(float64(0), nil)
+			mockRepo.On("UpdateBalance", ctx, tx, mock.Anything).Return(nil)
+			mockRepo.On("WriteTransaction", ctx, tx, mock.MatchedBy(func(trx model.Transaction) bool {
+				return trx.Type == model.TransactionTypeAdjustment && trx.Amount == tt.req.Amount && trx.Description == tt.req.Reason
+			})).Return(int64(11), nil)
+			mockRepo.On("WriteAdjustment", ctx, tx, model.Adjustment{WalletID: 5, TransactionID: 11, Amount: tt.req.Amount, Reason: tt.req.Reason, AdjustedBy: admin.ID}).Return(int64(1), nil)
+
+			s := NewWalletService(&mockRepo, &mocks.UserRepository{}, &mocks.RiskRepository{}, &mockHoldRepo, &mocks.RefundRepository{}, &mocks.MerchantRepository{}, &mocks.PaymentRequestRepository{}, &mocks.EscrowRepository{}, NewLimitService(&mocks.LimitRepository{}), noFeeService(), allowRiskEngine(), 0, event.NewBus(0), noWebhooks{}, noEvents{}, noAudit{})
+			got, err := s.Adjust(ctx, tx, tt.req)
+			if err != tt.wantErr {
+				t.Fatalf("walletService.Adjust() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				mockRepo.AssertNotCalled(t, "WriteAdjustment", ctx, tx, mock.Anything)
+				return
+			}
+			if got.ID != 11 || wallet.Balance != tt.wallet.Balance+tt.req.Amount {
+				t.Errorf("walletService.Adjust() = %+v, balance %v", got, wallet.Balance)
 			}
 		})
 	}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/repository"
+	"github.com/cecepsprd/starworks-test/internal/webhook"
+	"github.com/cecepsprd/starworks-test/utils"
+	"github.com/cecepsprd/starworks-test/utils/logger"
+)
+
+const (
+	// webhookBatchSize is how many due deliveries one pass of Deliver
+	// picks up.
+	webhookBatchSize = 100
+	// webhookDeliveryLogSize is how many of a subscription's latest
+	// deliveries are listed.
+	webhookDeliveryLogSize = 100
+	// webhookMaxAttempts is how many times a delivery is attempted before
+	// it is given up on as dead.
+	webhookMaxAttempts = 8
+	// webhookRetryBackoff is the wait after the first failed attempt,
+	// doubled after each further one.
+	webhookRetryBackoff = 30 * time.Second
+	// webhookLease is how long a worker has to attempt a delivery it
+	// claimed before another worker may pick it up.
+	webhookLease = time.Minute
+)
+
+// WebhookDispatcher queues webhook deliveries for an event in the caller's
+// transaction, so they are only sent if the change they report is
+// committed.
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context, tx *sql.Tx, userID int64, eventType string, data interface{}) error
+}
+
+type webhookService struct {
+	repo   repository.WebhookRepository
+	sender webhook.Sender
+}
+
+type WebhookService interface {
+	WebhookDispatcher
+	CreateSubscription(ctx context.Context, req model.WebhookSubscriptionRequest) (*model.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, userID int64) ([]model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subID, userID int64) error
+	ListDeliveries(ctx context.Context, subID, userID int64) ([]model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID, userID int64) (*model.WebhookDelivery, error)
+	Deliver(ctx context.Context) (int64, error)
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, sender webhook.Sender) WebhookService {
+	return &webhookService{
+		repo:   webhookRepo,
+		sender: sender,
+	}
+}
+
+// CreateSubscription subscribes the requester to events of the given
+// types. Only admins may subscribe to every user's events. The signing
+// secret is generated and returned this once.
+func (s *webhookService) CreateSubscription(ctx context.Context, req model.WebhookSubscriptionRequest) (*model.WebhookSubscription, error) {
+	if req.AllUsers && req.Requester.Role != model.RoleAdmin {
+		return nil, cs.ErrForbidden
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	sub := model.WebhookSubscription{
+		UserID:     req.Requester.ID,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		AllUsers:   req.AllUsers,
+		Secret:     hex.EncodeToString(secret),
+		Active:     true,
+		CreatedAt:  time.Now(),
+	}
+
+	var err error
+	sub.ID, err = s.repo.WriteSubscription(ctx, sub)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context, userID int64) ([]model.WebhookSubscription, error) {
+	subs, err := s.repo.ReadSubscriptionsByUser(ctx, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+
+	return subs, nil
+}
+
+// DeleteSubscription stops a subscription. Deliveries already queued for it
+// are given up on when they come due.
+func (s *webhookService) DeleteSubscription(ctx context.Context, subID, userID int64) error {
+	if err := s.repo.DeactivateSubscription(ctx, subID, userID); err == sql.ErrNoRows {
+		return cs.ErrNotFound
+	} else if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the latest deliveries of one of the user's
+// subscriptions, newest first.
+func (s *webhookService) ListDeliveries(ctx context.Context, subID, userID int64) ([]model.WebhookDelivery, error) {
+	if _, err := s.subscription(ctx, subID, userID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.repo.ReadDeliveriesBySubscription(ctx, subID, webhookDeliveryLogSize)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver sends a delivery of one of the user's active subscriptions
+// again straight away, whatever became of it before. If it fails, it is
+// retried like a new delivery.
+func (s *webhookService) Redeliver(ctx context.Context, deliveryID, userID int64) (*model.WebhookDelivery, error) {
+	delivery, err := s.repo.ReadDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if delivery == nil {
+		return nil, cs.ErrNotFound
+	}
+
+	sub, err := s.subscription(ctx, delivery.SubscriptionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !sub.Active {
+		return nil, cs.ErrNotFound
+	}
+
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.DeliveredAt = nil
+
+	return s.attempt(ctx, *delivery, *sub)
+}
+
+// Dispatch queues a delivery of the event to every subscription it is for.
+func (s *webhookService) Dispatch(ctx context.Context, tx *sql.Tx, userID int64, eventType string, data interface{}) error {
+	subs, err := s.repo.ReadSubscriptionsForEvent(ctx, tx, eventType, userID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	if len(subs) == 0 {
+		return nil
+	}
+
+	evt := model.WebhookEvent{
+		ID:        utils.GenerateReference(),
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		Data:      data,
+	}
+
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return err
+	}
+
+	for _, sub := range subs {
+		_, err = s.repo.WriteDelivery(ctx, tx, model.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        evt.ID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  evt.CreatedAt,
+		})
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deliver attempts the deliveries that are due and returns how many were
+// accepted. Deliveries another worker claimed first are skipped.
+func (s *webhookService) Deliver(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	due, err := s.repo.ReadDueDeliveries(ctx, now, webhookBatchSize)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return 0, err
+	}
+
+	var delivered int64
+	for _, delivery := range due {
+		if err = s.repo.ClaimDelivery(ctx, delivery, now, now.Add(webhookLease)); err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			logger.Log.Error(err.Error())
+			return delivered, err
+		}
+
+		sub, err := s.repo.ReadSubscriptionByID(ctx, delivery.SubscriptionID)
+		if err != nil {
+			logger.Log.Error(err.Error())
+			return delivered, err
+		}
+
+		if sub == nil || !sub.Active {
+			delivery.Status = model.WebhookDeliveryDead
+			delivery.LastError = "subscription deleted"
+			if err = s.repo.UpdateDelivery(ctx, delivery); err != nil {
+				logger.Log.Error(err.Error())
+				return delivered, err
+			}
+			continue
+		}
+
+		got, err := s.attempt(ctx, delivery, *sub)
+		if err != nil {
+			return delivered, err
+		}
+
+		if got.Status == model.WebhookDeliverySucceeded {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// subscription returns one of the user's subscriptions, deleted or not.
+func (s *webhookService) subscription(ctx context.Context, subID, userID int64) (*model.WebhookSubscription, error) {
+	sub, err := s.repo.ReadSubscriptionByID(ctx, subID)
+	if err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	if sub == nil || sub.UserID != userID {
+		return nil, cs.ErrNotFound
+	}
+
+	return sub, nil
+}
+
+// attempt sends a delivery and records the outcome. A failed delivery is
+// retried with exponential backoff until webhookMaxAttempts, then given up
+// on as dead. Failing to reach the endpoint is not an error.
+func (s *webhookService) attempt(ctx context.Context, delivery model.WebhookDelivery, sub model.WebhookSubscription) (*model.WebhookDelivery, error) {
+	status, sendErr := s.sender.Send(ctx, webhook.Request{
+		URL:        sub.URL,
+		Secret:     sub.Secret,
+		DeliveryID: delivery.ID,
+		EventType:  delivery.EventType,
+		Body:       delivery.Payload,
+	})
+
+	now := time.Now()
+
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	delivery.LastError = ""
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = model.WebhookDeliveryDead
+	default:
+		delivery.Status = model.WebhookDeliveryPending
+		delivery.NextAttemptAt = now.Add(webhookRetryBackoff << (delivery.Attempts - 1))
+	}
+
+	if sendErr != nil {
+		delivery.LastError = sendErr.Error()
+		if len(delivery.LastError) > 255 {
+			delivery.LastError = delivery.LastError[:255]
+		}
+		logger.Log.Error(fmt.Sprintf("delivering webhook %d: %s", delivery.ID, delivery.LastError))
+	}
+
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Log.Error(err.Error())
+		return nil, err
+	}
+
+	delivery.UpdatedAt = now
+
+	return &delivery, nil
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	cs "github.com/cecepsprd/starworks-test/constans"
+	"github.com/cecepsprd/starworks-test/internal/mocks"
+	"github.com/cecepsprd/starworks-test/internal/model"
+	"github.com/cecepsprd/starworks-test/internal/webhook"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubSender answers every delivery with status and err, recording what it
+// was asked to send.
+type stubSender struct {
+	status int
+	err    error
+	sent   *[]webhook.Request
+}
+
+func (s stubSender) Send(ctx context.Context, req webhook.Request) (int, error) {
+	if s.sent != nil {
+		*s.sent = append(*s.sent, req)
+	}
+	return s.status, s.err
+}
+
+func Test_webhookService_CreateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		req     model.WebhookSubscriptionRequest
+		wantErr error
+	}{
+		{
+			name: "positif",
+			req:  model.WebhookSubscriptionRequest{URL: "https://example.com/hook", EventTypes: []string{model.WebhookEventTopUpCompleted}, Requester: model.User{ID: 1}},
+		},
+		{
+			name: "positif: admin for every user",
+			req:  model.WebhookSubscriptionRequest{URL: "https://example.com/hook", EventTypes: []string{model.WebhookEventUserRegistered}, AllUsers: true, Requester: model.User{ID: 2, Role: model.RoleAdmin}},
+		},
+		{
+			name:    "negatif: every user without admin",
+			req:     model.WebhookSubscriptionRequest{URL: "https://example.com/hook", EventTypes: []string{model.WebhookEventUserRegistered}, AllUsers: true, Requester: model.User{ID: 1}},
+			wantErr: cs.ErrForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.WebhookRepository{}
+			mockRepo.On("WriteSubscription", mock.Anything, mock.Anything).Return(int64(4), nil)
+
+			s := NewWebhookService(&mockRepo, stubSender{})
+
+			got, err := s.CreateSubscription(ctx, tt.req)
+			if err != tt.wantErr {
+				t.Fatalf("webhookService.CreateSubscription() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				mockRepo.AssertNotCalled(t, "WriteSubscription", mock.Anything, mock.Anything)
+				return
+			}
+			if got.ID != 4 || got.UserID != tt.req.Requester.ID || len(got.Secret) != 64 || !got.Active {
+				t.Errorf("webhookService.CreateSubscription() = %+v", got)
+			}
+		})
+	}
+}
+
+func Test_webhookService_Dispatch(t *testing.T) {
+	db, mockDB := dbConn()
+
+	ctx := context.Background()
+	subs := []model.WebhookSubscription{{ID: 1, UserID: 1}, {ID: 2, UserID: 9, AllUsers: true}}
+	data := model.Transaction{ID: 10, WalletID: 3, Amount: 50000}
+
+	mockRepo := mocks.WebhookRepository{}
+	tx := beginTx(db, mockDB)
+
+	mockRepo.On("ReadSubscriptionsForEvent", mock.Anything, tx, model.WebhookEventTopUpCompleted, int64(1)).Return(subs, nil)
+	mockRepo.On("ReadSubscriptionsForEvent", mock.Anything, tx, model.WebhookEventPaymentSent, int64(1)).Return([]model.WebhookSubscription{}, nil)
+
+	var written []model.WebhookDelivery
+	mockRepo.On("WriteDelivery", mock.Anything, tx, mock.Anything).Run(func(args mock.Arguments) {
+		written = append(written, args.Get(2).(model.WebhookDelivery))
+	}).Return(int64(1), nil)
+
+	s := NewWebhookService(&mockRepo, stubSender{})
+
+	if err := s.Dispatch(ctx, tx, 1, model.WebhookEventPaymentSent, data); err != nil || len(written) != 0 {
+		t.Fatalf("webhookService.Dispatch() without subscriptions = %v, wrote %d", err, len(written))
+	}
+
+	if err := s.Dispatch(ctx, tx, 1, model.WebhookEventTopUpCompleted, data); err != nil {
+		t.Fatalf("webhookService.Dispatch() error = %v", err)
+	}
+
+	if len(written) != 2 || written[0].SubscriptionID != 1 || written[1].SubscriptionID != 2 {
+		t.Fatalf("webhookService.Dispatch() wrote %+v", written)
+	}
+
+	var evt struct {
+		ID   string            `json:"id"`
+		Type string            `json:"type"`
+		Data model.Transaction `json:"data"`
+	}
+	if err := json.Unmarshal(written[0].Payload, &evt); err != nil {
+		t.Fatal(err)
+	}
+	if evt.ID != written[0].EventID || evt.ID != written[1].EventID || evt.Type != model.WebhookEventTopUpCompleted || evt.Data.ID != data.ID {
+		t.Errorf("webhookService.Dispatch() payload = %s", written[0].Payload)
+	}
+	if written[0].Status != model.WebhookDeliveryPending {
+		t.Errorf("webhookService.Dispatch() status = %s", written[0].Status)
+	}
+}
+
+func Test_webhookService_Deliver(t *testing.T) {
+	ctx := context.Background()
+	sub := &model.WebhookSubscription{ID: 1, UserID: 1, URL: "https://example.com/hook", Secret: "secret", Active: true}
+
+	tests := []struct {
+		name          string
+		attempts      int
+		sub           *model.WebhookSubscription
+		claimErr      error
+		sender        stubSender
+		wantDelivered int64
+		wantStatus    string
+		wantAttempts  int
+		wantBackoff   time.Duration
+	}{
+		{
+			name:          "positif",
+			sub:           sub,
+			sender:        stubSender{status: 200},
+			wantDelivered: 1,
+			wantStatus:    model.WebhookDeliverySucceeded,
+			wantAttempts:  1,
+		},
+		{
+			name:         "negatif: retried with backoff",
+			attempts:     2,
+			sub:          sub,
+			sender:       stubSender{status: 500, err: errors.New("endpoint answered 500")},
+			wantStatus:   model.WebhookDeliveryPending,
+			wantAttempts: 3,
+			wantBackoff:  4 * webhookRetryBackoff,
+		},
+		{
+			name:         "negatif: dead after the last attempt",
+			attempts:     webhookMaxAttempts - 1,
+			sub:          sub,
+			sender:       stubSender{err: errors.New("connection refused")},
+			wantStatus:   model.WebhookDeliveryDead,
+			wantAttempts: webhookMaxAttempts,
+		},
+		{
+			name:       "negatif: subscription deleted",
+			sub:        &model.WebhookSubscription{ID: 1, UserID: 1, Active: false},
+			wantStatus: model.WebhookDeliveryDead,
+		},
+		{
+			name:     "negatif: claimed elsewhere",
+			sub:      sub,
+			claimErr: sql.ErrNoRows,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := model.WebhookDelivery{ID: 5, SubscriptionID: 1, EventType: model.WebhookEventTopUpCompleted, Payload: []byte(`{}`), Status: model.WebhookDeliveryPending, Attempts: tt.attempts}
+
+			var sent []webhook.Request
+			tt.sender.sent = &sent
+
+			mockRepo := mocks.WebhookRepository{}
+			mockRepo.On("ReadDueDeliveries", mock.Anything, mock.Anything, webhookBatchSize).Return([]model.WebhookDelivery{delivery}, nil)
+			mockRepo.On("ClaimDelivery", mock.Anything, delivery, mock.Anything, mock.Anything).Return(tt.claimErr)
+			mockRepo.On("ReadSubscriptionByID", mock.Anything, int64(1)).Return(tt.sub, nil)
+
+			var updated *model.WebhookDelivery
+			mockRepo.On("UpdateDelivery", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				d := args.Get(1).(model.WebhookDelivery)
+				updated = &d
+			}).Return(nil)
+
+			s := NewWebhookService(&mockRepo, tt.sender)
+
+			before := time.Now()
+			got, err := s.Deliver(ctx)
+			if err != nil {
+				t.Fatalf("webhookService.Deliver() error = %v", err)
+			}
+			if got != tt.wantDelivered {
+				t.Errorf("webhookService.Deliver() = %d, want %d", got, tt.wantDelivered)
+			}
+
+			if tt.claimErr != nil {
+				if updated != nil || len(sent) != 0 {
+					t.Errorf("webhookService.Deliver() attempted a delivery claimed elsewhere")
+				}
+				return
+			}
+
+			if updated == nil || updated.Status != tt.wantStatus || updated.Attempts != tt.wantAttempts {
+				t.Fatalf("webhookService.Deliver() recorded %+v, want %s after %d attempt(s)", updated, tt.wantStatus, tt.wantAttempts)
+			}
+			if len(sent) != tt.wantAttempts-tt.attempts {
+				t.Errorf("webhookService.Deliver() sent %d request(s)", len(sent))
+			}
+			if len(sent) > 0 && (sent[0].Secret != sub.Secret || sent[0].DeliveryID != delivery.ID) {
+				t.Errorf("webhookService.Deliver() sent %+v", sent[0])
+			}
+			if tt.wantBackoff > 0 && updated.NextAttemptAt.Before(before.Add(tt.wantBackoff)) {
+				t.Errorf("webhookService.Deliver() next attempt at %v, want %v later", updated.NextAttemptAt, tt.wantBackoff)
+			}
+		})
+	}
+}
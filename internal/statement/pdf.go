@@ -0,0 +1,123 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 portrait page laid out in the built-in Courier font, which every PDF
+// reader has, so nothing needs to be embedded.
+const (
+	pageWidth     = 595
+	pageHeight    = 842
+	pageMargin    = 40
+	fontSize      = 8
+	lineHeight    = 11
+	linesPerPage  = (pageHeight - 2*pageMargin) / lineHeight
+	charsPerLine  = (pageWidth - 2*pageMargin) * 10 / (fontSize * 6)
+	footerSpacing = 2
+)
+
+// page is the text lines of a single page, top to bottom.
+type page []string
+
+// writePDF lays out lines on as many pages as they need, with footer at the
+// bottom of every page, and writes them as a PDF document.
+func writePDF(lines []string, footer string) []byte {
+	perPage := linesPerPage - footerSpacing - 1
+
+	var pages []page
+	for len(lines) > 0 {
+		n := perPage
+		if n > len(lines) {
+			n = len(lines)
+		}
+		pages = append(pages, page(lines[:n]))
+		lines = lines[n:]
+	}
+	if len(pages) == 0 {
+		pages = append(pages, page{})
+	}
+
+	// Objects 1 and 2 are the catalog and the page tree, 3 is the font and
+	// every page takes two more: the page and its content stream.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	}
+
+	kids := make([]string, len(pages))
+	for i, p := range pages {
+		pageID := len(objects) + 1
+		kids[i] = fmt.Sprintf("%d 0 R", pageID)
+
+		content := pageContent(p, fmt.Sprintf("%s  page %d of %d", footer, i+1, len(pages)))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, pageID+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages))
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pageContent is the content stream drawing the lines of a page from the
+// top margin down, and footer on the bottom margin.
+func pageContent(p page, footer string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, lineHeight, pageMargin, pageHeight-pageMargin)
+	for _, line := range p {
+		fmt.Fprintf(&b, "(%s) '\n", escapeText(line))
+	}
+	b.WriteString("ET\n")
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d %d Td\n(%s) Tj\nET", fontSize, pageMargin, pageMargin, escapeText(footer))
+
+	return b.String()
+}
+
+// escapeText makes s safe to put in a PDF string: it is cut to the width of
+// a line, the delimiters are escaped and anything outside printable ASCII is
+// replaced.
+func escapeText(s string) string {
+	var b strings.Builder
+
+	n := 0
+	for _, r := range s {
+		if n == charsPerLine {
+			break
+		}
+		n++
+
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,131 @@
+// Package statement renders account statements as CSV and PDF documents and
+// signs them. A statement's hash is an HMAC-SHA256 of its canonical CSV
+// body, so both renderings of the same statement carry the same hash and
+// only the server can issue one.
+package statement
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04:05"
+)
+
+var transactionColumns = []string{"date", "type", "reference", "description", "amount", "balance_after"}
+
+// Hash signs the content of s with secret. It does not depend on s.Hash or
+// s.GeneratedAt, so the same statement always gets the same hash.
+func Hash(secret string, s model.Statement) (string, error) {
+	body, err := canonical(s)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// CSV renders s as a summary block, the transactions under a header row and
+// a closing document_hash row.
+func CSV(s model.Statement) ([]byte, error) {
+	body, err := canonical(s)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(body)
+	w := csv.NewWriter(buf)
+	w.Write([]string{"document_hash", s.Hash})
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+// PDF renders s as a printable document with the hash in the footer of every
+// page.
+func PDF(s model.Statement) []byte {
+	lines := []string{
+		"ACCOUNT STATEMENT",
+		"",
+		fmt.Sprintf("Wallet        %s", s.Address),
+		fmt.Sprintf("Period        %s to %s", s.From.Format(dateLayout), lastDay(s).Format(dateLayout)),
+		fmt.Sprintf("Currency      %s", s.Currency),
+		fmt.Sprintf("Generated at  %s", s.GeneratedAt.Format(dateTimeLayout)),
+		"",
+		fmt.Sprintf("Opening balance  %18s", money(s.OpeningBalance)),
+		fmt.Sprintf("Total credits    %18s", money(s.TotalCredits)),
+		fmt.Sprintf("Total debits     %18s", money(s.TotalDebits)),
+		fmt.Sprintf("Total fees       %18s", money(s.TotalFees)),
+		fmt.Sprintf("Closing balance  %18s", money(s.ClosingBalance)),
+		"",
+		fmt.Sprintf("%-19s %-19s %-22s %15s %15s", "Date", "Type", "Reference", "Amount", "Balance"),
+	}
+
+	for _, trx := range s.Transactions {
+		lines = append(lines, fmt.Sprintf("%-19s %-19s %-22s %15s %15s",
+			trx.CreatedAt.Format(dateTimeLayout), trx.Type, trx.Reference, money(trx.Amount), money(trx.BalanceAfter)))
+		if trx.Description != "" {
+			lines = append(lines, "    "+trx.Description)
+		}
+	}
+
+	if len(s.Transactions) == 0 {
+		lines = append(lines, "No transactions in this period.")
+	}
+
+	return writePDF(lines, "Document hash "+s.Hash)
+}
+
+// canonical is the CSV rendering of s without its hash row, which is what
+// the hash is computed over.
+func canonical(s model.Statement) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	w.Write([]string{"wallet", s.Address})
+	w.Write([]string{"period", s.From.Format(dateLayout), lastDay(s).Format(dateLayout)})
+	w.Write([]string{"currency", s.Currency})
+	w.Write([]string{"opening_balance", money(s.OpeningBalance)})
+	w.Write([]string{"total_credits", money(s.TotalCredits)})
+	w.Write([]string{"total_debits", money(s.TotalDebits)})
+	w.Write([]string{"total_fees", money(s.TotalFees)})
+	w.Write([]string{"closing_balance", money(s.ClosingBalance)})
+	w.Write(transactionColumns)
+
+	for _, trx := range s.Transactions {
+		w.Write([]string{
+			trx.CreatedAt.Format(dateTimeLayout),
+			trx.Type,
+			trx.Reference,
+			trx.Description,
+			money(trx.Amount),
+			money(trx.BalanceAfter),
+		})
+	}
+
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+// lastDay is the last day a statement covers; its To is exclusive.
+func lastDay(s model.Statement) time.Time {
+	return s.To.AddDate(0, 0, -1)
+}
+
+func money(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,136 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cecepsprd/starworks-test/internal/model"
+)
+
+func testStatement(transactions int) model.Statement {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	s := model.Statement{
+		Address:        "wallet-address",
+		Month:          "2026-03",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		Currency:       model.CurrencyIDR,
+		OpeningBalance: 100000,
+		GeneratedAt:    time.Now(),
+	}
+
+	balance := s.OpeningBalance
+	for i := 0; i < transactions; i++ {
+		balance -= 1000
+		s.Transactions = append(s.Transactions, model.Transaction{
+			ID: int64(i + 1), Type: model.TransactionTypePayment, Amount: -1000, BalanceAfter: balance,
+			Reference: fmt.Sprintf("PAY-%d", i), Description: "payment (coffee)", CreatedAt: from.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	s.TotalDebits = 1000 * float64(transactions)
+	s.ClosingBalance = balance
+
+	return s
+}
+
+func TestHash(t *testing.T) {
+	s := testStatement(3)
+
+	hash, err := Hash("secret", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.GeneratedAt = s.GeneratedAt.Add(time.Hour)
+	s.Hash = "anything"
+	if again, _ := Hash("secret", s); again != hash {
+		t.Errorf("Hash() of the same statement = %s, want %s", again, hash)
+	}
+
+	if other, _ := Hash("other secret", s); other == hash {
+		t.Error("Hash() with another secret gave the same hash")
+	}
+
+	s.Transactions[1].Amount = -10
+	if tampered, _ := Hash("secret", s); tampered == hash {
+		t.Error("Hash() of a changed statement gave the same hash")
+	}
+}
+
+func TestCSV(t *testing.T) {
+	s := testStatement(2)
+	s.Hash, _ = Hash("secret", s)
+
+	got, err := CSV(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+	for _, want := range []string{"opening_balance,100000.00", "closing_balance,98000.00", "date,type,reference,description,amount,balance_after"} {
+		if !strings.Contains(string(got), want+"\n") {
+			t.Errorf("CSV() has no line %q", want)
+		}
+	}
+	if last := lines[len(lines)-1]; last != "document_hash,"+s.Hash {
+		t.Errorf("CSV() last line = %q, want the document hash", last)
+	}
+	// The summary and header take nine lines, then the transactions and
+	// the hash.
+	if want := 9 + len(s.Transactions) + 1; len(lines) != want {
+		t.Errorf("CSV() has %d lines, want %d", len(lines), want)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions int
+		wantPages    int
+	}{
+		{
+			name:      "no transactions",
+			wantPages: 1,
+		},
+		{
+			name:         "several pages",
+			transactions: 100,
+			wantPages:    4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testStatement(tt.transactions)
+			s.Hash, _ = Hash("secret", s)
+
+			got := PDF(s)
+
+			if !bytes.HasPrefix(got, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(got, []byte("%%EOF\n")) {
+				t.Fatal("PDF() is not framed as a PDF document")
+			}
+			if n := bytes.Count(got, []byte("/Type /Page ")); n != tt.wantPages {
+				t.Errorf("PDF() has %d pages, want %d", n, tt.wantPages)
+			}
+			if n := bytes.Count(got, []byte("Document hash "+s.Hash)); n != tt.wantPages {
+				t.Errorf("PDF() has the hash on %d pages, want %d", n, tt.wantPages)
+			}
+
+			// Every cross-reference entry must point at its object.
+			xref := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(got)
+			start, _ := strconv.Atoi(string(xref[1]))
+			entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(got[start:], -1)
+			for i, entry := range entries {
+				offset, _ := strconv.Atoi(string(entry[1]))
+				if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(got[offset:], []byte(want)) {
+					t.Errorf("PDF() xref entry %d does not point at %q", i+1, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func Test_localBlobStore_PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalBlobStore(t.TempDir())
+
+	if err := store.Put(ctx, "kyc/1/id_card.png", strings.NewReader("content")); err != nil {
+		t.Fatalf("localBlobStore.Put() error = %v", err)
+	}
+
+	r, err := store.Get(ctx, "kyc/1/id_card.png")
+	if err != nil {
+		t.Fatalf("localBlobStore.Get() error = %v", err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+
+	if string(got) != "content" {
+		t.Errorf("localBlobStore.Get() = %s, want %s", got, "content")
+	}
+
+	if err := store.Delete(ctx, "kyc/1/id_card.png"); err != nil {
+		t.Fatalf("localBlobStore.Delete() error = %v", err)
+	}
+
+	if _, err := store.Get(ctx, "kyc/1/id_card.png"); err == nil {
+		t.Errorf("localBlobStore.Get() after delete should fail")
+	}
+}
+
+func Test_localBlobStore_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalBlobStore(t.TempDir())
+
+	tests := []string{"", "/etc/passwd", "../outside", "kyc/../../outside"}
+	for _, key := range tests {
+		t.Run(key, func(t *testing.T) {
+			if err := store.Put(ctx, key, strings.NewReader("x")); err != ErrInvalidKey {
+				t.Errorf("localBlobStore.Put() error = %v, want %v", err, ErrInvalidKey)
+			}
+		})
+	}
+}
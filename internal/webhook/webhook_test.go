@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSender_Send(t *testing.T) {
+	const secret = "secret"
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify(secret, r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != "top_up.completed" || r.Header.Get(DeliveryHeader) != "7" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	var (
+		ctx    = context.Background()
+		sender = NewHTTPSender()
+	)
+
+	tests := []struct {
+		name       string
+		req        Request
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			name:       "positif",
+			req:        Request{URL: receiver.URL, Secret: secret, DeliveryID: 7, EventType: "top_up.completed", Body: []byte(`{"id":"1"}`)},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "negatif: wrong secret",
+			req:        Request{URL: receiver.URL, Secret: "other", DeliveryID: 7, EventType: "top_up.completed", Body: []byte(`{"id":"1"}`)},
+			wantStatus: http.StatusUnauthorized,
+			wantErr:    true,
+		},
+		{
+			name:       "negatif: rejected",
+			req:        Request{URL: receiver.URL + "/gone", Secret: secret, DeliveryID: 7, EventType: "top_up.completed", Body: []byte(`{"id":"1"}`)},
+			wantStatus: http.StatusGone,
+			wantErr:    true,
+		},
+		{
+			name:    "negatif: unreachable",
+			req:     Request{URL: "http://127.0.0.1:0", Secret: secret},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := sender.Send(ctx, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.wantStatus {
+				t.Errorf("Send() status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signature := Sign("secret", "1700000000", body)
+
+	if !Verify("secret", "1700000000", body, signature) {
+		t.Error("Verify() rejected a valid signature")
+	}
+	if Verify("secret", "1700000001", body, signature) {
+		t.Error("Verify() accepted a signature for another timestamp")
+	}
+	if Verify("", "1700000000", body, Sign("", "1700000000", body)) {
+		t.Error("Verify() accepted an empty secret")
+	}
+}
@@ -11,8 +11,11 @@ CREATE TABLE `user` (
   `username` varchar(45) NOT NULL,
   `password` varchar(255) NOT NULL,
   `token` varchar(255),
+  `role` varchar(16) NOT NULL DEFAULT 'user',
+  `kyc_level` varchar(16) NOT NULL DEFAULT 'unverified',
   `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `deleted_at` datetime,
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=latin1
 
@@ -23,10 +26,18 @@ CREATE TABLE `wallet` (
   `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `user_id` bigint,
+  `version` bigint NOT NULL DEFAULT 0,
+  `status` varchar(16) NOT NULL DEFAULT 'active',
+  `block_credits` tinyint(1) NOT NULL DEFAULT 0,
+  `kind` varchar(16) NOT NULL DEFAULT 'personal',
   FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=latin1
 
+INSERT INTO `wallet` (`id`, `address`, `balance`, `user_id`, `kind`) VALUES (1, 'system-revenue', 0, NULL, 'system')
+
+INSERT INTO `wallet` (`id`, `address`, `balance`, `user_id`, `kind`) VALUES (2, 'system-escrow', 0, NULL, 'system')
+
 CREATE TABLE `login_history` (
   `id` bigint NOT NULL AUTO_INCREMENT,
   `browser_name` varchar(255) NOT NULL,
@@ -37,4 +48,522 @@ CREATE TABLE `login_history` (
   `user_id` bigint,
   FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
   PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `wallet_transaction` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `wallet_id` bigint NOT NULL,
+  `type` varchar(32) NOT NULL,
+  `amount` bigint NOT NULL,
+  `balance_after` bigint NOT NULL,
+  `reference` varchar(64) NOT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  KEY (`reference`),
+  KEY (`wallet_id`, `type`, `created_at`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `account_deletion` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `scheduled_at` datetime NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  KEY (`status`, `scheduled_at`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `kyc_document` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `document_type` varchar(32) NOT NULL,
+  `requested_level` varchar(16) NOT NULL,
+  `storage_key` varchar(255) NOT NULL,
+  `content_type` varchar(64) NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `reviewer_id` bigint,
+  `review_note` varchar(255) NOT NULL DEFAULT '',
+  `reviewed_at` datetime,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`reviewer_id`) REFERENCES `user`(`id`),
+  KEY (`status`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `limit_rule` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL,
+  `operation` varchar(32) NOT NULL,
+  `type` varchar(16) NOT NULL,
+  `threshold` bigint NOT NULL,
+  `window_seconds` bigint NOT NULL DEFAULT 0,
+  `user_id` bigint,
+  `active` tinyint(1) NOT NULL DEFAULT 1,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  KEY (`operation`, `active`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `held_transaction` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `wallet_id` bigint NOT NULL,
+  `operation` varchar(32) NOT NULL,
+  `amount` bigint NOT NULL,
+  `payload` text NOT NULL,
+  `score` int NOT NULL,
+  `reasons` varchar(255) NOT NULL DEFAULT '',
+  `status` varchar(16) NOT NULL,
+  `reviewer_id` bigint,
+  `reviewed_at` datetime,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  KEY (`status`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `merchant` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `name` varchar(128) NOT NULL,
+  `wallet_id` bigint NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  UNIQUE KEY (`wallet_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `wallet_hold` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `wallet_id` bigint NOT NULL,
+  `merchant_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `captured_amount` bigint NOT NULL DEFAULT 0,
+  `status` varchar(16) NOT NULL,
+  `reference` varchar(64) NOT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  `expires_at` datetime NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  FOREIGN KEY (`merchant_id`) REFERENCES `merchant`(`id`),
+  KEY (`wallet_id`, `status`, `expires_at`),
+  KEY (`status`, `expires_at`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `refund` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `transaction_id` bigint NOT NULL,
+  `refund_transaction_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `reason` varchar(255) NOT NULL,
+  `created_by` bigint NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  FOREIGN KEY (`refund_transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  FOREIGN KEY (`created_by`) REFERENCES `user`(`id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `merchant_payment` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `merchant_id` bigint NOT NULL,
+  `transaction_id` bigint NOT NULL,
+  `credit_transaction_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `reference` varchar(64) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`merchant_id`) REFERENCES `merchant`(`id`),
+  FOREIGN KEY (`transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  FOREIGN KEY (`credit_transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  UNIQUE KEY (`transaction_id`),
+  KEY (`merchant_id`, `id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `fee_rule` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL,
+  `operation` varchar(32) NOT NULL,
+  `type` varchar(16) NOT NULL,
+  `flat` bigint NOT NULL DEFAULT 0,
+  `percentage` decimal(5,2) NOT NULL DEFAULT 0,
+  `tiers` text,
+  `min_fee` bigint NOT NULL DEFAULT 0,
+  `max_fee` bigint NOT NULL DEFAULT 0,
+  `merchant_id` bigint,
+  `active` tinyint(1) NOT NULL DEFAULT 1,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`merchant_id`) REFERENCES `merchant`(`id`),
+  KEY (`operation`, `active`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `bill` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `organizer_id` bigint NOT NULL,
+  `wallet_id` bigint NOT NULL,
+  `title` varchar(128) NOT NULL,
+  `amount` bigint NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`organizer_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  KEY (`organizer_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `payment_request` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `code` varchar(16) NOT NULL,
+  `requester_id` bigint NOT NULL,
+  `wallet_id` bigint NOT NULL,
+  `payer_id` bigint,
+  `bill_id` bigint,
+  `amount` bigint NOT NULL,
+  `currency` char(3) NOT NULL DEFAULT 'IDR',
+  `description` varchar(255) NOT NULL DEFAULT '',
+  `status` varchar(16) NOT NULL,
+  `transaction_id` bigint,
+  `paid_at` datetime,
+  `reminders` int NOT NULL DEFAULT 0,
+  `reminded_at` datetime,
+  `expires_at` datetime NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`requester_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  FOREIGN KEY (`payer_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`bill_id`) REFERENCES `bill`(`id`),
+  FOREIGN KEY (`transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  UNIQUE KEY (`code`),
+  KEY (`requester_id`),
+  KEY (`payer_id`),
+  KEY (`bill_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `payment_schedule` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `merchant_id` bigint,
+  `recipient_id` bigint,
+  `amount` bigint NOT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  `frequency` varchar(16) NOT NULL,
+  `start_at` datetime NOT NULL,
+  `end_at` datetime,
+  `next_run_at` datetime NOT NULL,
+  `due_at` datetime NOT NULL,
+  `occurrences` int NOT NULL DEFAULT 0,
+  `attempts` int NOT NULL DEFAULT 0,
+  `last_error` varchar(255) NOT NULL DEFAULT '',
+  `status` varchar(16) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`merchant_id`) REFERENCES `merchant`(`id`),
+  FOREIGN KEY (`recipient_id`) REFERENCES `user`(`id`),
+  KEY (`user_id`),
+  KEY (`status`, `due_at`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `payment_schedule_run` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `schedule_id` bigint NOT NULL,
+  `scheduled_for` datetime NOT NULL,
+  `attempt` int NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `transaction_id` bigint,
+  `error` varchar(255) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`schedule_id`) REFERENCES `payment_schedule`(`id`),
+  FOREIGN KEY (`transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  UNIQUE KEY (`schedule_id`, `scheduled_for`, `attempt`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `escrow` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `buyer_id` bigint NOT NULL,
+  `buyer_wallet_id` bigint NOT NULL,
+  `merchant_id` bigint NOT NULL,
+  `escrow_wallet_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `timeout_action` varchar(16) NOT NULL,
+  `reference` varchar(64) NOT NULL,
+  `description` varchar(255) NOT NULL DEFAULT '',
+  `expires_at` datetime NOT NULL,
+  `resolved_at` datetime,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`buyer_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`buyer_wallet_id`) REFERENCES `wallet`(`id`),
+  FOREIGN KEY (`merchant_id`) REFERENCES `merchant`(`id`),
+  FOREIGN KEY (`escrow_wallet_id`) REFERENCES `wallet`(`id`),
+  UNIQUE KEY (`reference`),
+  KEY (`buyer_id`),
+  KEY (`merchant_id`),
+  KEY (`status`, `expires_at`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `escrow_event` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `escrow_id` bigint NOT NULL,
+  `from_status` varchar(16) NOT NULL DEFAULT '',
+  `to_status` varchar(16) NOT NULL,
+  `actor_id` bigint,
+  `transaction_id` bigint,
+  `note` varchar(255) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`escrow_id`) REFERENCES `escrow`(`id`),
+  FOREIGN KEY (`actor_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  KEY (`escrow_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `bank_account` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `bank_code` varchar(16) NOT NULL,
+  `account_number` varchar(20) NOT NULL,
+  `account_name` varchar(64) NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `deleted_at` datetime,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  KEY (`user_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `payout` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `wallet_id` bigint NOT NULL,
+  `bank_account_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `currency` char(3) NOT NULL DEFAULT 'IDR',
+  `status` varchar(16) NOT NULL,
+  `reference` varchar(64) NOT NULL,
+  `provider_reference` varchar(64) NOT NULL DEFAULT '',
+  `failure_reason` varchar(255) NOT NULL DEFAULT '',
+  `transaction_id` bigint NOT NULL,
+  `reversal_transaction_id` bigint,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  FOREIGN KEY (`bank_account_id`) REFERENCES `bank_account`(`id`),
+  FOREIGN KEY (`transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  FOREIGN KEY (`reversal_transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  UNIQUE KEY (`reference`),
+  KEY (`user_id`),
+  KEY (`status`, `updated_at`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `topup_intent` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `wallet_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `currency` char(3) NOT NULL DEFAULT 'IDR',
+  `status` varchar(16) NOT NULL,
+  `reference` varchar(64) NOT NULL,
+  `gateway_reference` varchar(64) NOT NULL DEFAULT '',
+  `redirect_url` varchar(255) NOT NULL DEFAULT '',
+  `failure_reason` varchar(255) NOT NULL DEFAULT '',
+  `transaction_id` bigint,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  FOREIGN KEY (`transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  UNIQUE KEY (`reference`),
+  KEY (`user_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `statement` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `wallet_id` bigint NOT NULL,
+  `month` char(7) NOT NULL,
+  `opening_balance` bigint NOT NULL,
+  `closing_balance` bigint NOT NULL,
+  `transaction_count` int NOT NULL,
+  `hash` char(64) NOT NULL,
+  `issued_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  UNIQUE KEY (`hash`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `webhook_subscription` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `user_id` bigint NOT NULL,
+  `url` varchar(2048) NOT NULL,
+  `event_types` varchar(255) NOT NULL,
+  `all_users` tinyint(1) NOT NULL DEFAULT 0,
+  `secret` varchar(64) NOT NULL,
+  `active` tinyint(1) NOT NULL DEFAULT 1,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`),
+  KEY (`user_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+
+CREATE TABLE `webhook_delivery` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `subscription_id` bigint NOT NULL,
+  `event_id` varchar(64) NOT NULL,
+  `event_type` varchar(32) NOT NULL,
+  `payload` text NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `next_attempt_at` datetime NOT NULL,
+  `last_error` varchar(255) NOT NULL DEFAULT '',
+  `response_status` int NOT NULL DEFAULT 0,
+  `delivered_at` datetime,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`subscription_id`) REFERENCES `webhook_subscription`(`id`),
+  KEY (`status`, `next_attempt_at`),
+  KEY (`subscription_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `outbox` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `event_id` varchar(64) NOT NULL,
+  `event_type` varchar(32) NOT NULL,
+  `event_key` varchar(64) NOT NULL,
+  `payload` text NOT NULL,
+  `attempts` int NOT NULL DEFAULT 0,
+  `next_attempt_at` datetime NOT NULL,
+  `last_error` varchar(255) NOT NULL DEFAULT '',
+  `published_at` datetime,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY (`event_id`),
+  KEY (`published_at`, `next_attempt_at`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+
+CREATE TABLE `wallet_event` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `wallet_id` bigint NOT NULL,
+  `version` bigint NOT NULL,
+  `type` varchar(32) NOT NULL,
+  `data` text NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY (`wallet_id`, `version`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+
+CREATE TABLE `wallet_snapshot` (
+  `wallet_id` bigint NOT NULL,
+  `version` bigint NOT NULL,
+  `state` text NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`wallet_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `wallet_status_change` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `wallet_id` bigint NOT NULL,
+  `from_status` varchar(16) NOT NULL,
+  `to_status` varchar(16) NOT NULL,
+  `block_credits` tinyint(1) NOT NULL DEFAULT 0,
+  `reason` varchar(32) NOT NULL,
+  `note` varchar(255) NOT NULL DEFAULT '',
+  `changed_by` bigint NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  KEY (`wallet_id`, `id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `wallet_adjustment` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `wallet_id` bigint NOT NULL,
+  `transaction_id` bigint NOT NULL,
+  `amount` bigint NOT NULL,
+  `reason` varchar(255) NOT NULL,
+  `adjusted_by` bigint NOT NULL,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`wallet_id`) REFERENCES `wallet`(`id`),
+  FOREIGN KEY (`transaction_id`) REFERENCES `wallet_transaction`(`id`),
+  KEY (`adjusted_by`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `audit_log` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `seq` bigint,
+  `action` varchar(64) NOT NULL,
+  `actor_id` bigint NOT NULL DEFAULT 0,
+  `target_type` varchar(32) NOT NULL,
+  `target_id` bigint NOT NULL DEFAULT 0,
+  `ip` varchar(64) NOT NULL DEFAULT '',
+  `user_agent` varchar(255) NOT NULL DEFAULT '',
+  `before_value` text NOT NULL,
+  `after_value` text NOT NULL,
+  `prev_hash` char(64) NOT NULL DEFAULT '',
+  `hash` char(64) NOT NULL DEFAULT '',
+  `created_at` datetime NOT NULL,
+  UNIQUE KEY (`seq`),
+  KEY (`action`, `created_at`),
+  KEY (`actor_id`, `created_at`),
+  KEY (`target_type`, `target_id`),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `audit_head` (
+  `id` tinyint NOT NULL,
+  `seq` bigint NOT NULL DEFAULT 0,
+  `hash` char(64) NOT NULL DEFAULT '',
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+
+CREATE TABLE `pending_action` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `type` varchar(32) NOT NULL,
+  `target_id` bigint NOT NULL DEFAULT 0,
+  `payload` text NOT NULL,
+  `status` varchar(16) NOT NULL DEFAULT 'pending',
+  `proposed_by` bigint NOT NULL,
+  `reviewed_by` bigint,
+  `review_note` varchar(255) NOT NULL DEFAULT '',
+  `result` text,
+  `expires_at` datetime NOT NULL,
+  `reviewed_at` datetime,
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  KEY (`status`, `expires_at`),
+  KEY (`proposed_by`),
+  PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=latin1
\ No newline at end of file
@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 
 	cs "github.com/cecepsprd/starworks-test/constans"
 	"github.com/cecepsprd/starworks-test/internal/model"
@@ -32,6 +36,38 @@ func MappingInterface(request interface{}, model interface{}) error {
 	return nil
 }
 
+// ZipJSON encodes every value as indented JSON and packs them into a zip
+// archive, one file per map key.
+func ZipJSON(files map[string]interface{}) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	for _, name := range names {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(files[name]); err != nil {
+			return nil, fmt.Errorf("error encode %s: %s", name, err.Error())
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 func HashPassword(password string) (hashedString string, err error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
 	if err != nil {
@@ -49,6 +85,33 @@ func GenerateEncryptedAddress(username, email string) string {
 	return encryptedAddress
 }
 
+// GenerateReference returns a random identifier used to group the ledger
+// entries that belong to a single money movement.
+func GenerateReference() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logger.Log.Error(err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// GenerateShortCode returns a random code of n characters that is easy to
+// read out and type, for links meant to be shared.
+func GenerateShortCode(n int) string {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		logger.Log.Error(err.Error())
+	}
+
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+
+	return string(b)
+}
+
 func GetUserIDByContext(ctx echo.Context) int64 {
 	u := ctx.Get("user")
 	claims := u.(*jwt.Token).Claims.(*model.JwtCustomClaims)
@@ -62,6 +125,7 @@ func GetUserByContext(ctx echo.Context) model.User {
 		ID:       claims.UserID,
 		Username: claims.Username,
 		Email:    claims.Email,
+		Role:     claims.Role,
 	}
 }
 
@@ -95,6 +159,38 @@ func SetHTTPStatusCode(err error) int {
 	// return http.StatusConflict
 	case cs.ErrWrongEmailOrPassword.Error():
 		return http.StatusBadRequest
+	case cs.ErrBalanceNotZero.Error(), cs.ErrWalletNotEmpty.Error():
+		return http.StatusBadRequest
+	case cs.ErrDeletionRequested.Error():
+		return http.StatusConflict
+	case cs.ErrForbidden.Error():
+		return http.StatusForbidden
+	case cs.ErrUnsupportedDocument.Error(), cs.ErrKYCLevelReached.Error():
+		return http.StatusBadRequest
+	case cs.ErrDocumentReviewed.Error(), cs.ErrHeldTransactionReviewed.Error(), cs.ErrHoldNotActive.Error():
+		return http.StatusConflict
+	case cs.ErrPaymentRequestNotActive.Error(), cs.ErrScheduleNotActive.Error(), cs.ErrBillNotActive.Error(), cs.ErrEscrowNotFunded.Error(), cs.ErrPayoutFinalized.Error(), cs.ErrTopUpFinalized.Error():
+		return http.StatusConflict
+	case cs.ErrInvalidSignature.Error(), cs.ErrAccountDeleted.Error():
+		return http.StatusUnauthorized
+	case cs.ErrConcurrentUpdate.Error(), cs.ErrWalletStatusTransition.Error():
+		return http.StatusConflict
+	case cs.ErrWalletFrozen.Error(), cs.ErrWalletClosed.Error():
+		return http.StatusForbidden
+	case cs.ErrTransactionDenied.Error():
+		return http.StatusForbidden
+	case cs.ErrApprovalRequired.Error(), cs.ErrSelfApproval.Error():
+		return http.StatusForbidden
+	case cs.ErrPendingActionReviewed.Error(), cs.ErrPendingActionExpired.Error():
+		return http.StatusConflict
+	case cs.ErrInsufficientBalance.Error(), cs.ErrBalanceLimitExceeded.Error(), cs.ErrDailyLimitExceeded.Error(), cs.ErrCaptureExceedsHold.Error():
+		return http.StatusBadRequest
+	case cs.ErrNotRefundable.Error(), cs.ErrRefundExceedsPayment.Error(), cs.ErrMerchantNotActive.Error():
+		return http.StatusBadRequest
+	case cs.ErrFeeExceedsAmount.Error(), cs.ErrInvalidQRPayload.Error():
+		return http.StatusBadRequest
+	case cs.ErrBadParamInput.Error():
+		return http.StatusBadRequest
 	default:
 		return http.StatusInternalServerError
 	}